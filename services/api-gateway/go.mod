@@ -21,4 +21,6 @@ require (
     github.com/iceber/iouring-go v0.0.0-20230403020409-002cfd2e2a90
     github.com/google/flatbuffers v23.5.26+incompatible
     github.com/lirm/aeron-go v1.0.8
+    github.com/DATA-DOG/go-sqlmock v1.5.2
+    github.com/stretchr/testify v1.8.4
 )
\ No newline at end of file
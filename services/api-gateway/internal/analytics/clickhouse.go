@@ -2,17 +2,27 @@ package analytics
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
+	"log"
 	"time"
 
+	"tradecaptain/api-gateway/internal/services"
+
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
 // ClickHouseClient provides ultra-fast analytical queries for financial data
 type ClickHouseClient struct {
-	conn driver.Conn
+	conn        driver.Conn
+	instruments InstrumentResolver
+}
+
+// InstrumentResolver looks symbols up in the instrument master the data
+// collector maintains in Postgres, returning the primary exchange and sector
+// of those it lists; services.InstrumentClassifier implements it
+type InstrumentResolver interface {
+	Classify(ctx context.Context, symbols []string) (map[string]services.InstrumentClass, error)
 }
 
 // NewClickHouseClient creates a new ClickHouse client
@@ -49,6 +59,13 @@ func NewClickHouseClient(host string, database string, username string, password
 	return &ClickHouseClient{conn: conn}, nil
 }
 
+// UseInstruments makes BatchInsertMarketAnalytics take exchange and sector
+// from the instrument master, so GetSectorPerformance groups every symbol
+// the master classifies whatever the rows were built with
+func (c *ClickHouseClient) UseInstruments(instruments InstrumentResolver) {
+	c.instruments = instruments
+}
+
 // MarketAnalytics represents aggregated market data for analytics
 type MarketAnalytics struct {
 	Symbol          string    `ch:"symbol"`
@@ -102,7 +119,22 @@ func (c *ClickHouseClient) BatchInsertMarketAnalytics(data []MarketAnalytics) er
 		return fmt.Errorf("failed to prepare batch: %w", err)
 	}
 
+	var classes map[string]services.InstrumentClass
+	if c.instruments != nil && len(data) > 0 {
+		symbols := make([]string, len(data))
+		for i, item := range data {
+			symbols[i] = item.Symbol
+		}
+		// Without the master the rows keep the exchange and sector they were built with
+		if classes, err = c.instruments.Classify(ctx, symbols); err != nil {
+			log.Printf("Failed to classify %d analytics rows through the instrument master: %v", len(data), err)
+		}
+	}
+
 	for _, item := range data {
+		if class, ok := classes[item.Symbol]; ok {
+			item.Exchange, item.Sector = class.Exchange, class.Sector
+		}
 		err := batch.Append(
 			item.Symbol,
 			item.Date,
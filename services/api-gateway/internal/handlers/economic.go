@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"tradecaptain/api-gateway/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// yieldCurveTenors are the Treasury par yield curve maturities in months,
+// shortest first, as the collector stores them
+var yieldCurveTenors = []struct {
+	Tenor  string
+	Months int
+}{
+	{"1M", 1}, {"2M", 2}, {"3M", 3}, {"4M", 4}, {"6M", 6},
+	{"1Y", 12}, {"2Y", 24}, {"3Y", 36}, {"5Y", 60}, {"7Y", 84},
+	{"10Y", 120}, {"20Y", 240}, {"30Y", 360},
+}
+
+// yieldCurveSpreads are the spreads reported with a curve, long minus short
+var yieldCurveSpreads = []struct {
+	Name  string
+	Short string
+	Long  string
+}{
+	{"2s10s", "2Y", "10Y"},
+	{"3m10y", "3M", "10Y"},
+}
+
+type EconomicHandler struct {
+	economicService *services.EconomicService
+}
+
+func NewEconomicHandler(economicService *services.EconomicService) *EconomicHandler {
+	return &EconomicHandler{
+		economicService: economicService,
+	}
+}
+
+// GetYieldCurve godoc
+// @Summary Get the Treasury yield curve
+// @Description Retrieve the Treasury par yield curve in effect on a date: that day's curve, or the last one before it on weekends and holidays
+// @Tags economic
+// @Accept json
+// @Produce json
+// @Param date query string false "Date (YYYY-MM-DD), today if omitted"
+// @Success 200 {object} YieldCurveResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /economic/yield-curve [get]
+func (h *EconomicHandler) GetYieldCurve(c *gin.Context) {
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if raw := c.Query("date"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_date",
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("date %q is not YYYY-MM-DD", raw),
+			})
+			return
+		}
+		date = parsed
+	}
+
+	curve, err := h.economicService.GetYieldCurve(c.Request.Context(), date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Code:    http.StatusInternalServerError,
+			Message: "failed to load yield curve",
+		})
+		return
+	}
+	if curve == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Code:    http.StatusNotFound,
+			Message: "no yield curve on or before " + date.Format("2006-01-02"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, newYieldCurveResponse(curve, date))
+}
+
+// newYieldCurveResponse orders the curve's points by maturity and adds the
+// standard spreads the curve quotes both tenors of
+func newYieldCurveResponse(curve *services.YieldCurve, requested time.Time) YieldCurveResponse {
+	response := YieldCurveResponse{
+		Date:          curve.Date.Format("2006-01-02"),
+		RequestedDate: requested.Format("2006-01-02"),
+		Points:        []YieldCurvePoint{},
+		Spreads:       map[string]float64{},
+		Source:        curve.Source,
+	}
+	for _, tenor := range yieldCurveTenors {
+		if yield, ok := curve.Yields[tenor.Tenor]; ok {
+			response.Points = append(response.Points, YieldCurvePoint{Tenor: tenor.Tenor, Months: tenor.Months, Yield: yield})
+		}
+	}
+	for _, spread := range yieldCurveSpreads {
+		short, okShort := curve.Yields[spread.Short]
+		long, okLong := curve.Yields[spread.Long]
+		if !okShort || !okLong {
+			continue
+		}
+		value := math.Round((long-short)*100) / 100
+		response.Spreads[spread.Name] = value
+		if value < 0 {
+			response.Inverted = append(response.Inverted, spread.Name)
+		}
+	}
+	return response
+}
+
+// Response types for API documentation
+type YieldCurveResponse struct {
+	Date          string             `json:"date"` // the business day the curve is from
+	RequestedDate string             `json:"requested_date"`
+	Points        []YieldCurvePoint  `json:"points"`
+	Spreads       map[string]float64 `json:"spreads"` // percentage points, e.g. "2s10s"
+	Inverted      []string           `json:"inverted,omitempty"`
+	Source        string             `json:"source"`
+}
+
+type YieldCurvePoint struct {
+	Tenor  string  `json:"tenor"`
+	Months int     `json:"months"`
+	Yield  float64 `json:"yield"` // percent
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"tradecaptain/api-gateway/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEconomicRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewEconomicHandler(services.NewEconomicService(db))
+	router.GET("/economic/yield-curve", handler.GetYieldCurve)
+	return router, mock
+}
+
+var yieldCurveColumns = []string{"date", "source", "updated_at",
+	"y_1m", "y_2m", "y_3m", "y_4m", "y_6m", "y_1y", "y_2y", "y_3y", "y_5y", "y_7y", "y_10y", "y_20y", "y_30y"}
+
+func TestGetYieldCurve(t *testing.T) {
+	router, mock := newTestEconomicRouter(t)
+	friday := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// A Saturday gets Friday's curve; the 4M tenor was not quoted that day
+	mock.ExpectQuery("FROM treasury_yield_curves").
+		WithArgs(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows(yieldCurveColumns).AddRow(friday, "treasury", friday.Add(22*time.Hour),
+			5.53, 5.50, 5.46, nil, 5.33, 5.01, 4.53, 4.36, 4.18, 4.20, 4.18, 4.45, 4.33))
+
+	w := get(router, "/economic/yield-curve?date=2024-03-02")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var curve YieldCurveResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &curve))
+	assert.Equal(t, "2024-03-01", curve.Date)
+	assert.Equal(t, "2024-03-02", curve.RequestedDate)
+	assert.Equal(t, "treasury", curve.Source)
+	require.Len(t, curve.Points, 12)
+	assert.Equal(t, YieldCurvePoint{Tenor: "1M", Months: 1, Yield: 5.53}, curve.Points[0])
+	assert.Equal(t, YieldCurvePoint{Tenor: "6M", Months: 6, Yield: 5.33}, curve.Points[3])
+	assert.Equal(t, YieldCurvePoint{Tenor: "30Y", Months: 360, Yield: 4.33}, curve.Points[11])
+
+	// 10Y minus 2Y and 10Y minus 3M, both inverted
+	assert.Equal(t, map[string]float64{"2s10s": -0.35, "3m10y": -1.28}, curve.Spreads)
+	assert.Equal(t, []string{"2s10s", "3m10y"}, curve.Inverted)
+
+	// A normal 2s10s with the short end still inverted flags only 3m10y
+	mock.ExpectQuery("FROM treasury_yield_curves").
+		WillReturnRows(sqlmock.NewRows(yieldCurveColumns).AddRow(friday, "treasury", friday,
+			nil, nil, 4.80, nil, nil, nil, 3.90, nil, nil, nil, 4.10, nil, nil))
+	w = get(router, "/economic/yield-curve?date=2024-03-01")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &curve))
+	assert.Equal(t, map[string]float64{"2s10s": 0.2, "3m10y": -0.7}, curve.Spreads)
+	assert.Equal(t, []string{"3m10y"}, curve.Inverted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetYieldCurveErrors(t *testing.T) {
+	router, mock := newTestEconomicRouter(t)
+
+	w := get(router, "/economic/yield-curve?date=03/01/2024")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "invalid_date", response.Error)
+
+	// Nothing collected that far back
+	mock.ExpectQuery("FROM treasury_yield_curves").WillReturnRows(sqlmock.NewRows(yieldCurveColumns))
+	w = get(router, "/economic/yield-curve?date=1980-01-01")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "not_found", response.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
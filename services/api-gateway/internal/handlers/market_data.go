@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -79,6 +81,7 @@ func (h *MarketDataHandler) GetMultipleQuotes(c *gin.Context) {
 // @Param symbol path string true "Stock symbol"
 // @Param period query string false "Time period (1d, 5d, 1mo, 3mo, 6mo, 1y, 2y, 5y, 10y, ytd, max)" default(1mo)
 // @Param interval query string false "Data interval (1m, 2m, 5m, 15m, 30m, 60m, 90m, 1h, 1d, 5d, 1wk, 1mo, 3mo)" default(1d)
+// @Param adjustment query string false "Corporate action adjustment (raw, split_adjusted, total_return)" default(split_adjusted)
 // @Success 200 {array} models.MarketData
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -89,6 +92,8 @@ func (h *MarketDataHandler) GetHistoricalData(c *gin.Context) {
 	// - Extract symbol from URL parameter
 	// - Parse period and interval query parameters
 	// - Validate period and interval combinations
+	// - Parse adjustment (raw, split_adjusted or total_return) for the
+	//   collector's back-adjusted series
 	// - Convert period to start/end dates
 	// - Call market data service for historical data
 	// - Handle large datasets with pagination if needed
@@ -125,28 +130,77 @@ func (h *MarketDataHandler) GetIntradayData(c *gin.Context) {
 
 // SearchSymbols godoc
 // @Summary Search for symbols
-// @Description Search for stocks, ETFs, and other securities by name or symbol
+// @Description Search the instrument master for stocks, ETFs, and other securities by symbol, venue ticker, ISIN, CUSIP, FIGI or name
 // @Tags market-data
 // @Accept json
 // @Produce json
-// @Param q query string true "Search query (symbol or company name)"
-// @Param limit query int false "Maximum number of results" default(10)
+// @Param q query string true "Search query (symbol, identifier or company name)"
+// @Param limit query int false "Maximum number of results, at most 50" default(10)
 // @Success 200 {array} SymbolSearchResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /market/search [get]
 func (h *MarketDataHandler) SearchSymbols(c *gin.Context) {
-	// TODO: Implement symbol search endpoint
-	// - Extract search query from query parameter
-	// - Validate query length (minimum 1-2 characters)
-	// - Parse limit parameter with reasonable default and maximum
-	// - Call market data service for symbol search
-	// - Return search results with symbol, name, exchange, type
-	// - Include relevance scoring if available
-	// - Add search result caching for popular queries
-	// - Handle empty results gracefully
-	// - Log search queries for analytics
-	panic("TODO: Implement SearchSymbols handler")
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_query",
+			Code:    http.StatusBadRequest,
+			Message: "q is required",
+		})
+		return
+	}
+	limit := 10
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_limit",
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("limit %q is not a positive integer", raw),
+			})
+			return
+		}
+		limit = min(parsed, maxSearchResults)
+	}
+
+	// The instrument master ranks exact symbols, then identifiers and venue
+	// tickers, then symbol and name prefixes
+	matches, err := h.marketDataService.SearchInstruments(c.Request.Context(), query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Code:    http.StatusInternalServerError,
+			Message: "failed to search instruments",
+		})
+		return
+	}
+
+	results := make([]SymbolSearchResult, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, newSymbolSearchResult(match))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// maxSearchResults caps the limit a symbol search may ask for
+const maxSearchResults = 50
+
+func newSymbolSearchResult(match services.InstrumentMatch) SymbolSearchResult {
+	inst := match.Instrument
+	return SymbolSearchResult{
+		InstrumentID: inst.ID,
+		Symbol:       inst.Symbol,
+		Name:         inst.Name,
+		Exchange:     inst.Exchange,
+		Type:         inst.AssetClass,
+		Currency:     inst.Currency,
+		ISIN:         inst.ISIN,
+		FIGI:         inst.FIGI,
+		Sector:       inst.Sector,
+		Status:       inst.Status,
+		Relevance:    match.Relevance,
+	}
 }
 
 // GetMarketSummary godoc
@@ -199,28 +253,156 @@ func (h *MarketDataHandler) GetTechnicalIndicators(c *gin.Context) {
 
 // GetOptionChain godoc
 // @Summary Get options chain for a symbol
-// @Description Retrieve options chain data for calls and puts
+// @Description Retrieve the options chain for calls and puts with implied volatility and Greeks computed from mid prices, optionally filtered by moneyness or strike range
 // @Tags market-data
 // @Accept json
 // @Produce json
 // @Param symbol path string true "Underlying symbol"
-// @Param expiration query string false "Expiration date (YYYY-MM-DD)"
+// @Param expiration query string false "Expiration date (YYYY-MM-DD), the nearest if omitted"
+// @Param moneyness query string false "itm, atm (strike within 2% of the underlying) or otm"
+// @Param minStrike query number false "Lowest strike"
+// @Param maxStrike query number false "Highest strike"
 // @Success 200 {object} OptionsChainResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /market/options/{symbol} [get]
 func (h *MarketDataHandler) GetOptionChain(c *gin.Context) {
-	// TODO: Implement options chain endpoint
-	// - Extract underlying symbol and validate
-	// - Parse expiration date parameter
-	// - Call market data service for options data
-	// - Handle different expiration dates
-	// - Return calls and puts with strike prices
-	// - Include implied volatility and Greeks if available
-	// - Add filtering by moneyness or strike range
-	// - Handle cases where no options are available
-	panic("TODO: Implement GetOptionChain handler")
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+	badRequest := func(code, message string) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: code, Code: http.StatusBadRequest, Message: message})
+	}
+	if symbol == "" {
+		badRequest("invalid_symbol", "symbol is required")
+		return
+	}
+
+	var expiration time.Time
+	if raw := c.Query("expiration"); raw != "" {
+		parsed, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			badRequest("invalid_expiration", fmt.Sprintf("expiration %q is not YYYY-MM-DD", raw))
+			return
+		}
+		expiration = parsed
+	}
+
+	moneyness := strings.ToLower(c.Query("moneyness"))
+	switch moneyness {
+	case "", moneynessITM, moneynessATM, moneynessOTM:
+	default:
+		badRequest("invalid_moneyness", fmt.Sprintf("moneyness %q is not itm, atm or otm", moneyness))
+		return
+	}
+	var strikes [2]float64
+	for i, name := range []string{"minStrike", "maxStrike"} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 {
+			badRequest("invalid_strike", fmt.Sprintf("%s %q is not a positive number", name, raw))
+			return
+		}
+		strikes[i] = parsed
+	}
+	minStrike, maxStrike := strikes[0], strikes[1]
+	if maxStrike > 0 && minStrike > maxStrike {
+		badRequest("invalid_strike", "minStrike is above maxStrike")
+		return
+	}
+
+	// The collector stores the chains of the nearest expirations with
+	// implied volatility and Greeks already computed
+	chain, err := h.marketDataService.GetOptionChain(c.Request.Context(), symbol, expiration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Code:    http.StatusInternalServerError,
+			Message: "failed to load options chain",
+		})
+		return
+	}
+	if chain == nil {
+		message := "no options chain for " + symbol
+		if !expiration.IsZero() {
+			message += " expiring " + expiration.Format("2006-01-02")
+		}
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Code:    http.StatusNotFound,
+			Message: message,
+		})
+		return
+	}
+
+	response := OptionsChainResponse{
+		Symbol:          chain.Underlying,
+		Expiration:      chain.Expiration.Format("2006-01-02"),
+		UnderlyingPrice: chain.UnderlyingPrice,
+		Expirations:     make([]string, 0, len(chain.Expirations)),
+		Calls:           []OptionQuote{},
+		Puts:            []OptionQuote{},
+		Timestamp:       chain.Timestamp,
+	}
+	for _, listed := range chain.Expirations {
+		response.Expirations = append(response.Expirations, listed.Format("2006-01-02"))
+	}
+	for _, contract := range chain.Contracts {
+		if (minStrike > 0 && contract.Strike < minStrike) || (maxStrike > 0 && contract.Strike > maxStrike) {
+			continue
+		}
+		quote := newOptionQuote(contract, chain.UnderlyingPrice)
+		if moneyness != "" && quote.Moneyness != moneyness {
+			continue
+		}
+		if contract.Type == "put" {
+			response.Puts = append(response.Puts, quote)
+		} else {
+			response.Calls = append(response.Calls, quote)
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Moneyness of an option; a strike within atmBand of the underlying price,
+// as a fraction of it, is at the money, as in the collector
+const (
+	moneynessITM = "itm"
+	moneynessATM = "atm"
+	moneynessOTM = "otm"
+	atmBand      = 0.02
+)
+
+func newOptionQuote(contract services.OptionContract, underlyingPrice float64) OptionQuote {
+	quote := OptionQuote{
+		ContractSymbol:    contract.Symbol,
+		Strike:            contract.Strike,
+		LastPrice:         contract.Last,
+		Bid:               contract.Bid,
+		Ask:               contract.Ask,
+		Volume:            contract.Volume,
+		OpenInterest:      contract.OpenInterest,
+		ImpliedVolatility: contract.ImpliedVolatility,
+		Delta:             contract.Delta,
+		Gamma:             contract.Gamma,
+		Theta:             contract.Theta,
+		Vega:              contract.Vega,
+		Rho:               contract.Rho,
+	}
+	if underlyingPrice > 0 {
+		distance := (contract.Strike - underlyingPrice) / underlyingPrice
+		switch {
+		case math.Abs(distance) <= atmBand:
+			quote.Moneyness = moneynessATM
+		case (distance < 0) == (contract.Type == "call"):
+			quote.Moneyness = moneynessITM
+		default:
+			quote.Moneyness = moneynessOTM
+		}
+	}
+	return quote
 }
 
 // GetMarketStatus godoc
@@ -362,13 +544,21 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// SymbolSearchResult is an instrument master entry matching a search. Type is
+// its asset class (equity, etf, crypto, ...) and Status whether it is active,
+// suspended or delisted.
 type SymbolSearchResult struct {
-	Symbol      string `json:"symbol"`
-	Name        string `json:"name"`
-	Exchange    string `json:"exchange"`
-	Type        string `json:"type"`
-	Currency    string `json:"currency"`
-	Relevance   float64 `json:"relevance,omitempty"`
+	InstrumentID int64   `json:"instrumentId"`
+	Symbol       string  `json:"symbol"`
+	Name         string  `json:"name"`
+	Exchange     string  `json:"exchange"`
+	Type         string  `json:"type"`
+	Currency     string  `json:"currency"`
+	ISIN         string  `json:"isin,omitempty"`
+	FIGI         string  `json:"figi,omitempty"`
+	Sector       string  `json:"sector,omitempty"`
+	Status       string  `json:"status"`
+	Relevance    float64 `json:"relevance,omitempty"`
 }
 
 type TechnicalIndicatorsResponse struct {
@@ -378,26 +568,36 @@ type TechnicalIndicatorsResponse struct {
 	Timestamp  time.Time             `json:"timestamp"`
 }
 
+// OptionsChainResponse is one expiration of an underlying's options.
+// Expirations lists every expiration with a stored chain.
 type OptionsChainResponse struct {
-	Symbol      string         `json:"symbol"`
-	Expiration  string         `json:"expiration"`
-	Calls       []OptionQuote  `json:"calls"`
-	Puts        []OptionQuote  `json:"puts"`
-	Timestamp   time.Time      `json:"timestamp"`
+	Symbol          string        `json:"symbol"`
+	Expiration      string        `json:"expiration"`
+	UnderlyingPrice float64       `json:"underlyingPrice"`
+	Expirations     []string      `json:"expirations"`
+	Calls           []OptionQuote `json:"calls"`
+	Puts            []OptionQuote `json:"puts"`
+	Timestamp       time.Time     `json:"timestamp"`
 }
 
+// OptionQuote is a contract by its OCC symbol. Implied volatility is a
+// fraction; theta is per calendar day, vega and rho per percentage point.
+// They are omitted where no volatility explains the contract's price.
 type OptionQuote struct {
+	ContractSymbol    string  `json:"contractSymbol"`
 	Strike            float64 `json:"strike"`
 	LastPrice         float64 `json:"lastPrice"`
 	Bid               float64 `json:"bid"`
 	Ask               float64 `json:"ask"`
 	Volume            int64   `json:"volume"`
 	OpenInterest      int64   `json:"openInterest"`
+	Moneyness         string  `json:"moneyness,omitempty"`
 	ImpliedVolatility float64 `json:"impliedVolatility,omitempty"`
 	Delta             float64 `json:"delta,omitempty"`
 	Gamma             float64 `json:"gamma,omitempty"`
 	Theta             float64 `json:"theta,omitempty"`
 	Vega              float64 `json:"vega,omitempty"`
+	Rho               float64 `json:"rho,omitempty"`
 }
 
 type MarketStatusResponse struct {
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tradecaptain/api-gateway/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMarketRouter(t *testing.T) (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewMarketDataHandler(services.NewMarketDataService(db))
+	router.GET("/market/search", handler.SearchSymbols)
+	router.GET("/market/options/:symbol", handler.GetOptionChain)
+	return router, mock
+}
+
+func get(router *gin.Engine, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+var instrumentColumns = []string{"id", "symbol", "name", "asset_class", "exchange", "currency",
+	"isin", "cusip", "figi", "sector", "industry", "status", "relevance"}
+
+func TestSearchSymbols(t *testing.T) {
+	router, mock := newTestMarketRouter(t)
+
+	// The query is upper-cased and its LIKE wildcards escaped; the limit is capped
+	mock.ExpectQuery("FROM instruments").
+		WithArgs("BRK_B", `BRK\_B`, maxSearchResults).
+		WillReturnRows(sqlmock.NewRows(instrumentColumns).
+			AddRow(1, "BRK_B", "Berkshire Hathaway Inc. Class B", "equity", "NYSE", "USD",
+				"US0846707026", "084670702", "", "Financials", "Insurance", "active", 1.0).
+			AddRow(2, "BRK_BW", "Berkshire Hathaway Warrant", "equity", "NYSE", "USD",
+				"", "", "", "", "", "delisted", 0.38))
+
+	w := get(router, "/market/search?q=brk_b&limit=500")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var results []SymbolSearchResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	require.Len(t, results, 2)
+	assert.Equal(t, SymbolSearchResult{
+		InstrumentID: 1, Symbol: "BRK_B", Name: "Berkshire Hathaway Inc. Class B", Exchange: "NYSE",
+		Type: "equity", Currency: "USD", ISIN: "US0846707026", Sector: "Financials", Status: "active", Relevance: 1,
+	}, results[0])
+	assert.Equal(t, "delisted", results[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchSymbolsErrors(t *testing.T) {
+	router, mock := newTestMarketRouter(t)
+
+	tests := []struct {
+		target string
+		code   int
+		error  string
+	}{
+		{"/market/search", http.StatusBadRequest, "invalid_query"},
+		{"/market/search?q=%20", http.StatusBadRequest, "invalid_query"},
+		{"/market/search?q=AAPL&limit=0", http.StatusBadRequest, "invalid_limit"},
+		{"/market/search?q=AAPL&limit=ten", http.StatusBadRequest, "invalid_limit"},
+	}
+	for _, tt := range tests {
+		w := get(router, tt.target)
+		assert.Equal(t, tt.code, w.Code, tt.target)
+		var response ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, tt.error, response.Error, tt.target)
+	}
+
+	// No match is an empty list, not null
+	mock.ExpectQuery("FROM instruments").WillReturnRows(sqlmock.NewRows(instrumentColumns))
+	w := get(router, "/market/search?q=ZZZZ")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	mock.ExpectQuery("FROM instruments").WillReturnError(errors.New("connection reset"))
+	w = get(router, "/market/search?q=AAPL")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var optionColumns = []string{"symbol", "type", "strike", "multiplier", "bid", "ask", "last", "volume",
+	"open_interest", "underlying_price", "implied_volatility", "delta", "gamma", "theta", "vega", "rho", "timestamp"}
+
+func TestGetOptionChain(t *testing.T) {
+	router, mock := newTestMarketRouter(t)
+	near := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	far := near.AddDate(0, 0, 28)
+	quoted := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	// Without an expiration the nearest one is served
+	mock.ExpectQuery("SELECT DISTINCT expiration FROM option_quotes").
+		WithArgs("AAPL", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"expiration"}).AddRow(near).AddRow(far))
+	mock.ExpectQuery("FROM option_quotes").
+		WithArgs("AAPL", near).
+		WillReturnRows(sqlmock.NewRows(optionColumns).
+			AddRow("AAPL  240308C00180000", "call", 180.0, 100, 6.1, 6.3, 6.2, 1200, 5400, 185.0, 0.24, 0.81, 0.03, -0.12, 0.07, 0.03, quoted.Add(-time.Minute)).
+			AddRow("AAPL  240308P00185000", "put", 185.0, 100, 2.0, 2.2, 2.1, 800, 3100, 185.5, 0.22, -0.47, 0.06, -0.15, 0.11, -0.02, quoted).
+			AddRow("AAPL  240308C00190000", "call", 190.0, 100, 0.9, 1.0, 0.95, 2500, 8800, 185.0, 0.21, 0.22, 0.04, -0.09, 0.08, 0.01, quoted.Add(-time.Minute)))
+
+	w := get(router, "/market/options/aapl")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var chain OptionsChainResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chain))
+	assert.Equal(t, "AAPL", chain.Symbol)
+	assert.Equal(t, near.Format("2006-01-02"), chain.Expiration)
+	assert.Equal(t, []string{near.Format("2006-01-02"), far.Format("2006-01-02")}, chain.Expirations)
+	assert.Equal(t, 185.5, chain.UnderlyingPrice, "the latest quote's underlying price")
+	require.Len(t, chain.Calls, 2)
+	require.Len(t, chain.Puts, 1)
+	assert.Equal(t, moneynessITM, chain.Calls[0].Moneyness)
+	assert.Equal(t, moneynessOTM, chain.Calls[1].Moneyness)
+	assert.Equal(t, moneynessATM, chain.Puts[0].Moneyness)
+	assert.Equal(t, 0.81, chain.Calls[0].Delta)
+
+	// Nothing stored for the underlying
+	mock.ExpectQuery("SELECT DISTINCT expiration FROM option_quotes").
+		WillReturnRows(sqlmock.NewRows([]string{"expiration"}))
+	w = get(router, "/market/options/XYZ")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
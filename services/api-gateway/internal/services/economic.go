@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// yieldCurveTenors are the maturities of the Treasury par yield curve as the
+// collector stores them, each in a y_<tenor> column of treasury_yield_curves
+var yieldCurveTenors = []string{"1M", "2M", "3M", "4M", "6M", "1Y", "2Y", "3Y", "5Y", "7Y", "10Y", "20Y", "30Y"}
+
+// YieldCurve is the Treasury par yield curve of one business day. Yields are
+// in percent by tenor; tenors the Treasury did not quote that day are absent.
+type YieldCurve struct {
+	Date      time.Time
+	Yields    map[string]float64
+	Source    string
+	UpdatedAt time.Time
+}
+
+type EconomicService struct {
+	db DB
+}
+
+func NewEconomicService(db DB) *EconomicService {
+	return &EconomicService{db: db}
+}
+
+// GetYieldCurve returns the latest curve on or before date, so weekends and
+// holidays get the previous business day's curve. It returns nil when no
+// curve that old is stored.
+func (s *EconomicService) GetYieldCurve(ctx context.Context, date time.Time) (*YieldCurve, error) {
+	columns := make([]string, len(yieldCurveTenors))
+	for i, tenor := range yieldCurveTenors {
+		columns[i] = "y_" + strings.ToLower(tenor)
+	}
+
+	curve := &YieldCurve{Yields: make(map[string]float64, len(yieldCurveTenors))}
+	yields := make([]sql.NullFloat64, len(yieldCurveTenors))
+	dest := []interface{}{&curve.Date, &curve.Source, &curve.UpdatedAt}
+	for i := range yields {
+		dest = append(dest, &yields[i])
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT date, source, updated_at, `+strings.Join(columns, ", ")+`
+		FROM treasury_yield_curves
+		WHERE date <= $1
+		ORDER BY date DESC
+		LIMIT 1
+	`, date).Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query yield curve for %s: %w", date.Format("2006-01-02"), err)
+	}
+	curve.Date = curve.Date.UTC()
+	for i, tenor := range yieldCurveTenors {
+		if yields[i].Valid {
+			curve.Yields[tenor] = yields[i].Float64
+		}
+	}
+	return curve, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// InstrumentClass is the primary exchange and sector the instrument master
+// lists an instrument under
+type InstrumentClass struct {
+	Exchange string
+	Sector   string
+}
+
+// InstrumentClassifier classifies symbols through the collector's instrument
+// master
+type InstrumentClassifier struct {
+	db DB
+}
+
+func NewInstrumentClassifier(db DB) *InstrumentClassifier {
+	return &InstrumentClassifier{db: db}
+}
+
+// Classify returns the class of each of symbols the master resolves, keyed
+// by the symbol as given. A symbol resolves as in the collector: to the
+// instrument listed under it, or else to the one instrument trading under it
+// on some venue; a ticker several instruments use resolves to none.
+func (c *InstrumentClassifier) Classify(ctx context.Context, symbols []string) (map[string]InstrumentClass, error) {
+	given := make(map[string][]string, len(symbols)) // normalized -> as given
+	for _, symbol := range symbols {
+		normalized := strings.ToUpper(strings.TrimSpace(symbol))
+		if normalized != "" {
+			given[normalized] = append(given[normalized], symbol)
+		}
+	}
+	if len(given) == 0 {
+		return map[string]InstrumentClass{}, nil
+	}
+	normalized := make([]string, 0, len(given))
+	for symbol := range given {
+		normalized = append(normalized, symbol)
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT i.symbol, TRUE, i.id, i.exchange, i.sector
+		FROM instruments i
+		WHERE i.symbol = ANY($1)
+		UNION ALL
+		SELECT DISTINCT t.ticker, FALSE, i.id, i.exchange, i.sector
+		FROM instrument_tickers t
+		JOIN instruments i ON i.id = t.instrument_id
+		WHERE t.ticker = ANY($1)
+	`, pq.Array(normalized))
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify %d symbols: %w", len(normalized), err)
+	}
+	defer rows.Close()
+
+	listed := make(map[string]InstrumentClass)
+	tickers := make(map[string]map[int64]InstrumentClass)
+	for rows.Next() {
+		var symbol string
+		var bySymbol bool
+		var id int64
+		var class InstrumentClass
+		if err := rows.Scan(&symbol, &bySymbol, &id, &class.Exchange, &class.Sector); err != nil {
+			return nil, fmt.Errorf("failed to scan instrument class: %w", err)
+		}
+		if bySymbol {
+			listed[symbol] = class
+			continue
+		}
+		if tickers[symbol] == nil {
+			tickers[symbol] = make(map[int64]InstrumentClass)
+		}
+		tickers[symbol][id] = class
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	classes := make(map[string]InstrumentClass, len(symbols))
+	for symbol, asGiven := range given {
+		class, ok := listed[symbol]
+		if !ok && len(tickers[symbol]) == 1 {
+			for _, class = range tickers[symbol] {
+				ok = true
+			}
+		}
+		if !ok {
+			continue
+		}
+		for _, original := range asGiven {
+			classes[original] = class
+		}
+	}
+	return classes, nil
+}
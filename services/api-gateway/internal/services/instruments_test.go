@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentClassifierClassify(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	classifier := NewInstrumentClassifier(db)
+
+	columns := []string{"symbol", "by_symbol", "id", "exchange", "sector"}
+	mock.ExpectQuery("FROM instruments i").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("AAPL", true, 1, "NASDAQ", "Information Technology").
+			AddRow("SHEL", true, 2, "NYSE", "Energy").
+			// RDSA is Shell's ticker on another venue; AAPL also trades as AAPL elsewhere
+			AddRow("RDSA", false, 2, "NYSE", "Energy").
+			AddRow("AAPL", false, 1, "NASDAQ", "Information Technology").
+			// Two instruments trade as ABC on different venues
+			AddRow("ABC", false, 3, "LSE", "Materials").
+			AddRow("ABC", false, 4, "TSX", "Financials"))
+
+	classes, err := classifier.Classify(context.Background(), []string{"aapl", "AAPL", "RDSA", "ABC", "ZZZZ", " "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]InstrumentClass{
+		"aapl": {Exchange: "NASDAQ", Sector: "Information Technology"},
+		"AAPL": {Exchange: "NASDAQ", Sector: "Information Technology"},
+		"RDSA": {Exchange: "NYSE", Sector: "Energy"},
+	}, classes)
+
+	// Nothing to look up, no query
+	classes, err = classifier.Classify(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, classes)
+
+	mock.ExpectQuery("FROM instruments i").WillReturnError(errors.New("connection reset"))
+	_, err = classifier.Classify(context.Background(), []string{"AAPL"})
+	assert.ErrorContains(t, err, "connection reset")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
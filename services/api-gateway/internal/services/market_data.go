@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Instrument is an entry of the collector's instrument master. Identifiers
+// the master does not know are empty.
+type Instrument struct {
+	ID         int64
+	Symbol     string
+	Name       string
+	AssetClass string
+	Exchange   string
+	Currency   string
+	ISIN       string
+	CUSIP      string
+	FIGI       string
+	Sector     string
+	Industry   string
+	Status     string
+}
+
+// InstrumentMatch is a search result. Relevance runs from 0 to 1, an exact
+// symbol match scoring 1, and is halved for delisted instruments.
+type InstrumentMatch struct {
+	Instrument Instrument
+	Relevance  float64
+}
+
+// OptionContract is a listed option by its OCC symbol with the implied
+// volatility and Greeks the collector computed from its mid price. Type is
+// "call" or "put".
+type OptionContract struct {
+	Symbol            string
+	Type              string
+	Strike            float64
+	Multiplier        int64
+	Bid               float64
+	Ask               float64
+	Last              float64
+	Volume            int64
+	OpenInterest      int64
+	ImpliedVolatility float64
+	Delta             float64
+	Gamma             float64
+	Theta             float64
+	Vega              float64
+	Rho               float64
+	Timestamp         time.Time
+}
+
+// OptionChain is the contracts of an underlying expiring on one date, by
+// strike with the call before the put. UnderlyingPrice is the one the
+// latest contract was quoted against and Expirations the stored
+// expirations not yet past.
+type OptionChain struct {
+	Underlying      string
+	Expiration      time.Time
+	UnderlyingPrice float64
+	Expirations     []time.Time
+	Contracts       []OptionContract
+	Timestamp       time.Time
+}
+
+type MarketDataService struct {
+	db DB
+}
+
+func NewMarketDataService(db DB) *MarketDataService {
+	return &MarketDataService{db: db}
+}
+
+// likeEscaper escapes the LIKE wildcards in a search query
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchInstruments finds instruments by symbol, venue ticker, identifier or
+// name, best match first, ranked as the collector's instrument master ranks
+// them: the exact symbol, then an ISIN, CUSIP or FIGI, then a venue ticker,
+// then a symbol prefix (shorter symbols first), a word of the name starting
+// with the query and last the name containing it
+func (s *MarketDataService) SearchInstruments(ctx context.Context, query string, limit int) ([]InstrumentMatch, error) {
+	query = strings.ToUpper(strings.TrimSpace(query))
+	if query == "" || limit <= 0 {
+		return nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, symbol, name, asset_class, exchange, currency, isin, cusip, figi, sector, industry, status, relevance
+		FROM (
+			SELECT i.id, i.symbol, i.name, i.asset_class, i.exchange, i.currency,
+				COALESCE(i.isin, '') AS isin, COALESCE(i.cusip, '') AS cusip, COALESCE(i.figi, '') AS figi,
+				i.sector, i.industry, i.status,
+				CASE
+					WHEN i.symbol = $1 THEN 1
+					WHEN $1 IN (i.isin, i.cusip, i.figi) THEN 0.95
+					WHEN EXISTS (
+						SELECT 1 FROM instrument_tickers t WHERE t.instrument_id = i.id AND t.ticker = $1
+					) THEN 0.9
+					WHEN i.symbol LIKE $2 || '%' THEN 0.6 + 0.2 * length($1) / length(i.symbol)
+					WHEN ' ' || upper(i.name) LIKE '% ' || $2 || '%' THEN 0.5
+					WHEN upper(i.name) LIKE '%' || $2 || '%' THEN 0.3
+					ELSE 0
+				END * CASE WHEN i.status = 'delisted' THEN 0.5 ELSE 1 END AS relevance
+			FROM instruments i
+		) matches
+		WHERE relevance > 0
+		ORDER BY relevance DESC, symbol
+		LIMIT $3
+	`, query, likeEscaper.Replace(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search instruments for %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	var matches []InstrumentMatch
+	for rows.Next() {
+		var m InstrumentMatch
+		inst := &m.Instrument
+		if err := rows.Scan(&inst.ID, &inst.Symbol, &inst.Name, &inst.AssetClass, &inst.Exchange, &inst.Currency,
+			&inst.ISIN, &inst.CUSIP, &inst.FIGI, &inst.Sector, &inst.Industry, &inst.Status, &m.Relevance); err != nil {
+			return nil, fmt.Errorf("failed to scan instrument: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return matches, nil
+}
+
+// GetOptionChain returns the stored chain of underlying expiring on
+// expiration or, when expiration is zero, on the nearest expiration not yet
+// past. It returns nil when no such contracts are stored.
+func (s *MarketDataService) GetOptionChain(ctx context.Context, underlying string, expiration time.Time) (*OptionChain, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	expirations, err := s.optionExpirations(ctx, underlying, today)
+	if err != nil {
+		return nil, err
+	}
+	if expiration.IsZero() {
+		if len(expirations) == 0 {
+			return nil, nil
+		}
+		expiration = expirations[0]
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT symbol, type, strike, multiplier, bid, ask, last, volume, open_interest, underlying_price,
+			implied_volatility, delta, gamma, theta, vega, rho, timestamp
+		FROM option_quotes
+		WHERE underlying = $1 AND expiration = $2
+		ORDER BY strike, type
+	`, underlying, expiration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s option chain: %w", underlying, err)
+	}
+	defer rows.Close()
+
+	chain := &OptionChain{Underlying: underlying, Expiration: expiration, Expirations: expirations}
+	for rows.Next() {
+		var c OptionContract
+		var underlyingPrice float64
+		if err := rows.Scan(&c.Symbol, &c.Type, &c.Strike, &c.Multiplier, &c.Bid, &c.Ask, &c.Last, &c.Volume,
+			&c.OpenInterest, &underlyingPrice, &c.ImpliedVolatility, &c.Delta, &c.Gamma, &c.Theta, &c.Vega, &c.Rho,
+			&c.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan option quote: %w", err)
+		}
+		if c.Timestamp.After(chain.Timestamp) {
+			chain.Timestamp = c.Timestamp
+			chain.UnderlyingPrice = underlyingPrice
+		}
+		chain.Contracts = append(chain.Contracts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	if len(chain.Contracts) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// optionExpirations returns the expirations of underlying's stored contracts
+// on or after from, earliest first
+func (s *MarketDataService) optionExpirations(ctx context.Context, underlying string, from time.Time) ([]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT expiration FROM option_quotes
+		WHERE underlying = $1 AND expiration >= $2
+		ORDER BY expiration
+	`, underlying, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s option expirations: %w", underlying, err)
+	}
+	defer rows.Close()
+
+	var expirations []time.Time
+	for rows.Next() {
+		var expiration time.Time
+		if err := rows.Scan(&expiration); err != nil {
+			return nil, fmt.Errorf("failed to scan option expiration: %w", err)
+		}
+		expirations = append(expirations, expiration.UTC())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return expirations, nil
+}
@@ -0,0 +1,14 @@
+// Package services reads the data the collector stores for the handlers.
+package services
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DB is the part of a database/sql handle on the collector's Postgres
+// database the services query through; *sql.DB satisfies it
+type DB interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
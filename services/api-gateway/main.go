@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"tradecaptain/api-gateway/internal/analytics"
 	"tradecaptain/api-gateway/internal/config"
 	"tradecaptain/api-gateway/internal/handlers"
 	"tradecaptain/api-gateway/internal/middleware"
@@ -67,10 +68,19 @@ func main() {
 	defer consumer.Close()
 
 	// Initialize services
-	marketDataService := services.NewMarketDataService(db, cache)
+	marketDataService := services.NewMarketDataService(db)
 	portfolioService := services.NewPortfolioService(db)
 	userService := services.NewUserService(db)
 	newsService := services.NewNewsService(db, cache)
+	economicService := services.NewEconomicService(db)
+
+	// Analytics rows take exchange and sector from the instrument master
+	analyticsClient, err := analytics.NewClickHouseClient(cfg.ClickHouseHost, cfg.ClickHouseDatabase, cfg.ClickHouseUser, cfg.ClickHousePassword)
+	if err != nil {
+		log.Fatalf("Failed to connect to ClickHouse: %v", err)
+	}
+	defer analyticsClient.Close()
+	analyticsClient.UseInstruments(services.NewInstrumentClassifier(db))
 
 	// Initialize WebSocket hub
 	wsHub := websocket.NewHub()
@@ -99,6 +109,7 @@ func main() {
 	portfolioHandler := handlers.NewPortfolioHandler(portfolioService)
 	userHandler := handlers.NewUserHandler(userService, cfg.JWTSecret)
 	newsHandler := handlers.NewNewsHandler(newsService)
+	economicHandler := handlers.NewEconomicHandler(economicService)
 	wsHandler := handlers.NewWebSocketHandler(wsHub)
 
 	// API routes
@@ -119,6 +130,7 @@ func main() {
 			market.GET("/quotes", marketHandler.GetMultipleQuotes)
 			market.GET("/historical/:symbol", marketHandler.GetHistoricalData)
 			market.GET("/search", marketHandler.SearchSymbols)
+			market.GET("/options/:symbol", marketHandler.GetOptionChain)
 		}
 
 		// News routes
@@ -128,6 +140,12 @@ func main() {
 			news.GET("/search", newsHandler.SearchNews)
 		}
 
+		// Economic data routes
+		economic := v1.Group("/economic")
+		{
+			economic.GET("/yield-curve", economicHandler.GetYieldCurve)
+		}
+
 		// Protected routes
 		protected := v1.Group("/")
 		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
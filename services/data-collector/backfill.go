@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"tradecaptain/data-collector/internal/backfill"
+	"tradecaptain/data-collector/internal/collector"
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/instruments"
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+const backfillUsage = `usage: data-collector backfill -start YYYY-MM-DD [-end YYYY-MM-DD] [-interval 1d]
+                              (-symbols AAPL,MSFT | -file symbols.txt) [-checkpoint dir]
+
+Fetches history through the configured providers and stores it in Postgres and,
+when QUESTDB_URL is set, QuestDB. Progress is checkpointed after every chunk;
+rerun the same command to resume after an interruption.
+`
+
+// runBackfill implements the backfill subcommand
+func runBackfill(args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), backfillUsage)
+		flags.PrintDefaults()
+	}
+	symbolList := flags.String("symbols", "", "comma separated symbols")
+	symbolFile := flags.String("file", "", "file with one symbol per line, # starts a comment")
+	startDate := flags.String("start", "", "first day to backfill (YYYY-MM-DD)")
+	endDate := flags.String("end", "", "day after the last one to backfill (YYYY-MM-DD), defaults to today")
+	interval := flags.String("interval", "1d", "bar interval: 1m, 2m, 5m, 15m, 30m, 60m, 90m, 1h, 1d, 1wk or 1mo")
+	checkpointPath := flags.String("checkpoint", "./data/backfill", "checkpoint directory")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	symbols, err := backfillSymbols(*symbolList, *symbolFile)
+	if err != nil {
+		return err
+	}
+	start, err := time.Parse("2006-01-02", *startDate)
+	if err != nil {
+		return errors.New("-start must be a YYYY-MM-DD date")
+	}
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if *endDate != "" {
+		if end, err = time.Parse("2006-01-02", *endDate); err != nil {
+			return errors.New("-end must be a YYYY-MM-DD date")
+		}
+	}
+	if _, err := backfill.ChunkSize(*interval); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	db, err := storage.NewPostgresDB(cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	var questDB *storage.QuestDBClient
+	if cfg.QuestDBURL != "" {
+		if questDB, err = storage.NewQuestDBClient(cfg.QuestDBURL); err != nil {
+			return err
+		}
+		defer questDB.Close()
+
+		master := instruments.NewMaster(db)
+		if err := db.EnsureInstruments(context.Background()); err != nil {
+			return err
+		}
+		if err := master.Load(context.Background()); err != nil {
+			return fmt.Errorf("failed to load instrument master: %w", err)
+		}
+		questDB.UseInstruments(master)
+	}
+
+	// Share rate limit buckets with running collectors when Redis is reachable
+	var redisCache *storage.RedisCache
+	if cfg.RateLimitMode == "redis" {
+		if redisCache, err = storage.NewRedisCache(cfg.RedisURL); err != nil {
+			log.Printf("Redis unavailable, rate limiting this process only: %v", err)
+			redisCache = nil
+		} else {
+			defer redisCache.Close()
+		}
+	}
+
+	checkpoints, err := backfill.NewCheckpointStore(*checkpointPath)
+	if err != nil {
+		return err
+	}
+	defer checkpoints.Close()
+
+	sink := func(ctx context.Context, bars []*models.MarketData) error {
+		if err := db.UpdateMarketDataBatch(ctx, bars); err != nil {
+			return fmt.Errorf("failed to store bars in Postgres: %w", err)
+		}
+		if questDB != nil {
+			if err := questDB.BatchInsertMarketData(bars); err != nil {
+				return fmt.Errorf("failed to store bars in QuestDB: %w", err)
+			}
+		}
+		return nil
+	}
+	runner := backfill.NewRunner(collector.NewProviderPoolFromConfig(cfg, redisCache), sink, checkpoints, os.Stdout)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := runner.Run(ctx, backfill.Job{
+		Symbols:   symbols,
+		Start:     start,
+		End:       end,
+		Interval:  *interval,
+		OpenEnded: *endDate == "",
+	})
+	log.Printf("Backfill stored %d bars in %d chunks for %d symbols", summary.Bars, summary.Chunks, summary.Symbols)
+	if ctx.Err() != nil {
+		log.Println("Backfill interrupted, rerun the same command to resume")
+	}
+	return err
+}
+
+// backfillSymbols merges the -symbols list and the -file contents, upper-cased
+// and without duplicates
+func backfillSymbols(list, path string) ([]string, error) {
+	raw := strings.Split(list, ",")
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			raw = append(raw, strings.Split(line, ",")...)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, symbol := range raw {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("no symbols given, use -symbols or -file")
+	}
+	return symbols, nil
+}
@@ -14,4 +14,7 @@ require (
     github.com/vmihailenco/msgpack/v5 v5.4.1
     github.com/questdb/go-questdb-client v1.1.0
     github.com/lirm/aeron-go v1.0.8
+    github.com/stretchr/testify v1.8.4
+    github.com/alicebob/miniredis/v2 v2.31.1
+    gopkg.in/yaml.v3 v3.0.1
 )
\ No newline at end of file
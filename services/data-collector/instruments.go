@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/instruments"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+const importInstrumentsUsage = `usage: data-collector import-instruments -file instruments.csv
+
+Loads instruments into the instrument master in Postgres, all of them or, if
+any row is invalid or conflicts with another instrument, none. The header row
+names the columns: symbol is required; id, name, asset_class, exchange,
+currency, isin, cusip, figi, lot_size, tick_size, sector, industry, status
+and tickers (VENUE:TICKER;...) are optional. Rows without an id update the
+instrument with the same FIGI, ISIN, CUSIP or symbol. Use -file - for stdin.
+Running collectors pick the changes up within ten minutes.
+`
+
+// runImportInstruments implements the import-instruments subcommand
+func runImportInstruments(args []string) error {
+	flags := flag.NewFlagSet("import-instruments", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), importInstrumentsUsage)
+		flags.PrintDefaults()
+	}
+	path := flags.String("file", "", "instrument CSV to import, - for stdin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("-file is required")
+	}
+
+	var in io.Reader = os.Stdin
+	if *path != "-" {
+		file, err := os.Open(*path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	cfg, err := config.LoadFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	db, err := storage.NewPostgresDB(cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.EnsureInstruments(ctx); err != nil {
+		return err
+	}
+	master := instruments.NewMaster(db)
+	if err := master.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load instrument master: %w", err)
+	}
+
+	added, updated, err := master.Import(ctx, in)
+	if err != nil {
+		return err
+	}
+	log.Printf("Imported %s: %d instruments added, %d updated, %d in the master", *path, added, updated, master.Len())
+	return nil
+}
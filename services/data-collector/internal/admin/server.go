@@ -0,0 +1,531 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradecaptain/data-collector/internal/collector"
+	"tradecaptain/data-collector/internal/corpactions"
+	"tradecaptain/data-collector/internal/instruments"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Collector is the part of the data collector the admin API controls
+type Collector interface {
+	Services() []collector.ServiceStatus
+	PauseCollection(ctx context.Context, service string) error
+	ResumeCollection(ctx context.Context, service string) error
+	StartBackfill(ctx context.Context, symbols []string, start, end time.Time) (collector.BackfillJob, error)
+	BackfillJobs() []collector.BackfillJob
+	RetryQueueStats() (collector.RetryQueueStats, error)
+	DeadLetters(limit int) ([]*collector.DeadLetter, error)
+	RequeueDeadLetter(id string) error
+	DeleteDeadLetter(id string) error
+	EffectiveConfig() map[string]interface{}
+	GenerateCollectionMetrics(ctx context.Context) map[string]interface{}
+}
+
+// Instruments is the instrument master the admin API looks up and edits;
+// *instruments.Master is one
+type Instruments interface {
+	Get(id int64) (*models.Instrument, bool)
+	Lookup(kind, value string) (*models.Instrument, bool)
+	LookupTicker(venue, ticker string) (*models.Instrument, bool)
+	Search(query string, limit int) []instruments.Match
+	Save(ctx context.Context, inst *models.Instrument) error
+	Import(ctx context.Context, r io.Reader) (added, updated int, err error)
+}
+
+// CorporateActions is the corporate actions book operations edit;
+// *corpactions.Book is one
+type CorporateActions interface {
+	List(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+	Get(ctx context.Context, id int64) (*models.CorporateAction, error)
+	Save(ctx context.Context, action *models.CorporateAction) error
+	Cancel(ctx context.Context, id int64) (*models.CorporateAction, error)
+}
+
+// maxImportSize bounds an instrument CSV upload
+const maxImportSize = 64 << 20
+
+// Server is the collector's control-plane HTTP API. Every /admin route needs
+// "Authorization: Bearer <token>"; /health is open for liveness probes.
+type Server struct {
+	collector   Collector
+	instruments Instruments      // nil until UseInstruments
+	actions     CorporateActions // nil until UseCorporateActions
+	token       string
+	addr        string
+
+	// jobCtx outlives individual requests so backfills keep running after
+	// the triggering request returns
+	jobCtx context.Context
+}
+
+type backfillRequest struct {
+	Symbols []string `json:"symbols"`
+	Start   string   `json:"start"`
+	End     string   `json:"end"`
+}
+
+func NewServer(addr, token string, collector Collector) *Server {
+	return &Server{
+		collector: collector,
+		token:     token,
+		addr:      addr,
+		jobCtx:    context.Background(),
+	}
+}
+
+// UseInstruments serves the instrument master under /admin/instruments;
+// without one those routes answer 503
+func (s *Server) UseInstruments(master Instruments) {
+	s.instruments = master
+}
+
+// UseCorporateActions serves the corporate actions book under
+// /admin/corporate-actions; without one those routes answer 503
+func (s *Server) UseCorporateActions(actions CorporateActions) {
+	s.actions = actions
+}
+
+// Run serves the API until ctx is cancelled, then shuts down gracefully
+func (s *Server) Run(ctx context.Context) error {
+	s.jobCtx = ctx
+
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	log.Printf("Admin API listening on %s", s.addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// Handler builds the router
+func (s *Server) Handler() http.Handler {
+	gin.SetMode(gin.ReleaseMode)
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	admin := router.Group("/admin")
+	admin.Use(s.requireToken())
+	{
+		admin.GET("/services", s.listServices)
+		admin.POST("/services/:name/pause", s.pauseService)
+		admin.POST("/services/:name/resume", s.resumeService)
+
+		admin.GET("/backfills", s.listBackfills)
+		admin.POST("/backfills", s.startBackfill)
+
+		admin.GET("/retry", s.retryQueue)
+		admin.POST("/retry/dead-letters/:id/requeue", s.requeueDeadLetter)
+		admin.DELETE("/retry/dead-letters/:id", s.deleteDeadLetter)
+
+		admin.GET("/config", s.effectiveConfig)
+		admin.GET("/metrics", s.metrics)
+	}
+
+	master := admin.Group("/instruments")
+	master.Use(s.requireInstruments())
+	{
+		master.GET("", s.findInstruments)
+		master.GET("/:id", s.getInstrument)
+		master.POST("", s.createInstrument)
+		master.PUT("/:id", s.updateInstrument)
+		master.POST("/import", s.importInstruments)
+	}
+
+	actions := admin.Group("/corporate-actions")
+	actions.Use(s.requireCorporateActions())
+	{
+		actions.GET("", s.listCorporateActions)
+		actions.GET("/:id", s.getCorporateAction)
+		actions.POST("", s.createCorporateAction)
+		actions.PUT("/:id", s.updateCorporateAction)
+		actions.DELETE("/:id", s.cancelCorporateAction)
+	}
+
+	return router
+}
+
+// requireToken rejects requests without the admin token in a Bearer
+// Authorization header. An empty configured token rejects everything rather
+// than leaving the API open.
+func (s *Server) requireToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, bearer := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !bearer || s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s *Server) requireInstruments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.instruments == nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "instrument master not loaded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s *Server) requireCorporateActions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.actions == nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "corporate actions not available"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s *Server) listServices(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"services": s.collector.Services()})
+}
+
+func (s *Server) pauseService(c *gin.Context) {
+	name := c.Param("name")
+	if err := s.collector.PauseCollection(c.Request.Context(), name); err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: paused %s from %s", name, c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{"service": name, "state": "paused"})
+}
+
+func (s *Server) resumeService(c *gin.Context) {
+	name := c.Param("name")
+	if err := s.collector.ResumeCollection(c.Request.Context(), name); err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: resumed %s from %s", name, c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{"service": name, "state": "running"})
+}
+
+func (s *Server) listBackfills(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"backfills": s.collector.BackfillJobs()})
+}
+
+// startBackfill accepts {"symbols": [...], "start": "2006-01-02", "end": "2006-01-02"};
+// end defaults to now
+func (s *Server) startBackfill(c *gin.Context) {
+	var req backfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	start, err := time.Parse("2006-01-02", req.Start)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start must be a YYYY-MM-DD date"})
+		return
+	}
+	end := time.Now()
+	if req.End != "" {
+		if end, err = time.Parse("2006-01-02", req.End); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end must be a YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	symbols := make([]string, 0, len(req.Symbols))
+	for _, symbol := range req.Symbols {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	job, err := s.collector.StartBackfill(s.jobCtx, symbols, start, end)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	log.Printf("Admin API: started %s for %v from %s", job.ID, symbols, c.ClientIP())
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (s *Server) retryQueue(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative integer"})
+		return
+	}
+
+	stats, err := s.collector.RetryQueueStats()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	letters, err := s.collector.DeadLetters(limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"depth":              stats.Depth,
+		"due":                stats.Due,
+		"oldest_age_seconds": stats.OldestAge.Seconds(),
+		"dead_letter_count":  stats.DeadLetters,
+		"dead_letters":       letters,
+	})
+}
+
+func (s *Server) requeueDeadLetter(c *gin.Context) {
+	if err := s.collector.RequeueDeadLetter(c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "state": "requeued"})
+}
+
+func (s *Server) deleteDeadLetter(c *gin.Context) {
+	if err := s.collector.DeleteDeadLetter(c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "state": "deleted"})
+}
+
+func (s *Server) effectiveConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, s.collector.EffectiveConfig())
+}
+
+func (s *Server) metrics(c *gin.Context) {
+	c.JSON(http.StatusOK, s.collector.GenerateCollectionMetrics(c.Request.Context()))
+}
+
+// findInstruments looks an instrument up by one of ?symbol=, ?isin=,
+// ?cusip=, ?figi= or ?venue=&ticker=, or searches with ?q= (and ?limit=,
+// default 20) returning matches best first
+func (s *Server) findInstruments(c *gin.Context) {
+	if query := c.Query("q"); query != "" {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		matches := s.instruments.Search(query, limit)
+		if matches == nil {
+			matches = []instruments.Match{}
+		}
+		c.JSON(http.StatusOK, gin.H{"matches": matches})
+		return
+	}
+
+	if venue, ticker := c.Query("venue"), c.Query("ticker"); venue != "" && ticker != "" {
+		inst, ok := s.instruments.LookupTicker(venue, ticker)
+		s.respondInstrument(c, inst, ok)
+		return
+	}
+	for _, kind := range []string{instruments.BySymbol, instruments.ByISIN, instruments.ByCUSIP, instruments.ByFIGI} {
+		if value := c.Query(kind); value != "" {
+			inst, ok := s.instruments.Lookup(kind, value)
+			s.respondInstrument(c, inst, ok)
+			return
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "give q, symbol, isin, cusip, figi or venue and ticker"})
+}
+
+func (s *Server) getInstrument(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+	inst, ok := s.instruments.Get(id)
+	s.respondInstrument(c, inst, ok)
+}
+
+func (s *Server) respondInstrument(c *gin.Context, inst *models.Instrument, ok bool) {
+	if !ok {
+		respondError(c, instruments.ErrNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, inst)
+}
+
+func (s *Server) createInstrument(c *gin.Context) {
+	var inst models.Instrument
+	if err := c.ShouldBindJSON(&inst); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	inst.ID = 0
+	if err := s.instruments.Save(c.Request.Context(), &inst); err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: added instrument %d (%s) from %s", inst.ID, inst.Symbol, c.ClientIP())
+	c.JSON(http.StatusCreated, inst)
+}
+
+// updateInstrument replaces the instrument with the body; fields left out
+// are cleared, so send the whole instrument
+func (s *Server) updateInstrument(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+	var inst models.Instrument
+	if err := c.ShouldBindJSON(&inst); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	inst.ID = id
+	if err := s.instruments.Save(c.Request.Context(), &inst); err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: updated instrument %d (%s) from %s", inst.ID, inst.Symbol, c.ClientIP())
+	c.JSON(http.StatusOK, inst)
+}
+
+// importInstruments takes an instrument CSV as the request body; see
+// instruments.ReadCSV for the columns
+func (s *Server) importInstruments(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	added, updated, err := s.instruments.Import(c.Request.Context(), body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: imported instruments from %s, %d added and %d updated", c.ClientIP(), added, updated)
+	c.JSON(http.StatusOK, gin.H{"added": added, "updated": updated})
+}
+
+// listCorporateActions returns ?symbol='s actions by ex-date, cancelled
+// ones included
+func (s *Server) listCorporateActions(c *gin.Context) {
+	actions, err := s.actions.List(c.Request.Context(), c.Query("symbol"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if actions == nil {
+		actions = []*models.CorporateAction{}
+	}
+	c.JSON(http.StatusOK, gin.H{"actions": actions})
+}
+
+func (s *Server) getCorporateAction(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+	action, err := s.actions.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, action)
+}
+
+// createCorporateAction adds an action, or takes over the collected one of
+// the same symbol, type and ex-date so collection no longer changes it
+func (s *Server) createCorporateAction(c *gin.Context) {
+	var action models.CorporateAction
+	if err := c.ShouldBindJSON(&action); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	action.ID = 0
+	if err := s.actions.Save(c.Request.Context(), &action); err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: saved corporate action %d (%s %s of %s) from %s", action.ID, action.Type,
+		action.ExDate.Format("2006-01-02"), action.Symbol, c.ClientIP())
+	c.JSON(http.StatusCreated, action)
+}
+
+// updateCorporateAction replaces the action with the body; fields left out
+// are cleared, so send the whole action
+func (s *Server) updateCorporateAction(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+	var action models.CorporateAction
+	if err := c.ShouldBindJSON(&action); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	action.ID = id
+	if err := s.actions.Save(c.Request.Context(), &action); err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: updated corporate action %d (%s %s of %s) from %s", action.ID, action.Type,
+		action.ExDate.Format("2006-01-02"), action.Symbol, c.ClientIP())
+	c.JSON(http.StatusOK, action)
+}
+
+// cancelCorporateAction marks an action cancelled rather than deleting it,
+// so collection does not bring it back
+func (s *Server) cancelCorporateAction(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+	action, err := s.actions.Cancel(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	log.Printf("Admin API: cancelled corporate action %d (%s %s of %s) from %s", action.ID, action.Type,
+		action.ExDate.Format("2006-01-02"), action.Symbol, c.ClientIP())
+	c.JSON(http.StatusOK, action)
+}
+
+func respondError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, instruments.ErrInvalid), errors.Is(err, corpactions.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, collector.ErrUnknownService), errors.Is(err, collector.ErrRetryItemNotFound),
+		errors.Is(err, instruments.ErrNotFound), errors.Is(err, corpactions.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, collector.ErrRetryQueueDisabled):
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,270 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/collector"
+	"tradecaptain/data-collector/internal/corpactions"
+	"tradecaptain/data-collector/internal/instruments"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCollector struct {
+	paused    map[string]bool
+	backfills []collector.BackfillJob
+}
+
+func (f *fakeCollector) Services() []collector.ServiceStatus {
+	var services []collector.ServiceStatus
+	for _, name := range []string{collector.ServiceMarket, collector.ServiceNews} {
+		state := "running"
+		if f.paused[name] {
+			state = "paused"
+		}
+		services = append(services, collector.ServiceStatus{Name: name, Kind: "collector", State: state})
+	}
+	return services
+}
+
+func (f *fakeCollector) setPaused(service string, paused bool) error {
+	if service != collector.ServiceMarket && service != collector.ServiceNews {
+		return collector.ErrUnknownService
+	}
+	f.paused[service] = paused
+	return nil
+}
+
+func (f *fakeCollector) PauseCollection(ctx context.Context, service string) error {
+	return f.setPaused(service, true)
+}
+
+func (f *fakeCollector) ResumeCollection(ctx context.Context, service string) error {
+	return f.setPaused(service, false)
+}
+
+func (f *fakeCollector) StartBackfill(ctx context.Context, symbols []string, start, end time.Time) (collector.BackfillJob, error) {
+	job := collector.BackfillJob{ID: "backfill-1", Symbols: symbols, Start: start, End: end, State: "running"}
+	f.backfills = append(f.backfills, job)
+	return job, nil
+}
+
+func (f *fakeCollector) BackfillJobs() []collector.BackfillJob { return f.backfills }
+
+func (f *fakeCollector) RetryQueueStats() (collector.RetryQueueStats, error) {
+	return collector.RetryQueueStats{}, collector.ErrRetryQueueDisabled
+}
+
+func (f *fakeCollector) DeadLetters(limit int) ([]*collector.DeadLetter, error) {
+	return nil, collector.ErrRetryQueueDisabled
+}
+
+func (f *fakeCollector) RequeueDeadLetter(id string) error { return collector.ErrRetryItemNotFound }
+func (f *fakeCollector) DeleteDeadLetter(id string) error  { return collector.ErrRetryItemNotFound }
+
+func (f *fakeCollector) EffectiveConfig() map[string]interface{} {
+	return map[string]interface{}{"api_keys.alpha_vantage": "set"}
+}
+
+func (f *fakeCollector) GenerateCollectionMetrics(ctx context.Context) map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func newTestServer() (*fakeCollector, http.Handler) {
+	fake := &fakeCollector{paused: make(map[string]bool)}
+	return fake, NewServer("", "secret", fake).Handler()
+}
+
+func do(t *testing.T, handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	_, handler := newTestServer()
+
+	assert.Equal(t, http.StatusUnauthorized, do(t, handler, http.MethodGet, "/admin/services", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(t, handler, http.MethodGet, "/admin/services", "wrong", "").Code)
+	assert.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/admin/services", "secret", "").Code)
+	assert.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/health", "", "").Code)
+
+	// The token only counts with the Bearer scheme
+	for _, header := range []string{"secret", "Basic secret", "bearer secret", "Bearer"} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/services", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, header)
+	}
+
+	// An unset token never authorises anything
+	open := NewServer("", "", &fakeCollector{}).Handler()
+	assert.Equal(t, http.StatusUnauthorized, do(t, open, http.MethodGet, "/admin/services", "", "").Code)
+}
+
+func TestAdminPauseResume(t *testing.T) {
+	fake, handler := newTestServer()
+
+	rec := do(t, handler, http.MethodPost, "/admin/services/market/pause", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, fake.paused[collector.ServiceMarket])
+
+	rec = do(t, handler, http.MethodPost, "/admin/services/market/resume", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, fake.paused[collector.ServiceMarket])
+
+	rec = do(t, handler, http.MethodPost, "/admin/services/options/pause", "secret", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdminBackfill(t *testing.T) {
+	fake, handler := newTestServer()
+
+	rec := do(t, handler, http.MethodPost, "/admin/backfills", "secret",
+		`{"symbols": ["aapl", " msft "], "start": "2024-01-02", "end": "2024-03-29"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Len(t, fake.backfills, 1)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, fake.backfills[0].Symbols)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), fake.backfills[0].Start)
+
+	rec = do(t, handler, http.MethodPost, "/admin/backfills", "secret", `{"symbols": ["AAPL"], "start": "January"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAdminRetryQueueAndConfig(t *testing.T) {
+	_, handler := newTestServer()
+
+	assert.Equal(t, http.StatusServiceUnavailable, do(t, handler, http.MethodGet, "/admin/retry", "secret", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(t, handler, http.MethodDelete, "/admin/retry/dead-letters/x", "secret", "").Code)
+
+	rec := do(t, handler, http.MethodGet, "/admin/config", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var cfg map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cfg))
+	assert.Equal(t, "set", cfg["api_keys.alpha_vantage"])
+}
+
+type memoryInstruments struct {
+	saved []*models.Instrument
+}
+
+func (m *memoryInstruments) GetInstruments(ctx context.Context) ([]*models.Instrument, error) {
+	return m.saved, nil
+}
+
+func (m *memoryInstruments) SaveInstruments(ctx context.Context, instruments []*models.Instrument) error {
+	for _, inst := range instruments {
+		if inst.ID == 0 {
+			inst.ID = int64(len(m.saved) + 1)
+			m.saved = append(m.saved, inst)
+		}
+	}
+	return nil
+}
+
+func TestAdminInstruments(t *testing.T) {
+	_, handler := newTestServer()
+	assert.Equal(t, http.StatusServiceUnavailable, do(t, handler, http.MethodGet, "/admin/instruments?q=AAPL", "secret", "").Code)
+
+	server := NewServer("", "secret", &fakeCollector{})
+	server.UseInstruments(instruments.NewMaster(&memoryInstruments{}))
+	handler = server.Handler()
+
+	rec := do(t, handler, http.MethodPost, "/admin/instruments/import", "secret",
+		"symbol,name,exchange,isin\nAAPL,Apple Inc.,NMS,US0378331005\nMSFT,Microsoft Corporation,NMS,US5949181045\n")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"added": 2, "updated": 0}`, rec.Body.String())
+
+	rec = do(t, handler, http.MethodGet, "/admin/instruments?isin=US0378331005", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var apple models.Instrument
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &apple))
+	assert.Equal(t, "AAPL", apple.Symbol)
+	assert.Equal(t, "NASDAQ", apple.Exchange)
+
+	apple.Sector = "Technology"
+	body, err := json.Marshal(apple)
+	require.NoError(t, err)
+	rec = do(t, handler, http.MethodPut, "/admin/instruments/1", "secret", string(body))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = do(t, handler, http.MethodGet, "/admin/instruments/1", "secret", "")
+	assert.Contains(t, rec.Body.String(), `"sector":"Technology"`)
+
+	rec = do(t, handler, http.MethodGet, "/admin/instruments?q=micro", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"symbol":"MSFT"`)
+
+	assert.Equal(t, http.StatusNotFound, do(t, handler, http.MethodGet, "/admin/instruments?cusip=037833101", "secret", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(t, handler, http.MethodPut, "/admin/instruments/9", "secret", `{"symbol": "IBM"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodPost, "/admin/instruments", "secret", `{"symbol": "IBM", "isin": "US4592001015"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodGet, "/admin/instruments", "secret", "").Code)
+}
+
+type memoryActions map[int64]models.CorporateAction
+
+func (m memoryActions) GetCorporateActions(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	var actions []*models.CorporateAction
+	for _, action := range m {
+		if action.Symbol == symbol {
+			action := action
+			actions = append(actions, &action)
+		}
+	}
+	return actions, nil
+}
+
+func (m memoryActions) GetCorporateAction(ctx context.Context, id int64) (*models.CorporateAction, error) {
+	if action, ok := m[id]; ok {
+		return &action, nil
+	}
+	return nil, nil
+}
+
+func (m memoryActions) SaveCorporateAction(ctx context.Context, action *models.CorporateAction) error {
+	if action.ID == 0 {
+		action.ID = int64(len(m) + 1)
+	}
+	m[action.ID] = *action
+	return nil
+}
+
+func TestAdminCorporateActions(t *testing.T) {
+	_, handler := newTestServer()
+	assert.Equal(t, http.StatusServiceUnavailable, do(t, handler, http.MethodGet, "/admin/corporate-actions?symbol=AAPL", "secret", "").Code)
+
+	server := NewServer("", "secret", &fakeCollector{})
+	store := memoryActions{}
+	server.UseCorporateActions(corpactions.NewBook(store))
+	handler = server.Handler()
+
+	rec := do(t, handler, http.MethodPost, "/admin/corporate-actions", "secret",
+		`{"symbol": "aapl", "type": "split", "ex_date": "2020-08-31T00:00:00Z", "ratio": "4"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, corpactions.SourceManual, store[1].Source)
+
+	rec = do(t, handler, http.MethodDelete, "/admin/corporate-actions/1", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.True(t, store[1].Cancelled)
+
+	rec = do(t, handler, http.MethodGet, "/admin/corporate-actions?symbol=AAPL", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"cancelled":true`)
+
+	assert.Equal(t, http.StatusNotFound, do(t, handler, http.MethodGet, "/admin/corporate-actions/9", "secret", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodGet, "/admin/corporate-actions", "secret", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodPut, "/admin/corporate-actions/1", "secret",
+		`{"symbol": "AAPL", "type": "split", "ex_date": "2020-08-31T00:00:00Z", "ratio": "0.25"}`).Code)
+}
@@ -0,0 +1,90 @@
+package backfill
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+)
+
+// Checkpoint records how far a symbol has been backfilled for one job
+type Checkpoint struct {
+	Symbol    string    `json:"symbol"`
+	Through   time.Time `json:"through"` // end of the last stored chunk
+	Bars      int       `json:"bars"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointStore persists checkpoints in BadgerDB so an interrupted backfill
+// resumes at the first chunk it had not stored. Keys are
+//
+//	backfill:<interval>:<start>:<end>:<symbol> -> Checkpoint JSON
+//
+// with "open" for the end of an open-ended job, so rerunning the same
+// command resumes, even on a later day, while a different range or interval
+// starts afresh.
+type CheckpointStore struct {
+	db *badger.DB
+}
+
+// NewCheckpointStore opens the store at path; an empty path keeps it in memory
+func NewCheckpointStore(path string) (*CheckpointStore, error) {
+	opts := badger.DefaultOptions(path).
+		WithSyncWrites(true).
+		WithLogger(nil)
+	if path == "" {
+		opts = opts.WithInMemory(true)
+	}
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backfill checkpoints: %w", err)
+	}
+	return &CheckpointStore{db: db}, nil
+}
+
+func (s *CheckpointStore) Close() error {
+	return s.db.Close()
+}
+
+// Load returns the checkpoint for symbol in job, or nil if it has none
+func (s *CheckpointStore) Load(job Job, symbol string) (*Checkpoint, error) {
+	var cp *Checkpoint
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(checkpointKey(job, symbol))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			cp = &Checkpoint{}
+			return json.Unmarshal(val, cp)
+		})
+	})
+	return cp, err
+}
+
+// Save stores cp for its symbol in job
+func (s *CheckpointStore) Save(job Job, cp *Checkpoint) error {
+	raw, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(checkpointKey(job, cp.Symbol), raw)
+	})
+}
+
+func checkpointKey(job Job, symbol string) []byte {
+	end := strconv.FormatInt(job.End.Unix(), 10)
+	if job.OpenEnded {
+		end = "open"
+	}
+	return []byte(fmt.Sprintf("backfill:%s:%d:%s:%s", job.Interval, job.Start.Unix(), end, symbol))
+}
@@ -0,0 +1,56 @@
+package backfill
+
+import (
+	"fmt"
+	"time"
+)
+
+// Chunk is one provider request worth of history, [Start, End)
+type Chunk struct {
+	Start time.Time
+	End   time.Time
+}
+
+// chunkSizes keeps each request within what providers return in one call.
+// Yahoo serves at most 7 days of 1m bars per request and caps the other
+// intraday intervals at 60 days of history.
+var chunkSizes = map[string]time.Duration{
+	"1m":  7 * 24 * time.Hour,
+	"2m":  30 * 24 * time.Hour,
+	"5m":  30 * 24 * time.Hour,
+	"15m": 30 * 24 * time.Hour,
+	"30m": 30 * 24 * time.Hour,
+	"90m": 30 * 24 * time.Hour,
+	"60m": 180 * 24 * time.Hour,
+	"1h":  180 * 24 * time.Hour,
+	"1d":  365 * 24 * time.Hour,
+	"1wk": 10 * 365 * 24 * time.Hour,
+	"1mo": 10 * 365 * 24 * time.Hour,
+}
+
+// ChunkSize returns how much history is requested at once for interval
+func ChunkSize(interval string) (time.Duration, error) {
+	size, ok := chunkSizes[interval]
+	if !ok {
+		return 0, fmt.Errorf("unsupported backfill interval %q", interval)
+	}
+	return size, nil
+}
+
+// Plan splits [start, end) into consecutive chunks sized for interval
+func Plan(start, end time.Time, interval string) ([]Chunk, error) {
+	size, err := ChunkSize(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []Chunk
+	for from := start; from.Before(end); from = from.Add(size) {
+		to := from.Add(size)
+		if to.After(end) {
+			to = end
+		}
+		chunks = append(chunks, Chunk{Start: from, End: to})
+	}
+	return chunks, nil
+}
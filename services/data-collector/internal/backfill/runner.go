@@ -0,0 +1,200 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"tradecaptain/data-collector/internal/collector"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// HistorySource fetches bars in [start, end). The collector's ProviderPool
+// implements it, rate limits and failover included.
+type HistorySource interface {
+	GetHistoricalRange(ctx context.Context, symbol string, start, end time.Time, interval string) ([]*models.MarketData, error)
+}
+
+// Sink stores one chunk of bars
+type Sink func(ctx context.Context, bars []*models.MarketData) error
+
+// Job is a backfill of Symbols over [Start, End) at Interval. An open-ended
+// job's End is just today's date: its checkpoints are kept without it, so a
+// rerun on a later day resumes them and extends them to the new End.
+type Job struct {
+	Symbols   []string
+	Start     time.Time
+	End       time.Time
+	Interval  string
+	OpenEnded bool
+}
+
+// Summary is what a run stored
+type Summary struct {
+	Symbols int
+	Chunks  int
+	Bars    int
+	Failed  map[string]string // symbol -> last error
+}
+
+// Runner works through a job chunk by chunk, checkpointing after every stored
+// chunk and printing progress with an ETA
+type Runner struct {
+	source      HistorySource
+	sink        Sink
+	checkpoints *CheckpointStore
+	out         io.Writer
+
+	maxAttempts int
+	retryDelay  time.Duration
+	now         func() time.Time
+}
+
+func NewRunner(source HistorySource, sink Sink, checkpoints *CheckpointStore, out io.Writer) *Runner {
+	return &Runner{
+		source:      source,
+		sink:        sink,
+		checkpoints: checkpoints,
+		out:         out,
+		maxAttempts: 3,
+		retryDelay:  5 * time.Second,
+		now:         time.Now,
+	}
+}
+
+// symbolPlan is the remaining work for one symbol
+type symbolPlan struct {
+	checkpoint *Checkpoint
+	chunks     []Chunk
+}
+
+// Run backfills job. Symbols already completed under the same job are skipped
+// and partly done ones resume after their last stored chunk. A symbol whose
+// chunk keeps failing is reported in the summary and the run moves on; if the
+// context is cancelled, Run stops and the next run picks up from there.
+func (r *Runner) Run(ctx context.Context, job Job) (Summary, error) {
+	summary := Summary{Failed: make(map[string]string)}
+	if !job.Start.Before(job.End) {
+		return summary, fmt.Errorf("backfill start %s must be before end %s",
+			job.Start.Format("2006-01-02"), job.End.Format("2006-01-02"))
+	}
+	if len(job.Symbols) == 0 {
+		return summary, errors.New("at least one symbol is required")
+	}
+
+	plans := make([]symbolPlan, 0, len(job.Symbols))
+	total := 0
+	for _, symbol := range job.Symbols {
+		cp, err := r.checkpoints.Load(job, symbol)
+		if err != nil {
+			return summary, err
+		}
+		if cp == nil {
+			cp = &Checkpoint{Symbol: symbol, Through: job.Start}
+		}
+		if cp.Done && !cp.Through.Before(job.End) {
+			fmt.Fprintf(r.out, "%s: already backfilled (%d bars)\n", symbol, cp.Bars)
+			continue
+		}
+
+		chunks, err := Plan(cp.Through, job.End, job.Interval)
+		if err != nil {
+			return summary, err
+		}
+		plans = append(plans, symbolPlan{checkpoint: cp, chunks: chunks})
+		total += len(chunks)
+	}
+
+	started := r.now()
+	for _, plan := range plans {
+		cp := plan.checkpoint
+		summary.Symbols++
+
+		for i, chunk := range plan.chunks {
+			bars, err := r.fetch(ctx, cp.Symbol, chunk, job.Interval)
+			if err == nil && len(bars) > 0 {
+				err = r.sink(ctx, bars)
+			}
+			if ctx.Err() != nil {
+				return summary, ctx.Err()
+			}
+			if err != nil {
+				summary.Failed[cp.Symbol] = err.Error()
+				fmt.Fprintf(r.out, "%s: giving up at %s: %v\n", cp.Symbol, chunk.Start.Format("2006-01-02"), err)
+				// The remaining chunks of this symbol no longer count towards the ETA
+				total -= len(plan.chunks) - i
+				break
+			}
+
+			cp.Through = chunk.End
+			cp.Bars += len(bars)
+			cp.Done = !chunk.End.Before(job.End)
+			cp.UpdatedAt = r.now()
+			if err := r.checkpoints.Save(job, cp); err != nil {
+				return summary, fmt.Errorf("failed to save checkpoint for %s: %w", cp.Symbol, err)
+			}
+
+			summary.Chunks++
+			summary.Bars += len(bars)
+			r.progress(summary.Chunks, total, started, cp.Symbol, chunk, len(bars))
+		}
+	}
+
+	if len(summary.Failed) > 0 {
+		failed := make([]string, 0, len(summary.Failed))
+		for symbol := range summary.Failed {
+			failed = append(failed, symbol)
+		}
+		sort.Strings(failed)
+		return summary, fmt.Errorf("backfill incomplete for %s", strings.Join(failed, ", "))
+	}
+	return summary, nil
+}
+
+// fetch gets one chunk, retrying transient failures. A chunk no provider has
+// data for (a holiday week, or intraday history past what providers keep) is
+// stored as empty so the run moves past it.
+func (r *Runner) fetch(ctx context.Context, symbol string, chunk Chunk, interval string) ([]*models.MarketData, error) {
+	var err error
+	for attempt := 1; attempt <= r.maxAttempts; attempt++ {
+		var bars []*models.MarketData
+		bars, err = r.source.GetHistoricalRange(ctx, symbol, chunk.Start, chunk.End, interval)
+		if err == nil {
+			return bars, nil
+		}
+		if errors.Is(err, collector.ErrNoHistory) {
+			return nil, nil
+		}
+		if ctx.Err() != nil || attempt == r.maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(r.retryDelay * time.Duration(attempt)):
+		}
+	}
+	return nil, err
+}
+
+func (r *Runner) progress(done, total int, started time.Time, symbol string, chunk Chunk, bars int) {
+	pct := 100.0
+	if total > 0 {
+		pct = float64(done) / float64(total) * 100
+	}
+
+	eta := "done"
+	if done < total {
+		elapsed := r.now().Sub(started)
+		eta = (elapsed / time.Duration(done) * time.Duration(total-done)).Round(time.Second).String()
+	}
+
+	fmt.Fprintf(r.out, "[%d/%d %5.1f%%] %s %s..%s: %d bars, ETA %s\n",
+		done, total, pct, symbol,
+		chunk.Start.Format("2006-01-02"), chunk.End.Format("2006-01-02"), bars, eta)
+}
@@ -0,0 +1,194 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/collector"
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource returns one bar per chunk and can fail or cancel on demand
+type fakeSource struct {
+	calls    []string
+	failFor  map[string]error
+	onCall   func(n int)
+	noneFrom time.Time
+}
+
+func (f *fakeSource) GetHistoricalRange(ctx context.Context, symbol string, start, end time.Time, interval string) ([]*models.MarketData, error) {
+	f.calls = append(f.calls, fmt.Sprintf("%s %s", symbol, start.Format("2006-01-02")))
+	if f.onCall != nil {
+		f.onCall(len(f.calls))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err, ok := f.failFor[symbol]; ok {
+		return nil, err
+	}
+	if !f.noneFrom.IsZero() && !start.Before(f.noneFrom) {
+		return nil, collector.ErrNoHistory
+	}
+	return []*models.MarketData{{Symbol: symbol, Timestamp: start, Close: decimal.FromInt(100)}}, nil
+}
+
+func newTestRunner(t *testing.T, source HistorySource, path string) (*Runner, *[]*models.MarketData) {
+	store, err := NewCheckpointStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	var stored []*models.MarketData
+	r := NewRunner(source, func(ctx context.Context, bars []*models.MarketData) error {
+		stored = append(stored, bars...)
+		return nil
+	}, store, io.Discard)
+	r.retryDelay = 0
+	return r, &stored
+}
+
+func TestPlan(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	chunks, err := Plan(start, end, "1d")
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, start, chunks[0].Start)
+	assert.Equal(t, chunks[0].End, chunks[1].Start)
+	assert.Equal(t, end, chunks[2].End)
+
+	chunks, err = Plan(start, start.AddDate(0, 0, 20), "1m")
+	require.NoError(t, err)
+	assert.Len(t, chunks, 3)
+
+	_, err = Plan(start, end, "3d")
+	assert.Error(t, err)
+}
+
+func TestRunnerResumesFromCheckpoint(t *testing.T) {
+	path := t.TempDir()
+	job := Job{
+		Symbols:  []string{"AAPL", "MSFT"},
+		Start:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Interval: "5m", // 30 day chunks: Jan 1, Jan 31, Mar 1
+	}
+
+	// Crash partway through MSFT's first chunk
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &fakeSource{onCall: func(n int) {
+		if n == 3 {
+			cancel()
+		}
+	}}
+	r, stored := newTestRunner(t, source, path)
+	_, err := r.Run(ctx, job)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, *stored, 2)
+	require.NoError(t, r.checkpoints.Close())
+
+	// The rerun only fetches what was not stored
+	source = &fakeSource{}
+	r, stored = newTestRunner(t, source, path)
+	summary, err := r.Run(context.Background(), job)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MSFT 2024-01-01", "MSFT 2024-01-31"}, source.calls)
+	assert.Equal(t, 2, summary.Bars)
+	assert.Len(t, *stored, 2)
+
+	cp, err := r.checkpoints.Load(job, "MSFT")
+	require.NoError(t, err)
+	assert.True(t, cp.Done)
+	assert.Equal(t, job.End, cp.Through.UTC())
+
+	// A finished job does nothing
+	source = &fakeSource{}
+	r.source = source
+	_, err = r.Run(context.Background(), job)
+	require.NoError(t, err)
+	assert.Empty(t, source.calls)
+}
+
+func TestRunnerResumesOpenEndedJobOnALaterDay(t *testing.T) {
+	path := t.TempDir()
+	job := Job{
+		Symbols:   []string{"AAPL", "MSFT"},
+		Start:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:       time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), // no -end, so today
+		Interval:  "5m",
+		OpenEnded: true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &fakeSource{onCall: func(n int) {
+		if n == 3 {
+			cancel()
+		}
+	}}
+	r, _ := newTestRunner(t, source, path)
+	_, err := r.Run(ctx, job)
+	require.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, r.checkpoints.Close())
+
+	// Restarted after midnight the default end has moved on a day: AAPL is
+	// extended and MSFT resumes where it was instead of starting over
+	job.End = job.End.AddDate(0, 0, 1)
+	source = &fakeSource{}
+	r, _ = newTestRunner(t, source, path)
+	_, err = r.Run(context.Background(), job)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL 2024-03-01", "MSFT 2024-01-01", "MSFT 2024-01-31", "MSFT 2024-03-01"}, source.calls)
+
+	cp, err := r.checkpoints.Load(job, "AAPL")
+	require.NoError(t, err)
+	assert.True(t, cp.Done)
+	assert.Equal(t, 3, cp.Bars)
+	assert.Equal(t, job.End, cp.Through.UTC())
+
+	// An explicit end is part of the job, so it does not share those checkpoints
+	job.OpenEnded = false
+	cp, err = r.checkpoints.Load(job, "AAPL")
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+}
+
+func TestRunnerSkipsEmptyChunksAndReportsFailures(t *testing.T) {
+	source := &fakeSource{
+		failFor:  map[string]error{"BAD": errors.New("HTTP 500")},
+		noneFrom: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	r, stored := newTestRunner(t, source, "")
+
+	job := Job{
+		Symbols:  []string{"BAD", "AAPL"},
+		Start:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Interval: "5m",
+	}
+	summary, err := r.Run(context.Background(), job)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BAD")
+	assert.Equal(t, "HTTP 500", summary.Failed["BAD"])
+
+	// BAD was tried three times, then AAPL ran through its empty second chunk
+	assert.Len(t, source.calls, 5)
+	assert.Len(t, *stored, 1)
+
+	cp, err := r.checkpoints.Load(job, "AAPL")
+	require.NoError(t, err)
+	assert.True(t, cp.Done)
+	assert.Equal(t, 1, cp.Bars)
+
+	cp, err = r.checkpoints.Load(job, "BAD")
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+}
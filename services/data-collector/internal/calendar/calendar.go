@@ -0,0 +1,448 @@
+// Package calendar knows when exchanges trade: weekends, holidays, half days and
+// the pre-market/regular/after-hours sessions, all in the exchange's own timezone.
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Containers often ship without a zoneinfo database
+	_ "time/tzdata"
+)
+
+//go:embed holidays.csv
+var holidayTable []byte
+
+// Session is the trading session an exchange is in at a given instant. The
+// string values are what gets stored in market_session columns.
+type Session int
+
+const (
+	SessionClosed Session = iota
+	SessionPreMarket
+	SessionRegular
+	SessionBreak
+	SessionAfterHours
+)
+
+func (s Session) String() string {
+	switch s {
+	case SessionPreMarket:
+		return "pre_market"
+	case SessionRegular:
+		return "market_hours"
+	case SessionBreak:
+		return "break"
+	case SessionAfterHours:
+		return "after_hours"
+	default:
+		return "closed"
+	}
+}
+
+// Extended reports whether the session is outside regular hours but still trading
+func (s Session) Extended() bool {
+	return s == SessionPreMarket || s == SessionAfterHours
+}
+
+// clock is minutes after local midnight
+type clock int
+
+func hm(hour, minute int) clock {
+	return clock(hour*60 + minute)
+}
+
+type span struct {
+	start, end clock
+}
+
+func (s span) valid() bool {
+	return s.end > s.start
+}
+
+type holiday struct {
+	closed bool
+	close  clock // early close time when not closed
+}
+
+// Exchange is one venue's trading schedule
+type Exchange struct {
+	Code       string
+	Name       string
+	Location   *time.Location
+	AlwaysOpen bool
+
+	holidayCalendar string
+	preMarket       span
+	regular         []span // more than one when the exchange breaks for lunch
+	afterHours      span
+	holidays        map[string]holiday // keyed by local date
+}
+
+type interval struct {
+	session    Session
+	start, end time.Time
+}
+
+// searchDays bounds next-open/next-close lookups; the longest closure in the
+// tables (Golden Week, year end) is well under this
+const searchDays = 14
+
+// Session returns the session the exchange is in at t
+func (e *Exchange) Session(t time.Time) Session {
+	if e.AlwaysOpen {
+		return SessionRegular
+	}
+
+	local := t.In(e.Location)
+	for _, iv := range e.intervals(local.Date()) {
+		if !local.Before(iv.start) && local.Before(iv.end) {
+			return iv.session
+		}
+	}
+	return SessionClosed
+}
+
+// IsOpen reports whether regular trading is in progress at t
+func (e *Exchange) IsOpen(t time.Time) bool {
+	return e.Session(t) == SessionRegular
+}
+
+// IsTradingDay reports whether the exchange trades at all on t's local date
+func (e *Exchange) IsTradingDay(t time.Time) bool {
+	if e.AlwaysOpen {
+		return true
+	}
+	return len(e.intervals(t.In(e.Location).Date())) > 0
+}
+
+// NextOpen returns the next start of regular trading after t. For exchanges
+// with a lunch break this includes the afternoon reopen. Always-open venues
+// return t.
+func (e *Exchange) NextOpen(t time.Time) time.Time {
+	if e.AlwaysOpen {
+		return t
+	}
+	return e.next(t, func(iv interval) (time.Time, bool) {
+		return iv.start, iv.session == SessionRegular && iv.start.After(t)
+	})
+}
+
+// NextClose returns the end of the current regular session, or of the next one
+// if the exchange is not open at t. Always-open venues return the zero time.
+func (e *Exchange) NextClose(t time.Time) time.Time {
+	if e.AlwaysOpen {
+		return time.Time{}
+	}
+	return e.next(t, func(iv interval) (time.Time, bool) {
+		return iv.end, iv.session == SessionRegular && iv.end.After(t)
+	})
+}
+
+// NextActive returns t if any session (including pre-market and after-hours)
+// is in progress, otherwise when the next one begins
+func (e *Exchange) NextActive(t time.Time) time.Time {
+	if e.AlwaysOpen || e.Session(t) != SessionClosed {
+		return t
+	}
+	return e.next(t, func(iv interval) (time.Time, bool) {
+		return iv.start, iv.start.After(t)
+	})
+}
+
+// Window is one stretch of regular trading
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// RegularSessions returns the regular trading windows overlapping [from, to),
+// clipped to that range. Always-open venues have no session grid and return nil.
+func (e *Exchange) RegularSessions(from, to time.Time) []Window {
+	if e.AlwaysOpen || !from.Before(to) {
+		return nil
+	}
+
+	var windows []Window
+	year, month, day := from.In(e.Location).Date()
+	for i := 0; ; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, e.Location)
+		if !date.Before(to) {
+			return windows
+		}
+		for _, iv := range e.intervals(date.Date()) {
+			if iv.session != SessionRegular || !iv.end.After(from) || !iv.start.Before(to) {
+				continue
+			}
+			w := Window{Start: iv.start, End: iv.end}
+			if w.Start.Before(from) {
+				w.Start = from
+			}
+			if w.End.After(to) {
+				w.End = to
+			}
+			windows = append(windows, w)
+		}
+	}
+}
+
+func (e *Exchange) next(t time.Time, match func(interval) (time.Time, bool)) time.Time {
+	year, month, day := t.In(e.Location).Date()
+	for i := 0; i < searchDays; i++ {
+		// Noon avoids DST edge cases when stepping days
+		date := time.Date(year, month, day+i, 12, 0, 0, 0, e.Location)
+		for _, iv := range e.intervals(date.Date()) {
+			if at, ok := match(iv); ok {
+				return at
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// intervals lays out the sessions of one local date, applying weekends,
+// holidays and early closes. After-hours keeps its length but moves up to the
+// early close, as the US exchanges do on half days.
+func (e *Exchange) intervals(year int, month time.Month, day int) []interval {
+	date := time.Date(year, month, day, 0, 0, 0, 0, e.Location)
+	if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday || len(e.regular) == 0 {
+		return nil
+	}
+
+	h, isHoliday := e.holidays[date.Format("2006-01-02")]
+	if isHoliday && h.closed {
+		return nil
+	}
+
+	at := func(c clock) time.Time {
+		return time.Date(year, month, day, int(c)/60, int(c)%60, 0, 0, e.Location)
+	}
+
+	normalClose := e.regular[len(e.regular)-1].end
+	closeAt := normalClose
+	if isHoliday && h.close < normalClose {
+		closeAt = h.close
+	}
+
+	var out []interval
+	if e.preMarket.valid() {
+		out = append(out, interval{SessionPreMarket, at(e.preMarket.start), at(e.preMarket.end)})
+	}
+
+	var lastEnd clock = -1
+	for _, r := range e.regular {
+		if r.start >= closeAt {
+			break
+		}
+		end := r.end
+		if end > closeAt {
+			end = closeAt
+		}
+		if lastEnd >= 0 {
+			out = append(out, interval{SessionBreak, at(lastEnd), at(r.start)})
+		}
+		out = append(out, interval{SessionRegular, at(r.start), at(end)})
+		lastEnd = end
+	}
+
+	if e.afterHours.valid() {
+		shift := normalClose - closeAt
+		out = append(out, interval{SessionAfterHours, at(e.afterHours.start - shift), at(e.afterHours.end - shift)})
+	}
+	return out
+}
+
+// Calendar is a set of exchanges with their holiday tables loaded
+type Calendar struct {
+	exchanges map[string]*Exchange
+	aliases   map[string]string
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultCalendar *Calendar
+)
+
+// Default returns the calendar built from the embedded holiday tables
+func Default() *Calendar {
+	defaultOnce.Do(func() {
+		cal, err := New(bytes.NewReader(holidayTable))
+		if err != nil {
+			panic(fmt.Sprintf("calendar: embedded holiday table: %v", err))
+		}
+		defaultCalendar = cal
+	})
+	return defaultCalendar
+}
+
+// New builds the standard exchanges (NYSE, NASDAQ, LSE, TSE, CRYPTO) with
+// holidays read from table, in the same format as the embedded holidays.csv
+func New(table io.Reader) (*Calendar, error) {
+	holidays, err := parseHolidays(table)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{
+		exchanges: make(map[string]*Exchange),
+		aliases: map[string]string{
+			"XNYS": "NYSE", "NYQ": "NYSE", "NYS": "NYSE",
+			"XNAS": "NASDAQ", "NMS": "NASDAQ", "NGM": "NASDAQ", "NCM": "NASDAQ", "NAS": "NASDAQ", "NASDAQGS": "NASDAQ",
+			"XLON": "LSE", "LON": "LSE",
+			"XTKS": "TSE", "JPX": "TSE", "TYO": "TSE",
+			"CCC": "CRYPTO",
+		},
+	}
+
+	for _, ex := range builtinExchanges() {
+		ex.holidays = holidays[ex.holidayCalendar]
+		cal.exchanges[ex.Code] = ex
+	}
+	return cal, nil
+}
+
+func builtinExchanges() []*Exchange {
+	newYork := mustLoadLocation("America/New_York")
+	us := func(code, name string) *Exchange {
+		return &Exchange{
+			Code:            code,
+			Name:            name,
+			Location:        newYork,
+			holidayCalendar: "US",
+			preMarket:       span{hm(4, 0), hm(9, 30)},
+			regular:         []span{{hm(9, 30), hm(16, 0)}},
+			afterHours:      span{hm(16, 0), hm(20, 0)},
+		}
+	}
+
+	return []*Exchange{
+		us("NYSE", "New York Stock Exchange"),
+		us("NASDAQ", "Nasdaq"),
+		{
+			Code:            "LSE",
+			Name:            "London Stock Exchange",
+			Location:        mustLoadLocation("Europe/London"),
+			holidayCalendar: "UK",
+			regular:         []span{{hm(8, 0), hm(16, 30)}},
+		},
+		{
+			Code:            "TSE",
+			Name:            "Tokyo Stock Exchange",
+			Location:        mustLoadLocation("Asia/Tokyo"),
+			holidayCalendar: "JP",
+			regular:         []span{{hm(9, 0), hm(11, 30)}, {hm(12, 30), hm(15, 30)}},
+		},
+		{
+			Code:       "CRYPTO",
+			Name:       "Crypto (24/7)",
+			Location:   time.UTC,
+			AlwaysOpen: true,
+		},
+	}
+}
+
+// Exchange looks up an exchange by code or common alias (MIC, Yahoo exchange code)
+func (c *Calendar) Exchange(code string) (*Exchange, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if canonical, ok := c.aliases[code]; ok {
+		code = canonical
+	}
+	ex, ok := c.exchanges[code]
+	return ex, ok
+}
+
+// Exchanges returns every exchange sorted by code
+func (c *Calendar) Exchanges() []*Exchange {
+	out := make([]*Exchange, 0, len(c.exchanges))
+	for _, ex := range c.exchanges {
+		out = append(out, ex)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// cryptoQuoteCurrencies are the quote legs that mark a Yahoo-style pair (BTC-USD) as crypto
+var cryptoQuoteCurrencies = map[string]bool{
+	"USD": true, "USDT": true, "USDC": true, "EUR": true, "GBP": true, "BTC": true, "ETH": true,
+}
+
+// Resolve picks the exchange for a quote: the reported exchange code when it is
+// known, otherwise the symbol's suffix (.L London, .T Tokyo, BTC-USD crypto),
+// falling back to NYSE
+func (c *Calendar) Resolve(exchange, symbol string) *Exchange {
+	if ex, ok := c.Exchange(exchange); ok && exchange != "" {
+		return ex
+	}
+
+	symbol = strings.ToUpper(symbol)
+	switch {
+	case strings.HasSuffix(symbol, ".L"):
+		return c.exchanges["LSE"]
+	case strings.HasSuffix(symbol, ".T"):
+		return c.exchanges["TSE"]
+	}
+	if i := strings.LastIndex(symbol, "-"); i > 0 && cryptoQuoteCurrencies[symbol[i+1:]] {
+		return c.exchanges["CRYPTO"]
+	}
+	return c.exchanges["NYSE"]
+}
+
+func parseHolidays(r io.Reader) (map[string]map[string]holiday, error) {
+	tables := make(map[string]map[string]holiday)
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ",")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: expected calendar,date,kind", line)
+		}
+		name, date, kind := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), strings.TrimSpace(fields[2])
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("line %d: bad date %q", line, date)
+		}
+
+		var h holiday
+		switch kind {
+		case "closed":
+			h.closed = true
+		case "early":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("line %d: early close needs a close time", line)
+			}
+			closeAt, err := time.Parse("15:04", strings.TrimSpace(fields[3]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad close time %q", line, fields[3])
+			}
+			h.close = hm(closeAt.Hour(), closeAt.Minute())
+		default:
+			return nil, fmt.Errorf("line %d: unknown kind %q", line, kind)
+		}
+
+		if tables[name] == nil {
+			tables[name] = make(map[string]holiday)
+		}
+		tables[name][date] = h
+	}
+
+	return tables, scanner.Err()
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("calendar: load %s: %v", name, err))
+	}
+	return loc
+}
@@ -0,0 +1,118 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(t *testing.T, ex *Exchange, value string) time.Time {
+	ts, err := time.ParseInLocation("2006-01-02 15:04", value, ex.Location)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestNYSESessions(t *testing.T) {
+	nyse, ok := Default().Exchange("XNYS")
+	require.True(t, ok)
+
+	assert.Equal(t, SessionPreMarket, nyse.Session(at(t, nyse, "2024-03-12 08:00")))
+	assert.Equal(t, SessionRegular, nyse.Session(at(t, nyse, "2024-03-12 09:30")))
+	assert.Equal(t, SessionAfterHours, nyse.Session(at(t, nyse, "2024-03-12 16:00")))
+	assert.Equal(t, SessionClosed, nyse.Session(at(t, nyse, "2024-03-12 20:00")))
+
+	// The session follows New York, not the caller's timezone, across DST
+	assert.Equal(t, SessionRegular, nyse.Session(time.Date(2024, 3, 8, 14, 30, 0, 0, time.UTC)))  // EST: 09:30
+	assert.Equal(t, SessionRegular, nyse.Session(time.Date(2024, 3, 11, 13, 30, 0, 0, time.UTC))) // EDT: 09:30
+	assert.Equal(t, SessionPreMarket, nyse.Session(time.Date(2024, 3, 8, 13, 30, 0, 0, time.UTC)))
+
+	// Weekend and holiday
+	assert.Equal(t, SessionClosed, nyse.Session(at(t, nyse, "2024-03-16 11:00")))
+	assert.Equal(t, SessionClosed, nyse.Session(at(t, nyse, "2024-07-04 11:00")))
+	assert.False(t, nyse.IsTradingDay(at(t, nyse, "2024-12-25 11:00")))
+}
+
+func TestNYSEHalfDay(t *testing.T) {
+	nyse, _ := Default().Exchange("NYSE")
+
+	assert.Equal(t, SessionRegular, nyse.Session(at(t, nyse, "2024-11-29 12:59")))
+	assert.Equal(t, SessionAfterHours, nyse.Session(at(t, nyse, "2024-11-29 13:00")))
+	assert.Equal(t, SessionClosed, nyse.Session(at(t, nyse, "2024-11-29 17:00")))
+	assert.Equal(t, at(t, nyse, "2024-11-29 13:00"), nyse.NextClose(at(t, nyse, "2024-11-29 10:00")))
+}
+
+func TestNextOpenAndClose(t *testing.T) {
+	nyse, _ := Default().Exchange("NYSE")
+
+	// Wednesday evening before Thanksgiving: Thursday is closed, Friday is a half day
+	wed := at(t, nyse, "2024-11-27 18:00")
+	assert.Equal(t, at(t, nyse, "2024-11-29 09:30"), nyse.NextOpen(wed))
+	assert.Equal(t, at(t, nyse, "2024-11-29 13:00"), nyse.NextClose(wed))
+	assert.Equal(t, at(t, nyse, "2024-11-27 18:00"), nyse.NextActive(wed))
+	assert.Equal(t, at(t, nyse, "2024-11-29 04:00"), nyse.NextActive(at(t, nyse, "2024-11-27 21:00")))
+
+	// Friday after close skips the weekend
+	assert.Equal(t, at(t, nyse, "2024-12-02 09:30"), nyse.NextOpen(at(t, nyse, "2024-11-29 16:00")))
+}
+
+func TestTokyoLunchBreak(t *testing.T) {
+	tse, ok := Default().Exchange("TSE")
+	require.True(t, ok)
+
+	assert.Equal(t, SessionRegular, tse.Session(at(t, tse, "2025-06-02 11:00")))
+	assert.Equal(t, SessionBreak, tse.Session(at(t, tse, "2025-06-02 12:00")))
+	assert.Equal(t, at(t, tse, "2025-06-02 12:30"), tse.NextOpen(at(t, tse, "2025-06-02 11:45")))
+	assert.Equal(t, SessionClosed, tse.Session(at(t, tse, "2025-06-02 15:30")))
+
+	// Golden Week: Sat 3 May through Tue 6 May 2025
+	assert.Equal(t, at(t, tse, "2025-05-07 09:00"), tse.NextOpen(at(t, tse, "2025-05-02 16:00")))
+}
+
+func TestLondon(t *testing.T) {
+	lse, _ := Default().Exchange("LSE")
+
+	// 08:00 London is 07:00 UTC in summer
+	assert.Equal(t, SessionRegular, lse.Session(time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC)))
+	assert.Equal(t, SessionClosed, lse.Session(at(t, lse, "2025-12-24 13:00")))
+	assert.Equal(t, SessionClosed, lse.Session(at(t, lse, "2025-12-26 10:00")))
+}
+
+func TestResolve(t *testing.T) {
+	cal := Default()
+
+	assert.Equal(t, "NASDAQ", cal.Resolve("NMS", "AAPL").Code)
+	assert.Equal(t, "LSE", cal.Resolve("", "VOD.L").Code)
+	assert.Equal(t, "TSE", cal.Resolve("", "7203.T").Code)
+	assert.Equal(t, "CRYPTO", cal.Resolve("", "BTC-USD").Code)
+	assert.Equal(t, "NYSE", cal.Resolve("", "BRK-B").Code)
+	assert.Equal(t, "NYSE", cal.Resolve("UNKNOWN", "IBM").Code)
+
+	crypto := cal.Resolve("", "ETH-USD")
+	sunday := time.Date(2024, 12, 29, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, SessionRegular, crypto.Session(sunday))
+	assert.Equal(t, sunday, crypto.NextOpen(sunday))
+}
+
+func TestParseHolidaysRejectsBadRows(t *testing.T) {
+	_, err := New(strings.NewReader("US,2024-13-01,closed\n"))
+	assert.Error(t, err)
+
+	_, err = New(strings.NewReader("US,2024-12-24,early\n"))
+	assert.Error(t, err)
+}
+
+func TestRegularSessions(t *testing.T) {
+	tse, _ := Default().Exchange("TSE")
+
+	// Friday 2 May to Wednesday 7 May 2025 spans Golden Week; Friday has a lunch break
+	windows := tse.RegularSessions(at(t, tse, "2025-05-02 10:00"), at(t, tse, "2025-05-07 10:00"))
+	require.Len(t, windows, 3)
+	assert.Equal(t, Window{Start: at(t, tse, "2025-05-02 10:00"), End: at(t, tse, "2025-05-02 11:30")}, windows[0])
+	assert.Equal(t, Window{Start: at(t, tse, "2025-05-02 12:30"), End: at(t, tse, "2025-05-02 15:30")}, windows[1])
+	assert.Equal(t, Window{Start: at(t, tse, "2025-05-07 09:00"), End: at(t, tse, "2025-05-07 10:00")}, windows[2])
+
+	assert.Nil(t, Default().Resolve("", "BTC-USD").RegularSessions(time.Now().Add(-time.Hour), time.Now()))
+}
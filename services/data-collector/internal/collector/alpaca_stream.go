@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// AlpacaStreamFeed is the StreamFeed for Alpaca's v2 stock data stream
+// (wss://stream.data.alpaca.markets/v2/iex, or /v2/sip with a paid plan).
+// Trades move the price; the daily bars Alpaca pushes every minute carry the
+// session's open, high, low and volume, which trades are merged into.
+type AlpacaStreamFeed struct {
+	keyID  string
+	secret string
+
+	mu   sync.Mutex
+	days map[string]alpacaEvent // latest daily bar by symbol
+}
+
+func NewAlpacaStreamFeed(keyID, secret string) *AlpacaStreamFeed {
+	return &AlpacaStreamFeed{
+		keyID:  keyID,
+		secret: secret,
+		days:   make(map[string]alpacaEvent),
+	}
+}
+
+// Name identifies the feed in MarketData.Source and in configuration
+func (a *AlpacaStreamFeed) Name() string {
+	return "alpaca"
+}
+
+// alpacaEvent covers the control ("success", "error", "subscription"), trade
+// ("t") and daily bar ("d") messages. Alpaca sends them in JSON arrays. Keys
+// differing only in case are all declared, since encoding/json would
+// otherwise fold the trade size "s" into the symbol "S".
+type alpacaEvent struct {
+	Type   string    `json:"T"`
+	Msg    string    `json:"msg"`
+	Code   int       `json:"code"`
+	Symbol string    `json:"S"`
+	Time   time.Time `json:"t"`
+
+	// Trades
+	Price decimal.Decimal `json:"p"`
+	Size  int64           `json:"s"`
+
+	// Daily bars. A trade's "c" is its condition list, so a bar's close is
+	// left undecoded; trades carry the price.
+	Open   decimal.Decimal `json:"o"`
+	High   decimal.Decimal `json:"h"`
+	Low    decimal.Decimal `json:"l"`
+	Volume int64           `json:"v"`
+}
+
+// Authenticate waits for the greeting, sends the key and waits for the
+// server to accept it
+func (a *AlpacaStreamFeed) Authenticate(conn *StreamConn) error {
+	if err := a.expect(conn, "connected"); err != nil {
+		return err
+	}
+	if err := conn.SendJSON(map[string]string{"action": "auth", "key": a.keyID, "secret": a.secret}); err != nil {
+		return err
+	}
+	return a.expect(conn, "authenticated")
+}
+
+// expect reads the next message and fails unless it is the success message
+// msg
+func (a *AlpacaStreamFeed) expect(conn *StreamConn, msg string) error {
+	message, err := conn.Receive()
+	if err != nil {
+		return err
+	}
+	var events []alpacaEvent
+	if err := json.Unmarshal(message, &events); err != nil {
+		return fmt.Errorf("failed to decode message: %w", err)
+	}
+	for _, event := range events {
+		switch {
+		case event.Type == "error":
+			return a.eventError(event)
+		case event.Type == "success" && event.Msg == msg:
+			return nil
+		}
+	}
+	return fmt.Errorf("expected %q, got %s", msg, message)
+}
+
+func (a *AlpacaStreamFeed) Subscribe(conn *StreamConn, symbols []string) error {
+	return conn.SendJSON(map[string]interface{}{"action": "subscribe", "trades": symbols, "dailyBars": symbols})
+}
+
+func (a *AlpacaStreamFeed) Unsubscribe(conn *StreamConn, symbols []string) error {
+	return conn.SendJSON(map[string]interface{}{"action": "unsubscribe", "trades": symbols, "dailyBars": symbols})
+}
+
+// Decode returns a quote for every trade, with the day's figures from the
+// latest daily bar of the same session
+func (a *AlpacaStreamFeed) Decode(message []byte) ([]*models.MarketData, error) {
+	var events []alpacaEvent
+	if err := json.Unmarshal(message, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var quotes []*models.MarketData
+	for _, event := range events {
+		switch event.Type {
+		case "d":
+			a.days[event.Symbol] = event
+
+		case "t":
+			if event.Price.Sign() <= 0 {
+				continue
+			}
+			quote := &models.MarketData{
+				Symbol:    event.Symbol,
+				Price:     event.Price,
+				Close:     event.Price,
+				Timestamp: event.Time.UTC(),
+				Source:    a.Name(),
+			}
+			// Daily bars are stamped at midnight New York time
+			if day, ok := a.days[event.Symbol]; ok && !event.Time.Before(day.Time) && event.Time.Sub(day.Time) < 24*time.Hour {
+				quote.Open = day.Open
+				quote.High = decimal.Max(day.High, event.Price)
+				quote.Low = decimal.Min(day.Low, event.Price)
+				quote.Volume = day.Volume
+			}
+			quotes = append(quotes, quote)
+
+		case "error":
+			return nil, a.eventError(event)
+		}
+	}
+	return quotes, nil
+}
+
+// eventError maps Alpaca's error codes: 402 is a bad key, 406 the
+// connection limit and 405 the symbol limit
+func (a *AlpacaStreamFeed) eventError(event alpacaEvent) error {
+	err := &ProviderError{Provider: a.Name(), Message: fmt.Sprintf("%s (%d)", event.Msg, event.Code)}
+	switch event.Code {
+	case 405, 406:
+		err.Err = ErrRateLimited
+	}
+	return err
+}
@@ -3,87 +3,205 @@ package collector
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"tradecaptain/data-collector/internal/decimal"
 	"tradecaptain/data-collector/internal/models"
 )
 
 type AlphaVantageClient struct {
 	httpClient  *http.Client
 	baseURL     string
+	keyMu       sync.RWMutex
 	apiKey      string
 	rateLimiter *RateLimiter
+	breaker     *CircuitBreaker
 }
 
 func NewAlphaVantageClient(apiKey string) *AlphaVantageClient {
-	// TODO: Initialize Alpha Vantage client
-	// - Set up HTTP client with appropriate timeouts
-	// - Configure rate limiting (5 requests per minute for free tier)
-	// - Set up proper API key authentication
-	// - Initialize retry logic with exponential backoff
-	// - Set up request/response logging for debugging
-	// - Configure premium tier settings if available
-	panic("TODO: Implement Alpha Vantage client initialization")
+	av := &AlphaVantageClient{
+		httpClient: newHTTPClient(15 * time.Second),
+		baseURL:    "https://www.alphavantage.co/query",
+		apiKey:     apiKey,
+	}
+	av.breaker = NewCircuitBreaker(av.Name(), DefaultCircuitBreakerConfig(), av.GetAPIHealth)
+	return av
+}
+
+// CircuitBreaker exposes the client's breaker so the collector can observe transitions
+func (av *AlphaVantageClient) CircuitBreaker() *CircuitBreaker {
+	return av.breaker
+}
+
+// SetAPIKey rotates the key; requests already built keep the old one
+func (av *AlphaVantageClient) SetAPIKey(apiKey string) {
+	av.keyMu.Lock()
+	defer av.keyMu.Unlock()
+	av.apiKey = apiKey
+}
+
+// Name identifies the provider in MarketData.Source and in configuration
+func (av *AlphaVantageClient) Name() string {
+	return "alphavantage"
 }
 
 // Real-time and Intraday Data
 func (av *AlphaVantageClient) GetQuote(ctx context.Context, symbol string) (*models.MarketData, error) {
-	// TODO: Get real-time quote using GLOBAL_QUOTE function
-	// - Build API URL with GLOBAL_QUOTE function
-	// - Add symbol and API key parameters
-	// - Make HTTP request with rate limiting
-	// - Parse Alpha Vantage JSON response format
-	// - Handle Alpha Vantage specific field names
-	// - Convert percentage strings to float values
-	// - Map response to MarketData model structure
-	// - Handle errors and API limit responses
-	panic("TODO: Implement real-time quote from Alpha Vantage")
+	body, err := av.makeRequest(ctx, av.buildRequestURL("GLOBAL_QUOTE", map[string]string{
+		"symbol": symbol,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := av.parseQuoteResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := av.validateAlphaVantageData(data); err != nil {
+		return nil, &ProviderError{Provider: av.Name(), Message: err.Error()}
+	}
+	return data, nil
+}
+
+// GetMultipleQuotes fetches quotes one symbol at a time; the free tier has no
+// batch endpoint, so failed symbols are skipped and reported together
+func (av *AlphaVantageClient) GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error) {
+	var results []*models.MarketData
+	var failed []string
+
+	for _, symbol := range symbols {
+		data, err := av.GetQuote(ctx, symbol)
+		if err != nil {
+			// Once the daily/minute quota is exhausted or the breaker trips every further call fails too
+			if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) {
+				return results, err
+			}
+			failed = append(failed, symbol)
+			continue
+		}
+		results = append(results, data)
+	}
+
+	if len(failed) > 0 {
+		return results, &ProviderError{Provider: av.Name(), Message: "no quote for " + strings.Join(failed, ",")}
+	}
+	return results, nil
+}
+
+// GetHistoricalData maps Yahoo-style period/interval arguments onto the matching
+// TIME_SERIES_* function and trims the result to the requested period
+func (av *AlphaVantageClient) GetHistoricalData(ctx context.Context, symbol string, period string, interval string) ([]*models.MarketData, error) {
+	start, err := periodStart(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*models.MarketData
+	switch interval {
+	case "1m", "5m", "15m", "30m", "60m", "1h":
+		avInterval := strings.TrimSuffix(interval, "m") + "min"
+		if interval == "1h" {
+			avInterval = "60min"
+		}
+		history, err = av.GetIntradayData(ctx, symbol, avInterval)
+	case "1d":
+		history, err = av.GetDailyData(ctx, symbol, false)
+	case "1wk":
+		history, err = av.GetWeeklyData(ctx, symbol, false)
+	case "1mo":
+		history, err = av.GetMonthlyData(ctx, symbol, false)
+	default:
+		return nil, fmt.Errorf("unsupported interval for %s: %s", av.Name(), interval)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := history[:0]
+	for _, data := range history {
+		if !data.Timestamp.Before(start) {
+			trimmed = append(trimmed, data)
+		}
+	}
+	return trimmed, nil
 }
 
 func (av *AlphaVantageClient) GetIntradayData(ctx context.Context, symbol string, interval string) ([]*models.MarketData, error) {
-	// TODO: Get intraday data using TIME_SERIES_INTRADAY
-	// - Validate interval parameter (1min, 5min, 15min, 30min, 60min)
-	// - Build API URL with TIME_SERIES_INTRADAY function
-	// - Handle outputsize parameter (compact vs full)
-	// - Parse time series JSON response structure
-	// - Convert Alpha Vantage timestamp format
-	// - Extract OHLCV data from nested JSON
-	// - Handle market hours and timezone conversions
-	// - Sort data by timestamp for consistency
-	panic("TODO: Implement intraday data retrieval from Alpha Vantage")
+	switch interval {
+	case "1min", "5min", "15min", "30min", "60min":
+	default:
+		return nil, fmt.Errorf("unsupported intraday interval: %s", interval)
+	}
+
+	body, err := av.makeRequest(ctx, av.buildRequestURL("TIME_SERIES_INTRADAY", map[string]string{
+		"symbol":     symbol,
+		"interval":   interval,
+		"outputsize": "compact",
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return av.parseTimeSeriesResponse(body, "Time Series ("+interval+")")
 }
 
 func (av *AlphaVantageClient) GetDailyData(ctx context.Context, symbol string, adjusted bool) ([]*models.MarketData, error) {
-	// TODO: Get daily historical data
-	// - Use TIME_SERIES_DAILY or TIME_SERIES_DAILY_ADJUSTED
-	// - Handle adjusted vs unadjusted data preferences
-	// - Parse daily time series response format
-	// - Handle large datasets with pagination if needed
-	// - Process dividend and split adjustments
-	// - Validate data completeness and order
-	panic("TODO: Implement daily data retrieval from Alpha Vantage")
+	function := "TIME_SERIES_DAILY"
+	if adjusted {
+		function = "TIME_SERIES_DAILY_ADJUSTED"
+	}
+
+	body, err := av.makeRequest(ctx, av.buildRequestURL(function, map[string]string{
+		"symbol":     symbol,
+		"outputsize": "full",
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return av.parseTimeSeriesResponse(body, "Time Series (Daily)")
 }
 
 func (av *AlphaVantageClient) GetWeeklyData(ctx context.Context, symbol string, adjusted bool) ([]*models.MarketData, error) {
-	// TODO: Get weekly historical data
-	// - Use TIME_SERIES_WEEKLY or TIME_SERIES_WEEKLY_ADJUSTED
-	// - Handle weekly aggregation logic
-	// - Parse weekly time series response format
-	// - Convert weekly timestamps to standard format
-	// - Process corporate actions adjustments
-	panic("TODO: Implement weekly data retrieval from Alpha Vantage")
+	function, dataKey := "TIME_SERIES_WEEKLY", "Weekly Time Series"
+	if adjusted {
+		function, dataKey = "TIME_SERIES_WEEKLY_ADJUSTED", "Weekly Adjusted Time Series"
+	}
+
+	body, err := av.makeRequest(ctx, av.buildRequestURL(function, map[string]string{
+		"symbol": symbol,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return av.parseTimeSeriesResponse(body, dataKey)
 }
 
 func (av *AlphaVantageClient) GetMonthlyData(ctx context.Context, symbol string, adjusted bool) ([]*models.MarketData, error) {
-	// TODO: Get monthly historical data
-	// - Use TIME_SERIES_MONTHLY or TIME_SERIES_MONTHLY_ADJUSTED
-	// - Handle monthly aggregation and end-of-month logic
-	// - Parse monthly time series response format
-	// - Process long-term adjustments and splits
-	// - Handle data gaps for delisted securities
-	panic("TODO: Implement monthly data retrieval from Alpha Vantage")
+	function, dataKey := "TIME_SERIES_MONTHLY", "Monthly Time Series"
+	if adjusted {
+		function, dataKey = "TIME_SERIES_MONTHLY_ADJUSTED", "Monthly Adjusted Time Series"
+	}
+
+	body, err := av.makeRequest(ctx, av.buildRequestURL(function, map[string]string{
+		"symbol": symbol,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return av.parseTimeSeriesResponse(body, dataKey)
 }
 
 // Technical Indicators
@@ -224,106 +342,310 @@ func (av *AlphaVantageClient) GetCryptoIntraday(ctx context.Context, symbol stri
 
 // Data Processing and Utilities
 func (av *AlphaVantageClient) parseTimeSeriesResponse(response []byte, dataKey string) ([]*models.MarketData, error) {
-	// TODO: Parse Alpha Vantage time series responses
-	// - Define Alpha Vantage response structure
-	// - Handle nested time series JSON objects
-	// - Extract OHLCV data from specific keys
-	// - Convert Alpha Vantage timestamp format
-	// - Map Alpha Vantage fields to MarketData
-	// - Handle missing or invalid data points
-	// - Sort results by timestamp chronologically
-	panic("TODO: Implement Alpha Vantage time series parsing")
+	if err := av.handleAlphaVantageError(response); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(response, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode Alpha Vantage response: %w", err)
+	}
+
+	var meta map[string]string
+	if err := json.Unmarshal(raw["Meta Data"], &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode Alpha Vantage metadata: %w", err)
+	}
+
+	var series map[string]map[string]string
+	seriesJSON, ok := raw[dataKey]
+	if !ok {
+		return nil, &ProviderError{Provider: av.Name(), Message: "missing " + dataKey + " in response"}
+	}
+	if err := json.Unmarshal(seriesJSON, &series); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", dataKey, err)
+	}
+
+	// Meta Data keys are numbered differently per function, so match on suffix
+	var symbol string
+	loc := time.UTC
+	for key, value := range meta {
+		switch {
+		case strings.HasSuffix(key, "Symbol"):
+			symbol = value
+		case strings.HasSuffix(key, "Time Zone"):
+			if tz, err := time.LoadLocation(value); err == nil {
+				loc = tz
+			}
+		}
+	}
+
+	results := make([]*models.MarketData, 0, len(series))
+	for stamp, values := range series {
+		layout := "2006-01-02"
+		if len(stamp) > len(layout) {
+			layout = "2006-01-02 15:04:05"
+		}
+		ts, err := time.ParseInLocation(layout, stamp, loc)
+		if err != nil {
+			continue
+		}
+
+		closePrice := parseAlphaVantageDecimal(values["4. close"])
+		data := &models.MarketData{
+			Symbol:    symbol,
+			Open:      parseAlphaVantageDecimal(values["1. open"]),
+			High:      parseAlphaVantageDecimal(values["2. high"]),
+			Low:       parseAlphaVantageDecimal(values["3. low"]),
+			Close:     closePrice,
+			Price:     closePrice,
+			Timestamp: ts.UTC(),
+			Source:    av.Name(),
+		}
+		// Adjusted series put volume under "6. volume", raw series under "5. volume"
+		if volume, ok := values["6. volume"]; ok {
+			data.Volume = int64(parseAlphaVantageFloat(volume))
+		} else {
+			data.Volume = int64(parseAlphaVantageFloat(values["5. volume"]))
+		}
+		results = append(results, data)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Timestamp.Before(results[j].Timestamp)
+	})
+	return results, nil
+}
+
+func parseAlphaVantageFloat(value string) float64 {
+	parsed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
+
+// parseAlphaVantageDecimal reads a price or percentage exactly as quoted;
+// like parseAlphaVantageFloat, "None" and other non-numbers are zero
+func parseAlphaVantageDecimal(value string) decimal.Decimal {
+	parsed, err := decimal.Parse(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	if err != nil {
+		return decimal.Zero
+	}
+	return parsed
 }
 
 func (av *AlphaVantageClient) parseQuoteResponse(response []byte) (*models.MarketData, error) {
-	// TODO: Parse Alpha Vantage global quote response
-	// - Define global quote response structure
-	// - Extract current price and change data
-	// - Parse percentage change strings to float
-	// - Convert Alpha Vantage field names to standard format
-	// - Handle null or missing values gracefully
-	// - Validate quote data integrity
-	panic("TODO: Implement Alpha Vantage quote parsing")
+	if err := av.handleAlphaVantageError(response); err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		GlobalQuote map[string]string `json:"Global Quote"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode Alpha Vantage quote: %w", err)
+	}
+
+	quote := parsed.GlobalQuote
+	if len(quote) == 0 {
+		// Unknown symbols come back as an empty "Global Quote" object
+		return nil, &ProviderError{Provider: av.Name(), Message: "empty global quote", Err: ErrSymbolNotFound}
+	}
+
+	// GLOBAL_QUOTE only reports the trading day, so stamp today's quote with the
+	// fetch time and older days with that session's close
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		eastern = time.UTC
+	}
+	now := time.Now().In(eastern)
+	timestamp := now
+	if day, err := time.ParseInLocation("2006-01-02", quote["07. latest trading day"], eastern); err == nil {
+		if day.Format("2006-01-02") != now.Format("2006-01-02") {
+			timestamp = day.Add(16 * time.Hour)
+		}
+	}
+
+	price := parseAlphaVantageDecimal(quote["05. price"])
+	return &models.MarketData{
+		Symbol:        quote["01. symbol"],
+		Open:          parseAlphaVantageDecimal(quote["02. open"]),
+		High:          parseAlphaVantageDecimal(quote["03. high"]),
+		Low:           parseAlphaVantageDecimal(quote["04. low"]),
+		Price:         price,
+		Close:         price,
+		Volume:        int64(parseAlphaVantageFloat(quote["06. volume"])),
+		Change:        parseAlphaVantageDecimal(quote["09. change"]),
+		ChangePercent: parseAlphaVantageDecimal(quote["10. change percent"]),
+		PrevClose:     parseAlphaVantageDecimal(quote["08. previous close"]),
+		Timestamp:     timestamp.UTC(),
+		Source:        av.Name(),
+	}, nil
 }
 
 func (av *AlphaVantageClient) buildRequestURL(function string, params map[string]string) string {
-	// TODO: Build Alpha Vantage API request URLs
-	// - Construct base URL with function parameter
-	// - Add API key to all requests
-	// - Include all required and optional parameters
-	// - Handle URL encoding for special characters
-	// - Validate parameter combinations for each function
-	panic("TODO: Implement Alpha Vantage URL building")
+	query := url.Values{}
+	query.Set("function", function)
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	av.keyMu.RLock()
+	query.Set("apikey", av.apiKey)
+	av.keyMu.RUnlock()
+
+	return av.baseURL + "?" + query.Encode()
 }
 
 func (av *AlphaVantageClient) makeRequest(ctx context.Context, url string) ([]byte, error) {
-	// TODO: Make HTTP request to Alpha Vantage API
-	// - Create HTTP request with timeout
-	// - Add required headers and user agent
-	// - Implement rate limiting before request
-	// - Handle HTTP errors and Alpha Vantage API limits
-	// - Implement retry logic with exponential backoff
-	// - Parse Alpha Vantage error responses
-	// - Log requests and responses for monitoring
-	panic("TODO: Implement Alpha Vantage HTTP request handling")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Alpha Vantage request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if av.breaker != nil {
+		if err := av.breaker.Allow(ctx); err != nil {
+			return nil, err
+		}
+	}
+	// Every call counts against the same key quota; endpoint limits are per function
+	if err := av.checkRateLimit(ctx, req.URL.Query().Get("function")); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	body, err := av.doRequest(req)
+	if av.breaker != nil {
+		av.breaker.Record(ctx, time.Since(start), err)
+	}
+	return body, err
+}
+
+func (av *AlphaVantageClient) doRequest(req *http.Request) ([]byte, error) {
+	resp, err := av.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: av.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: av.Name(), Message: "failed to read response", Err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		providerErr := &ProviderError{Provider: av.Name(), StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			providerErr.Err = ErrRateLimited
+		}
+		return nil, providerErr
+	}
+
+	// Alpha Vantage reports most failures, including throttling, with HTTP 200
+	if err := av.handleAlphaVantageError(body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
 }
 
 // Rate Limiting and API Management
-func (av *AlphaVantageClient) checkRateLimit(ctx context.Context) error {
-	// TODO: Check rate limit before making requests
-	// - Implement 5 requests per minute limit for free tier
-	// - Handle premium tier rate limits differently
-	// - Use token bucket or sliding window algorithm
-	// - Wait for rate limit reset if exceeded
-	// - Return appropriate error for rate limit exceeded
-	panic("TODO: Implement Alpha Vantage rate limiting")
+func (av *AlphaVantageClient) checkRateLimit(ctx context.Context, endpoint string) error {
+	if av.rateLimiter == nil {
+		return nil
+	}
+	return av.rateLimiter.Wait(ctx, endpoint)
 }
 
 func (av *AlphaVantageClient) GetAPIUsage(ctx context.Context) (map[string]interface{}, error) {
-	// TODO: Get current API usage statistics
-	// - Track requests made in current period
-	// - Calculate remaining API calls
-	// - Monitor usage against tier limits
-	// - Return usage statistics for monitoring
-	panic("TODO: Implement API usage tracking")
+	if av.rateLimiter == nil {
+		return map[string]interface{}{"rate_limited": false}, nil
+	}
+
+	used, resetTime, limit := av.rateLimiter.Status()
+	return map[string]interface{}{
+		"rate_limited":  true,
+		"requests_used": used,
+		"limit":         limit,
+		"remaining":     limit - used,
+		"reset_time":    resetTime,
+	}, nil
 }
 
 func (av *AlphaVantageClient) GetAPIHealth(ctx context.Context) (bool, error) {
-	// TODO: Check Alpha Vantage API health
-	// - Make test request to Alpha Vantage
-	// - Verify API response format and timing
-	// - Check for API maintenance notifications
-	// - Return health status with details
-	panic("TODO: Implement Alpha Vantage health check")
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := av.GetQuote(ctx, "IBM"); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // Error Handling
 func (av *AlphaVantageClient) handleAlphaVantageError(response []byte) error {
-	// TODO: Handle Alpha Vantage specific errors
-	// - Parse Alpha Vantage error response format
-	// - Handle "Note" field in responses (rate limiting)
-	// - Process "Error Message" field for API errors
-	// - Handle invalid symbol or function errors
-	// - Map Alpha Vantage errors to standard error types
-	panic("TODO: Implement Alpha Vantage error handling")
+	var envelope struct {
+		Note         string `json:"Note"`
+		Information  string `json:"Information"`
+		ErrorMessage string `json:"Error Message"`
+	}
+	if err := json.Unmarshal(response, &envelope); err != nil {
+		// Not a JSON object (e.g. CSV datatype); nothing to inspect
+		return nil
+	}
+
+	switch {
+	case av.isRateLimitError(response):
+		message := envelope.Note
+		if message == "" {
+			message = envelope.Information
+		}
+		return &ProviderError{Provider: av.Name(), StatusCode: http.StatusTooManyRequests, Message: message, Err: ErrRateLimited}
+	case envelope.ErrorMessage != "":
+		providerErr := &ProviderError{Provider: av.Name(), Message: envelope.ErrorMessage}
+		if strings.Contains(envelope.ErrorMessage, "Invalid API call") {
+			providerErr.Err = ErrSymbolNotFound
+		}
+		return providerErr
+	case envelope.Information != "":
+		return &ProviderError{Provider: av.Name(), Message: envelope.Information}
+	}
+	return nil
 }
 
 func (av *AlphaVantageClient) isRateLimitError(response []byte) bool {
-	// TODO: Detect rate limiting in Alpha Vantage responses
-	// - Check for "Note" field indicating rate limits
-	// - Detect "Thank you for using Alpha Vantage" messages
-	// - Handle premium tier rate limit messages
-	// - Return true if rate limited
-	panic("TODO: Implement rate limit error detection")
+	var envelope struct {
+		Note        string `json:"Note"`
+		Information string `json:"Information"`
+	}
+	if err := json.Unmarshal(response, &envelope); err != nil {
+		return false
+	}
+
+	for _, message := range []string{envelope.Note, envelope.Information} {
+		lower := strings.ToLower(message)
+		if strings.Contains(lower, "call frequency") ||
+			strings.Contains(lower, "rate limit") ||
+			strings.Contains(lower, "requests per day") {
+			return true
+		}
+	}
+	return false
 }
 
 // Data Validation
 func (av *AlphaVantageClient) validateAlphaVantageData(data *models.MarketData) error {
-	// TODO: Validate Alpha Vantage market data
-	// - Check all required fields are present
-	// - Validate numeric ranges and relationships
-	// - Verify timestamp format and timezone
-	// - Check for Alpha Vantage specific data anomalies
-	// - Validate symbol format consistency
-	panic("TODO: Implement Alpha Vantage data validation")
-}
\ No newline at end of file
+	if data.Symbol == "" {
+		return fmt.Errorf("missing symbol")
+	}
+	if data.Price.Sign() <= 0 {
+		return fmt.Errorf("non-positive price %s", data.Price)
+	}
+	if data.High.Sign() > 0 && data.Low.Sign() > 0 && data.High.LessThan(data.Low) {
+		return fmt.Errorf("high %s below low %s", data.High, data.Low)
+	}
+	if data.Volume < 0 {
+		return fmt.Errorf("negative volume %d", data.Volume)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// binanceReadTimeout drops a connection that has gone quiet; tickers
+	// arrive every second on a healthy one
+	binanceReadTimeout = 30 * time.Second
+
+	binanceMinBackoff = time.Second
+	binanceMaxBackoff = time.Minute
+)
+
+// BinanceStreamClient streams trades and 24h tickers from Binance's combined
+// websocket stream. Binance has no USD books, so USD pairs are read from the
+// USDT book and reported under the pair that was asked for. The REST-style
+// methods serve the latest streamed values and fail until the stream runs.
+type BinanceStreamClient struct {
+	url    string
+	dialer *websocket.Dialer
+
+	mu          sync.RWMutex
+	latest      map[string]*models.CryptoData // by requested pair
+	connected   bool
+	lastMessage time.Time
+}
+
+func NewBinanceStreamClient(streamURL string) *BinanceStreamClient {
+	return &BinanceStreamClient{
+		url: strings.TrimSuffix(streamURL, "?"),
+		dialer: &websocket.Dialer{
+			HandshakeTimeout: 10 * time.Second,
+		},
+		latest: make(map[string]*models.CryptoData),
+	}
+}
+
+// Name identifies the source in CryptoData.Source and in configuration
+func (b *BinanceStreamClient) Name() string {
+	return "binance"
+}
+
+func (b *BinanceStreamClient) GetCryptoPrices(ctx context.Context, symbols []string) ([]*models.CryptoData, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var result []*models.CryptoData
+	for _, pair := range parseCryptoPairs(symbols) {
+		if data, ok := b.latest[pair.String()]; ok {
+			snapshot := *data
+			result = append(result, &snapshot)
+		}
+	}
+	return result, nil
+}
+
+func (b *BinanceStreamClient) GetCryptoMarketData(ctx context.Context, symbol string) (*models.CryptoData, error) {
+	pair, err := ParseCryptoPair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	data, ok := b.latest[pair.String()]
+	if !ok {
+		return nil, &ProviderError{Provider: b.Name(), Message: pair.String() + " is not streaming", Err: ErrSymbolNotFound}
+	}
+	snapshot := *data
+	return &snapshot, nil
+}
+
+// GetCryptoHistory is not available from a stream
+func (b *BinanceStreamClient) GetCryptoHistory(ctx context.Context, symbol string, start, end time.Time) ([]*models.CryptoData, error) {
+	return nil, fmt.Errorf("%w: %s streams live data only", ErrNoHistory, b.Name())
+}
+
+// GetAPIHealth reports whether the stream is connected and has delivered a
+// message within the read timeout
+func (b *BinanceStreamClient) GetAPIHealth(ctx context.Context) (bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.connected && time.Since(b.lastMessage) < binanceReadTimeout, nil
+}
+
+// binanceSymbol is the lower-case market name Binance streams a pair under
+func binanceSymbol(pair CryptoPair) string {
+	quote := pair.Quote
+	if quote == "USD" {
+		quote = "USDT"
+	}
+	return strings.ToLower(pair.Base + quote)
+}
+
+// Stream subscribes to the trade and ticker streams of symbols and sends an
+// update for every message until ctx ends, reconnecting with exponential
+// backoff whenever the connection drops
+func (b *BinanceStreamClient) Stream(ctx context.Context, symbols []string, out chan<- *models.CryptoData) error {
+	// Binance market name -> the requested pairs it serves
+	markets := make(map[string][]string)
+	var streams []string
+	for _, pair := range parseCryptoPairs(symbols) {
+		market := binanceSymbol(pair)
+		if _, ok := markets[market]; !ok {
+			streams = append(streams, market+"@aggTrade", market+"@ticker")
+		}
+		markets[market] = append(markets[market], pair.String())
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+	streamURL := b.url + "?streams=" + strings.Join(streams, "/")
+
+	backoff := binanceMinBackoff
+	for {
+		received, err := b.streamOnce(ctx, streamURL, markets, out)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			backoff = binanceMinBackoff
+		}
+		log.Printf("%s stream disconnected, reconnecting in %s: %v", b.Name(), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > binanceMaxBackoff {
+			backoff = binanceMaxBackoff
+		}
+	}
+}
+
+// streamOnce reads one connection until it fails, reporting whether any
+// message arrived on it
+func (b *BinanceStreamClient) streamOnce(ctx context.Context, streamURL string, markets map[string][]string, out chan<- *models.CryptoData) (bool, error) {
+	conn, _, err := b.dialer.DialContext(ctx, streamURL, nil)
+	if err != nil {
+		return false, &ProviderError{Provider: b.Name(), Message: "dial failed", Err: err}
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	b.setConnected(true)
+	defer b.setConnected(false)
+
+	received := false
+	for {
+		conn.SetReadDeadline(time.Now().Add(binanceReadTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		received = true
+
+		updates, err := b.handleMessage(message, markets)
+		if err != nil {
+			log.Printf("Skipping %s message: %v", b.Name(), err)
+			continue
+		}
+		for _, data := range updates {
+			select {
+			case out <- data:
+			case <-ctx.Done():
+				return received, ctx.Err()
+			}
+		}
+	}
+}
+
+func (b *BinanceStreamClient) setConnected(connected bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.connected = connected
+}
+
+// Message Parsing
+
+// binanceEvent covers the fields of the aggTrade, trade and 24hrTicker
+// events used here. "p" is the trade price but the ticker's price change,
+// and "q" the trade quantity but the ticker's quote volume. Keys differing
+// only in case are all declared, since encoding/json would otherwise fold
+// "C" into "c".
+type binanceEvent struct {
+	Event     string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	TradeID   int64  `json:"t"`
+	TradeTime int64  `json:"T"`
+	P         string `json:"p"`
+	PctChange string `json:"P"`
+	Close     string `json:"c"`
+	CloseTime int64  `json:"C"`
+	Q         string `json:"q"`
+	LastQty   string `json:"Q"`
+}
+
+// handleMessage updates the latest values from a combined stream message and
+// returns a snapshot for every requested pair the market serves. A ticker
+// refreshes the 24h figures; a trade moves the price.
+func (b *BinanceStreamClient) handleMessage(message []byte, markets map[string][]string) ([]*models.CryptoData, error) {
+	var envelope struct {
+		Data binanceEvent `json:"data"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	event := envelope.Data
+	pairs := markets[strings.ToLower(event.Symbol)]
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastMessage = time.Now()
+
+	var updates []*models.CryptoData
+	for _, symbol := range pairs {
+		data := b.latest[symbol]
+		if data == nil {
+			data = &models.CryptoData{Symbol: symbol, Source: b.Name()}
+		} else {
+			snapshot := *data
+			data = &snapshot
+		}
+
+		switch event.Event {
+		case "aggTrade", "trade":
+			price, err := decimal.Parse(event.P)
+			if err != nil {
+				return nil, fmt.Errorf("bad trade price %q", event.P)
+			}
+			data.Price = price
+			data.Timestamp = time.UnixMilli(event.TradeTime).UTC()
+
+		case "24hrTicker":
+			price, err := decimal.Parse(event.Close)
+			if err != nil {
+				return nil, fmt.Errorf("bad ticker price %q", event.Close)
+			}
+			data.Price = price
+			data.Change24h, _ = decimal.Parse(event.P)
+			data.ChangePercent24h, _ = decimal.Parse(event.PctChange)
+			data.Volume24h, _ = strconv.ParseFloat(event.Q, 64)
+			data.Timestamp = time.UnixMilli(event.EventTime).UTC()
+
+		default:
+			return nil, nil
+		}
+
+		b.latest[symbol] = data
+		update := *data
+		updates = append(updates, &update)
+	}
+	return updates, nil
+}
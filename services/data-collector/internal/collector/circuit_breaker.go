@@ -0,0 +1,376 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+)
+
+// CircuitState is the state of a provider circuit breaker
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitBreakerConfig holds the trip thresholds. Error and slow-call rates are
+// evaluated over a rolling Window once MinRequests calls have been seen; the
+// 429 count trips on its own regardless of volume.
+type CircuitBreakerConfig struct {
+	Window                time.Duration
+	MinRequests           int
+	ErrorRateThreshold    float64
+	SlowCallLatency       time.Duration
+	SlowCallRateThreshold float64
+	RateLimitThreshold    int
+	OpenTimeout           time.Duration
+	MaxOpenTimeout        time.Duration
+	ProbeTimeout          time.Duration
+}
+
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		Window:                time.Minute,
+		MinRequests:           10,
+		ErrorRateThreshold:    0.5,
+		SlowCallLatency:       5 * time.Second,
+		SlowCallRateThreshold: 0.5,
+		RateLimitThreshold:    3,
+		OpenTimeout:           30 * time.Second,
+		MaxOpenTimeout:        10 * time.Minute,
+		ProbeTimeout:          10 * time.Second,
+	}
+}
+
+// circuitBreakerConfigFrom overlays configured thresholds on the defaults
+func circuitBreakerConfigFrom(cfg *config.Config) CircuitBreakerConfig {
+	cbCfg := DefaultCircuitBreakerConfig()
+	if cfg.BreakerErrorRate > 0 {
+		cbCfg.ErrorRateThreshold = cfg.BreakerErrorRate
+	}
+	if cfg.BreakerSlowCallLatency > 0 {
+		cbCfg.SlowCallLatency = cfg.BreakerSlowCallLatency
+	}
+	if cfg.BreakerRateLimitCount > 0 {
+		cbCfg.RateLimitThreshold = cfg.BreakerRateLimitCount
+	}
+	if cfg.BreakerOpenTimeout > 0 {
+		cbCfg.OpenTimeout = cfg.BreakerOpenTimeout
+	}
+	return cbCfg
+}
+
+// CircuitStats is a snapshot of a breaker for health reporting
+type CircuitStats struct {
+	Provider    string       `json:"provider"`
+	State       CircuitState `json:"-"`
+	StateName   string       `json:"state"`
+	Requests    int          `json:"requests"`
+	Failures    int          `json:"failures"`
+	SlowCalls   int          `json:"slow_calls"`
+	RateLimited int          `json:"rate_limited"`
+	OpenedAt    time.Time    `json:"opened_at,omitempty"`
+	NextProbeAt time.Time    `json:"next_probe_at,omitempty"`
+	LastReason  string       `json:"last_reason,omitempty"`
+}
+
+// StateChangeFunc is notified after every breaker transition
+type StateChangeFunc func(provider string, from, to CircuitState, reason string)
+
+type callResult struct {
+	at          time.Time
+	failed      bool
+	slow        bool
+	rateLimited bool
+}
+
+// CircuitBreaker stops calls to a provider that is failing, slow or throttling us.
+// After OpenTimeout the breaker goes half-open and runs the provider's health
+// check as a probe; real traffic resumes only once the probe succeeds, and each
+// failed probe doubles the wait up to MaxOpenTimeout.
+type CircuitBreaker struct {
+	name          string
+	cfg           CircuitBreakerConfig
+	probe         func(ctx context.Context) (bool, error)
+	onStateChange StateChangeFunc
+
+	mu          sync.Mutex
+	state       CircuitState
+	openedAt    time.Time
+	openTimeout time.Duration
+	lastReason  string
+	results     []callResult
+	now         func() time.Time
+}
+
+func NewCircuitBreaker(name string, cfg CircuitBreakerConfig, probe func(ctx context.Context) (bool, error)) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:        name,
+		cfg:         cfg,
+		probe:       probe,
+		openTimeout: cfg.OpenTimeout,
+		now:         time.Now,
+	}
+}
+
+// OnStateChange registers the transition callback
+func (cb *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onStateChange = fn
+}
+
+// SetConfig replaces the thresholds without resetting state
+func (cb *CircuitBreaker) SetConfig(cfg CircuitBreakerConfig) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.cfg = cfg
+	if cb.state == CircuitClosed {
+		cb.openTimeout = cfg.OpenTimeout
+	}
+}
+
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+type probeContextKey struct{}
+
+// withBreakerProbe marks a context as the breaker's own health probe so the
+// probe's requests are not rejected by the breaker they are testing
+func withBreakerProbe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, probeContextKey{}, true)
+}
+
+func isBreakerProbe(ctx context.Context) bool {
+	probe, _ := ctx.Value(probeContextKey{}).(bool)
+	return probe
+}
+
+// Allow reports whether a call may proceed. When the open timeout has elapsed
+// the first caller runs the half-open probe; everyone else is rejected until it finishes.
+func (cb *CircuitBreaker) Allow(ctx context.Context) error {
+	if isBreakerProbe(ctx) {
+		return nil
+	}
+
+	cb.mu.Lock()
+	switch cb.state {
+	case CircuitClosed:
+		cb.mu.Unlock()
+		return nil
+	case CircuitHalfOpen:
+		cb.mu.Unlock()
+		return cb.openError()
+	}
+
+	if cb.now().Before(cb.openedAt.Add(cb.openTimeout)) || cb.probe == nil {
+		cb.mu.Unlock()
+		return cb.openError()
+	}
+
+	notify := cb.transition(CircuitHalfOpen, "open timeout elapsed, probing health")
+	probeTimeout := cb.cfg.ProbeTimeout
+	cb.mu.Unlock()
+	notify()
+
+	probeCtx, cancel := context.WithTimeout(withBreakerProbe(ctx), probeTimeout)
+	healthy, err := cb.probe(probeCtx)
+	cancel()
+
+	cb.mu.Lock()
+	if healthy && err == nil {
+		cb.results = nil
+		cb.openTimeout = cb.cfg.OpenTimeout
+		notify = cb.transition(CircuitClosed, "health probe succeeded")
+		cb.mu.Unlock()
+		notify()
+		return nil
+	}
+
+	reason := "health probe failed"
+	if err != nil {
+		reason = fmt.Sprintf("health probe failed: %v", err)
+	}
+	cb.openTimeout *= 2
+	if cb.cfg.MaxOpenTimeout > 0 && cb.openTimeout > cb.cfg.MaxOpenTimeout {
+		cb.openTimeout = cb.cfg.MaxOpenTimeout
+	}
+	notify = cb.transition(CircuitOpen, reason)
+	cb.mu.Unlock()
+	notify()
+	return cb.openError()
+}
+
+// Record feeds the outcome of a call into the rolling window and trips the
+// breaker when a threshold is crossed
+func (cb *CircuitBreaker) Record(ctx context.Context, latency time.Duration, err error) {
+	if isBreakerProbe(ctx) || errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	cb.mu.Lock()
+	if cb.state != CircuitClosed {
+		cb.mu.Unlock()
+		return
+	}
+
+	now := cb.now()
+	cb.results = append(cb.results, callResult{
+		at:          now,
+		failed:      err != nil && retryable(err),
+		slow:        cb.cfg.SlowCallLatency > 0 && latency >= cb.cfg.SlowCallLatency,
+		rateLimited: errors.Is(err, ErrRateLimited),
+	})
+	cb.prune(now)
+
+	var failures, slow, rateLimited int
+	for _, r := range cb.results {
+		if r.failed {
+			failures++
+		}
+		if r.slow {
+			slow++
+		}
+		if r.rateLimited {
+			rateLimited++
+		}
+	}
+	total := len(cb.results)
+
+	var reason string
+	switch {
+	case cb.cfg.RateLimitThreshold > 0 && rateLimited >= cb.cfg.RateLimitThreshold:
+		reason = fmt.Sprintf("%d rate-limit responses in %s", rateLimited, cb.cfg.Window)
+	case total >= cb.cfg.MinRequests && float64(failures)/float64(total) >= cb.cfg.ErrorRateThreshold:
+		reason = fmt.Sprintf("error rate %.0f%% (%d/%d) in %s", 100*float64(failures)/float64(total), failures, total, cb.cfg.Window)
+	case total >= cb.cfg.MinRequests && float64(slow)/float64(total) >= cb.cfg.SlowCallRateThreshold:
+		reason = fmt.Sprintf("%d/%d calls slower than %s", slow, total, cb.cfg.SlowCallLatency)
+	}
+
+	if reason == "" {
+		cb.mu.Unlock()
+		return
+	}
+
+	cb.openTimeout = cb.cfg.OpenTimeout
+	notify := cb.transition(CircuitOpen, reason)
+	cb.mu.Unlock()
+	notify()
+}
+
+// Stats returns a snapshot of the breaker's rolling window
+func (cb *CircuitBreaker) Stats() CircuitStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.prune(cb.now())
+	stats := CircuitStats{
+		Provider:   cb.name,
+		State:      cb.state,
+		StateName:  cb.state.String(),
+		Requests:   len(cb.results),
+		LastReason: cb.lastReason,
+	}
+	for _, r := range cb.results {
+		if r.failed {
+			stats.Failures++
+		}
+		if r.slow {
+			stats.SlowCalls++
+		}
+		if r.rateLimited {
+			stats.RateLimited++
+		}
+	}
+	if cb.state != CircuitClosed {
+		stats.OpenedAt = cb.openedAt
+		stats.NextProbeAt = cb.openedAt.Add(cb.openTimeout)
+	}
+	return stats
+}
+
+func (cb *CircuitBreaker) prune(now time.Time) {
+	cutoff := now.Add(-cb.cfg.Window)
+	i := 0
+	for i < len(cb.results) && cb.results[i].at.Before(cutoff) {
+		i++
+	}
+	cb.results = cb.results[i:]
+}
+
+// transition must be called with mu held; the returned func delivers the
+// notification and must be called after mu is released
+func (cb *CircuitBreaker) transition(to CircuitState, reason string) func() {
+	from := cb.state
+	cb.state = to
+	cb.lastReason = reason
+	if to == CircuitOpen {
+		cb.openedAt = cb.now()
+	}
+
+	fn := cb.onStateChange
+	if fn == nil || from == to {
+		return func() {}
+	}
+	name := cb.name
+	return func() { fn(name, from, to, reason) }
+}
+
+func (cb *CircuitBreaker) openError() error {
+	return &ProviderError{Provider: cb.name, Message: "circuit breaker open", Err: ErrCircuitOpen}
+}
+
+// retryable classifies provider errors: network failures, timeouts, 5xx and
+// throttling are transient; bad symbols and other client errors are not
+func retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	if errors.Is(err, ErrSymbolNotFound) {
+		return false
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.StatusCode != 0 {
+		return providerErr.StatusCode >= 500 ||
+			providerErr.StatusCode == http.StatusRequestTimeout ||
+			providerErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// Transport failures wrapped by makeRequest without a status code
+	return errors.As(err, &providerErr) && providerErr.Err != nil
+}
@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type transitionRecord struct {
+	from, to CircuitState
+}
+
+func newTestBreaker(probe func(ctx context.Context) (bool, error)) (*CircuitBreaker, *time.Time, *[]transitionRecord) {
+	now := time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)
+	var transitions []transitionRecord
+
+	cb := NewCircuitBreaker("test", DefaultCircuitBreakerConfig(), probe)
+	cb.now = func() time.Time { return now }
+	cb.OnStateChange(func(provider string, from, to CircuitState, reason string) {
+		transitions = append(transitions, transitionRecord{from, to})
+	})
+	return cb, &now, &transitions
+}
+
+func rateLimitedErr() error {
+	return &ProviderError{Provider: "test", StatusCode: 429, Message: "Too Many Requests", Err: ErrRateLimited}
+}
+
+func TestCircuitBreaker_TripsOnRateLimits(t *testing.T) {
+	cb, _, transitions := newTestBreaker(nil)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		cb.Record(ctx, 100*time.Millisecond, rateLimitedErr())
+	}
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	cb.Record(ctx, 100*time.Millisecond, rateLimitedErr())
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.Equal(t, []transitionRecord{{CircuitClosed, CircuitOpen}}, *transitions)
+
+	err := cb.Allow(ctx)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_ErrorRateNeedsMinimumVolume(t *testing.T) {
+	cb, _, _ := newTestBreaker(nil)
+	ctx := context.Background()
+	serverErr := &ProviderError{Provider: "test", StatusCode: 503, Message: "Service Unavailable"}
+
+	// Bad symbols are the caller's problem, not the provider's
+	for i := 0; i < 20; i++ {
+		cb.Record(ctx, 50*time.Millisecond, &ProviderError{Provider: "test", StatusCode: 404, Err: ErrSymbolNotFound})
+	}
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	cb2, _, _ := newTestBreaker(nil)
+	for i := 0; i < 9; i++ {
+		cb2.Record(ctx, 50*time.Millisecond, serverErr)
+	}
+	assert.Equal(t, CircuitClosed, cb2.State())
+	cb2.Record(ctx, 50*time.Millisecond, serverErr)
+	assert.Equal(t, CircuitOpen, cb2.State())
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	healthy := false
+	probes := 0
+	cb, now, transitions := newTestBreaker(func(ctx context.Context) (bool, error) {
+		probes++
+		require.True(t, isBreakerProbe(ctx))
+		if !healthy {
+			return false, errors.New("connection refused")
+		}
+		return true, nil
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		cb.Record(ctx, time.Second, rateLimitedErr())
+	}
+	require.Equal(t, CircuitOpen, cb.State())
+
+	// No probe before the open timeout
+	*now = now.Add(29 * time.Second)
+	assert.ErrorIs(t, cb.Allow(ctx), ErrCircuitOpen)
+	assert.Equal(t, 0, probes)
+
+	// Failed probe re-opens with a doubled timeout
+	*now = now.Add(2 * time.Second)
+	assert.ErrorIs(t, cb.Allow(ctx), ErrCircuitOpen)
+	assert.Equal(t, 1, probes)
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.Equal(t, now.Add(time.Minute), cb.Stats().NextProbeAt)
+
+	*now = now.Add(time.Minute)
+	healthy = true
+	assert.NoError(t, cb.Allow(ctx))
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	assert.Equal(t, []transitionRecord{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}, *transitions)
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, retryable(rateLimitedErr()))
+	assert.True(t, retryable(&ProviderError{Provider: "test", StatusCode: 502}))
+	assert.True(t, retryable(context.DeadlineExceeded))
+	assert.True(t, retryable(&ProviderError{Provider: "test", Message: "request failed", Err: errors.New("connection reset")}))
+	assert.False(t, retryable(&ProviderError{Provider: "test", StatusCode: 400}))
+	assert.False(t, retryable(&ProviderError{Provider: "test", StatusCode: 404, Err: ErrSymbolNotFound}))
+	assert.False(t, retryable(context.Canceled))
+	assert.False(t, retryable(nil))
+}
@@ -0,0 +1,376 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// coinGeckoIDs maps asset codes to CoinGecko coin IDs. Codes are not unique
+// on CoinGecko, so the common ones are pinned; others are looked up with
+// /search once and remembered.
+var coinGeckoIDs = map[string]string{
+	"BTC":   "bitcoin",
+	"ETH":   "ethereum",
+	"ADA":   "cardano",
+	"DOT":   "polkadot",
+	"SOL":   "solana",
+	"MATIC": "matic-network",
+	"AVAX":  "avalanche-2",
+	"ATOM":  "cosmos",
+	"DOGE":  "dogecoin",
+	"XRP":   "ripple",
+	"LTC":   "litecoin",
+	"LINK":  "chainlink",
+	"BNB":   "binancecoin",
+	"USDT":  "tether",
+	"USDC":  "usd-coin",
+}
+
+// coinGeckoCurrency maps a pair's quote to the vs_currency CoinGecko prices
+// it in. Stablecoin quotes are priced in dollars.
+func coinGeckoCurrency(quote string) (string, bool) {
+	switch quote {
+	case "USD", "USDT", "USDC", "BUSD", "FDUSD":
+		return "usd", true
+	case "EUR", "GBP", "JPY", "BTC", "ETH":
+		return strings.ToLower(quote), true
+	}
+	return "", false
+}
+
+// CoinGeckoClient polls CoinGecko's public API. The key is optional: without
+// one the keyless tier applies, with one the demo tier's higher limits.
+type CoinGeckoClient struct {
+	httpClient  *http.Client
+	baseURL     string
+	keyMu       sync.RWMutex
+	apiKey      string
+	rateLimiter *RateLimiter
+
+	idsMu sync.Mutex
+	ids   map[string]string // asset code to coin ID, "" when /search found none
+}
+
+func NewCoinGeckoClient(apiKey string) *CoinGeckoClient {
+	return &CoinGeckoClient{
+		httpClient: newHTTPClient(15 * time.Second),
+		baseURL:    "https://api.coingecko.com/api/v3",
+		apiKey:     apiKey,
+		ids:        make(map[string]string),
+	}
+}
+
+// SetAPIKey rotates the key; requests already built keep the old one
+func (c *CoinGeckoClient) SetAPIKey(apiKey string) {
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	c.apiKey = apiKey
+}
+
+// Name identifies the source in CryptoData.Source and in configuration
+func (c *CoinGeckoClient) Name() string {
+	return "coingecko"
+}
+
+// GetCryptoPrices returns a snapshot for every pair CoinGecko lists, with one
+// /coins/markets request per quote currency
+func (c *CoinGeckoClient) GetCryptoPrices(ctx context.Context, symbols []string) ([]*models.CryptoData, error) {
+	// vs_currency -> coin ID -> the pairs asking for it
+	byCurrency := make(map[string]map[string][]string)
+	for _, pair := range parseCryptoPairs(symbols) {
+		currency, ok := coinGeckoCurrency(pair.Quote)
+		if !ok {
+			continue
+		}
+		id, err := c.coinID(ctx, pair.Base)
+		if err != nil {
+			return nil, err
+		}
+		if id == "" {
+			continue
+		}
+		if byCurrency[currency] == nil {
+			byCurrency[currency] = make(map[string][]string)
+		}
+		byCurrency[currency][id] = append(byCurrency[currency][id], pair.String())
+	}
+
+	currencies := make([]string, 0, len(byCurrency))
+	for currency := range byCurrency {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var result []*models.CryptoData
+	for _, currency := range currencies {
+		ids := make([]string, 0, len(byCurrency[currency]))
+		for id := range byCurrency[currency] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		body, err := c.makeRequest(ctx, "/coins/markets", map[string]string{
+			"vs_currency": currency,
+			"ids":         strings.Join(ids, ","),
+			"per_page":    "250",
+		})
+		if err != nil {
+			return result, err
+		}
+		snapshots, err := c.parseMarkets(body, byCurrency[currency])
+		if err != nil {
+			return result, err
+		}
+		result = append(result, snapshots...)
+	}
+	return result, nil
+}
+
+func (c *CoinGeckoClient) GetCryptoMarketData(ctx context.Context, symbol string) (*models.CryptoData, error) {
+	pair, err := ParseCryptoPair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := c.GetCryptoPrices(ctx, []string{pair.String()})
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, &ProviderError{Provider: c.Name(), Message: "no market data for " + pair.String(), Err: ErrSymbolNotFound}
+	}
+	return snapshots[0], nil
+}
+
+// GetCryptoHistory returns prices between start and end. CoinGecko picks the
+// granularity from the range: 5-minutely within a day, hourly within 90
+// days, daily beyond that.
+func (c *CoinGeckoClient) GetCryptoHistory(ctx context.Context, symbol string, start, end time.Time) ([]*models.CryptoData, error) {
+	pair, err := ParseCryptoPair(symbol)
+	if err != nil {
+		return nil, err
+	}
+	currency, ok := coinGeckoCurrency(pair.Quote)
+	if !ok {
+		return nil, &ProviderError{Provider: c.Name(), Message: "unsupported quote currency " + pair.Quote, Err: ErrSymbolNotFound}
+	}
+	id, err := c.coinID(ctx, pair.Base)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, &ProviderError{Provider: c.Name(), Message: "unknown asset " + pair.Base, Err: ErrSymbolNotFound}
+	}
+
+	body, err := c.makeRequest(ctx, "/coins/"+id+"/market_chart/range", map[string]string{
+		"vs_currency": currency,
+		"from":        strconv.FormatInt(start.Unix(), 10),
+		"to":          strconv.FormatInt(end.Unix(), 10),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.parseMarketChart(body, pair.String())
+}
+
+// GetAPIHealth pings the API
+func (c *CoinGeckoClient) GetAPIHealth(ctx context.Context) (bool, error) {
+	if _, err := c.makeRequest(ctx, "/ping", nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// coinID resolves an asset code, returning "" for codes CoinGecko does not list
+func (c *CoinGeckoClient) coinID(ctx context.Context, code string) (string, error) {
+	if id, ok := coinGeckoIDs[code]; ok {
+		return id, nil
+	}
+	c.idsMu.Lock()
+	id, ok := c.ids[code]
+	c.idsMu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	body, err := c.makeRequest(ctx, "/search", map[string]string{"query": code})
+	if err != nil {
+		return "", err
+	}
+	var response struct {
+		Coins []struct {
+			ID     string `json:"id"`
+			Symbol string `json:"symbol"`
+		} `json:"coins"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to decode CoinGecko search: %w", err)
+	}
+	// Results come by market cap rank, so the first exact match is the coin
+	// people mean
+	for _, coin := range response.Coins {
+		if strings.EqualFold(coin.Symbol, code) {
+			id = coin.ID
+			break
+		}
+	}
+
+	c.idsMu.Lock()
+	c.ids[code] = id
+	c.idsMu.Unlock()
+	return id, nil
+}
+
+// Response Parsing
+func (c *CoinGeckoClient) parseMarkets(response []byte, pairs map[string][]string) ([]*models.CryptoData, error) {
+	var markets []struct {
+		ID                       string           `json:"id"`
+		Name                     string           `json:"name"`
+		CurrentPrice             *decimal.Decimal `json:"current_price"`
+		MarketCap                float64          `json:"market_cap"`
+		TotalVolume              float64          `json:"total_volume"`
+		PriceChange24h           decimal.Decimal  `json:"price_change_24h"`
+		PriceChangePercentage24h decimal.Decimal  `json:"price_change_percentage_24h"`
+		LastUpdated              time.Time        `json:"last_updated"`
+	}
+	if err := json.Unmarshal(response, &markets); err != nil {
+		return nil, fmt.Errorf("failed to decode CoinGecko markets: %w", err)
+	}
+
+	var result []*models.CryptoData
+	for _, m := range markets {
+		if m.CurrentPrice == nil {
+			continue
+		}
+		for _, symbol := range pairs[m.ID] {
+			result = append(result, &models.CryptoData{
+				Symbol:           symbol,
+				Name:             m.Name,
+				Price:            *m.CurrentPrice,
+				Volume24h:        m.TotalVolume,
+				MarketCap:        m.MarketCap,
+				Change24h:        m.PriceChange24h,
+				ChangePercent24h: m.PriceChangePercentage24h,
+				Timestamp:        m.LastUpdated.UTC(),
+				Source:           c.Name(),
+			})
+		}
+	}
+	return result, nil
+}
+
+// parseMarketChart zips the price, market cap and volume series, which are
+// [unix millis, value] pairs sampled at the same times
+func (c *CoinGeckoClient) parseMarketChart(response []byte, symbol string) ([]*models.CryptoData, error) {
+	var chart struct {
+		Prices       [][2]float64 `json:"prices"`
+		MarketCaps   [][2]float64 `json:"market_caps"`
+		TotalVolumes [][2]float64 `json:"total_volumes"`
+	}
+	if err := json.Unmarshal(response, &chart); err != nil {
+		return nil, fmt.Errorf("failed to decode CoinGecko market chart: %w", err)
+	}
+
+	result := make([]*models.CryptoData, 0, len(chart.Prices))
+	for i, point := range chart.Prices {
+		data := &models.CryptoData{
+			Symbol:    symbol,
+			Price:     decimal.FromFloat(point[1]),
+			Timestamp: time.UnixMilli(int64(point[0])).UTC(),
+			Source:    c.Name(),
+		}
+		if i < len(chart.MarketCaps) && chart.MarketCaps[i][0] == point[0] {
+			data.MarketCap = chart.MarketCaps[i][1]
+		}
+		if i < len(chart.TotalVolumes) && chart.TotalVolumes[i][0] == point[0] {
+			data.Volume24h = chart.TotalVolumes[i][1]
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+// HTTP Request Handling
+func (c *CoinGeckoClient) makeRequest(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	requestURL := c.baseURL + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CoinGecko request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	c.keyMu.RLock()
+	if c.apiKey != "" {
+		req.Header.Set("x-cg-demo-api-key", c.apiKey)
+	}
+	c.keyMu.RUnlock()
+
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx, endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: c.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: c.Name(), Message: "failed to read response", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleCoinGeckoError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// Error Handling
+
+// handleCoinGeckoError classifies a failed response. CoinGecko answers with
+// {"error": "..."} or {"status": {"error_code": 429, "error_message": "..."}}.
+func (c *CoinGeckoClient) handleCoinGeckoError(statusCode int, body []byte) error {
+	var apiErr struct {
+		Error  string `json:"error"`
+		Status struct {
+			ErrorMessage string `json:"error_message"`
+		} `json:"status"`
+	}
+	message := http.StatusText(statusCode)
+	if json.Unmarshal(body, &apiErr) == nil {
+		if apiErr.Error != "" {
+			message = apiErr.Error
+		} else if apiErr.Status.ErrorMessage != "" {
+			message = apiErr.Status.ErrorMessage
+		}
+	}
+	providerErr := &ProviderError{Provider: c.Name(), StatusCode: statusCode, Message: message}
+
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		providerErr.Err = ErrRateLimited
+	case http.StatusNotFound:
+		providerErr.Err = ErrSymbolNotFound
+	}
+	return providerErr
+}
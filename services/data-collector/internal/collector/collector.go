@@ -2,10 +2,17 @@ package collector
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"sync"
 	"time"
 
+	"tradecaptain/data-collector/internal/cache"
+	"tradecaptain/data-collector/internal/calendar"
 	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/instruments"
 	"tradecaptain/data-collector/internal/models"
 	"tradecaptain/data-collector/internal/storage"
 )
@@ -14,155 +21,468 @@ type DataCollector struct {
 	db       *storage.PostgresDB
 	cache    *storage.RedisCache
 	producer *storage.KafkaProducer
-	config   *config.Config
+
+	// config, providers and rateLimiters are swapped on reload; read them
+	// through currentConfig/providerPool
+	mu            sync.RWMutex
+	config        *config.Config
+	configUpdated chan struct{}
+	paused        map[string]time.Time // paused services and providers
+
+	// Optional fast-path storage (nil when created with New)
+	l1Cache    *cache.L1Cache
+	wal        *storage.BadgerWAL
+	retryQueue *RetryQueue
 
 	// API clients
-	yahooClient      *YahooFinanceClient
-	alphaVantageClient *AlphaVantageClient
-	iexClient        *IEXCloudClient
+	providers        *ProviderPool
 	fredClient       *FREDClient
+	edgarClient      *EDGARClient
+	treasuryClient   *TreasuryClient
+	replay           *ReplayProvider // set for offline runs, see UseReplay
+	instruments      *instruments.Master
 	newsClients      map[string]NewsClient
 	cryptoClients    map[string]CryptoClient
 
+	// Cross-source reconciliation of quotes
+	consensus *Reconciler
+
 	// Rate limiting and coordination
 	rateLimiters     map[string]*RateLimiter
 	dataChannels     map[string]chan interface{}
 	shutdownChannels map[string]chan bool
 	wg               sync.WaitGroup
+
+	// Backfills started at runtime
+	jobsMu    sync.Mutex
+	backfills []*BackfillJob
 }
 
 func New(db *storage.PostgresDB, cache *storage.RedisCache, producer *storage.KafkaProducer, cfg *config.Config) *DataCollector {
-	// TODO: Initialize DataCollector with all dependencies
-	// - Set up all API clients with proper configuration
-	// - Initialize rate limiters for each API provider
-	// - Create data processing channels with appropriate buffer sizes
-	// - Set up graceful shutdown channels for each service
-	// - Configure concurrent processing pools
-	// - Initialize metrics collection for monitoring
-	panic("TODO: Implement DataCollector initialization")
+	rateLimiters := newRateLimiters(cfg, cache)
+
+	dc := &DataCollector{
+		db:               db,
+		cache:            cache,
+		producer:         producer,
+		config:           cfg,
+		providers:        newProviderPool(cfg, rateLimiters),
+		consensus:        NewReconcilerFromConfig(cfg),
+		newsClients:      newNewsClients(cfg, rateLimiters),
+		cryptoClients:    newCryptoClients(cfg, rateLimiters),
+		treasuryClient:   NewTreasuryClient(),
+		rateLimiters:     rateLimiters,
+		dataChannels:     make(map[string]chan interface{}),
+		shutdownChannels: make(map[string]chan bool),
+		configUpdated:    make(chan struct{}, 1),
+		paused:           make(map[string]time.Time),
+	}
+
+	dc.treasuryClient.rateLimiter = rateLimiters[dc.treasuryClient.Name()]
+
+	if cfg.FREDAPIKey != "" {
+		dc.fredClient = NewFREDClient(cfg.FREDAPIKey)
+		dc.fredClient.rateLimiter = rateLimiters[dc.fredClient.Name()]
+	}
+
+	if cfg.SECUserAgent != "" {
+		dc.edgarClient = NewEDGARClient(cfg.SECUserAgent)
+		dc.edgarClient.rateLimiter = rateLimiters[dc.edgarClient.Name()]
+	}
+
+	for _, breaker := range dc.providers.Breakers() {
+		breaker.OnStateChange(dc.publishBreakerTransition)
+	}
+	dc.consensus.OnConsensus(dc.publishConsensus)
+	return dc
+}
+
+func (dc *DataCollector) currentConfig() *config.Config {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.config
+}
+
+func (dc *DataCollector) providerPool() *ProviderPool {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.providers
+}
+
+// economicClient returns the FRED client, nil while no API key is configured
+func (dc *DataCollector) economicClient() *FREDClient {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.fredClient
+}
+
+func (dc *DataCollector) filingsClient() *EDGARClient {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.edgarClient
+}
+
+func (dc *DataCollector) yieldCurveClient() *TreasuryClient {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.treasuryClient
+}
+
+// NewWithOptimizations wires the embedded L1 cache, BadgerDB WAL and durable
+// retry queue in front of the shared Redis/Postgres/Kafka path
+func NewWithOptimizations(db *storage.PostgresDB, l1Cache *cache.L1Cache, redisCache *storage.RedisCache, wal *storage.BadgerWAL, retryQueue *RetryQueue, producer *storage.KafkaProducer, cfg *config.Config) *DataCollector {
+	dc := New(db, redisCache, producer, cfg)
+	dc.l1Cache = l1Cache
+	dc.wal = wal
+	dc.retryQueue = retryQueue
+	return dc
 }
 
 // Main Collection Orchestration
+
+// StartMarketDataCollection polls equities on their exchange's calendar: every
+// MarketDataInterval in regular hours, every ExtendedHoursInterval in pre-market,
+// after-hours and lunch breaks, and not at all while the exchange is closed.
+// With a replay loaded it plays the recording back instead.
 func (dc *DataCollector) StartMarketDataCollection(ctx context.Context) {
-	// TODO: Start market data collection orchestrator
-	// - Start concurrent collection for each configured symbol
-	// - Coordinate between different API providers
-	// - Handle API rate limiting and failover strategies
-	// - Process collected data through validation pipeline
-	// - Publish processed data to Kafka for real-time consumption
-	// - Handle graceful shutdown on context cancellation
-	// - Monitor collection performance and error rates
-	panic("TODO: Implement market data collection orchestration")
+	if replay := dc.replaySource(); replay != nil {
+		dc.runReplay(ctx, replay)
+		return
+	}
+
+	cal := calendar.Default()
+	lastPolled := make(map[string]time.Time)
+	suspended := false
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-dc.configUpdated:
+			// Re-plan with the new symbols and intervals
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+
+		// Resuming wakes the loop through configUpdated
+		if dc.isPaused(ServiceMarket) {
+			timer.Reset(time.Minute)
+			continue
+		}
+
+		now := time.Now()
+		due, wake := dc.dueStockSymbols(cal, now, lastPolled)
+
+		if len(due) == 0 && !suspended {
+			log.Printf("Equity polling suspended: no tracked exchange in session until %s", wake.Format(time.RFC3339))
+		} else if len(due) > 0 && suspended {
+			log.Printf("Equity polling resumed")
+		}
+		suspended = len(due) == 0
+
+		if len(due) > 0 {
+			if err := dc.CollectStockData(ctx, due); err != nil && ctx.Err() == nil {
+				dc.HandleCollectionError(ctx, err, "stocks", due)
+			}
+			for _, symbol := range due {
+				lastPolled[symbol] = now
+			}
+		}
+
+		wait := time.Until(wake)
+		if wait < time.Second {
+			wait = time.Second
+		}
+		timer.Reset(wait)
+	}
 }
 
+// StartNewsCollection reads every news source each NewsInterval
 func (dc *DataCollector) StartNewsCollection(ctx context.Context) {
-	// TODO: Start news collection orchestrator
-	// - Collect news from multiple configured sources
-	// - Implement news deduplication across sources
-	// - Perform sentiment analysis on collected articles
-	// - Categorize news articles automatically
-	// - Store processed news in database and cache
-	// - Publish news events to Kafka for real-time distribution
-	panic("TODO: Implement news collection orchestration")
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		timer.Reset(dc.currentConfig().NewsInterval)
+		if dc.isPaused(ServiceNews) {
+			continue
+		}
+
+		if err := dc.CollectNews(ctx); err != nil && ctx.Err() == nil {
+			dc.HandleCollectionError(ctx, err, "news", nil)
+		}
+	}
 }
 
+// StartEconomicDataCollection collects the configured FRED series every
+// EconomicDataInterval. Series rarely change more than monthly, so a pause or
+// a new interval is picked up at the next pass rather than immediately.
 func (dc *DataCollector) StartEconomicDataCollection(ctx context.Context) {
-	// TODO: Start economic data collection orchestrator
-	// - Collect economic indicators from FRED and other sources
-	// - Handle different data frequencies (daily, weekly, monthly)
-	// - Process economic calendar events
-	// - Store economic data with proper time series structure
-	// - Publish economic events for market impact analysis
-	panic("TODO: Implement economic data collection orchestration")
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	warned := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		cfg := dc.currentConfig()
+		timer.Reset(cfg.EconomicDataInterval)
+		if dc.isPaused(ServiceEconomic) {
+			continue
+		}
+		// The key can be added by a config reload
+		if dc.economicClient() == nil {
+			if !warned {
+				log.Println("Economic data collection idle: FRED_API_KEY not set")
+				warned = true
+			}
+			continue
+		}
+
+		if err := dc.CollectEconomicData(ctx, cfg.EconomicSeries); err != nil && ctx.Err() == nil {
+			dc.HandleCollectionError(ctx, err, "economic", cfg.EconomicSeries)
+		}
+	}
 }
 
 // Market Data Collection Methods
 func (dc *DataCollector) CollectStockData(ctx context.Context, symbols []string) error {
-	// TODO: Collect stock market data for given symbols
-	// - Distribute symbols across available API providers
-	// - Implement round-robin or weighted distribution strategy
-	// - Handle API failures with automatic fallback
-	// - Validate collected data for consistency and completeness
-	// - Cache collected data with appropriate TTL
-	// - Store validated data in PostgreSQL database
-	// - Publish real-time updates to Kafka streams
-	panic("TODO: Implement stock data collection")
-}
-
-func (dc *DataCollector) CollectCryptoData(ctx context.Context, symbols []string) error {
-	// TODO: Collect cryptocurrency data for given symbols
-	// - Use multiple crypto data sources for reliability
-	// - Handle crypto-specific fields (market cap, circulating supply)
-	// - Normalize crypto symbols across different exchanges
-	// - Calculate percentage changes and technical indicators
-	// - Handle high-frequency crypto price updates efficiently
-	panic("TODO: Implement cryptocurrency data collection")
-}
-
-func (dc *DataCollector) CollectOptionsData(ctx context.Context, underlyingSymbols []string) error {
-	// TODO: Collect options chain data (if available)
-	// - Fetch options chains for underlying securities
-	// - Calculate implied volatility and Greeks
-	// - Store options data with proper expiration handling
-	// - Handle options-specific data validation
-	// - Monitor options flow and unusual activity
-	panic("TODO: Implement options data collection")
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	// The pool tries providers by priority/weight and fills gaps from the next one
+	quotes, fetchErr := dc.providerPool().GetMultipleQuotes(ctx, symbols)
+	if len(quotes) == 0 {
+		return &CollectionError{Source: "stocks", Symbols: symbols, Err: fetchErr}
+	}
+
+	accepted := make([]*models.MarketData, 0, len(quotes))
+	var rejectErr error
+	for _, quote := range quotes {
+		if _, err := dc.ProcessMarketData(ctx, quote); err != nil {
+			log.Printf("Rejected quote: %v", err)
+			if rejectErr == nil {
+				rejectErr = err
+			}
+			continue
+		}
+		accepted = append(accepted, quote)
+	}
+
+	if len(accepted) > 0 {
+		if err := dc.storeQuotes(ctx, accepted); err != nil {
+			return err
+		}
+		dc.crossCheck(ctx, accepted)
+	}
+
+	// Partial success: report the symbols no provider could serve or whose
+	// quote was rejected
+	if missing := missingSymbols(symbols, accepted); len(missing) > 0 {
+		if fetchErr == nil {
+			fetchErr = rejectErr
+		}
+		return &CollectionError{Source: "stocks", Symbols: missing, Err: fetchErr}
+	}
+	return nil
+}
+
+// crossCheck has a second provider quote a sample of the symbols just
+// collected and feeds its answers to the reconciler, so a consensus can form
+// even though the pool only asks later providers for what earlier ones
+// missed. The answers are only compared, never stored.
+func (dc *DataCollector) crossCheck(ctx context.Context, quotes []*models.MarketData) {
+	cfg := dc.currentConfig()
+	if cfg.ConsensusSampleSize <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.MarketDataInterval/2)
+	defer cancel()
+	for _, check := range dc.providerPool().CrossCheck(ctx, quotes, cfg.ConsensusSampleSize) {
+		if _, err := dc.ProcessMarketData(ctx, check); err != nil {
+			log.Printf("Rejected cross-check quote: %v", err)
+		}
+	}
+}
+
+// storeQuotes writes processed quotes to the WAL, the caches and the
+// database, and publishes them. Polled and streamed quotes both end here.
+func (dc *DataCollector) storeQuotes(ctx context.Context, quotes []*models.MarketData) error {
+	if dc.wal != nil {
+		if err := dc.wal.BatchWrite(quotes); err != nil {
+			log.Printf("Failed to write %d quotes to WAL: %v", len(quotes), err)
+		}
+	}
+
+	if dc.l1Cache != nil {
+		for _, quote := range quotes {
+			if err := dc.l1Cache.Set("quote:"+quote.Symbol, quote); err != nil {
+				log.Printf("Failed to cache %s in L1: %v", quote.Symbol, err)
+			}
+		}
+	}
+
+	if dc.cache != nil {
+		bySymbol := make(map[string]*models.MarketData, len(quotes))
+		for _, quote := range quotes {
+			bySymbol[quote.Symbol] = quote
+		}
+		if err := dc.cache.CacheMultipleMarketData(ctx, bySymbol, dc.currentConfig().MarketDataInterval*2); err != nil {
+			log.Printf("Failed to cache %d quotes: %v", len(quotes), err)
+		}
+	}
+
+	if dc.db != nil {
+		if err := dc.db.UpdateMarketDataBatch(ctx, quotes); err != nil {
+			return fmt.Errorf("failed to store stock data: %w", err)
+		}
+	}
+
+	if dc.producer != nil {
+		if err := dc.producer.PublishMarketDataBatch(ctx, quotes); err != nil {
+			return fmt.Errorf("failed to publish stock data: %w", err)
+		}
+	}
+	return nil
 }
 
 // Historical Data Backfill
+// BackfillHistoricalData fetches daily bars for symbol through the provider
+// pool and stores the ones between startDate and endDate
 func (dc *DataCollector) BackfillHistoricalData(ctx context.Context, symbol string, startDate, endDate time.Time) error {
-	// TODO: Backfill historical market data
-	// - Implement efficient historical data retrieval
-	// - Handle API rate limits during bulk data collection
-	// - Validate historical data integrity and completeness
-	// - Detect and handle data gaps or anomalies
-	// - Store historical data in TimescaleDB optimized format
-	// - Update data quality metrics and statistics
-	panic("TODO: Implement historical data backfill")
-}
-
-func (dc *DataCollector) BackfillMissingData(ctx context.Context) error {
-	// TODO: Identify and backfill missing data points
-	// - Scan database for data gaps in time series
-	// - Prioritize missing data by symbol importance
-	// - Implement intelligent gap detection algorithms
-	// - Fill gaps using multiple data sources if available
-	// - Log backfill operations for audit and monitoring
-	panic("TODO: Implement missing data backfill")
+	if !startDate.Before(endDate) {
+		return fmt.Errorf("invalid backfill range for %s: %s to %s", symbol,
+			startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	}
+
+	bars, err := dc.providerPool().GetHistoricalRange(ctx, symbol, startDate, endDate, "1d")
+	if err != nil {
+		return err
+	}
+
+	if dc.db != nil {
+		if err := dc.db.UpdateMarketDataBatch(ctx, bars); err != nil {
+			return fmt.Errorf("failed to store backfill for %s: %w", symbol, err)
+		}
+	}
+	log.Printf("Backfilled %d bars for %s", len(bars), symbol)
+	return nil
 }
 
 // Data Processing and Enrichment
+// ProcessMarketData sets rawData's exchange to the symbol's primary listing
+// in the instrument master, whatever code the provider reported, and
+// reconciles it with the other sources that quoted the symbol recently. Once
+// two or more sources have, their consensus price and any source deviating
+// from it beyond tolerance are published next to the raw quotes.
 func (dc *DataCollector) ProcessMarketData(ctx context.Context, rawData *models.MarketData) (*models.MarketData, error) {
-	// TODO: Process and enrich raw market data
-	// - Validate data quality and detect anomalies
-	// - Calculate derived metrics (price changes, ratios)
-	// - Add technical indicators (moving averages, RSI)
-	// - Normalize data format across different sources
-	// - Add metadata and data quality scores
-	// - Handle currency conversions if needed
-	panic("TODO: Implement market data processing")
+	if rawData == nil || rawData.Symbol == "" {
+		return nil, errors.New("market data without a symbol")
+	}
+	if rawData.Price.Sign() <= 0 {
+		return nil, fmt.Errorf("%s quote for %s has invalid price %v", rawData.Source, rawData.Symbol, rawData.Price)
+	}
+	rawData.Exchange = dc.instrumentMaster().Exchange(rawData.Symbol, rawData.Exchange)
+
+	dc.consensus.Observe(rawData, time.Now())
+	return rawData, nil
+}
+
+// publishConsensus logs the sources deviating from a consensus and publishes
+// it next to the raw quotes
+func (dc *DataCollector) publishConsensus(consensus *models.QuoteConsensus) {
+	for _, outlier := range consensus.Outliers {
+		log.Printf("%s quote for %s is %.0f bps from consensus %s (%s)",
+			outlier.Source, consensus.Symbol, outlier.DeviationBps, consensus.Price, outlier.Price)
+	}
+
+	if dc.producer == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := dc.producer.PublishQuoteConsensus(ctx, consensus); err != nil {
+		log.Printf("Failed to publish consensus for %s: %v", consensus.Symbol, err)
+	}
 }
 
+// ProcessNewsArticle normalizes an article and stores it. It returns nil for
+// an article without a title or link, or one whose URL is already stored.
+// Sentiment is left for downstream consumers of the news topic.
 func (dc *DataCollector) ProcessNewsArticle(ctx context.Context, article *models.NewsArticle) (*models.NewsArticle, error) {
-	// TODO: Process and enrich news articles
-	// - Perform sentiment analysis on article content
-	// - Extract key entities (companies, people, locations)
-	// - Categorize articles by topic and relevance
-	// - Detect duplicate articles across sources
-	// - Calculate article relevance scores
-	// - Add social media engagement metrics if available
-	panic("TODO: Implement news article processing")
+	if dc.db == nil {
+		return nil, errors.New("news needs a database")
+	}
+	if normalizeArticle(article) == nil {
+		return nil, nil
+	}
+	if article.CreatedAt.IsZero() {
+		article.CreatedAt = time.Now()
+	}
+
+	stored, err := dc.db.SaveNewsArticle(ctx, article)
+	if err != nil || !stored {
+		return nil, err
+	}
+	return article, nil
 }
 
+// ProcessEconomicData validates a vintage of an observation and stores it
+// next to the earlier vintages. When an earlier vintage had a different value
+// the indicator is a revision and PreviousValue is set to that value. It
+// returns nil when the vintage was already stored or repeats the previous
+// value; FRED clips vintages to the requested realtime period, so an
+// unchanged value can come back stamped with a later day.
 func (dc *DataCollector) ProcessEconomicData(ctx context.Context, indicator *models.EconomicIndicator) (*models.EconomicIndicator, error) {
-	// TODO: Process economic indicator data
-	// - Validate economic data consistency
-	// - Calculate period-over-period changes
-	// - Add seasonal adjustment calculations
-	// - Correlate with related economic indicators
-	// - Calculate market impact scores
-	// - Handle data revisions and updates
-	panic("TODO: Implement economic data processing")
+	if dc.db == nil {
+		return nil, errors.New("economic data needs a database")
+	}
+	switch {
+	case indicator.Series == "":
+		return nil, errors.New("economic indicator without series")
+	case indicator.Date.IsZero() || indicator.RealtimeStart.IsZero():
+		return nil, fmt.Errorf("%s observation without date or vintage", indicator.Series)
+	case math.IsNaN(indicator.Value) || math.IsInf(indicator.Value, 0):
+		return nil, fmt.Errorf("%s observation for %s is not a number", indicator.Series, indicator.Date.Format("2006-01-02"))
+	}
+
+	previous, err := dc.db.GetEconomicVintage(ctx, indicator.Series, indicator.Date, indicator.RealtimeStart)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		if previous.Value == indicator.Value {
+			return nil, nil
+		}
+		value := previous.Value
+		indicator.PreviousValue = &value
+	}
+	if indicator.LastUpdated.IsZero() {
+		indicator.LastUpdated = time.Now()
+	}
+
+	stored, err := dc.db.SaveEconomicIndicator(ctx, indicator)
+	if err != nil || !stored {
+		return nil, err
+	}
+	return indicator, nil
 }
 
 // Data Quality and Monitoring
@@ -171,7 +491,7 @@ func (dc *DataCollector) ValidateDataQuality(ctx context.Context, data interface
 	// - Check data completeness and required fields
 	// - Validate data ranges and logical consistency
 	// - Detect statistical anomalies and outliers
-	// - Compare data across multiple sources for accuracy
+	// - Cross-source comparison is done by ProcessMarketData (see Reconciler)
 	// - Generate data quality reports and alerts
 	// - Update data quality metrics for monitoring
 	panic("TODO: Implement data quality validation")
@@ -188,67 +508,320 @@ func (dc *DataCollector) MonitorCollectionHealth(ctx context.Context) error {
 	panic("TODO: Implement collection health monitoring")
 }
 
+// GenerateCollectionMetrics summarises service state, provider breakers, the
+// retry queue and backfills
 func (dc *DataCollector) GenerateCollectionMetrics(ctx context.Context) map[string]interface{} {
-	// TODO: Generate comprehensive collection metrics
-	// - Calculate collection success rates per API
-	// - Measure data processing latencies
-	// - Track cache hit ratios and performance
-	// - Monitor database storage utilization
-	// - Generate API usage statistics
-	// - Return metrics for Prometheus/Grafana dashboards
-	panic("TODO: Implement collection metrics generation")
+	cfg := dc.currentConfig()
+
+	breakers := make(map[string]CircuitStats)
+	for name, breaker := range dc.providerPool().Breakers() {
+		breakers[name] = breaker.Stats()
+	}
+
+	running := 0
+	for _, job := range dc.BackfillJobs() {
+		if job.State == "running" {
+			running++
+		}
+	}
+
+	metrics := map[string]interface{}{
+		"services":          dc.Services(),
+		"circuit_breakers":  breakers,
+		"stock_symbols":     len(cfg.StockSymbols),
+		"crypto_symbols":    len(cfg.CryptoSymbols),
+		"running_backfills": running,
+		"source_accuracy":   dc.consensus.Accuracy(),
+	}
+	if dc.retryQueue != nil {
+		if stats, err := dc.retryQueue.Stats(); err != nil {
+			metrics["retry_queue_error"] = err.Error()
+		} else {
+			metrics["retry_queue"] = stats
+		}
+	}
+	return metrics
 }
 
 // Error Handling and Recovery
+// publishBreakerTransition reports a provider circuit breaker state change to
+// the error topic so on-call can see why a provider stopped receiving traffic
+func (dc *DataCollector) publishBreakerTransition(provider string, from, to CircuitState, reason string) {
+	severity := "info"
+	switch to {
+	case CircuitOpen:
+		severity = "critical"
+	case CircuitHalfOpen:
+		severity = "warning"
+	}
+
+	message := fmt.Sprintf("%s circuit breaker %s -> %s: %s", provider, from, to, reason)
+	log.Printf("Circuit breaker: %s", message)
+
+	if dc.producer == nil {
+		return
+	}
+	// Transitions fire on the request path; don't hold the caller up on Kafka
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := dc.producer.PublishErrorEvent(ctx, "data-collector", "circuit_breaker", message, severity); err != nil {
+			log.Printf("Failed to publish circuit breaker event for %s: %v", provider, err)
+		}
+	}()
+}
+
+// HandleCollectionError logs and reports a failed collection and, when the
+// failure is transient, queues the failed request for a later attempt. data is
+// the request to retry: a *RetryItem, a symbol list or a single symbol.
 func (dc *DataCollector) HandleCollectionError(ctx context.Context, err error, source string, data interface{}) {
-	// TODO: Handle collection errors gracefully
-	// - Log errors with appropriate context and metadata
-	// - Implement error classification and severity levels
-	// - Trigger appropriate retry mechanisms
-	// - Notify monitoring systems of critical errors
-	// - Store failed data for manual review if needed
-	// - Update error metrics and statistics
-	panic("TODO: Implement collection error handling")
-}
-
-func (dc *DataCollector) RetryFailedCollection(ctx context.Context, retryQueue []RetryItem) {
-	// TODO: Retry failed collection operations
-	// - Implement exponential backoff for retry attempts
-	// - Prioritize retries by data importance and age
-	// - Handle persistent failures with circuit breaker pattern
-	// - Log retry attempts and success rates
-	// - Remove items from retry queue after max attempts
-	panic("TODO: Implement retry mechanism for failed collections")
+	if err == nil {
+		return
+	}
+
+	var collErr *CollectionError
+	partial := errors.As(err, &collErr)
+
+	severity := "error"
+	if !retryable(err) && !partial {
+		// Bad symbols and malformed requests will fail the same way again
+		log.Printf("Collection error from %s (not retried): %v", source, err)
+	} else if dc.retryQueue == nil {
+		log.Printf("Collection error from %s (no retry queue): %v", source, err)
+	} else if item := newRetryItem(source, data, collErr); item == nil {
+		// Sources collected whole each pass (news, the yield curve) have
+		// nothing to replay; the next pass fetches it again
+		log.Printf("Collection error from %s (not retried, next pass refetches): %v", source, err)
+	} else {
+		item.LastError = err.Error()
+		if qErr := dc.retryQueue.Enqueue(item); qErr != nil {
+			// The failed request is lost; this is the case on-call must hear about
+			severity = "critical"
+			log.Printf("Failed to queue retry for %s: %v (original error: %v)", source, qErr, err)
+		} else {
+			severity = "warning"
+			log.Printf("Collection error from %s, retry %s queued for %s: %v", source, item.ID, item.NextAttempt.Format(time.RFC3339), err)
+		}
+	}
+
+	if dc.producer != nil {
+		if pErr := dc.producer.PublishErrorEvent(ctx, "data-collector", source, err.Error(), severity); pErr != nil {
+			log.Printf("Failed to publish collection error for %s: %v", source, pErr)
+		}
+	}
+}
+
+// RetryFailedCollection works through the due items of the durable retry queue.
+// While the breakers of every provider an item would go to are open, it is
+// deferred to the next probe instead of burning attempts against providers
+// known to be down.
+func (dc *DataCollector) RetryFailedCollection(ctx context.Context) error {
+	if dc.retryQueue == nil {
+		return nil
+	}
+
+	items, err := dc.retryQueue.Due(dc.currentConfig().RetryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if dc.isPaused(serviceForSource(item.Source)) {
+			if err := dc.retryQueue.Defer(item, time.Now().Add(dc.currentConfig().RetryInterval)); err != nil {
+				log.Printf("Failed to defer retry %s: %v", item.ID, err)
+			}
+			continue
+		}
+		if until, down := dc.providersDown(item.Source); down {
+			if err := dc.retryQueue.Defer(item, until); err != nil {
+				log.Printf("Failed to defer retry %s: %v", item.ID, err)
+			}
+			continue
+		}
+
+		retryErr := dc.runRetry(ctx, item)
+		if retryErr == nil {
+			if err := dc.retryQueue.Complete(item.ID); err != nil {
+				log.Printf("Failed to complete retry %s: %v", item.ID, err)
+			}
+			log.Printf("Retry %s (%s %s) succeeded after %d attempts", item.ID, item.Source, item.Operation, item.Attempts+1)
+			continue
+		}
+
+		// Only keep retrying what is still missing
+		var collErr *CollectionError
+		if errors.As(retryErr, &collErr) && len(collErr.Symbols) > 0 {
+			item.Symbols = collErr.Symbols
+		}
+
+		dead, err := dc.retryQueue.Fail(item, retryErr)
+		if err != nil {
+			log.Printf("Failed to reschedule retry %s: %v", item.ID, err)
+			continue
+		}
+		if dead {
+			message := fmt.Sprintf("retry %s (%s %s %v) dead-lettered after %d attempts: %v",
+				item.ID, item.Source, item.Operation, item.Symbols, item.Attempts, retryErr)
+			log.Print(message)
+			if dc.producer != nil {
+				if err := dc.producer.PublishErrorEvent(ctx, "data-collector", "retry_dead_letter", message, "error"); err != nil {
+					log.Printf("Failed to publish dead letter event: %v", err)
+				}
+			}
+		} else {
+			log.Printf("Retry %s attempt %d failed, next at %s: %v", item.ID, item.Attempts, item.NextAttempt.Format(time.RFC3339), retryErr)
+		}
+	}
+
+	return nil
 }
 
 // Configuration and Control
+// UpdateCollectionConfig validates newConfig and applies only what changed.
+// Collection loops pick up symbols and intervals on their next pass; provider
+// changes build a fresh pool for new requests while in-flight requests finish
+// on the old one. Connection settings are only read at startup and are kept
+// until the next restart.
 func (dc *DataCollector) UpdateCollectionConfig(ctx context.Context, newConfig *config.Config) error {
-	// TODO: Update collection configuration dynamically
-	// - Validate new configuration parameters
-	// - Update API client configurations
-	// - Adjust collection frequencies and intervals
-	// - Update symbol lists and data sources
-	// - Apply configuration changes without service restart
-	// - Log configuration changes for audit
-	panic("TODO: Implement dynamic configuration updates")
+	if err := newConfig.Validate(); err != nil {
+		return err
+	}
+
+	dc.mu.Lock()
+	old := dc.config
+	diff := config.Compare(old, newConfig)
+	if diff.Empty() {
+		dc.mu.Unlock()
+		return nil
+	}
+
+	applied := *newConfig
+	applied.DatabaseURL = old.DatabaseURL
+	applied.RedisURL = old.RedisURL
+	applied.QuestDBURL = old.QuestDBURL
+	applied.KafkaBootstrapServers = old.KafkaBootstrapServers
+	applied.RetryQueuePath = old.RetryQueuePath
+	applied.AdminAddr = old.AdminAddr
+	applied.AdminToken = old.AdminToken
+	applied.ReplayFile = old.ReplayFile
+	applied.ReplaySpeed = old.ReplaySpeed
+	applied.ReplayClock = old.ReplayClock
+	applied.HTTPFixtureMode = old.HTTPFixtureMode
+	applied.HTTPFixtureDir = old.HTTPFixtureDir
+
+	rebuildProviders := diff.Changed(
+		"rate_limits.max_requests_per_second", "rate_limits.mode", "rate_limits.providers",
+		"quote_providers.order", "quote_providers.weights", "quote_providers.strategy", "quote_providers.max_quote_age",
+		"synthetic.volatility", "synthetic.correlation", "synthetic.jumps_per_day", "synthetic.halts_per_day",
+		"synthetic.halt_duration", "synthetic.spread_bps", "synthetic.seed",
+	) || (old.AlphaVantageAPIKey == "") != (applied.AlphaVantageAPIKey == "") ||
+		(old.IEXCloudAPIKey == "") != (applied.IEXCloudAPIKey == "")
+
+	var newBreakers map[string]*CircuitBreaker
+	switch {
+	case rebuildProviders:
+		dc.rateLimiters = newRateLimiters(&applied, dc.cache)
+		dc.providers = newProviderPool(&applied, dc.rateLimiters)
+		if dc.replay != nil {
+			// Offline runs keep the recording as their only provider
+			dc.providers = replayPool(dc.replay)
+		}
+		for name := range dc.paused {
+			dc.providers.SetPaused(name, true)
+		}
+		newBreakers = dc.providers.Breakers()
+	case diff.Changed("api_keys.alpha_vantage", "api_keys.iex_cloud"):
+		if provider, ok := dc.providers.Get("alphavantage"); ok {
+			if av, ok := provider.(*AlphaVantageClient); ok {
+				av.SetAPIKey(applied.AlphaVantageAPIKey)
+			}
+		}
+		if provider, ok := dc.providers.Get("iex"); ok {
+			if iex, ok := provider.(*IEXCloudClient); ok {
+				iex.SetAPIKey(applied.IEXCloudAPIKey)
+			}
+		}
+	}
+	if rebuildProviders || diff.Changed("api_keys.news_api", "news.feeds") {
+		dc.newsClients = newNewsClients(&applied, dc.rateLimiters)
+	}
+	if rebuildProviders || diff.Changed("api_keys.coingecko", "crypto.stream_url") {
+		dc.cryptoClients = newCryptoClients(&applied, dc.rateLimiters)
+	}
+	if rebuildProviders {
+		dc.treasuryClient = NewTreasuryClient()
+		dc.treasuryClient.rateLimiter = dc.rateLimiters[dc.treasuryClient.Name()]
+	}
+	switch {
+	case applied.FREDAPIKey == "":
+		dc.fredClient = nil
+	case dc.fredClient == nil || rebuildProviders:
+		// New rate limiters need a new client; the old one may be mid-request
+		dc.fredClient = NewFREDClient(applied.FREDAPIKey)
+		dc.fredClient.rateLimiter = dc.rateLimiters[dc.fredClient.Name()]
+	case diff.Changed("api_keys.fred"):
+		dc.fredClient.SetAPIKey(applied.FREDAPIKey)
+	}
+	switch {
+	case applied.SECUserAgent == "":
+		dc.edgarClient = nil
+	case dc.edgarClient == nil || rebuildProviders || diff.Changed("filings.user_agent"):
+		dc.edgarClient = NewEDGARClient(applied.SECUserAgent)
+		dc.edgarClient.rateLimiter = dc.rateLimiters[dc.edgarClient.Name()]
+	}
+	if !rebuildProviders && diff.Changed("circuit_breaker.error_rate", "circuit_breaker.slow_call_latency",
+		"circuit_breaker.rate_limit_count", "circuit_breaker.open_timeout") {
+		for _, breaker := range dc.providers.Breakers() {
+			breaker.SetConfig(circuitBreakerConfigFrom(&applied))
+		}
+	}
+
+	dc.config = &applied
+	dc.mu.Unlock()
+
+	for _, breaker := range newBreakers {
+		breaker.OnStateChange(dc.publishBreakerTransition)
+	}
+	if dc.retryQueue != nil {
+		dc.retryQueue.SetConfig(RetryQueueConfigFrom(&applied))
+	}
+	dc.consensus.SetConfig(applied.ConsensusWindow, applied.ConsensusToleranceBps, applied.ConsensusMethod)
+
+	// Wake the scheduler so shorter intervals and new symbols apply now
+	dc.wakeScheduler()
+
+	log.Printf("Configuration updated: %s", diff)
+	for _, change := range diff.Changes {
+		if change.RestartRequired {
+			log.Printf("Configuration change to %s takes effect after restart", change.Field)
+		}
+	}
+
+	if dc.producer != nil {
+		if err := dc.producer.PublishAuditLog(ctx, 0, "config.update", "data-collector", map[string]interface{}{
+			"summary": diff.String(),
+			"diff":    diff,
+		}); err != nil {
+			log.Printf("Failed to publish config audit entry: %v", err)
+		}
+	}
+	return nil
 }
 
+// PauseCollection stops a collection service (market, news, economic) after
+// its in-flight pass, or takes a quote provider out of rotation. Retries for a
+// paused service are deferred rather than attempted.
 func (dc *DataCollector) PauseCollection(ctx context.Context, service string) error {
-	// TODO: Pause specific collection services
-	// - Stop collection for specified service gracefully
-	// - Complete in-flight operations before pausing
-	// - Update service status and monitoring metrics
-	// - Handle graceful resumption capability
-	panic("TODO: Implement collection service pausing")
+	return dc.setPaused(ctx, service, true)
 }
 
+// ResumeCollection undoes PauseCollection; a resumed loop polls immediately
 func (dc *DataCollector) ResumeCollection(ctx context.Context, service string) error {
-	// TODO: Resume paused collection services
-	// - Restart collection with current configuration
-	// - Handle potential data gaps during pause period
-	// - Update service status and metrics
-	// - Log resumption operations for audit
-	panic("TODO: Implement collection service resumption")
+	return dc.setPaused(ctx, service, false)
 }
 
 // Graceful Shutdown
@@ -263,26 +836,47 @@ func (dc *DataCollector) Shutdown(ctx context.Context) error {
 	panic("TODO: Implement graceful data collector shutdown")
 }
 
+// RetryItem is a failed collection request persisted for another attempt
 type RetryItem struct {
-	// TODO: Define retry item structure
-	// - Include original request details
-	// - Track retry count and timestamps
-	// - Store error information for analysis
-	// - Include priority and expiration information
+	ID          string            `json:"id"`
+	Source      string            `json:"source"`
+	Operation   string            `json:"operation"`
+	Symbols     []string          `json:"symbols,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+	Priority    int               `json:"priority"`
+	Attempts    int               `json:"attempts"`
+	MaxAttempts int               `json:"max_attempts"`
+	LastError   string            `json:"last_error,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	LastAttempt time.Time         `json:"last_attempt,omitempty"`
+	NextAttempt time.Time         `json:"next_attempt"`
+	ExpiresAt   time.Time         `json:"expires_at,omitempty"`
 }
 
+// NewsClient is a source of news articles. Articles come back normalized,
+// newest first, with at most limit of them.
 type NewsClient interface {
-	// TODO: Define news client interface
-	// - GetLatestNews method with filtering options
-	// - SearchNews method for keyword-based queries
-	// - GetNewsByCategory method for topical news
-	// - Rate limiting and error handling methods
+	Name() string
+	GetLatestNews(ctx context.Context, limit int) ([]*models.NewsArticle, error)
+	SearchNews(ctx context.Context, query string, limit int) ([]*models.NewsArticle, error)
+	GetNewsByCategory(ctx context.Context, category string, limit int) ([]*models.NewsArticle, error)
 }
 
+// CryptoClient is a source of crypto prices. Symbols are normalized pairs
+// such as BTC-USD (see ParseCryptoPair) and returned data carries them in
+// the same form; pairs the source does not list are left out.
 type CryptoClient interface {
-	// TODO: Define crypto client interface
-	// - GetCryptoPrices method for current prices
-	// - GetCryptoMarketData method for detailed data
-	// - GetCryptoHistory method for historical prices
-	// - Rate limiting and health check methods
+	Name() string
+	GetCryptoPrices(ctx context.Context, symbols []string) ([]*models.CryptoData, error)
+	GetCryptoMarketData(ctx context.Context, symbol string) (*models.CryptoData, error)
+	GetCryptoHistory(ctx context.Context, symbol string, start, end time.Time) ([]*models.CryptoData, error)
+	GetAPIHealth(ctx context.Context) (bool, error)
+}
+
+// CryptoStreamer is a crypto client that pushes updates. Stream sends every
+// trade and ticker update for symbols to out until ctx ends, reconnecting
+// as needed.
+type CryptoStreamer interface {
+	CryptoClient
+	Stream(ctx context.Context, symbols []string, out chan<- *models.CryptoData) error
 }
\ No newline at end of file
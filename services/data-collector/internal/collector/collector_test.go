@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectStockDataPublishesConsensus(t *testing.T) {
+	yahoo := &fakeProvider{name: "yahoo", prices: map[string]string{"AAPL": "180.00", "MSFT": "410.00"}}
+	alphavantage := &fakeProvider{name: "alphavantage", prices: map[string]string{"AAPL": "180.10", "MSFT": "410.20"}}
+
+	dc := newTestCollector()
+	dc.config.ConsensusSampleSize = 2
+	dc.providers = newTestPool(SelectByPriority, yahoo, alphavantage)
+
+	published := map[string]*models.QuoteConsensus{}
+	dc.consensus.OnConsensus(func(c *models.QuoteConsensus) { published[c.Symbol] = c })
+
+	require.NoError(t, dc.CollectStockData(context.Background(), []string{"AAPL", "MSFT"}))
+
+	// Yahoo served both; Alpha Vantage was asked only to cross-check them
+	require.Len(t, alphavantage.calls, 1)
+	assert.ElementsMatch(t, []string{"AAPL", "MSFT"}, alphavantage.calls[0])
+
+	require.Contains(t, published, "AAPL")
+	assert.Equal(t, decimal.MustParse("180.05"), published["AAPL"].Price)
+	assert.Len(t, published["AAPL"].Quotes, 2)
+	require.Contains(t, published, "MSFT")
+	assert.Equal(t, decimal.MustParse("410.1"), published["MSFT"].Price)
+}
+
+func TestCollectStockDataReportsRejectedQuotes(t *testing.T) {
+	yahoo := &fakeProvider{name: "yahoo", prices: map[string]string{"AAPL": "180.00", "MSFT": "0"}}
+
+	dc := newTestCollector()
+	dc.config.ConsensusSampleSize = 0
+	dc.providers = newTestPool(SelectByPriority, yahoo)
+
+	err := dc.CollectStockData(context.Background(), []string{"AAPL", "MSFT"})
+	var collErr *CollectionError
+	require.ErrorAs(t, err, &collErr)
+	assert.Equal(t, []string{"MSFT"}, collErr.Symbols)
+	assert.ErrorContains(t, err, "invalid price")
+}
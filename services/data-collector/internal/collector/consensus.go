@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// Consensus methods
+const (
+	ConsensusMedian      = "median"
+	ConsensusTrimmedMean = "trimmed_mean"
+)
+
+// accuracyDecay weights each new comparison in a source's accuracy score, so
+// the score reflects roughly the last 1/accuracyDecay comparisons
+const accuracyDecay = 0.05
+
+// SourceAccuracy is how often a source's quotes agreed with the consensus
+type SourceAccuracy struct {
+	Score    float64 `json:"score"` // exponentially weighted agreement, 1 is always within tolerance
+	Compared int     `json:"compared"`
+	Flagged  int     `json:"flagged"`
+}
+
+// ConsensusFunc is notified of every consensus the reconciler forms
+type ConsensusFunc func(consensus *models.QuoteConsensus)
+
+type sourceQuote struct {
+	price decimal.Decimal
+	seen  time.Time
+}
+
+// Reconciler keeps the last quote from each source per symbol for a short
+// window and reconciles them into a consensus price. Each new quote is scored
+// against the consensus it took part in, building a per-source accuracy score.
+//
+// With two sources the consensus is their midpoint, so a disagreement flags
+// both; it takes a third source to single out the one that is wrong.
+type Reconciler struct {
+	mu           sync.Mutex
+	window       time.Duration
+	toleranceBps float64
+	method       string
+	quotes       map[string]map[string]sourceQuote // symbol -> source -> last quote
+	accuracy     map[string]*SourceAccuracy
+	onConsensus  ConsensusFunc
+}
+
+func NewReconciler(window time.Duration, toleranceBps float64, method string) *Reconciler {
+	r := &Reconciler{
+		quotes:   make(map[string]map[string]sourceQuote),
+		accuracy: make(map[string]*SourceAccuracy),
+	}
+	r.SetConfig(window, toleranceBps, method)
+	return r
+}
+
+// NewReconcilerFromConfig builds a reconciler with the configured window,
+// tolerance and method
+func NewReconcilerFromConfig(cfg *config.Config) *Reconciler {
+	return NewReconciler(cfg.ConsensusWindow, cfg.ConsensusToleranceBps, cfg.ConsensusMethod)
+}
+
+// OnConsensus registers the consensus callback
+func (r *Reconciler) OnConsensus(fn ConsensusFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onConsensus = fn
+}
+
+// SetConfig applies new settings to consensus computed from now on
+func (r *Reconciler) SetConfig(window time.Duration, toleranceBps float64, method string) {
+	if method != ConsensusTrimmedMean {
+		method = ConsensusMedian
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.window = window
+	r.toleranceBps = toleranceBps
+	r.method = method
+}
+
+// Observe records quote and returns the consensus across every source that
+// quoted the symbol within the window, or nil while only one source has. A
+// consensus is also passed to the OnConsensus callback.
+func (r *Reconciler) Observe(quote *models.MarketData, now time.Time) *models.QuoteConsensus {
+	if quote.Price.Sign() <= 0 || quote.Source == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	consensus := r.observe(quote, now)
+	fn := r.onConsensus
+	r.mu.Unlock()
+
+	if consensus != nil && fn != nil {
+		fn(consensus)
+	}
+	return consensus
+}
+
+func (r *Reconciler) observe(quote *models.MarketData, now time.Time) *models.QuoteConsensus {
+
+	sources, ok := r.quotes[quote.Symbol]
+	if !ok {
+		sources = make(map[string]sourceQuote)
+		r.quotes[quote.Symbol] = sources
+	}
+	sources[quote.Source] = sourceQuote{price: quote.Price, seen: now}
+
+	prices := make(map[string]decimal.Decimal, len(sources))
+	for source, q := range sources {
+		if now.Sub(q.seen) > r.window {
+			delete(sources, source)
+			continue
+		}
+		prices[source] = q.price
+	}
+	if len(prices) < 2 {
+		return nil
+	}
+
+	consensus := &models.QuoteConsensus{
+		Symbol:    quote.Symbol,
+		Price:     consensusPrice(prices, r.method),
+		Method:    r.method,
+		Quotes:    prices,
+		Timestamp: now,
+	}
+	for _, source := range sortedKeys(prices) {
+		deviation := prices[source].Sub(consensus.Price).Abs().Div(consensus.Price).Float64() * 10000
+		if deviation > r.toleranceBps {
+			consensus.Outliers = append(consensus.Outliers, models.SourceDeviation{
+				Source:       source,
+				Price:        prices[source],
+				DeviationBps: deviation,
+			})
+		}
+	}
+
+	// Only the new quote is scored; the others were scored when they arrived
+	r.score(quote.Source, consensus)
+	return consensus
+}
+
+func (r *Reconciler) score(source string, consensus *models.QuoteConsensus) {
+	acc, ok := r.accuracy[source]
+	if !ok {
+		acc = &SourceAccuracy{Score: 1}
+		r.accuracy[source] = acc
+	}
+
+	agreed := 1.0
+	for _, outlier := range consensus.Outliers {
+		if outlier.Source == source {
+			agreed = 0
+			acc.Flagged++
+		}
+	}
+	acc.Compared++
+	acc.Score += accuracyDecay * (agreed - acc.Score)
+}
+
+// Accuracy returns each source's agreement with consensus so far
+func (r *Reconciler) Accuracy() map[string]SourceAccuracy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accuracy := make(map[string]SourceAccuracy, len(r.accuracy))
+	for source, acc := range r.accuracy {
+		accuracy[source] = *acc
+	}
+	return accuracy
+}
+
+// consensusPrice is the median of prices, or for trimmed_mean the mean once
+// the highest and lowest are dropped (plain mean below three sources)
+func consensusPrice(prices map[string]decimal.Decimal, method string) decimal.Decimal {
+	values := make([]decimal.Decimal, 0, len(prices))
+	for _, price := range prices {
+		values = append(values, price)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].LessThan(values[j])
+	})
+
+	n := len(values)
+	if method == ConsensusTrimmedMean {
+		if n >= 3 {
+			values = values[1 : n-1]
+		}
+		sum := decimal.Zero
+		for _, v := range values {
+			sum = sum.Add(v)
+		}
+		return sum.Div(decimal.FromInt(int64(len(values))))
+	}
+
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return values[n/2-1].Add(values[n/2]).Div(decimal.FromInt(2))
+}
+
+func sortedKeys(m map[string]decimal.Decimal) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcilerConsensus(t *testing.T) {
+	r := NewReconciler(time.Minute, 50, ConsensusMedian)
+	now := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	quote := func(source string, price string) *models.MarketData {
+		return &models.MarketData{Symbol: "AAPL", Source: source, Price: decimal.MustParse(price)}
+	}
+
+	// One source is not a consensus
+	assert.Nil(t, r.Observe(quote("yahoo", "180.00"), now))
+
+	c := r.Observe(quote("alphavantage", "180.10"), now.Add(time.Second))
+	require.NotNil(t, c)
+	assert.Equal(t, decimal.MustParse("180.05"), c.Price)
+	assert.Empty(t, c.Outliers)
+
+	// The median keeps the bad third source from moving the consensus
+	c = r.Observe(quote("iex", "183.00"), now.Add(2*time.Second))
+	require.NotNil(t, c)
+	assert.Equal(t, decimal.MustParse("180.10"), c.Price)
+	require.Len(t, c.Outliers, 1)
+	assert.Equal(t, "iex", c.Outliers[0].Source)
+	assert.InDelta(t, 161, c.Outliers[0].DeviationBps, 1)
+
+	accuracy := r.Accuracy()
+	assert.Equal(t, 1, accuracy["iex"].Flagged)
+	assert.Less(t, accuracy["iex"].Score, accuracy["alphavantage"].Score)
+	assert.Equal(t, 1.0, accuracy["alphavantage"].Score)
+
+	// Quotes older than the window drop out
+	assert.Nil(t, r.Observe(quote("yahoo", "180.20"), now.Add(2*time.Minute)))
+}
+
+func TestConsensusPrice(t *testing.T) {
+	d := decimal.MustParse
+	prices := map[string]decimal.Decimal{"a": d("100"), "b": d("101"), "c": d("102"), "d": d("150")}
+	assert.Equal(t, d("101.5"), consensusPrice(prices, ConsensusMedian))
+	assert.Equal(t, d("101.5"), consensusPrice(prices, ConsensusTrimmedMean))
+
+	prices = map[string]decimal.Decimal{"a": d("100"), "b": d("104")}
+	assert.Equal(t, d("102"), consensusPrice(prices, ConsensusTrimmedMean))
+
+	// Three-way means that float64 would leave at 0.30000000000000004
+	prices = map[string]decimal.Decimal{"a": d("0.1"), "b": d("0.3"), "c": d("0.5")}
+	assert.Equal(t, d("0.3"), consensusPrice(prices, ConsensusTrimmedMean))
+}
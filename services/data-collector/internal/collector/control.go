@@ -0,0 +1,271 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Collection services that can be paused and resumed at runtime. A quote
+// provider can be paused by its name as well, which takes it out of rotation.
+const (
+	ServiceMarket   = "market"
+	ServiceNews     = "news"
+	ServiceEconomic = "economic"
+	ServiceFilings  = "filings"
+)
+
+var collectionServices = []string{ServiceMarket, ServiceNews, ServiceEconomic, ServiceFilings}
+
+// maxBackfillJobs bounds how many finished backfill jobs are remembered
+const maxBackfillJobs = 20
+
+var ErrUnknownService = errors.New("unknown collection service")
+
+// ServiceStatus is the runtime state of a collection service or quote provider
+type ServiceStatus struct {
+	Name     string    `json:"name"`
+	Kind     string    `json:"kind"` // "collector" or "provider"
+	State    string    `json:"state"`
+	PausedAt time.Time `json:"paused_at,omitempty"`
+	Circuit  string    `json:"circuit,omitempty"`
+}
+
+// BackfillJob tracks a historical backfill started at runtime
+type BackfillJob struct {
+	ID         string            `json:"id"`
+	Symbols    []string          `json:"symbols"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	State      string            `json:"state"` // running, completed or failed
+	Completed  int               `json:"completed"`
+	Failed     map[string]string `json:"failed,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at,omitempty"`
+}
+
+func (j *BackfillJob) snapshot() BackfillJob {
+	job := *j
+	job.Symbols = append([]string(nil), j.Symbols...)
+	if j.Failed != nil {
+		job.Failed = make(map[string]string, len(j.Failed))
+		for symbol, reason := range j.Failed {
+			job.Failed[symbol] = reason
+		}
+	}
+	return job
+}
+
+// Services lists the collection services and quote providers with their state
+func (dc *DataCollector) Services() []ServiceStatus {
+	dc.mu.RLock()
+	paused := make(map[string]time.Time, len(dc.paused))
+	for name, at := range dc.paused {
+		paused[name] = at
+	}
+	pool := dc.providers
+	dc.mu.RUnlock()
+
+	status := func(name, kind string) ServiceStatus {
+		s := ServiceStatus{Name: name, Kind: kind, State: "running"}
+		if at, ok := paused[name]; ok {
+			s.State = "paused"
+			s.PausedAt = at
+		}
+		return s
+	}
+
+	services := make([]ServiceStatus, 0, len(collectionServices)+len(pool.Names()))
+	for _, name := range collectionServices {
+		services = append(services, status(name, "collector"))
+	}
+
+	breakers := pool.Breakers()
+	for _, name := range pool.Names() {
+		s := status(name, "provider")
+		if breaker, ok := breakers[name]; ok {
+			s.Circuit = breaker.State().String()
+		}
+		services = append(services, s)
+	}
+	return services
+}
+
+// EffectiveConfig returns the configuration in use, secrets redacted
+func (dc *DataCollector) EffectiveConfig() map[string]interface{} {
+	return dc.currentConfig().Effective()
+}
+
+func (dc *DataCollector) isPaused(service string) bool {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	_, ok := dc.paused[service]
+	return ok
+}
+
+// setPaused records the pause state of a service or provider. Pausing an
+// already paused service is a no-op.
+func (dc *DataCollector) setPaused(ctx context.Context, service string, pause bool) error {
+	service = strings.ToLower(strings.TrimSpace(service))
+
+	dc.mu.Lock()
+	known := false
+	for _, name := range collectionServices {
+		known = known || name == service
+	}
+	if dc.providers.SetPaused(service, pause) {
+		known = true
+	}
+	if !known {
+		dc.mu.Unlock()
+		return fmt.Errorf("%w: %q", ErrUnknownService, service)
+	}
+
+	_, wasPaused := dc.paused[service]
+	if pause == wasPaused {
+		dc.mu.Unlock()
+		return nil
+	}
+	if pause {
+		dc.paused[service] = time.Now()
+	} else {
+		delete(dc.paused, service)
+	}
+	dc.mu.Unlock()
+
+	dc.wakeScheduler()
+
+	action := "collection.resume"
+	if pause {
+		action = "collection.pause"
+	}
+	log.Printf("%s: %s", action, service)
+	if dc.producer != nil {
+		if err := dc.producer.PublishAuditLog(ctx, 0, action, "data-collector", map[string]interface{}{
+			"service": service,
+		}); err != nil {
+			log.Printf("Failed to publish %s audit entry: %v", action, err)
+		}
+	}
+	return nil
+}
+
+// wakeScheduler makes the collection loops re-plan immediately
+func (dc *DataCollector) wakeScheduler() {
+	select {
+	case dc.configUpdated <- struct{}{}:
+	default:
+	}
+}
+
+// serviceForSource maps a retry item source to the service that collects it
+func serviceForSource(source string) string {
+	switch source {
+	case "news":
+		return ServiceNews
+	case "economic":
+		return ServiceEconomic
+	case "filings":
+		return ServiceFilings
+	default:
+		return ServiceMarket
+	}
+}
+
+// StartBackfill runs BackfillHistoricalData for each symbol in the background
+// and returns the job so its progress can be followed with BackfillJobs. The
+// job stops when ctx is cancelled.
+func (dc *DataCollector) StartBackfill(ctx context.Context, symbols []string, start, end time.Time) (BackfillJob, error) {
+	if len(symbols) == 0 {
+		return BackfillJob{}, errors.New("at least one symbol is required")
+	}
+	if !start.Before(end) {
+		return BackfillJob{}, fmt.Errorf("backfill start %s must be before end %s",
+			start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	now := time.Now()
+	job := &BackfillJob{
+		ID:        "backfill-" + strconv.FormatInt(now.UnixNano(), 36),
+		Symbols:   symbols,
+		Start:     start,
+		End:       end,
+		State:     "running",
+		StartedAt: now,
+	}
+
+	dc.jobsMu.Lock()
+	dc.backfills = append(dc.backfills, job)
+	if len(dc.backfills) > maxBackfillJobs {
+		// Drop the oldest finished job; running ones are always kept
+		for i, old := range dc.backfills {
+			if old.State != "running" {
+				dc.backfills = append(dc.backfills[:i], dc.backfills[i+1:]...)
+				break
+			}
+		}
+	}
+	snapshot := job.snapshot()
+	dc.jobsMu.Unlock()
+
+	if dc.producer != nil {
+		if err := dc.producer.PublishAuditLog(ctx, 0, "collection.backfill", "data-collector", map[string]interface{}{
+			"job":     job.ID,
+			"symbols": symbols,
+			"start":   start,
+			"end":     end,
+		}); err != nil {
+			log.Printf("Failed to publish backfill audit entry: %v", err)
+		}
+	}
+
+	go dc.runBackfill(ctx, job)
+	return snapshot, nil
+}
+
+func (dc *DataCollector) runBackfill(ctx context.Context, job *BackfillJob) {
+	for _, symbol := range job.Symbols {
+		err := dc.BackfillHistoricalData(ctx, symbol, job.Start, job.End)
+
+		dc.jobsMu.Lock()
+		if err != nil {
+			if job.Failed == nil {
+				job.Failed = make(map[string]string)
+			}
+			job.Failed[symbol] = err.Error()
+		} else {
+			job.Completed++
+		}
+		dc.jobsMu.Unlock()
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	dc.jobsMu.Lock()
+	job.State = "completed"
+	if len(job.Failed) > 0 || job.Completed < len(job.Symbols) {
+		job.State = "failed"
+	}
+	job.FinishedAt = time.Now()
+	dc.jobsMu.Unlock()
+
+	log.Printf("Backfill %s %s: %d of %d symbols", job.ID, job.State, job.Completed, len(job.Symbols))
+}
+
+// BackfillJobs returns the running and recently finished backfill jobs
+func (dc *DataCollector) BackfillJobs() []BackfillJob {
+	dc.jobsMu.Lock()
+	defer dc.jobsMu.Unlock()
+
+	jobs := make([]BackfillJob, len(dc.backfills))
+	for i, job := range dc.backfills {
+		jobs[i] = job.snapshot()
+	}
+	return jobs
+}
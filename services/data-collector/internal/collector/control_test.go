@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCollector() *DataCollector {
+	cfg := config.Load()
+	cfg.RateLimitMode = "local"
+	cfg.QuoteProviders = []string{"yahoo"}
+	return New(nil, nil, nil, cfg)
+}
+
+func serviceState(dc *DataCollector, name string) string {
+	for _, s := range dc.Services() {
+		if s.Name == name {
+			return s.State
+		}
+	}
+	return ""
+}
+
+func TestPauseCollection(t *testing.T) {
+	dc := newTestCollector()
+	ctx := context.Background()
+
+	require.NoError(t, dc.PauseCollection(ctx, "Market"))
+	assert.True(t, dc.isPaused(ServiceMarket))
+	assert.Equal(t, "paused", serviceState(dc, ServiceMarket))
+	assert.Equal(t, "running", serviceState(dc, ServiceNews))
+	assert.Len(t, dc.configUpdated, 1, "pausing wakes the scheduler")
+
+	// Pausing twice is a no-op
+	require.NoError(t, dc.PauseCollection(ctx, ServiceMarket))
+
+	require.NoError(t, dc.ResumeCollection(ctx, ServiceMarket))
+	assert.False(t, dc.isPaused(ServiceMarket))
+
+	assert.ErrorIs(t, dc.PauseCollection(ctx, "options"), ErrUnknownService)
+}
+
+func TestPauseProviderTakesItOutOfRotation(t *testing.T) {
+	dc := newTestCollector()
+	ctx := context.Background()
+
+	require.NoError(t, dc.PauseCollection(ctx, "yahoo"))
+	assert.Equal(t, "paused", serviceState(dc, "yahoo"))
+	assert.Empty(t, dc.providerPool().order())
+	assert.Equal(t, []string{"yahoo"}, dc.providerPool().Names(), "paused providers stay registered")
+
+	_, err := dc.providerPool().GetQuote(ctx, "AAPL")
+	assert.ErrorIs(t, err, ErrNoProviders)
+
+	// A rebuilt pool keeps the provider paused
+	updated := *dc.currentConfig()
+	updated.QuoteProviderStrategy = SelectByWeight
+	require.NoError(t, dc.UpdateCollectionConfig(ctx, &updated))
+	assert.Empty(t, dc.providerPool().order())
+
+	require.NoError(t, dc.ResumeCollection(ctx, "yahoo"))
+	assert.Len(t, dc.providerPool().order(), 1)
+}
+
+func TestBackfillPeriod(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "5d", backfillPeriod(now.AddDate(0, 0, -3), now))
+	assert.Equal(t, "3mo", backfillPeriod(now.AddDate(0, -2, 0), now))
+	assert.Equal(t, "2y", backfillPeriod(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), now))
+	assert.Equal(t, "max", backfillPeriod(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), now))
+}
@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"tradecaptain/data-collector/internal/corpactions"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// A symbol's first corporate actions pass reads actionHistory back; later
+// passes read actionOverlap back again, picking up corrections to recent ones
+const (
+	actionHistory = 5 * 365 * 24 * time.Hour
+	actionOverlap = 90 * 24 * time.Hour
+)
+
+// StartCorporateActionsCollection collects the stock symbols' splits and
+// dividends every CorporateActionsInterval. It belongs to the market service
+// and pauses with it; it idles while no quote provider reports actions.
+func (dc *DataCollector) StartCorporateActionsCollection(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	warned := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		cfg := dc.currentConfig()
+		timer.Reset(cfg.CorporateActionsInterval)
+		if dc.isPaused(ServiceMarket) {
+			continue
+		}
+
+		err := dc.CollectCorporateActions(ctx, cfg.StockSymbols)
+		switch {
+		case errors.Is(err, ErrNoProviders):
+			if !warned {
+				log.Printf("Corporate actions collection idle: %v", err)
+				warned = true
+			}
+		case err != nil && ctx.Err() == nil:
+			dc.HandleCollectionError(ctx, err, "corporate_actions", cfg.StockSymbols)
+		}
+	}
+}
+
+// CollectCorporateActions reads each symbol's corporate actions from the
+// quote providers and merges them into the stored ones. Actions entered
+// through the admin API are left as they are.
+func (dc *DataCollector) CollectCorporateActions(ctx context.Context, symbols []string) error {
+	if dc.db == nil {
+		return errors.New("corporate actions collection needs a database")
+	}
+	if err := dc.db.EnsureCorporateActions(ctx); err != nil {
+		return err
+	}
+	pool := dc.providerPool()
+	now := time.Now().UTC()
+
+	var failed []string
+	var firstErr error
+	changed := 0
+	for _, symbol := range symbols {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := dc.collectSymbolActions(ctx, pool, symbol, now)
+		switch {
+		case err == nil:
+			changed += n
+		case errors.Is(err, ErrNoProviders):
+			return err
+		case errors.Is(err, ErrSymbolNotFound):
+			log.Printf("Skipping corporate actions for %s: %v", symbol, err)
+		default:
+			failed = append(failed, symbol)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if changed > 0 {
+		log.Printf("Stored %d new or changed corporate actions", changed)
+	}
+	if len(failed) > 0 {
+		return &CollectionError{Source: "corporate_actions", Symbols: failed, Err: firstErr}
+	}
+	return nil
+}
+
+// collectSymbolActions merges one symbol's actions and returns how many
+// were new or changed
+func (dc *DataCollector) collectSymbolActions(ctx context.Context, pool *ProviderPool, symbol string, now time.Time) (int, error) {
+	stored, err := dc.db.GetCorporateActions(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	from := now.Add(-actionHistory)
+	if len(stored) > 0 {
+		from = now.Add(-actionOverlap)
+	}
+
+	actions, err := pool.GetCorporateActions(ctx, symbol, from)
+	if err != nil {
+		return 0, err
+	}
+	valid := make([]*models.CorporateAction, 0, len(actions))
+	for _, action := range actions {
+		action.Symbol = symbol
+		action.UpdatedAt = now
+		if err := corpactions.Normalize(action); err != nil {
+			log.Printf("Skipping corporate action from %s: %v", action.Source, err)
+			continue
+		}
+		valid = append(valid, action)
+	}
+	return dc.db.MergeCorporateActions(ctx, valid)
+}
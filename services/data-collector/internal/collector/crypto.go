@@ -0,0 +1,300 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// cryptoStoreInterval is the most often a streamed pair is written to
+// Postgres; every update is still published
+const cryptoStoreInterval = time.Second
+
+// CryptoPair is a normalized trading pair. Its string form, BASE-QUOTE, is
+// the symbol crypto data is stored and published under.
+type CryptoPair struct {
+	Base  string
+	Quote string
+}
+
+func (p CryptoPair) String() string {
+	return p.Base + "-" + p.Quote
+}
+
+// cryptoAliases maps exchange-specific asset codes to the common ones
+var cryptoAliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+}
+
+// cryptoQuoteSuffixes split concatenated pairs such as BTCUSDT. Longer codes
+// come first so BTCFDUSD is read as BTC/FDUSD rather than BTCFD/USD.
+var cryptoQuoteSuffixes = []string{"FDUSD", "USDT", "USDC", "BUSD", "USD", "EUR", "GBP", "JPY", "BTC", "ETH"}
+
+// ParseCryptoPair normalizes the pair notations in use: BTC-USD, BTC/USD,
+// BTC_USD, BTCUSDT and XBT/USD all parse, and a bare asset such as BTC is
+// quoted in USD
+func ParseCryptoPair(symbol string) (CryptoPair, error) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+
+	var pair CryptoPair
+	if i := strings.IndexAny(s, "-/_:"); i >= 0 {
+		pair = CryptoPair{Base: s[:i], Quote: s[i+1:]}
+	} else {
+		pair = CryptoPair{Base: s, Quote: "USD"}
+		for _, quote := range cryptoQuoteSuffixes {
+			// Bases are at least two letters, so BUSD alone is an asset
+			if len(s) >= len(quote)+2 && strings.HasSuffix(s, quote) {
+				pair = CryptoPair{Base: strings.TrimSuffix(s, quote), Quote: quote}
+				break
+			}
+		}
+	}
+
+	for _, code := range []*string{&pair.Base, &pair.Quote} {
+		if alias, ok := cryptoAliases[*code]; ok {
+			*code = alias
+		}
+		if *code == "" || strings.IndexFunc(*code, func(r rune) bool {
+			return (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+		}) >= 0 {
+			return CryptoPair{}, fmt.Errorf("invalid crypto pair %q", symbol)
+		}
+	}
+	if pair.Base == pair.Quote {
+		return CryptoPair{}, fmt.Errorf("invalid crypto pair %q", symbol)
+	}
+	return pair, nil
+}
+
+// parseCryptoPairs normalizes symbols, dropping invalid ones and duplicates
+func parseCryptoPairs(symbols []string) []CryptoPair {
+	seen := make(map[CryptoPair]bool, len(symbols))
+	pairs := make([]CryptoPair, 0, len(symbols))
+	for _, symbol := range symbols {
+		pair, err := ParseCryptoPair(symbol)
+		if err != nil {
+			log.Printf("Skipping crypto symbol: %v", err)
+			continue
+		}
+		if !seen[pair] {
+			seen[pair] = true
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// newCryptoClients builds CoinGecko, which needs no key, and the exchange
+// stream when a stream URL is configured
+func newCryptoClients(cfg *config.Config, limiters map[string]*RateLimiter) map[string]CryptoClient {
+	clients := make(map[string]CryptoClient)
+
+	gecko := NewCoinGeckoClient(cfg.CoinGeckoAPIKey)
+	gecko.rateLimiter = limiters[gecko.Name()]
+	clients[gecko.Name()] = gecko
+
+	if cfg.CryptoStreamURL != "" {
+		stream := NewBinanceStreamClient(cfg.CryptoStreamURL)
+		clients[stream.Name()] = stream
+	}
+	return clients
+}
+
+// cryptoSources returns the crypto clients by name
+func (dc *DataCollector) cryptoSources() []CryptoClient {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+
+	clients := make([]CryptoClient, 0, len(dc.cryptoClients))
+	for _, client := range dc.cryptoClients {
+		clients = append(clients, client)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Name() < clients[j].Name() })
+	return clients
+}
+
+// StartCryptoCollection polls the tracked pairs every MarketDataInterval
+// (crypto trades around the clock) and runs every streaming client until ctx
+// ends. Streams are restarted when the symbols or the streaming clients
+// change.
+func (dc *DataCollector) StartCryptoCollection(ctx context.Context) {
+	updates := make(chan *models.CryptoData, 1024)
+	var streams sync.WaitGroup
+	defer streams.Wait()
+
+	var stopStreams context.CancelFunc = func() {}
+	defer func() { stopStreams() }()
+	var running []CryptoStreamer
+	var symbols []string
+	startStreams := func(cfg *config.Config) {
+		var streamers []CryptoStreamer
+		for _, client := range dc.cryptoSources() {
+			if streamer, ok := client.(CryptoStreamer); ok {
+				streamers = append(streamers, streamer)
+			}
+		}
+		if sameStreamers(running, streamers) && strings.Join(symbols, ",") == strings.Join(cfg.CryptoSymbols, ",") {
+			return
+		}
+
+		stopStreams()
+		var streamCtx context.Context
+		streamCtx, stopStreams = context.WithCancel(ctx)
+		running, symbols = streamers, cfg.CryptoSymbols
+
+		for _, streamer := range streamers {
+			streams.Add(1)
+			go func(streamer CryptoStreamer, symbols []string) {
+				defer streams.Done()
+				if err := streamer.Stream(streamCtx, symbols, updates); err != nil && streamCtx.Err() == nil {
+					log.Printf("%s crypto stream stopped: %v", streamer.Name(), err)
+				}
+			}(streamer, symbols)
+		}
+	}
+
+	// Streamed updates are saved as they arrive, before the first poll
+	if dc.db != nil {
+		if err := dc.db.EnsureCryptoData(ctx); err != nil {
+			log.Printf("Failed to prepare crypto storage: %v", err)
+		}
+	}
+	cfg := dc.currentConfig()
+	startStreams(cfg)
+	lastStored := make(map[string]time.Time)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case data := <-updates:
+			if dc.isPaused(ServiceMarket) {
+				continue
+			}
+			store := data.Timestamp.Sub(lastStored[data.Symbol]) >= cryptoStoreInterval
+			if store {
+				lastStored[data.Symbol] = data.Timestamp
+			}
+			if err := dc.storeCryptoData(ctx, data, store); err != nil {
+				log.Printf("Failed to store streamed %s: %v", data.Symbol, err)
+			}
+
+		case <-timer.C:
+			cfg = dc.currentConfig()
+			startStreams(cfg)
+			timer.Reset(cfg.MarketDataInterval)
+			if dc.isPaused(ServiceMarket) || len(cfg.CryptoSymbols) == 0 {
+				continue
+			}
+			if err := dc.CollectCryptoData(ctx, cfg.CryptoSymbols); err != nil && ctx.Err() == nil {
+				dc.HandleCollectionError(ctx, err, "crypto", cfg.CryptoSymbols)
+			}
+		}
+	}
+}
+
+func sameStreamers(a, b []CryptoStreamer) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CollectCryptoData fetches snapshots for symbols from the polling clients,
+// each filling in what the previous one could not serve, and stores and
+// publishes them
+func (dc *DataCollector) CollectCryptoData(ctx context.Context, symbols []string) error {
+	pairs := parseCryptoPairs(symbols)
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	if dc.db != nil {
+		if err := dc.db.EnsureCryptoData(ctx); err != nil {
+			return err
+		}
+	}
+
+	missing := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		missing[pair.String()] = true
+	}
+
+	var fetchErr error
+	for _, client := range dc.cryptoSources() {
+		if _, ok := client.(CryptoStreamer); ok || len(missing) == 0 {
+			continue
+		}
+
+		wanted := make([]string, 0, len(missing))
+		for symbol := range missing {
+			wanted = append(wanted, symbol)
+		}
+		sort.Strings(wanted)
+
+		snapshots, err := client.GetCryptoPrices(ctx, wanted)
+		if err != nil {
+			fetchErr = err
+		}
+		for _, data := range snapshots {
+			if !missing[data.Symbol] {
+				continue
+			}
+			if err := dc.storeCryptoData(ctx, data, true); err != nil {
+				return err
+			}
+			delete(missing, data.Symbol)
+		}
+	}
+
+	if len(missing) > 0 {
+		failed := make([]string, 0, len(missing))
+		for symbol := range missing {
+			failed = append(failed, symbol)
+		}
+		sort.Strings(failed)
+		if fetchErr == nil {
+			fetchErr = errors.New("no crypto source returned data")
+		}
+		return &CollectionError{Source: "crypto", Symbols: failed, Err: fetchErr}
+	}
+	return nil
+}
+
+// storeCryptoData validates a snapshot, saves it when save is set and
+// publishes it
+func (dc *DataCollector) storeCryptoData(ctx context.Context, data *models.CryptoData, save bool) error {
+	if data.Price.Sign() <= 0 {
+		return fmt.Errorf("%s price for %s is %v", data.Source, data.Symbol, data.Price)
+	}
+
+	if save && dc.db != nil {
+		if err := dc.db.SaveCryptoData(ctx, data); err != nil {
+			return err
+		}
+	}
+	if dc.producer != nil {
+		if err := dc.producer.PublishCryptoData(ctx, data); err != nil {
+			log.Printf("Failed to publish %s crypto data: %v", data.Symbol, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCryptoPair(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{"BTC-USD", "BTC-USD"},
+		{"btc/usd", "BTC-USD"},
+		{"XBT/USD", "BTC-USD"},
+		{"BTCUSDT", "BTC-USDT"},
+		{"ethbtc", "ETH-BTC"},
+		{"SOL_EUR", "SOL-EUR"},
+		{"XDGUSD", "DOGE-USD"},
+		{"BUSD", "BUSD-USD"},
+		{"BTCFDUSD", "BTC-FDUSD"},
+		{" ETH ", "ETH-USD"},
+	}
+	for _, tt := range tests {
+		pair, err := ParseCryptoPair(tt.symbol)
+		require.NoError(t, err, tt.symbol)
+		assert.Equal(t, tt.want, pair.String(), tt.symbol)
+	}
+
+	for _, symbol := range []string{"", "BTC-", "-USD", "BTC-BTC", "BTC/U$D"} {
+		_, err := ParseCryptoPair(symbol)
+		assert.Error(t, err, symbol)
+	}
+}
+
+func TestCoinGeckoClient(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		var name string
+		switch r.URL.Path {
+		case "/coins/markets":
+			name = "coins_markets_usd.json"
+		case "/search":
+			name = "search_pepe.json"
+		case "/coins/bitcoin/market_chart/range":
+			name = "market_chart_range.json"
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":{"error_code":429,"error_message":"You've exceeded the Rate Limit."}}`))
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "crypto", name))
+		require.NoError(t, err)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewCoinGeckoClient("demo-key")
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	// BTCUSDT and XBT/USD both price off bitcoin in dollars; PEPE is found
+	// through /search and the unknown coin is left out
+	prices, err := client.GetCryptoPrices(ctx, []string{"BTCUSDT", "XBT/USD", "PEPE", "DLT"})
+	require.NoError(t, err)
+	require.Len(t, prices, 3)
+
+	bySymbol := make(map[string]*models.CryptoData)
+	for _, data := range prices {
+		bySymbol[data.Symbol] = data
+	}
+	btc := bySymbol["BTC-USD"]
+	require.NotNil(t, btc)
+	assert.Equal(t, "Bitcoin", btc.Name)
+	assert.Equal(t, decimal.MustParse("67432"), btc.Price)
+	assert.Equal(t, 39187211342.0, btc.Volume24h)
+	assert.Equal(t, decimal.MustParse("6.16278"), btc.ChangePercent24h)
+	assert.Equal(t, time.Date(2024, 3, 20, 21, 14, 5, 162000000, time.UTC), btc.Timestamp)
+	assert.Equal(t, "coingecko", btc.Source)
+	assert.Equal(t, decimal.MustParse("67432"), bySymbol["BTC-USDT"].Price)
+	assert.Equal(t, decimal.MustParse("0.00000739"), bySymbol["PEPE-USD"].Price)
+
+	var markets *http.Request
+	for _, r := range requests {
+		if r.URL.Path == "/coins/markets" {
+			markets = r
+		}
+	}
+	require.NotNil(t, markets)
+	assert.Equal(t, "demo-key", markets.Header.Get("x-cg-demo-api-key"))
+	assert.Equal(t, "usd", markets.URL.Query().Get("vs_currency"))
+	assert.Equal(t, "bitcoin,pepe", markets.URL.Query().Get("ids"))
+
+	history, err := client.GetCryptoHistory(ctx, "BTC-USD", time.Unix(1710892800, 0), time.Unix(1710900000, 0))
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, time.Date(2024, 3, 20, 1, 0, 0, 0, time.UTC), history[1].Timestamp)
+	assert.Equal(t, decimal.MustParse("62980.11"), history[1].Price)
+	assert.Equal(t, 1238613095622.8, history[1].MarketCap)
+
+	healthy, err := client.GetAPIHealth(ctx)
+	assert.False(t, healthy)
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestBinanceStreamClient(t *testing.T) {
+	messages := []string{
+		`{"stream":"btcusdt@ticker","data":{"e":"24hrTicker","E":1710969245000,"s":"BTCUSDT","p":"3914.52000000","P":"6.163","w":"65201.3","x":"63517.48","c":"67432.00000000","Q":"0.01","b":"67431.99","B":"2.1","a":"67432.00","A":"1.3","o":"63517.48","h":"68064.00","l":"62413.00","v":"61234.1","q":"3992581421.51","O":1710882845000,"C":1710969245000,"F":3488161024,"L":3490371144,"n":2210121}}`,
+		`{"stream":"btcusdt@aggTrade","data":{"e":"aggTrade","E":1710969246001,"s":"BTCUSDT","a":2889136541,"p":"67440.10000000","q":"0.05000000","f":3490371145,"l":3490371146,"T":1710969246000,"m":false,"M":true}}`,
+		`{"result":null,"id":1}`,
+	}
+
+	upgrader := websocket.Upgrader{}
+	var streams string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streams = r.URL.Query().Get("streams")
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		for _, message := range messages {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+		}
+		// Hold the connection until the client goes away
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	client := NewBinanceStreamClient("ws" + strings.TrimPrefix(server.URL, "http") + "/stream")
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan *models.CryptoData, 10)
+	done := make(chan error, 1)
+	go func() { done <- client.Stream(ctx, []string{"XBT/USD"}, out) }()
+
+	ticker := <-out
+	assert.Equal(t, "BTC-USD", ticker.Symbol)
+	assert.Equal(t, decimal.MustParse("67432"), ticker.Price)
+	assert.Equal(t, decimal.MustParse("3914.52"), ticker.Change24h)
+	assert.Equal(t, 3992581421.51, ticker.Volume24h)
+	assert.Equal(t, "binance", ticker.Source)
+
+	// A trade moves the price and keeps the ticker's 24h figures
+	trade := <-out
+	assert.Equal(t, decimal.MustParse("67440.1"), trade.Price)
+	assert.Equal(t, decimal.MustParse("6.163"), trade.ChangePercent24h)
+	assert.Equal(t, time.UnixMilli(1710969246000).UTC(), trade.Timestamp)
+	assert.Equal(t, "btcusdt@aggTrade/btcusdt@ticker", streams)
+
+	latest, err := client.GetCryptoMarketData(ctx, "BTC-USD")
+	require.NoError(t, err)
+	assert.Equal(t, decimal.MustParse("67440.1"), latest.Price)
+	healthy, err := client.GetAPIHealth(ctx)
+	require.NoError(t, err)
+	assert.True(t, healthy)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	_, err = client.GetCryptoHistory(ctx, "BTC-USD", time.Now().Add(-time.Hour), time.Now())
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CollectEconomicData fetches each FRED series' observations within the
+// revision window and stores every vintage not seen before. New vintages
+// collected after a series' first pass are published: a revision when they
+// replace an earlier value, a release otherwise. The first pass only fills
+// history, so adding a series does not replay years of revisions as events.
+func (dc *DataCollector) CollectEconomicData(ctx context.Context, series []string) error {
+	client := dc.economicClient()
+	if client == nil {
+		return errors.New("economic data collection needs FRED_API_KEY")
+	}
+	if dc.db == nil {
+		return errors.New("economic data collection needs a database")
+	}
+	if err := dc.db.EnsureEconomicIndicators(ctx); err != nil {
+		return err
+	}
+
+	var failed []string
+	var firstErr error
+	for _, seriesID := range series {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := dc.collectEconomicSeries(ctx, client, seriesID)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrSymbolNotFound):
+			log.Printf("Skipping economic series %s: %v", seriesID, err)
+		default:
+			failed = append(failed, seriesID)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return &CollectionError{Source: "economic", Symbols: failed, Err: firstErr}
+	}
+	return nil
+}
+
+func (dc *DataCollector) collectEconomicSeries(ctx context.Context, client *FREDClient, seriesID string) error {
+	meta, err := client.GetSeries(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+	since, err := dc.db.LatestEconomicVintage(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+
+	window := time.Now().Add(-dc.currentConfig().EconomicRevisionWindow)
+	query := FREDObservationQuery{ObservationStart: window, RealtimeStart: since}
+	if since.IsZero() {
+		query.RealtimeStart = window
+	}
+	observations, err := client.GetObservations(ctx, seriesID, query)
+	if err != nil {
+		return err
+	}
+
+	var releases, revisions int
+	for _, indicator := range observations {
+		indicator.Title = meta.Title
+		indicator.Units = meta.Units
+		indicator.Frequency = meta.Frequency
+		indicator.LastUpdated = meta.LastUpdated
+
+		processed, err := dc.ProcessEconomicData(ctx, indicator)
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", seriesID, err)
+		}
+		if processed == nil || since.IsZero() {
+			continue
+		}
+
+		if processed.PreviousValue != nil {
+			revisions++
+			log.Printf("%s for %s revised from %g to %g (vintage %s)", seriesID, processed.Date.Format("2006-01-02"),
+				*processed.PreviousValue, processed.Value, processed.RealtimeStart.Format("2006-01-02"))
+		} else {
+			releases++
+		}
+		if dc.producer != nil {
+			if err := dc.producer.PublishEconomicEvent(ctx, processed); err != nil {
+				log.Printf("Failed to publish %s economic event: %v", seriesID, err)
+			}
+		}
+	}
+
+	if releases+revisions > 0 {
+		log.Printf("Collected %s: %d new observations, %d revisions", seriesID, releases, revisions)
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+const (
+	edgarDateLayout = "2006-01-02"
+
+	// edgarTickerRefresh is how long the ticker to CIK map is trusted;
+	// the SEC regenerates it daily
+	edgarTickerRefresh = 24 * time.Hour
+)
+
+// EDGARCompany is a ticker's entry in the SEC's ticker to CIK map
+type EDGARCompany struct {
+	CIK    int    `json:"cik_str"`
+	Ticker string `json:"ticker"`
+	Name   string `json:"title"`
+}
+
+// EDGARClient reads SEC EDGAR: the ticker map and submissions JSON for
+// current filings, and the quarterly full-index for older ones. The SEC
+// blocks clients that do not identify themselves, so every request carries
+// the configured User-Agent ("Company admin@company.com").
+type EDGARClient struct {
+	httpClient  *http.Client
+	baseURL     string // www.sec.gov: archives and the ticker map
+	dataURL     string // data.sec.gov: submissions
+	userAgent   string
+	rateLimiter *RateLimiter
+
+	tickersMu sync.Mutex
+	tickers   map[string]EDGARCompany
+	tickersAt time.Time
+}
+
+func NewEDGARClient(userAgent string) *EDGARClient {
+	return &EDGARClient{
+		httpClient: newHTTPClient(60 * time.Second), // quarterly indexes run to tens of megabytes
+		baseURL:    "https://www.sec.gov",
+		dataURL:    "https://data.sec.gov",
+		userAgent:  userAgent,
+	}
+}
+
+// Name identifies the source in Filing.Source and in configuration
+func (e *EDGARClient) Name() string {
+	return "edgar"
+}
+
+// LookupCIK returns the company a ticker belongs to. Class suffixes are
+// written with a dash on EDGAR, so BRK.B and BRK/B find BRK-B.
+func (e *EDGARClient) LookupCIK(ctx context.Context, ticker string) (EDGARCompany, error) {
+	e.tickersMu.Lock()
+	defer e.tickersMu.Unlock()
+
+	if e.tickers == nil || time.Since(e.tickersAt) > edgarTickerRefresh {
+		body, err := e.makeRequest(ctx, e.baseURL+"/files/company_tickers.json")
+		if err != nil && e.tickers == nil {
+			return EDGARCompany{}, err
+		}
+		if err == nil {
+			tickers, err := parseEDGARTickers(body)
+			if err != nil {
+				return EDGARCompany{}, err
+			}
+			e.tickers, e.tickersAt = tickers, time.Now()
+		}
+		// A failed refresh keeps the map we have
+	}
+
+	key := strings.NewReplacer(".", "-", "/", "-").Replace(strings.ToUpper(strings.TrimSpace(ticker)))
+	company, ok := e.tickers[key]
+	if !ok {
+		return EDGARCompany{}, &ProviderError{Provider: e.Name(), Message: "no CIK for " + ticker, Err: ErrSymbolNotFound}
+	}
+	return company, nil
+}
+
+// GetRecentFilings returns a company's filings from its submissions JSON,
+// newest first. EDGAR lists at least the last year or the last 1,000
+// filings there, whichever is more.
+func (e *EDGARClient) GetRecentFilings(ctx context.Context, cik int) ([]*models.Filing, error) {
+	body, err := e.makeRequest(ctx, fmt.Sprintf("%s/submissions/CIK%010d.json", e.dataURL, cik))
+	if err != nil {
+		return nil, err
+	}
+	return e.parseSubmissions(body)
+}
+
+// GetFormIndex returns every filing in a quarter's full-index, in index
+// order. Index entries carry no primary document, so DocumentURL is the
+// complete submission text file.
+func (e *EDGARClient) GetFormIndex(ctx context.Context, year, quarter int) ([]*models.Filing, error) {
+	if quarter < 1 || quarter > 4 {
+		return nil, fmt.Errorf("invalid quarter %d", quarter)
+	}
+	body, err := e.makeRequest(ctx, fmt.Sprintf("%s/Archives/edgar/full-index/%d/QTR%d/master.idx", e.baseURL, year, quarter))
+	if err != nil {
+		return nil, err
+	}
+	return e.parseMasterIndex(body)
+}
+
+// Response Parsing
+func parseEDGARTickers(response []byte) (map[string]EDGARCompany, error) {
+	// {"0": {"cik_str": 320193, "ticker": "AAPL", "title": "Apple Inc."}, ...}
+	var entries map[string]EDGARCompany
+	if err := json.Unmarshal(response, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode EDGAR ticker map: %w", err)
+	}
+
+	tickers := make(map[string]EDGARCompany, len(entries))
+	for _, company := range entries {
+		company.Ticker = strings.ToUpper(company.Ticker)
+		tickers[company.Ticker] = company
+	}
+	return tickers, nil
+}
+
+// parseSubmissions reads the "recent" block, which lists each filing
+// attribute as its own array, index-aligned
+func (e *EDGARClient) parseSubmissions(response []byte) ([]*models.Filing, error) {
+	var parsed struct {
+		CIK     string `json:"cik"`
+		Name    string `json:"name"`
+		Filings struct {
+			Recent struct {
+				AccessionNumber       []string `json:"accessionNumber"`
+				FilingDate            []string `json:"filingDate"`
+				ReportDate            []string `json:"reportDate"`
+				AcceptanceDateTime    []string `json:"acceptanceDateTime"`
+				Form                  []string `json:"form"`
+				Items                 []string `json:"items"`
+				PrimaryDocument       []string `json:"primaryDocument"`
+				PrimaryDocDescription []string `json:"primaryDocDescription"`
+			} `json:"recent"`
+		} `json:"filings"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode EDGAR submissions: %w", err)
+	}
+	cik, err := strconv.Atoi(parsed.CIK)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIK %q in EDGAR submissions", parsed.CIK)
+	}
+
+	recent := parsed.Filings.Recent
+	at := func(values []string, i int) string {
+		if i < len(values) {
+			return strings.TrimSpace(values[i])
+		}
+		return ""
+	}
+
+	filings := make([]*models.Filing, 0, len(recent.AccessionNumber))
+	for i, accession := range recent.AccessionNumber {
+		filed, err := time.Parse(edgarDateLayout, at(recent.FilingDate, i))
+		if err != nil {
+			return nil, fmt.Errorf("invalid filing date for %s: %w", accession, err)
+		}
+		filing := &models.Filing{
+			CIK:             cik,
+			Company:         parsed.Name,
+			FormType:        at(recent.Form, i),
+			AccessionNumber: accession,
+			FiledAt:         filed,
+			Items:           at(recent.Items, i),
+			Description:     at(recent.PrimaryDocDescription, i),
+			IndexURL:        e.indexURL(cik, accession),
+			Source:          e.Name(),
+		}
+		filing.ReportDate, _ = time.Parse(edgarDateLayout, at(recent.ReportDate, i))
+		filing.AcceptedAt, _ = time.Parse(time.RFC3339, at(recent.AcceptanceDateTime, i))
+		if doc := at(recent.PrimaryDocument, i); doc != "" {
+			filing.DocumentURL = e.archiveURL(cik, accession) + "/" + doc
+		} else {
+			filing.DocumentURL = e.archiveURL(cik, accession) + "/" + accession + ".txt"
+		}
+		filings = append(filings, filing)
+	}
+
+	sort.SliceStable(filings, func(i, j int) bool { return filings[i].FiledAt.After(filings[j].FiledAt) })
+	return filings, nil
+}
+
+// parseMasterIndex reads a master.idx: a free-text header, a line of dashes,
+// then "CIK|Company Name|Form Type|Date Filed|Filename" rows
+func (e *EDGARClient) parseMasterIndex(response []byte) ([]*models.Filing, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(response))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var filings []*models.Filing
+	inBody := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !inBody {
+			inBody = strings.HasPrefix(line, "-----")
+			continue
+		}
+
+		fields := strings.Split(line, "|")
+		if len(fields) != 5 {
+			continue
+		}
+		cik, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		filed, err := time.Parse(edgarDateLayout, fields[3])
+		if err != nil {
+			continue
+		}
+		// edgar/data/320193/0000320193-24-000003.txt
+		file := fields[4]
+		accession := strings.TrimSuffix(file[strings.LastIndex(file, "/")+1:], ".txt")
+
+		filings = append(filings, &models.Filing{
+			CIK:             cik,
+			Company:         fields[1],
+			FormType:        fields[2],
+			AccessionNumber: accession,
+			FiledAt:         filed,
+			DocumentURL:     e.baseURL + "/Archives/" + file,
+			IndexURL:        e.indexURL(cik, accession),
+			Source:          e.Name(),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read EDGAR index: %w", err)
+	}
+	if !inBody {
+		return nil, fmt.Errorf("EDGAR index has no entries section")
+	}
+	return filings, nil
+}
+
+// archiveURL is the folder holding a filing's documents
+func (e *EDGARClient) archiveURL(cik int, accession string) string {
+	return fmt.Sprintf("%s/Archives/edgar/data/%d/%s", e.baseURL, cik, strings.ReplaceAll(accession, "-", ""))
+}
+
+// indexURL is a filing's human-readable index page
+func (e *EDGARClient) indexURL(cik int, accession string) string {
+	return e.archiveURL(cik, accession) + "/" + accession + "-index.htm"
+}
+
+// HTTP Request Handling
+func (e *EDGARClient) makeRequest(ctx context.Context, requestURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EDGAR request: %w", err)
+	}
+	// net/http asks for gzip and decompresses on its own
+	req.Header.Set("User-Agent", e.userAgent)
+
+	if e.rateLimiter != nil {
+		if err := e.rateLimiter.Wait(ctx, edgarEndpoint(requestURL)); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: e.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: e.Name(), Message: "failed to read response", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, e.handleEDGARError(resp.StatusCode)
+	}
+	return body, nil
+}
+
+// edgarEndpoint groups request paths for per-endpoint rate limits
+func edgarEndpoint(requestURL string) string {
+	switch {
+	case strings.Contains(requestURL, "/submissions/"):
+		return "/submissions"
+	case strings.Contains(requestURL, "/full-index/"):
+		return "/full-index"
+	}
+	return "/files"
+}
+
+// Error Handling
+
+// handleEDGARError classifies a failed response. The SEC answers both
+// excessive request rates and missing User-Agents with 403.
+func (e *EDGARClient) handleEDGARError(statusCode int) error {
+	providerErr := &ProviderError{Provider: e.Name(), StatusCode: statusCode, Message: http.StatusText(statusCode)}
+
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusForbidden:
+		providerErr.Message = "request refused; check the rate limit and SEC_USER_AGENT"
+		providerErr.Err = ErrRateLimited
+	case http.StatusNotFound:
+		providerErr.Err = ErrSymbolNotFound
+	}
+	return providerErr
+}
@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEDGARClient(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		var name string
+		switch r.URL.Path {
+		case "/files/company_tickers.json":
+			name = "company_tickers.json"
+		case "/submissions/CIK0000320193.json":
+			name = "submissions_CIK0000320193.json"
+		case "/Archives/edgar/full-index/2023/QTR4/master.idx":
+			name = "master_2023_QTR4.idx"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "edgar", name))
+		require.NoError(t, err)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewEDGARClient("TradeCaptain ops@example.com")
+	client.baseURL = server.URL
+	client.dataURL = server.URL
+	ctx := context.Background()
+
+	company, err := client.LookupCIK(ctx, "brk.b")
+	require.NoError(t, err)
+	assert.Equal(t, 1067983, company.CIK)
+	_, err = client.LookupCIK(ctx, "ZZZZ")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+
+	filings, err := client.GetRecentFilings(ctx, 320193)
+	require.NoError(t, err)
+	require.Len(t, filings, 4)
+
+	form4 := filings[0]
+	assert.Equal(t, "4", form4.FormType)
+	assert.Equal(t, "Apple Inc.", form4.Company)
+	assert.Equal(t, time.Date(2024, 3, 15, 18, 31, 5, 0, time.UTC), form4.AcceptedAt)
+	assert.Equal(t, server.URL+"/Archives/edgar/data/320193/000114036124016924/xslF345X05/form4.xml", form4.DocumentURL)
+
+	eightK := filings[2]
+	assert.Equal(t, "8-K", eightK.FormType)
+	assert.Equal(t, "0000320193-24-000003", eightK.AccessionNumber)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), eightK.FiledAt)
+	assert.Equal(t, "2.02,9.01", eightK.Items)
+	assert.Equal(t, server.URL+"/Archives/edgar/data/320193/000032019324000003/0000320193-24-000003-index.htm", eightK.IndexURL)
+	assert.Equal(t, "Apple Inc. filed 8-K (items 2.02, 9.01): "+eightK.DocumentURL, describeFiling(eightK))
+
+	tenQ := filings[1]
+	assert.Equal(t, time.Date(2023, 12, 30, 0, 0, 0, 0, time.UTC), tenQ.ReportDate)
+	assert.Equal(t, "Apple Inc. filed 10-Q for the period ended 2023-12-30: "+tenQ.DocumentURL, describeFiling(tenQ))
+
+	index, err := client.GetFormIndex(ctx, 2023, 4)
+	require.NoError(t, err)
+	require.Len(t, index, 5)
+	assert.Equal(t, 320193, index[2].CIK)
+	assert.Equal(t, "8-K", index[2].FormType)
+	assert.Equal(t, "0000320193-23-000104", index[2].AccessionNumber)
+	assert.Equal(t, server.URL+"/Archives/edgar/data/320193/0000320193-23-000104.txt", index[2].DocumentURL)
+
+	_, err = client.GetFormIndex(ctx, 2031, 1)
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+
+	for _, userAgent := range userAgents {
+		assert.Equal(t, "TradeCaptain ops@example.com", userAgent)
+	}
+}
+
+func TestFilingEventForms(t *testing.T) {
+	forms := []string{"8-K", "10-Q", "10-K", "4"}
+	assert.True(t, isEventForm("8-K/A", forms))
+	assert.True(t, isEventForm("4", forms))
+	assert.False(t, isEventForm("S-8", forms))
+	assert.False(t, isEventForm("424B2", forms))
+
+	assert.Equal(t, "high", filingImpact("8-K/A"))
+	assert.Equal(t, "medium", filingImpact("10-K"))
+	assert.Equal(t, "low", filingImpact("4"))
+
+	insider := &models.Filing{Company: "Apple Inc.", FormType: "4", DocumentURL: "https://www.sec.gov/x.xml"}
+	assert.Equal(t, "Apple Inc. filed Form 4 (insider transaction): https://www.sec.gov/x.xml", describeFiling(insider))
+}
@@ -0,0 +1,320 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+// feedDateLayouts covers RSS's RFC 822 dates as publishers actually write
+// them, and Atom's RFC 3339
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+}
+
+// FeedClient reads a fixed list of RSS 2.0, RSS 1.0 and Atom feeds. Every
+// call fetches all of them; search and categories filter what they return.
+// A feed that fails is logged and skipped unless all of them fail.
+type FeedClient struct {
+	httpClient *http.Client
+	feeds      []string
+}
+
+func NewFeedClient(feeds []string) *FeedClient {
+	return &FeedClient{
+		httpClient: newHTTPClient(15 * time.Second),
+		feeds:      append([]string(nil), feeds...),
+	}
+}
+
+// Name identifies the client in configuration
+func (f *FeedClient) Name() string {
+	return "rss"
+}
+
+func (f *FeedClient) GetLatestNews(ctx context.Context, limit int) ([]*models.NewsArticle, error) {
+	return f.collect(ctx, limit, func(*models.NewsArticle) bool { return true })
+}
+
+// SearchNews returns articles whose title or description contains every word of query
+func (f *FeedClient) SearchNews(ctx context.Context, query string, limit int) ([]*models.NewsArticle, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	return f.collect(ctx, limit, func(article *models.NewsArticle) bool {
+		text := strings.ToLower(article.Title + " " + article.Description)
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// GetNewsByCategory returns articles the publisher tagged with category
+func (f *FeedClient) GetNewsByCategory(ctx context.Context, category string, limit int) ([]*models.NewsArticle, error) {
+	return f.collect(ctx, limit, func(article *models.NewsArticle) bool {
+		return strings.EqualFold(article.Category, category)
+	})
+}
+
+func (f *FeedClient) collect(ctx context.Context, limit int, keep func(*models.NewsArticle) bool) ([]*models.NewsArticle, error) {
+	var articles []*models.NewsArticle
+	var lastErr error
+	failed := 0
+	for _, feed := range f.feeds {
+		items, err := f.fetchFeed(ctx, feed)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("Failed to read news feed %s: %v", feed, err)
+			lastErr = err
+			failed++
+			continue
+		}
+		for _, article := range items {
+			if keep(article) {
+				articles = append(articles, article)
+			}
+		}
+	}
+	if failed > 0 && failed == len(f.feeds) {
+		return nil, lastErr
+	}
+
+	sortArticles(articles)
+	if limit > 0 && len(articles) > limit {
+		articles = articles[:limit]
+	}
+	return articles, nil
+}
+
+func (f *FeedClient) fetchFeed(ctx context.Context, feedURL string) ([]*models.NewsArticle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feed request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+	req.Header.Set("User-Agent", "tradecaptain-data-collector/1.0")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: f.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: f.Name(), Message: "failed to read response", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		providerErr := &ProviderError{Provider: f.Name(), StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			providerErr.Err = ErrRateLimited
+		}
+		return nil, providerErr
+	}
+	return parseFeed(body, time.Now())
+}
+
+// Feed Parsing
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"date"` // dc:date in RSS 1.0
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"creator"` // dc:creator
+	Categories  []string `xml:"category"`
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"` // RSS 1.0 puts items next to the channel
+}
+
+type atomDocument struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+		Authors   []struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+// parseFeed reads an RSS or Atom document into articles. Entries without a
+// date are stamped with fetched.
+func parseFeed(body []byte, fetched time.Time) ([]*models.NewsArticle, error) {
+	root, err := feedRoot(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var articles []*models.NewsArticle
+	add := func(article *models.NewsArticle) {
+		if article = normalizeArticle(article); article != nil {
+			articles = append(articles, article)
+		}
+	}
+
+	switch root {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := decodeFeed(body, &doc); err != nil {
+			return nil, err
+		}
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			link := item.Link
+			if link == "" && strings.HasPrefix(item.GUID, "http") {
+				link = item.GUID
+			}
+			author := item.Creator
+			if author == "" {
+				author = item.Author
+			}
+			published := parseFeedDate(item.PubDate, fetched)
+			if item.PubDate == "" {
+				published = parseFeedDate(item.Date, fetched)
+			}
+			article := &models.NewsArticle{
+				Title:       item.Title,
+				Description: item.Description,
+				URL:         link,
+				Source:      doc.Channel.Title,
+				Author:      author,
+				PublishedAt: published,
+			}
+			if len(item.Categories) > 0 {
+				article.Category = item.Categories[0]
+			}
+			add(article)
+		}
+
+	case "feed":
+		var doc atomDocument
+		if err := decodeFeed(body, &doc); err != nil {
+			return nil, err
+		}
+		for _, entry := range doc.Entries {
+			article := &models.NewsArticle{
+				Title:       entry.Title,
+				Description: entry.Summary,
+				Source:      doc.Title,
+			}
+			if article.Description == "" {
+				article.Description = entry.Content
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					article.URL = link.Href
+					break
+				}
+			}
+			if len(entry.Authors) > 0 {
+				article.Author = entry.Authors[0].Name
+			}
+			if len(entry.Categories) > 0 {
+				article.Category = entry.Categories[0].Term
+			}
+			published := entry.Published
+			if published == "" {
+				published = entry.Updated
+			}
+			article.PublishedAt = parseFeedDate(published, fetched)
+			add(article)
+		}
+
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed: root element <%s>", root)
+	}
+
+	sortArticles(articles)
+	return articles, nil
+}
+
+// feedRoot returns the local name of the document's root element
+func feedRoot(body []byte) (string, error) {
+	decoder := newFeedDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to read feed: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func decodeFeed(body []byte, doc interface{}) error {
+	if err := newFeedDecoder(body).Decode(doc); err != nil {
+		return fmt.Errorf("failed to decode feed: %w", err)
+	}
+	return nil
+}
+
+func newFeedDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false // publishers ship bare ampersands and HTML entities
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "utf-8", "us-ascii":
+			return input, nil
+		case "iso-8859-1", "latin1":
+			raw, err := io.ReadAll(input)
+			if err != nil {
+				return nil, err
+			}
+			runes := make([]rune, len(raw))
+			for i, b := range raw {
+				runes[i] = rune(b)
+			}
+			return strings.NewReader(string(runes)), nil
+		}
+		return nil, errors.New("unsupported feed charset " + charset)
+	}
+	return decoder
+}
+
+func parseFeedDate(value string, fallback time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,280 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+// filingOverlap is how far before a symbol's newest stored filing each pass
+// looks again. Filings accepted after hours are dated the next business day,
+// so filing dates do not arrive strictly in order.
+const filingOverlap = 7 * 24 * time.Hour
+
+// filingHistory is a symbol collected for the first time, whose filings
+// before Before come from the full-index
+type filingHistory struct {
+	Symbol string
+	CIK    int
+	Before time.Time
+}
+
+// StartFilingsCollection collects the stock symbols' SEC filings every
+// FilingsInterval while an SEC user agent is configured
+func (dc *DataCollector) StartFilingsCollection(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	warned := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		cfg := dc.currentConfig()
+		timer.Reset(cfg.FilingsInterval)
+		if dc.isPaused(ServiceFilings) {
+			continue
+		}
+		// The user agent can be added by a config reload
+		if dc.filingsClient() == nil {
+			if !warned {
+				log.Println("Filings collection idle: SEC_USER_AGENT not set")
+				warned = true
+			}
+			continue
+		}
+
+		if err := dc.CollectFilings(ctx, cfg.StockSymbols); err != nil && ctx.Err() == nil {
+			dc.HandleCollectionError(ctx, err, "filings", cfg.StockSymbols)
+		}
+	}
+}
+
+// CollectFilings reads each symbol's company submissions from EDGAR and
+// stores the filings not seen before. New filings are added to the symbol's
+// filing feed, and those of FilingEventForms are also published as market
+// events. A symbol's first pass only fills history, from the submissions and
+// then from the full-index back to FilingsLookback, without publishing.
+func (dc *DataCollector) CollectFilings(ctx context.Context, symbols []string) error {
+	client := dc.filingsClient()
+	if client == nil {
+		return errors.New("filings collection needs SEC_USER_AGENT")
+	}
+	if dc.db == nil {
+		return errors.New("filings collection needs a database")
+	}
+	if err := dc.db.EnsureFilings(ctx); err != nil {
+		return err
+	}
+	cfg := dc.currentConfig()
+
+	var failed []string
+	var firstErr error
+	var pending []filingHistory
+	for _, symbol := range symbols {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		history, err := dc.collectSymbolFilings(ctx, client, symbol, cfg.FilingEventForms)
+		switch {
+		case err == nil:
+			if history != nil {
+				pending = append(pending, *history)
+			}
+		case errors.Is(err, ErrSymbolNotFound):
+			log.Printf("Skipping filings for %s: %v", symbol, err)
+		default:
+			failed = append(failed, symbol)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if len(pending) > 0 && cfg.FilingsLookback > 0 {
+		// History is best effort: the symbols are no longer new on the next pass
+		from := time.Now().UTC().Add(-cfg.FilingsLookback)
+		if err := dc.loadFilingHistory(ctx, client, pending, from); err != nil {
+			log.Printf("Failed to load filing history from the EDGAR full-index: %v", err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return &CollectionError{Source: "filings", Symbols: failed, Err: firstErr}
+	}
+	return nil
+}
+
+// collectSymbolFilings stores a symbol's recent filings. For a symbol with
+// no stored filings it returns what the full-index still has to fill in.
+func (dc *DataCollector) collectSymbolFilings(ctx context.Context, client *EDGARClient, symbol string, eventForms []string) (*filingHistory, error) {
+	company, err := client.LookupCIK(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := dc.db.LatestFilingDate(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	filings, err := client.GetRecentFilings(ctx, company.CIK)
+	if err != nil {
+		return nil, err
+	}
+
+	firstLoad := latest.IsZero()
+	now := time.Now().UTC()
+	stored := 0
+	// Oldest first, so the feed reads in filing order
+	for i := len(filings) - 1; i >= 0; i-- {
+		filing := filings[i]
+		if !firstLoad && filing.FiledAt.Before(latest.Add(-filingOverlap)) {
+			continue
+		}
+		filing.Symbol = symbol
+		filing.CreatedAt = now
+
+		saved, err := dc.db.SaveFiling(ctx, filing)
+		if err != nil {
+			return nil, err
+		}
+		if !saved {
+			continue
+		}
+		stored++
+		if !firstLoad {
+			dc.publishFiling(ctx, filing, eventForms)
+		}
+	}
+
+	if !firstLoad {
+		if stored > 0 {
+			log.Printf("Stored %d new %s filings", stored, symbol)
+		}
+		return nil, nil
+	}
+	log.Printf("Loaded %d recent %s filings", stored, symbol)
+	history := &filingHistory{Symbol: symbol, CIK: company.CIK, Before: now}
+	if len(filings) > 0 {
+		history.Before = filings[len(filings)-1].FiledAt
+	}
+	return history, nil
+}
+
+// loadFilingHistory stores the filings of new symbols filed between from and
+// the oldest filing their submissions listed, reading each quarter's
+// full-index once for all of them
+func (dc *DataCollector) loadFilingHistory(ctx context.Context, client *EDGARClient, pending []filingHistory, from time.Time) error {
+	byCIK := make(map[int][]filingHistory)
+	until := from
+	for _, history := range pending {
+		byCIK[history.CIK] = append(byCIK[history.CIK], history)
+		if history.Before.After(until) {
+			until = history.Before
+		}
+	}
+	if !until.After(from) {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	year, quarter := from.Year(), int(from.Month()-1)/3+1
+	lastYear, lastQuarter := until.Year(), int(until.Month()-1)/3+1
+	stored := 0
+	for year < lastYear || (year == lastYear && quarter <= lastQuarter) {
+		entries, err := client.GetFormIndex(ctx, year, quarter)
+		if err != nil && !errors.Is(err, ErrSymbolNotFound) {
+			return fmt.Errorf("%d Q%d index: %w", year, quarter, err)
+		}
+
+		for _, entry := range entries {
+			for _, history := range byCIK[entry.CIK] {
+				// The cutoff day is included: the submissions page may have cut
+				// it short, and the filings it did list are already stored
+				if entry.FiledAt.Before(from) || entry.FiledAt.After(history.Before) {
+					continue
+				}
+				filing := *entry
+				filing.Symbol = history.Symbol
+				filing.CreatedAt = now
+				saved, err := dc.db.SaveFiling(ctx, &filing)
+				if err != nil {
+					return err
+				}
+				if saved {
+					stored++
+				}
+			}
+		}
+
+		if quarter++; quarter > 4 {
+			year, quarter = year+1, 1
+		}
+	}
+
+	log.Printf("Loaded %d older filings from the EDGAR full-index", stored)
+	return nil
+}
+
+// publishFiling adds a new filing to its symbol's feed and, for the forms
+// in eventForms, publishes it as a market event
+func (dc *DataCollector) publishFiling(ctx context.Context, filing *models.Filing, eventForms []string) {
+	if dc.producer == nil {
+		return
+	}
+	if err := dc.producer.PublishFiling(ctx, filing); err != nil {
+		log.Printf("Failed to publish %s filing %s: %v", filing.Symbol, filing.AccessionNumber, err)
+	}
+	if !isEventForm(filing.FormType, eventForms) {
+		return
+	}
+	if err := dc.producer.PublishMarketEvent(ctx, "sec_filing", filing.Symbol, describeFiling(filing), filingImpact(filing.FormType)); err != nil {
+		log.Printf("Failed to publish %s filing event: %v", filing.Symbol, err)
+	}
+}
+
+// isEventForm reports whether form, or the form it amends, is in forms
+func isEventForm(form string, forms []string) bool {
+	base := strings.TrimSuffix(form, "/A")
+	for _, f := range forms {
+		if strings.EqualFold(f, form) || strings.EqualFold(f, base) {
+			return true
+		}
+	}
+	return false
+}
+
+// filingImpact rates how much a form tends to move the stock: 8-Ks report
+// material events, periodic reports are scheduled, insider trades are small
+func filingImpact(form string) string {
+	switch strings.TrimSuffix(form, "/A") {
+	case "8-K":
+		return "high"
+	case "4":
+		return "low"
+	default:
+		return "medium"
+	}
+}
+
+// describeFiling is the one-line summary shown in the event feed
+func describeFiling(filing *models.Filing) string {
+	form, detail := filing.FormType, ""
+	switch {
+	case strings.TrimSuffix(form, "/A") == "4":
+		form, detail = "Form "+form, " (insider transaction)"
+	case filing.Items != "":
+		detail = " (items " + strings.ReplaceAll(filing.Items, ",", ", ") + ")"
+	case !filing.ReportDate.IsZero():
+		detail = " for the period ended " + filing.ReportDate.Format(edgarDateLayout)
+	}
+	return fmt.Sprintf("%s filed %s%s: %s", filing.Company, form, detail, filing.DocumentURL)
+}
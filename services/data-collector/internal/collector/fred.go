@@ -0,0 +1,319 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+const (
+	fredDateLayout = "2006-01-02"
+
+	// fredRealtimeOpen is the realtime_end FRED gives a vintage that is still current
+	fredRealtimeOpen = "9999-12-31"
+)
+
+// FREDClient reads series from the St. Louis Fed's FRED API. It is not a quote
+// provider: economic series have a single source, so there is no pool or
+// circuit breaker in front of it, only the "fred" rate limit.
+type FREDClient struct {
+	httpClient  *http.Client
+	baseURL     string
+	keyMu       sync.RWMutex
+	apiKey      string
+	rateLimiter *RateLimiter
+}
+
+// FREDSeries is a series' metadata
+type FREDSeries struct {
+	ID                 string    `json:"id"`
+	Title              string    `json:"title"`
+	Frequency          string    `json:"frequency"`
+	Units              string    `json:"units"`
+	SeasonalAdjustment string    `json:"seasonal_adjustment"`
+	ObservationStart   time.Time `json:"observation_start"`
+	ObservationEnd     time.Time `json:"observation_end"`
+	LastUpdated        time.Time `json:"last_updated"`
+}
+
+// FREDRelease is the publication a series belongs to, e.g. "Employment Situation"
+type FREDRelease struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Link string `json:"link"`
+}
+
+// FREDObservationQuery selects observations by date and by vintage. Zero
+// times are left to FRED's defaults: all observation dates, and only the
+// values current today stamped with today's date. Setting RealtimeStart
+// returns every vintage valid since then instead, each stamped with the day
+// it was published, or with RealtimeStart if that was earlier.
+type FREDObservationQuery struct {
+	ObservationStart time.Time
+	ObservationEnd   time.Time
+	RealtimeStart    time.Time
+	RealtimeEnd      time.Time
+}
+
+func NewFREDClient(apiKey string) *FREDClient {
+	return &FREDClient{
+		httpClient: newHTTPClient(30 * time.Second),
+		baseURL:    "https://api.stlouisfed.org/fred",
+		apiKey:     apiKey,
+	}
+}
+
+// SetAPIKey rotates the key; requests already built keep the old one
+func (f *FREDClient) SetAPIKey(apiKey string) {
+	f.keyMu.Lock()
+	defer f.keyMu.Unlock()
+	f.apiKey = apiKey
+}
+
+// Name identifies the source in EconomicIndicator.Source and in configuration
+func (f *FREDClient) Name() string {
+	return "fred"
+}
+
+// Series
+func (f *FREDClient) GetSeries(ctx context.Context, seriesID string) (*FREDSeries, error) {
+	body, err := f.makeRequest(ctx, "/series", map[string]string{"series_id": seriesID})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Series []struct {
+			ID                 string `json:"id"`
+			Title              string `json:"title"`
+			Frequency          string `json:"frequency"`
+			Units              string `json:"units"`
+			SeasonalAdjustment string `json:"seasonal_adjustment"`
+			ObservationStart   string `json:"observation_start"`
+			ObservationEnd     string `json:"observation_end"`
+			LastUpdated        string `json:"last_updated"`
+		} `json:"seriess"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode FRED series: %w", err)
+	}
+	if len(response.Series) == 0 {
+		return nil, &ProviderError{Provider: f.Name(), Message: "series " + seriesID + " not found", Err: ErrSymbolNotFound}
+	}
+
+	s := response.Series[0]
+	series := &FREDSeries{
+		ID:                 s.ID,
+		Title:              s.Title,
+		Frequency:          s.Frequency,
+		Units:              s.Units,
+		SeasonalAdjustment: s.SeasonalAdjustment,
+	}
+	series.ObservationStart, _ = time.Parse(fredDateLayout, s.ObservationStart)
+	series.ObservationEnd, _ = time.Parse(fredDateLayout, s.ObservationEnd)
+	// e.g. "2024-02-29 07:55:02-06"
+	series.LastUpdated, _ = time.Parse("2006-01-02 15:04:05-07", s.LastUpdated)
+	return series, nil
+}
+
+// GetObservations returns the series' values, oldest observation first and,
+// for each observation, oldest vintage first. Missing values (".") are skipped.
+// Only Series, Value, Date, RealtimeStart and Source are set.
+func (f *FREDClient) GetObservations(ctx context.Context, seriesID string, query FREDObservationQuery) ([]*models.EconomicIndicator, error) {
+	params := map[string]string{"series_id": seriesID}
+	if !query.ObservationStart.IsZero() {
+		params["observation_start"] = query.ObservationStart.Format(fredDateLayout)
+	}
+	if !query.ObservationEnd.IsZero() {
+		params["observation_end"] = query.ObservationEnd.Format(fredDateLayout)
+	}
+	if !query.RealtimeStart.IsZero() {
+		params["realtime_start"] = query.RealtimeStart.Format(fredDateLayout)
+		params["realtime_end"] = fredRealtimeOpen
+	}
+	if !query.RealtimeEnd.IsZero() {
+		params["realtime_end"] = query.RealtimeEnd.Format(fredDateLayout)
+	}
+
+	body, err := f.makeRequest(ctx, "/series/observations", params)
+	if err != nil {
+		return nil, err
+	}
+	return f.parseObservations(body, seriesID)
+}
+
+// Releases
+func (f *FREDClient) GetSeriesRelease(ctx context.Context, seriesID string) (*FREDRelease, error) {
+	body, err := f.makeRequest(ctx, "/series/release", map[string]string{"series_id": seriesID})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Releases []FREDRelease `json:"releases"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode FRED release: %w", err)
+	}
+	if len(response.Releases) == 0 {
+		return nil, &ProviderError{Provider: f.Name(), Message: "no release for series " + seriesID, Err: ErrSymbolNotFound}
+	}
+	return &response.Releases[0], nil
+}
+
+// GetReleaseDates returns a release's publication dates from from onwards,
+// including scheduled dates that have no data yet
+func (f *FREDClient) GetReleaseDates(ctx context.Context, releaseID int, from time.Time) ([]time.Time, error) {
+	body, err := f.makeRequest(ctx, "/release/dates", map[string]string{
+		"release_id":                         strconv.Itoa(releaseID),
+		"realtime_start":                     from.Format(fredDateLayout),
+		"realtime_end":                       fredRealtimeOpen,
+		"include_release_dates_with_no_data": "true",
+		"sort_order":                         "asc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		ReleaseDates []struct {
+			Date string `json:"date"`
+		} `json:"release_dates"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode FRED release dates: %w", err)
+	}
+
+	dates := make([]time.Time, 0, len(response.ReleaseDates))
+	for _, rd := range response.ReleaseDates {
+		date, err := time.Parse(fredDateLayout, rd.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FRED release date %q: %w", rd.Date, err)
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// Response Parsing
+func (f *FREDClient) parseObservations(response []byte, seriesID string) ([]*models.EconomicIndicator, error) {
+	var parsed struct {
+		Observations []struct {
+			RealtimeStart string `json:"realtime_start"`
+			Date          string `json:"date"`
+			Value         string `json:"value"`
+		} `json:"observations"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode FRED observations: %w", err)
+	}
+
+	observations := make([]*models.EconomicIndicator, 0, len(parsed.Observations))
+	for _, obs := range parsed.Observations {
+		if obs.Value == "." {
+			continue // not yet reported, or not reported for this vintage
+		}
+		value, err := strconv.ParseFloat(obs.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FRED value %q for %s on %s", obs.Value, seriesID, obs.Date)
+		}
+		date, err := time.Parse(fredDateLayout, obs.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FRED observation date %q: %w", obs.Date, err)
+		}
+		vintage, err := time.Parse(fredDateLayout, obs.RealtimeStart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FRED realtime_start %q: %w", obs.RealtimeStart, err)
+		}
+
+		observations = append(observations, &models.EconomicIndicator{
+			Series:        seriesID,
+			Value:         value,
+			Date:          date,
+			RealtimeStart: vintage,
+			Source:        f.Name(),
+		})
+	}
+
+	sort.SliceStable(observations, func(i, j int) bool {
+		if !observations[i].Date.Equal(observations[j].Date) {
+			return observations[i].Date.Before(observations[j].Date)
+		}
+		return observations[i].RealtimeStart.Before(observations[j].RealtimeStart)
+	})
+	return observations, nil
+}
+
+// HTTP Request Handling
+func (f *FREDClient) makeRequest(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	query.Set("file_type", "json")
+	f.keyMu.RLock()
+	query.Set("api_key", f.apiKey)
+	f.keyMu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.baseURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FRED request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if f.rateLimiter != nil {
+		if err := f.rateLimiter.Wait(ctx, endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: f.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: f.Name(), Message: "failed to read response", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, f.handleFREDError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// Error Handling
+
+// handleFREDError classifies a failed response. FRED answers errors with
+// {"error_code": 400, "error_message": "..."}; an unknown series is a 400
+// saying it does not exist.
+func (f *FREDClient) handleFREDError(statusCode int, body []byte) error {
+	var fredErr struct {
+		Message string `json:"error_message"`
+	}
+	message := http.StatusText(statusCode)
+	if json.Unmarshal(body, &fredErr) == nil && fredErr.Message != "" {
+		message = fredErr.Message
+	}
+	providerErr := &ProviderError{Provider: f.Name(), StatusCode: statusCode, Message: message}
+
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		providerErr.Err = ErrRateLimited
+	case statusCode == http.StatusNotFound,
+		statusCode == http.StatusBadRequest && strings.Contains(message, "does not exist"):
+		providerErr.Err = ErrSymbolNotFound
+	}
+	return providerErr
+}
@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFREDFixtureClient serves recorded FRED responses from testdata/fred
+func newFREDFixtureClient(t *testing.T) (*FREDClient, *[]url.Values) {
+	fixtures := map[string]string{
+		"/series":              "series_gdp.json",
+		"/series/observations": "observations_gdp.json",
+		"/series/release":      "series_release_gdp.json",
+		"/release/dates":       "release_dates_53.json",
+	}
+
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		queries = append(queries, query)
+		if query.Get("series_id") == "NOPE" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_code":400,"error_message":"Bad Request.  The series does not exist."}`))
+			return
+		}
+
+		name, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "fred", name))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewFREDClient("test-key")
+	client.baseURL = server.URL
+	return client, &queries
+}
+
+func TestFREDSeries(t *testing.T) {
+	client, queries := newFREDFixtureClient(t)
+
+	series, err := client.GetSeries(context.Background(), "GDP")
+	require.NoError(t, err)
+	assert.Equal(t, "Gross Domestic Product", series.Title)
+	assert.Equal(t, "Quarterly", series.Frequency)
+	assert.Equal(t, "Billions of Dollars", series.Units)
+	assert.Equal(t, time.Date(2024, 2, 28, 13, 56, 2, 0, time.UTC), series.LastUpdated.UTC())
+
+	query := (*queries)[0]
+	assert.Equal(t, "test-key", query.Get("api_key"))
+	assert.Equal(t, "json", query.Get("file_type"))
+}
+
+func TestFREDObservationVintages(t *testing.T) {
+	client, queries := newFREDFixtureClient(t)
+
+	observations, err := client.GetObservations(context.Background(), "GDP", FREDObservationQuery{
+		ObservationStart: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC),
+		RealtimeStart:    time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	// Every vintage comes back, except the advance estimate that was not yet reported
+	require.Len(t, observations, 6)
+	first, revised := observations[0], observations[2]
+	assert.Equal(t, time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC), first.Date)
+	assert.Equal(t, time.Date(2023, 7, 27, 0, 0, 0, 0, time.UTC), first.RealtimeStart)
+	assert.Equal(t, 26834.995, first.Value)
+	assert.Equal(t, first.Date, revised.Date)
+	assert.Equal(t, 27063.012, revised.Value)
+	assert.Equal(t, time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), observations[5].RealtimeStart)
+	assert.Equal(t, "fred", observations[5].Source)
+
+	query := (*queries)[0]
+	assert.Equal(t, "2023-04-01", query.Get("observation_start"))
+	assert.Equal(t, "2023-06-01", query.Get("realtime_start"))
+	assert.Equal(t, "9999-12-31", query.Get("realtime_end"))
+}
+
+func TestFREDReleaseDates(t *testing.T) {
+	client, queries := newFREDFixtureClient(t)
+	ctx := context.Background()
+
+	release, err := client.GetSeriesRelease(ctx, "GDP")
+	require.NoError(t, err)
+	assert.Equal(t, 53, release.ID)
+	assert.Equal(t, "Gross Domestic Product", release.Name)
+
+	dates, err := client.GetReleaseDates(ctx, release.ID, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, dates, 3)
+	assert.Equal(t, time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC), dates[2])
+	assert.Equal(t, "53", (*queries)[1].Get("release_id"))
+	assert.Equal(t, "true", (*queries)[1].Get("include_release_dates_with_no_data"))
+}
+
+func TestFREDUnknownSeries(t *testing.T) {
+	client, _ := newFREDFixtureClient(t)
+
+	_, err := client.GetSeries(context.Background(), "NOPE")
+	require.ErrorIs(t, err, ErrSymbolNotFound)
+	var providerErr *ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusBadRequest, providerErr.StatusCode)
+	assert.Equal(t, "Bad Request.  The series does not exist.", providerErr.Message)
+}
@@ -0,0 +1,286 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+const (
+	// gapLockKey elects the one replica that scans for and fills gaps
+	gapLockKey = "data-collector:gap-detector"
+	gapLockTTL = 2 * time.Minute
+
+	// A gap that failed this many times in a day is left for a human
+	gapMaxAttempts = 3
+
+	gapIntervalDaily    = "1d"
+	gapIntervalIntraday = "5m"
+)
+
+// StartGapDetection runs BackfillMissingData every GapScanInterval. Replicas
+// compete for a Redis lock each pass; only the holder scans, and it keeps the
+// lock alive while filling. Without Redis the pass runs unguarded.
+func (dc *DataCollector) StartGapDetection(ctx context.Context) {
+	interval := dc.currentConfig().GapScanInterval
+	if interval <= 0 {
+		log.Println("Gap detection disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dc.runGapScan(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Gap scan failed: %v", err)
+			}
+		}
+	}
+}
+
+func (dc *DataCollector) runGapScan(ctx context.Context) error {
+	if dc.cache == nil {
+		return dc.BackfillMissingData(ctx)
+	}
+
+	token, err := dc.cache.AcquireLock(ctx, gapLockKey, gapLockTTL)
+	if errors.Is(err, storage.ErrLockNotAcquired) {
+		return nil // another replica is the leader for this pass
+	}
+	if err != nil {
+		return err
+	}
+
+	scanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Stop filling as soon as leadership is lost
+	go func() {
+		ticker := time.NewTicker(gapLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-scanCtx.Done():
+				return
+			case <-ticker.C:
+				if err := dc.cache.ExtendLock(scanCtx, gapLockKey, token, gapLockTTL); err != nil {
+					if scanCtx.Err() == nil {
+						log.Printf("Lost gap detector lock: %v", err)
+					}
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	scanErr := dc.BackfillMissingData(scanCtx)
+	cancel()
+
+	releaseCtx, release := context.WithTimeout(context.Background(), 5*time.Second)
+	defer release()
+	if err := dc.cache.ReleaseLock(releaseCtx, gapLockKey, token); err != nil && !errors.Is(err, storage.ErrLockNotHeld) {
+		log.Printf("Failed to release gap detector lock: %v", err)
+	}
+	return scanErr
+}
+
+// BackfillMissingData compares each tracked equity's stored series with its
+// exchange's session grid, ranks the gaps it finds and fills the top
+// GapMaxFills of them through the provider pool. Every gap found is
+// recorded in the data_gap_audit table as detected, and the record of each
+// attempted gap is updated with its outcome.
+func (dc *DataCollector) BackfillMissingData(ctx context.Context) error {
+	if dc.db == nil {
+		return errors.New("gap detection needs a database")
+	}
+	if dc.isPaused(ServiceMarket) {
+		return nil
+	}
+	if err := dc.db.EnsureDataGapAudit(ctx); err != nil {
+		return err
+	}
+
+	cfg := dc.currentConfig()
+	cal := calendar.Default()
+	now := time.Now()
+
+	var gaps []*models.DataGap
+	for _, symbol := range cfg.StockSymbols {
+		stored, err := dc.db.GetMarketDataTimestamps(ctx, symbol, now.Add(-cfg.GapLookback), now)
+		if err != nil {
+			return err
+		}
+		gaps = append(gaps, findGaps(dc.exchangeOf(cal, symbol), symbol, stored,
+			now.Add(-cfg.GapLookback), now.Add(-cfg.GapIntradayLookback), now, intradayGapThreshold(cfg))...)
+	}
+	rankGaps(gaps, cfg.StockSymbols, now)
+
+	for _, gap := range gaps {
+		gap.Status = "detected"
+		gap.DetectedAt = now
+		if err := dc.db.SaveDataGap(ctx, gap); err != nil {
+			return err
+		}
+	}
+
+	attempted, filled := 0, 0
+	for _, gap := range gaps {
+		if attempted >= cfg.GapMaxFills || ctx.Err() != nil {
+			break
+		}
+
+		failures, err := dc.db.CountDataGapAttempts(ctx, gap.Symbol, gap.Interval, gap.Start, now.Add(-24*time.Hour))
+		if err != nil {
+			return err
+		}
+		if failures >= gapMaxAttempts {
+			continue
+		}
+
+		attempted++
+		dc.fillGap(ctx, gap)
+		if err := dc.db.UpdateDataGap(ctx, gap); err != nil {
+			log.Printf("Failed to record gap fill: %v", err)
+		}
+		if gap.Status == "filled" {
+			filled++
+		}
+	}
+
+	log.Printf("Gap scan: %d gaps found, %d attempted, %d filled", len(gaps), attempted, filled)
+	if dc.producer != nil {
+		for metric, value := range map[string]float64{
+			"data_gaps_found":  float64(len(gaps)),
+			"data_gaps_filled": float64(filled),
+		} {
+			if err := dc.producer.PublishSystemMetric(ctx, "data-collector", metric, value, nil); err != nil {
+				log.Printf("Failed to publish %s: %v", metric, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fillGap fetches the gap's range at its interval and stores what the
+// providers return, recording the outcome on the gap
+func (dc *DataCollector) fillGap(ctx context.Context, gap *models.DataGap) {
+	bars, err := dc.providerPool().GetHistoricalRange(ctx, gap.Symbol, gap.Start, gap.End, gap.Interval)
+	if errors.Is(err, ErrNoHistory) {
+		// Unscheduled closures and halts look like gaps but have no data
+		gap.Status, gap.Error = "unfillable", err.Error()
+		return
+	}
+	if err != nil {
+		gap.Status, gap.Error = "failed", err.Error()
+		return
+	}
+
+	if err := dc.db.UpdateMarketDataBatch(ctx, bars); err != nil {
+		gap.Status, gap.Error = "failed", err.Error()
+		return
+	}
+	gap.Status, gap.Filled, gap.FilledAt = "filled", len(bars), time.Now()
+}
+
+// intradayGapThreshold is how long a session may go without data before it
+// counts as a gap: a few missed polls, but never less than one fill bar
+func intradayGapThreshold(cfg *config.Config) time.Duration {
+	threshold := 3 * cfg.MarketDataInterval
+	if threshold < 15*time.Minute {
+		threshold = 15 * time.Minute
+	}
+	return threshold
+}
+
+// findGaps lays stored timestamps over the regular sessions of ex between from
+// and to. Runs of trading days with no data at all become one daily gap
+// spanning those local dates. Within sessions since intradayFrom that do have
+// data, any silence longer than maxSilence becomes an intraday gap.
+func findGaps(ex *calendar.Exchange, symbol string, stored []time.Time, from, intradayFrom, to time.Time, maxSilence time.Duration) []*models.DataGap {
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Before(stored[j]) })
+
+	haveDay := make(map[string]bool)
+	for _, ts := range stored {
+		haveDay[ts.In(ex.Location).Format("2006-01-02")] = true
+	}
+
+	var gaps []*models.DataGap
+	var missing *models.DataGap
+	lastDay := ""
+
+	for _, w := range ex.RegularSessions(from, to) {
+		if to.Sub(w.Start) < maxSilence {
+			continue // session only just opened, too early to judge
+		}
+		local := w.Start.In(ex.Location)
+		day := local.Format("2006-01-02")
+		midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, ex.Location)
+
+		if !haveDay[day] {
+			if day == lastDay {
+				continue // afternoon session of a day already counted
+			}
+			if missing == nil {
+				missing = &models.DataGap{Symbol: symbol, Interval: gapIntervalDaily, Start: midnight}
+				gaps = append(gaps, missing)
+			}
+			missing.End = midnight.AddDate(0, 0, 1)
+			lastDay = day
+			continue
+		}
+		missing = nil
+		lastDay = day
+
+		if w.End.Before(intradayFrom) {
+			continue
+		}
+		prev := w.Start
+		for _, ts := range stored {
+			if ts.Before(w.Start) || !ts.Before(w.End) {
+				continue
+			}
+			if ts.Sub(prev) > maxSilence {
+				gaps = append(gaps, &models.DataGap{Symbol: symbol, Interval: gapIntervalIntraday, Start: prev, End: ts})
+			}
+			prev = ts
+		}
+		if w.End.Sub(prev) > maxSilence {
+			gaps = append(gaps, &models.DataGap{Symbol: symbol, Interval: gapIntervalIntraday, Start: prev, End: w.End})
+		}
+	}
+	return gaps
+}
+
+// rankGaps orders gaps by priority, highest first. Symbols earlier in the
+// configured list matter more, and recent gaps beat old ones: users look at
+// recent history first and intraday provider history only goes back weeks.
+func rankGaps(gaps []*models.DataGap, symbols []string, now time.Time) {
+	importance := make(map[string]float64, len(symbols))
+	for i, symbol := range symbols {
+		importance[symbol] = float64(len(symbols) - i)
+	}
+
+	for _, gap := range gaps {
+		ageDays := now.Sub(gap.End).Hours() / 24
+		if ageDays < 0 {
+			ageDays = 0
+		}
+		gap.Priority = importance[gap.Symbol] / (1 + ageDays)
+	}
+	sort.SliceStable(gaps, func(i, j int) bool {
+		return gaps[i].Priority > gaps[j].Priority
+	})
+}
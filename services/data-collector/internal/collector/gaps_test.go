@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ticks(start, end time.Time, every time.Duration) []time.Time {
+	var out []time.Time
+	for t := start; t.Before(end); t = t.Add(every) {
+		out = append(out, t)
+	}
+	return out
+}
+
+func TestFindGaps(t *testing.T) {
+	nyse, ok := calendar.Default().Exchange("NYSE")
+	require.True(t, ok)
+	ny := nyse.Location
+	day := func(d, h, m int) time.Time { return time.Date(2024, 11, d, h, m, 0, 0, ny) }
+
+	// Monday has an hour of silence, Tuesday and Wednesday are missing,
+	// Thursday is Thanksgiving and Friday is a complete half day
+	var stored []time.Time
+	stored = append(stored, ticks(day(25, 9, 30), day(25, 11, 0), 5*time.Minute)...)
+	stored = append(stored, ticks(day(25, 12, 0), day(25, 16, 0), 5*time.Minute)...)
+	stored = append(stored, ticks(day(29, 9, 30), day(29, 13, 0), 5*time.Minute)...)
+
+	gaps := findGaps(nyse, "AAPL", stored, day(25, 0, 0), day(25, 0, 0), day(30, 0, 0), 15*time.Minute)
+	require.Len(t, gaps, 2)
+
+	assert.Equal(t, gapIntervalIntraday, gaps[0].Interval)
+	assert.Equal(t, day(25, 10, 55), gaps[0].Start)
+	assert.Equal(t, day(25, 12, 0), gaps[0].End)
+
+	assert.Equal(t, gapIntervalDaily, gaps[1].Interval)
+	assert.Equal(t, day(26, 0, 0), gaps[1].Start)
+	assert.Equal(t, day(28, 0, 0), gaps[1].End)
+
+	// Outside the intraday window only whole missing days count
+	gaps = findGaps(nyse, "AAPL", stored, day(25, 0, 0), day(29, 0, 0), day(30, 0, 0), 15*time.Minute)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, gapIntervalDaily, gaps[0].Interval)
+}
+
+func TestFindGapsIgnoresSessionThatJustOpened(t *testing.T) {
+	nyse, _ := calendar.Default().Exchange("NYSE")
+	now := time.Date(2024, 11, 26, 9, 35, 0, 0, nyse.Location)
+
+	assert.Empty(t, findGaps(nyse, "AAPL", nil, now.Add(-time.Hour), now.Add(-time.Hour), now, 15*time.Minute))
+}
+
+func TestRankGaps(t *testing.T) {
+	now := time.Date(2024, 12, 2, 12, 0, 0, 0, time.UTC)
+	gaps := []*models.DataGap{
+		{Symbol: "MSFT", End: now.Add(-time.Hour)},
+		{Symbol: "AAPL", End: now.AddDate(0, 0, -10)},
+		{Symbol: "AAPL", End: now.Add(-time.Hour)},
+	}
+
+	rankGaps(gaps, []string{"AAPL", "MSFT"}, now)
+	assert.Equal(t, "AAPL", gaps[0].Symbol)
+	assert.Equal(t, now.Add(-time.Hour), gaps[0].End)
+	assert.Equal(t, "MSFT", gaps[1].Symbol)
+	assert.Equal(t, now.AddDate(0, 0, -10), gaps[2].End)
+}
@@ -0,0 +1,289 @@
+package collector
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HTTP fixture modes
+const (
+	FixtureRecord = "record" // call the provider and save each response
+	FixtureReplay = "replay" // serve saved responses and never touch the network
+)
+
+// redacted replaces API keys and tokens in recorded fixtures
+const redacted = "REDACTED"
+
+// secretParams are the query parameters provider clients send credentials in,
+// compared case-insensitively
+var secretParams = map[string]bool{
+	"apikey": true, "api_key": true, "token": true, "key": true, "access_key": true,
+	"x_cg_demo_api_key": true, "x_cg_pro_api_key": true,
+}
+
+// secretHeaders are the request headers provider clients send credentials in
+var secretHeaders = []string{"Authorization", "X-Api-Key", "X-Cg-Demo-Api-Key", "X-Cg-Pro-Api-Key"}
+
+// fixtureHeaders are the response headers kept in fixtures; the rest are noise
+// (dates, cookies, tracing) that would only churn recordings
+var fixtureHeaders = []string{"Content-Type", "Content-Encoding", "Retry-After"}
+
+var (
+	transportMu       sync.RWMutex
+	providerTransport http.RoundTripper
+)
+
+// UseHTTPTransport routes the provider clients created from now on through
+// transport, nil for the default. Set it before building the collector.
+func UseHTTPTransport(transport http.RoundTripper) {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	providerTransport = transport
+}
+
+// newHTTPClient is the HTTP client every provider client is built with
+func newHTTPClient(timeout time.Duration) *http.Client {
+	transportMu.RLock()
+	defer transportMu.RUnlock()
+	return &http.Client{Timeout: timeout, Transport: providerTransport}
+}
+
+// httpFixture is one recorded exchange, stored as JSON. JSON bodies are kept
+// as JSON so fixtures stay readable and diff well.
+type httpFixture struct {
+	Method     string          `json:"method"`
+	URL        string          `json:"url"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	Status     int             `json:"status"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	BodyText   string          `json:"body_text,omitempty"`
+	BodyBytes  []byte          `json:"body_base64,omitempty"`
+}
+
+func (f *httpFixture) body() []byte {
+	switch {
+	case f.Body != nil:
+		return f.Body
+	case f.BodyBytes != nil:
+		return f.BodyBytes
+	default:
+		return []byte(f.BodyText)
+	}
+}
+
+// FixtureTransport records provider responses to fixture files and replays
+// them. Fixtures are keyed by method and URL with credentials scrubbed, so a
+// replay matches whatever key the client is configured with; a request made
+// several times is recorded once per call and replayed in the same order,
+// the last recording answering any further calls. Recorded URLs, headers and
+// bodies have API keys and tokens replaced with "REDACTED".
+type FixtureTransport struct {
+	mode string
+	dir  string
+	next http.RoundTripper // the network, when recording
+
+	mu    sync.Mutex
+	calls map[string]int // by fixture key
+}
+
+// NewFixtureTransport records to or replays from dir
+func NewFixtureTransport(mode, dir string) (*FixtureTransport, error) {
+	if mode != FixtureRecord && mode != FixtureReplay {
+		return nil, fmt.Errorf("unknown HTTP fixture mode %q: use record or replay", mode)
+	}
+	if dir == "" {
+		return nil, errors.New("HTTP fixtures need a directory")
+	}
+	return &FixtureTransport{
+		mode:  mode,
+		dir:   dir,
+		next:  http.DefaultTransport,
+		calls: make(map[string]int),
+	}, nil
+}
+
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	scrubbed := scrubURL(req.URL)
+	key := req.Method + " " + scrubbed
+
+	t.mu.Lock()
+	t.calls[key]++
+	call := t.calls[key]
+	t.mu.Unlock()
+
+	if t.mode == FixtureRecord {
+		return t.record(req, scrubbed, call)
+	}
+	return t.replay(req, scrubbed, call)
+}
+
+func (t *FixtureTransport) record(req *http.Request, scrubbed string, call int) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	secrets := requestSecrets(req)
+	fixture := httpFixture{
+		Method:     req.Method,
+		URL:        scrubbed,
+		RecordedAt: time.Now().UTC(),
+		Status:     resp.StatusCode,
+		Header:     make(http.Header),
+	}
+	for _, name := range fixtureHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			fixture.Header.Set(name, scrubSecrets(value, secrets))
+		}
+	}
+	for name, values := range resp.Header {
+		if strings.HasPrefix(name, "X-Ratelimit") {
+			fixture.Header.Set(name, scrubSecrets(values[0], secrets))
+		}
+	}
+	clean := []byte(scrubSecrets(string(body), secrets))
+	switch {
+	case json.Valid(clean):
+		fixture.Body = clean
+	case utf8.Valid(clean):
+		fixture.BodyText = string(clean)
+	default:
+		fixture.BodyBytes = body
+	}
+
+	path := t.path(req.Method, scrubbed, call)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write HTTP fixture: %w", err)
+	}
+	return resp, nil
+}
+
+func (t *FixtureTransport) replay(req *http.Request, scrubbed string, call int) (*http.Response, error) {
+	var data []byte
+	var err error
+	for n := call; n >= 1; n-- {
+		if data, err = os.ReadFile(t.path(req.Method, scrubbed, n)); err == nil || !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no HTTP fixture for %s %s in %s, record it first", req.Method, scrubbed, t.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture httpFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid HTTP fixture for %s %s: %w", req.Method, scrubbed, err)
+	}
+	body := fixture.body()
+	header := fixture.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// path names the fixture of the call-th request for a URL:
+// <dir>/<host>/<path>-<hash>.json, with -2, -3, ... before the extension for
+// repeated calls
+func (t *FixtureTransport) path(method, scrubbed string, call int) string {
+	sum := sha256.Sum256([]byte(method + " " + scrubbed))
+	name := "root"
+	host := "unknown"
+	if u, err := url.Parse(scrubbed); err == nil {
+		host = u.Host
+		if trimmed := strings.Trim(u.Path, "/"); trimmed != "" {
+			name = strings.NewReplacer("/", "_", ".", "_", ":", "_").Replace(trimmed)
+		}
+	}
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	name += "-" + hex.EncodeToString(sum[:4])
+	if call > 1 {
+		name += fmt.Sprintf("-%d", call)
+	}
+	return filepath.Join(t.dir, host, name+".json")
+}
+
+// scrubURL returns u with credential parameters redacted and the query
+// sorted, the form fixtures are keyed and recorded by
+func scrubURL(u *url.URL) string {
+	clean := *u
+	query := u.Query()
+	for name := range query {
+		if secretParams[strings.ToLower(name)] {
+			query.Set(name, redacted)
+		}
+	}
+	clean.RawQuery = query.Encode()
+	clean.User = nil
+	clean.Fragment = ""
+	return clean.String()
+}
+
+// requestSecrets returns the credentials a request carries
+func requestSecrets(req *http.Request) []string {
+	var secrets []string
+	for name, values := range req.URL.Query() {
+		if secretParams[strings.ToLower(name)] {
+			secrets = append(secrets, values...)
+		}
+	}
+	for _, name := range secretHeaders {
+		if value := req.Header.Get(name); value != "" {
+			secrets = append(secrets, strings.TrimPrefix(value, "Bearer "))
+		}
+	}
+	return secrets
+}
+
+// scrubSecrets redacts every occurrence of secrets in s. Values too short to
+// be credentials are left alone rather than blanking unrelated text.
+func scrubSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if len(secret) >= 6 {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+	return s
+}
@@ -0,0 +1,189 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureDir holds responses recorded from the live Yahoo and Alpha Vantage
+// APIs with FixtureRecord
+var fixtureDir = filepath.Join("testdata", "http")
+
+func replayTransport(t *testing.T) *FixtureTransport {
+	transport, err := NewFixtureTransport(FixtureReplay, fixtureDir)
+	require.NoError(t, err)
+	return transport
+}
+
+func TestFixtureTransportRecordReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc123")
+		w.Header().Set("X-Ratelimit-Remaining", "4")
+		if calls == 1 {
+			w.Write([]byte(`{"Information": "The API key sk-live-1234 has reached its limit"}`))
+			return
+		}
+		w.Write([]byte(`{"price": "101.25"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewFixtureTransport(FixtureRecord, dir)
+	require.NoError(t, err)
+	client := &http.Client{Transport: recorder}
+
+	get := func(client *http.Client, apiKey string) (int, string) {
+		resp, err := client.Get(server.URL + "/query?symbol=IBM&apikey=" + apiKey)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	_, first := get(client, "sk-live-1234")
+	_, second := get(client, "sk-live-1234")
+	assert.Contains(t, first, "sk-live-1234", "recording doesn't change what the client sees")
+
+	var files []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	require.Len(t, files, 2)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "sk-live-1234")
+		assert.NotContains(t, string(data), "session=abc123")
+		assert.Contains(t, string(data), "apikey=REDACTED")
+		assert.Contains(t, string(data), "X-Ratelimit-Remaining")
+	}
+
+	// Replay matches with any key and serves the calls in order, repeating the
+	// last recording once they run out
+	replayer, err := NewFixtureTransport(FixtureReplay, dir)
+	require.NoError(t, err)
+	client = &http.Client{Transport: replayer}
+	server.Close()
+
+	status, body := get(client, "another-key")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, strings.ReplaceAll(first, "sk-live-1234", redacted), body)
+	_, body = get(client, "another-key")
+	assert.JSONEq(t, second, body)
+	_, body = get(client, "another-key")
+	assert.JSONEq(t, second, body)
+
+	_, err = client.Get(server.URL + "/query?symbol=MSFT")
+	assert.ErrorContains(t, err, "no HTTP fixture")
+
+	_, err = NewFixtureTransport("live", dir)
+	assert.Error(t, err)
+}
+
+func TestYahooFinanceFixtures(t *testing.T) {
+	client := NewYahooFinanceClient()
+	client.breaker = nil
+	client.httpClient.Transport = replayTransport(t)
+	ctx := context.Background()
+
+	quotes, err := client.GetMultipleQuotes(ctx, []string{"AAPL", "MSFT", "BRK/B"})
+	require.NoError(t, err)
+	require.Len(t, quotes, 3)
+	assert.Equal(t, "AAPL", quotes[0].Symbol)
+	assert.Equal(t, decimal.MustParse("227.48"), quotes[0].Price)
+	assert.Equal(t, int64(72071199), quotes[0].Volume)
+	assert.Equal(t, time.Date(2025, 3, 10, 20, 0, 1, 0, time.UTC), quotes[0].Timestamp)
+	assert.Equal(t, "BRK-B", quotes[2].Symbol)
+
+	history, err := client.GetHistoricalData(ctx, "AAPL", "5d", "1d")
+	require.NoError(t, err)
+	// Yahoo's null bar for the 7th is skipped
+	require.Len(t, history, 4)
+	assert.InDelta(t, 235.93, history[0].Close.Float64(), 0.001)
+	assert.Equal(t, decimal.MustParse("240.07"), history[0].High)
+	assert.Equal(t, time.Date(2025, 3, 10, 13, 30, 0, 0, time.UTC), history[3].Timestamp)
+
+	_, err = client.GetHistoricalData(ctx, "NOPE", "5d", "1d")
+	assert.True(t, errors.Is(err, ErrSymbolNotFound))
+	assert.ErrorContains(t, err, "symbol may be delisted")
+
+	_, err = client.GetQuote(ctx, "TSLA")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+
+	november := time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC)
+	chain, err := client.GetOptionsChain(ctx, "AAPL", november)
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", chain.Underlying)
+	assert.Equal(t, november, chain.Expiration)
+	assert.Equal(t, decimal.MustParse("247.5"), chain.UnderlyingPrice)
+	assert.Len(t, chain.Expirations, 5)
+	require.Len(t, chain.Contracts, 8)
+	// By strike, the call before the put
+	assert.Equal(t, "AAPL261120C00240000", chain.Contracts[0].Symbol)
+	assert.Equal(t, "AAPL261120P00240000", chain.Contracts[1].Symbol)
+	assert.Equal(t, decimal.MustParse("12.85"), chain.Contracts[0].Bid)
+	assert.Equal(t, int64(18422), chain.Contracts[0].OpenInterest)
+	assert.Equal(t, int64(100), chain.Contracts[0].Multiplier)
+	assert.Zero(t, chain.Contracts[0].ImpliedVolatility, "Yahoo's implied volatility is not taken")
+}
+
+func TestAlphaVantageFixtures(t *testing.T) {
+	client := NewAlphaVantageClient("test-key")
+	client.breaker = nil
+	client.httpClient.Transport = replayTransport(t)
+	ctx := context.Background()
+
+	quote, err := client.GetQuote(ctx, "IBM")
+	require.NoError(t, err)
+	assert.Equal(t, "IBM", quote.Symbol)
+	assert.Equal(t, decimal.MustParse("255.49"), quote.Price)
+	assert.Equal(t, int64(5453186), quote.Volume)
+
+	daily, err := client.GetDailyData(ctx, "IBM", false)
+	require.NoError(t, err)
+	require.Len(t, daily, 5)
+	assert.Equal(t, time.Date(2025, 3, 4, 5, 0, 0, 0, time.UTC), daily[0].Timestamp, "dates are midnight New York time")
+	assert.Equal(t, decimal.MustParse("245.68"), daily[0].Close)
+	assert.Equal(t, int64(7138565), daily[3].Volume)
+
+	intraday, err := client.GetIntradayData(ctx, "IBM", "5min")
+	require.NoError(t, err)
+	require.Len(t, intraday, 3)
+	assert.Equal(t, time.Date(2025, 3, 10, 19, 55, 0, 0, time.UTC), intraday[0].Timestamp)
+	assert.Equal(t, time.Date(2025, 3, 10, 23, 55, 0, 0, time.UTC), intraday[2].Timestamp, "extended hours bars are kept")
+
+	// The free tier answers a burst with a 200 and a "Note" in place of data
+	_, err = client.GetQuote(ctx, "MSFT")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	quote, err = client.GetQuote(ctx, "MSFT")
+	require.NoError(t, err)
+	assert.Equal(t, decimal.MustParse("380.16"), quote.Price)
+
+	// and the daily cap with an "Information" message naming the key
+	_, err = client.GetQuote(ctx, "TSLA")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.ErrorContains(t, err, "REDACTED")
+
+	_, err = client.GetDailyData(ctx, "NOPE", false)
+	assert.True(t, errors.Is(err, ErrSymbolNotFound))
+}
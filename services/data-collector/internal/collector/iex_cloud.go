@@ -0,0 +1,609 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+type IEXCloudClient struct {
+	httpClient  *http.Client
+	baseURL     string
+	keyMu       sync.RWMutex
+	apiKey      string
+	rateLimiter *RateLimiter
+	breaker     *CircuitBreaker
+}
+
+func NewIEXCloudClient(apiKey string) *IEXCloudClient {
+	iex := &IEXCloudClient{
+		httpClient: newHTTPClient(10 * time.Second),
+		baseURL:    "https://cloud.iexapis.com/stable",
+		apiKey:     apiKey,
+	}
+	iex.breaker = NewCircuitBreaker(iex.Name(), DefaultCircuitBreakerConfig(), iex.GetAPIHealth)
+	return iex
+}
+
+// CircuitBreaker exposes the client's breaker so the collector can observe transitions
+func (iex *IEXCloudClient) CircuitBreaker() *CircuitBreaker {
+	return iex.breaker
+}
+
+// SetAPIKey rotates the token; requests already built keep the old one
+func (iex *IEXCloudClient) SetAPIKey(apiKey string) {
+	iex.keyMu.Lock()
+	defer iex.keyMu.Unlock()
+	iex.apiKey = apiKey
+}
+
+// Name identifies the provider in MarketData.Source and in configuration
+func (iex *IEXCloudClient) Name() string {
+	return "iex"
+}
+
+// Quotes
+func (iex *IEXCloudClient) GetQuote(ctx context.Context, symbol string) (*models.MarketData, error) {
+	body, err := iex.makeRequest(ctx, "/stock/"+url.PathEscape(strings.ToLower(symbol))+"/quote", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var quote iexQuote
+	if err := json.Unmarshal(body, &quote); err != nil {
+		return nil, fmt.Errorf("failed to decode IEX quote: %w", err)
+	}
+	data := iex.quoteToMarketData(&quote)
+	if err := validateIEXData(data); err != nil {
+		return nil, &ProviderError{Provider: iex.Name(), Message: err.Error()}
+	}
+	return data, nil
+}
+
+// GetMultipleQuotes uses the batch endpoint, 100 symbols per request. Unknown
+// symbols are absent from the response rather than failing the batch.
+func (iex *IEXCloudClient) GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error) {
+	const maxSymbolsPerRequest = 100
+
+	var results []*models.MarketData
+	for start := 0; start < len(symbols); start += maxSymbolsPerRequest {
+		end := start + maxSymbolsPerRequest
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+
+		body, err := iex.makeRequest(ctx, "/stock/market/batch", map[string]string{
+			"symbols": strings.ToLower(strings.Join(symbols[start:end], ",")),
+			"types":   "quote",
+		})
+		if err != nil {
+			return results, err
+		}
+
+		quotes, err := iex.parseBatchQuotes(body)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, quotes...)
+	}
+
+	return results, nil
+}
+
+// GetHistoricalData maps Yahoo-style period/interval arguments onto IEX charts:
+// daily bars come from the chart endpoint, intraday bars from today's
+// intraday-prices sampled every interval
+func (iex *IEXCloudClient) GetHistoricalData(ctx context.Context, symbol string, period string, interval string) ([]*models.MarketData, error) {
+	start, err := periodStart(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*models.MarketData
+	switch interval {
+	case "1m", "5m", "15m", "30m", "60m", "1h":
+		history, err = iex.GetIntradayData(ctx, symbol, interval)
+	case "1d":
+		history, err = iex.GetChart(ctx, symbol, iexRange(period))
+	default:
+		return nil, fmt.Errorf("unsupported interval for %s: %s", iex.Name(), interval)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := history[:0]
+	for _, data := range history {
+		if !data.Timestamp.Before(start) {
+			trimmed = append(trimmed, data)
+		}
+	}
+	return trimmed, nil
+}
+
+// GetIntradayData returns today's minute bars, keeping every interval-th one
+func (iex *IEXCloudClient) GetIntradayData(ctx context.Context, symbol string, interval string) ([]*models.MarketData, error) {
+	minutes := map[string]string{"1m": "1", "5m": "5", "15m": "15", "30m": "30", "60m": "60", "1h": "60"}[interval]
+	if minutes == "" {
+		return nil, fmt.Errorf("unsupported intraday interval: %s", interval)
+	}
+
+	body, err := iex.makeRequest(ctx, "/stock/"+url.PathEscape(strings.ToLower(symbol))+"/intraday-prices", map[string]string{
+		"chartInterval": minutes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return iex.parseChart(body, symbol)
+}
+
+// GetChart returns daily bars for an IEX range (5d, 1m, 3m, 6m, ytd, 1y, 2y, 5y, max)
+func (iex *IEXCloudClient) GetChart(ctx context.Context, symbol string, chartRange string) ([]*models.MarketData, error) {
+	body, err := iex.makeRequest(ctx, "/stock/"+url.PathEscape(strings.ToLower(symbol))+"/chart/"+chartRange, nil)
+	if err != nil {
+		return nil, err
+	}
+	return iex.parseChart(body, symbol)
+}
+
+// Reference Data
+func (iex *IEXCloudClient) GetCompanyProfile(ctx context.Context, symbol string) (*models.CompanyProfile, error) {
+	body, err := iex.makeRequest(ctx, "/stock/"+url.PathEscape(strings.ToLower(symbol))+"/company", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var company struct {
+		Symbol      string   `json:"symbol"`
+		CompanyName string   `json:"companyName"`
+		Exchange    string   `json:"exchange"`
+		Industry    string   `json:"industry"`
+		Sector      string   `json:"sector"`
+		Website     string   `json:"website"`
+		Description string   `json:"description"`
+		CEO         string   `json:"CEO"`
+		IssueType   string   `json:"issueType"`
+		Employees   int      `json:"employees"`
+		Country     string   `json:"country"`
+		Tags        []string `json:"tags"`
+	}
+	if err := json.Unmarshal(body, &company); err != nil {
+		return nil, fmt.Errorf("failed to decode IEX company: %w", err)
+	}
+
+	return &models.CompanyProfile{
+		Symbol:      company.Symbol,
+		Name:        company.CompanyName,
+		Exchange:    company.Exchange,
+		Industry:    company.Industry,
+		Sector:      company.Sector,
+		Website:     company.Website,
+		Description: company.Description,
+		CEO:         company.CEO,
+		IssueType:   company.IssueType,
+		Employees:   company.Employees,
+		Country:     company.Country,
+		Tags:        company.Tags,
+		Source:      iex.Name(),
+	}, nil
+}
+
+// GetSymbols returns every symbol IEX supports
+func (iex *IEXCloudClient) GetSymbols(ctx context.Context) ([]*models.ListedSymbol, error) {
+	body, err := iex.makeRequest(ctx, "/ref-data/symbols", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []struct {
+		Symbol    string `json:"symbol"`
+		Name      string `json:"name"`
+		Exchange  string `json:"exchange"`
+		Type      string `json:"type"`
+		Region    string `json:"region"`
+		Currency  string `json:"currency"`
+		IsEnabled bool   `json:"isEnabled"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode IEX symbols: %w", err)
+	}
+
+	symbols := make([]*models.ListedSymbol, 0, len(raw))
+	for _, s := range raw {
+		symbols = append(symbols, &models.ListedSymbol{
+			Symbol:   s.Symbol,
+			Name:     s.Name,
+			Exchange: s.Exchange,
+			Type:     s.Type,
+			Region:   s.Region,
+			Currency: s.Currency,
+			Enabled:  s.IsEnabled,
+			Source:   iex.Name(),
+		})
+	}
+	return symbols, nil
+}
+
+// GetCorporateActions returns the symbol's splits and cash dividends that
+// went ex since from, IEX keeping five years of them
+func (iex *IEXCloudClient) GetCorporateActions(ctx context.Context, symbol string, from time.Time) ([]*models.CorporateAction, error) {
+	path := "/stock/" + url.PathEscape(strings.ToLower(symbol))
+	actionRange := iexActionRange(from, time.Now())
+
+	body, err := iex.makeRequest(ctx, path+"/splits/"+actionRange, nil)
+	if err != nil {
+		return nil, err
+	}
+	var splits []struct {
+		ExDate      string          `json:"exDate"`
+		FromFactor  decimal.Decimal `json:"fromFactor"`
+		ToFactor    decimal.Decimal `json:"toFactor"`
+		Description string          `json:"description"`
+	}
+	if err := json.Unmarshal(body, &splits); err != nil {
+		return nil, fmt.Errorf("failed to decode IEX splits: %w", err)
+	}
+
+	body, err = iex.makeRequest(ctx, path+"/dividends/"+actionRange, nil)
+	if err != nil {
+		return nil, err
+	}
+	var dividends []struct {
+		ExDate      string          `json:"exDate"`
+		RecordDate  string          `json:"recordDate"`
+		PaymentDate string          `json:"paymentDate"`
+		Amount      decimal.Decimal `json:"amount"`
+		Currency    string          `json:"currency"`
+		Flag        string          `json:"flag"` // Cash, Stock, ...
+		Description string          `json:"description"`
+	}
+	if err := json.Unmarshal(body, &dividends); err != nil {
+		return nil, fmt.Errorf("failed to decode IEX dividends: %w", err)
+	}
+
+	date := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+	symbol = strings.ToUpper(symbol)
+	var actions []*models.CorporateAction
+	for _, split := range splits {
+		if split.FromFactor.Sign() <= 0 || split.ToFactor.Sign() <= 0 || split.ToFactor == split.FromFactor {
+			continue
+		}
+		action := &models.CorporateAction{
+			Symbol: symbol,
+			Type:   models.ActionSplit,
+			ExDate: date(split.ExDate),
+			Ratio:  split.ToFactor.Div(split.FromFactor),
+			Note:   split.Description,
+			Source: iex.Name(),
+		}
+		if split.ToFactor.LessThan(split.FromFactor) {
+			action.Type = models.ActionReverseSplit
+		}
+		actions = append(actions, action)
+	}
+	for _, dividend := range dividends {
+		// Stock dividends come without a ratio
+		if dividend.Amount.Sign() <= 0 || strings.EqualFold(dividend.Flag, "Stock") {
+			continue
+		}
+		actions = append(actions, &models.CorporateAction{
+			Symbol:     symbol,
+			Type:       models.ActionCashDividend,
+			ExDate:     date(dividend.ExDate),
+			RecordDate: date(dividend.RecordDate),
+			PayDate:    date(dividend.PaymentDate),
+			Amount:     dividend.Amount,
+			Currency:   dividend.Currency,
+			Note:       dividend.Description,
+			Source:     iex.Name(),
+		})
+	}
+	return actions, nil
+}
+
+// Data Processing and Utilities
+
+// IEX sends null outside market hours and for fields the plan doesn't
+// include. Prices decode null as zero; the other iexQuote fields are
+// pointers.
+type iexQuote struct {
+	Symbol        string          `json:"symbol"`
+	LatestPrice   decimal.Decimal `json:"latestPrice"`
+	LatestUpdate  int64           `json:"latestUpdate"` // epoch milliseconds
+	Open          decimal.Decimal `json:"open"`
+	High          decimal.Decimal `json:"high"`
+	Low           decimal.Decimal `json:"low"`
+	Close         decimal.Decimal `json:"close"`
+	PreviousClose decimal.Decimal `json:"previousClose"`
+	Change        decimal.Decimal `json:"change"`
+	ChangePercent decimal.Decimal `json:"changePercent"` // a fraction, 0.0123 is 1.23%
+	Volume        *int64          `json:"volume"`
+	LatestVolume  *int64          `json:"latestVolume"`
+	MarketCap     *int64          `json:"marketCap"`
+
+	// Top of the IEX book only, not the consolidated quote
+	IEXBidPrice     decimal.Decimal `json:"iexBidPrice"`
+	IEXAskPrice     decimal.Decimal `json:"iexAskPrice"`
+	IEXBidSize      *int64          `json:"iexBidSize"`
+	IEXAskSize      *int64          `json:"iexAskSize"`
+	PrimaryExchange string          `json:"primaryExchange"`
+}
+
+type iexChartBar struct {
+	Date   string          `json:"date"`
+	Minute string          `json:"minute"` // only on intraday bars
+	Open   decimal.Decimal `json:"open"`
+	High   decimal.Decimal `json:"high"`
+	Low    decimal.Decimal `json:"low"`
+	Close  decimal.Decimal `json:"close"`
+	Volume *int64          `json:"volume"`
+}
+
+func (iex *IEXCloudClient) parseBatchQuotes(response []byte) ([]*models.MarketData, error) {
+	var batch map[string]struct {
+		Quote *iexQuote `json:"quote"`
+	}
+	if err := json.Unmarshal(response, &batch); err != nil {
+		return nil, fmt.Errorf("failed to decode IEX batch: %w", err)
+	}
+
+	results := make([]*models.MarketData, 0, len(batch))
+	for _, entry := range batch {
+		if entry.Quote == nil {
+			continue
+		}
+		data := iex.quoteToMarketData(entry.Quote)
+		if err := validateIEXData(data); err != nil {
+			continue
+		}
+		results = append(results, data)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Symbol < results[j].Symbol
+	})
+	return results, nil
+}
+
+func (iex *IEXCloudClient) quoteToMarketData(q *iexQuote) *models.MarketData {
+	data := &models.MarketData{
+		Symbol:        q.Symbol,
+		Price:         q.LatestPrice,
+		Open:          q.Open,
+		High:          q.High,
+		Low:           q.Low,
+		Close:         q.Close,
+		Change:        q.Change,
+		ChangePercent: q.ChangePercent.Mul(decimal.FromInt(100)),
+		PrevClose:     q.PreviousClose,
+		Bid:           q.IEXBidPrice,
+		Ask:           q.IEXAskPrice,
+		Exchange:      q.PrimaryExchange,
+		Timestamp:     time.UnixMilli(q.LatestUpdate).UTC(),
+		Source:        iex.Name(),
+	}
+	// Before the close "close" is null; use the latest price like the other providers
+	if data.Close.IsZero() {
+		data.Close = data.Price
+	}
+	if q.Volume != nil {
+		data.Volume = *q.Volume
+	} else if q.LatestVolume != nil {
+		data.Volume = *q.LatestVolume
+	}
+	if q.MarketCap != nil {
+		data.MarketCap = *q.MarketCap
+	}
+	if q.IEXBidSize != nil {
+		data.BidSize = *q.IEXBidSize
+	}
+	if q.IEXAskSize != nil {
+		data.AskSize = *q.IEXAskSize
+	}
+	return data
+}
+
+// parseChart maps chart and intraday-prices bars. Daily bars are stamped at
+// midnight New York time like Alpha Vantage's; intraday bars at their minute.
+func (iex *IEXCloudClient) parseChart(response []byte, symbol string) ([]*models.MarketData, error) {
+	var bars []iexChartBar
+	if err := json.Unmarshal(response, &bars); err != nil {
+		return nil, fmt.Errorf("failed to decode IEX chart: %w", err)
+	}
+
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		eastern = time.UTC
+	}
+
+	history := make([]*models.MarketData, 0, len(bars))
+	for _, bar := range bars {
+		// Minutes without IEX trades come back with null prices
+		if bar.Close.IsZero() {
+			continue
+		}
+
+		layout, stamp := "2006-01-02", bar.Date
+		if bar.Minute != "" {
+			layout, stamp = "2006-01-02 15:04", bar.Date+" "+bar.Minute
+		}
+		ts, err := time.ParseInLocation(layout, stamp, eastern)
+		if err != nil {
+			continue
+		}
+
+		data := &models.MarketData{
+			Symbol:    strings.ToUpper(symbol),
+			Price:     bar.Close,
+			Open:      bar.Open,
+			High:      bar.High,
+			Low:       bar.Low,
+			Close:     bar.Close,
+			Timestamp: ts.UTC(),
+			Source:    iex.Name(),
+		}
+		if bar.Volume != nil {
+			data.Volume = *bar.Volume
+		}
+		history = append(history, data)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Timestamp.Before(history[j].Timestamp)
+	})
+	return history, nil
+}
+
+// iexRange maps a Yahoo-style period onto the IEX chart range covering it
+func iexRange(period string) string {
+	switch period {
+	case "1d", "5d":
+		return "5d"
+	case "1mo":
+		return "1m"
+	case "3mo":
+		return "3m"
+	case "6mo":
+		return "6m"
+	case "1y", "2y", "5y", "ytd":
+		return period
+	default:
+		return "max"
+	}
+}
+
+// iexActionRange is the shortest splits and dividends range reaching back to from
+func iexActionRange(from, now time.Time) string {
+	for _, r := range []struct {
+		months int
+		name   string
+	}{{1, "1m"}, {3, "3m"}, {6, "6m"}, {12, "1y"}, {24, "2y"}} {
+		if !now.AddDate(0, -r.months, 0).After(from) {
+			return r.name
+		}
+	}
+	return "5y"
+}
+
+func (iex *IEXCloudClient) makeRequest(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	iex.keyMu.RLock()
+	query.Set("token", iex.apiKey)
+	iex.keyMu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iex.baseURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create IEX request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	// Per-symbol paths (/stock/aapl/chart/1y) share one limit per endpoint
+	limitKey := endpoint
+	if parts := strings.Split(endpoint, "/"); len(parts) >= 4 && parts[1] == "stock" && parts[2] != "market" {
+		limitKey = "/stock/" + parts[3]
+	}
+
+	if iex.breaker != nil {
+		if err := iex.breaker.Allow(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := iex.checkRateLimit(ctx, limitKey); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	body, err := iex.doRequest(req)
+	if iex.breaker != nil {
+		iex.breaker.Record(ctx, time.Since(start), err)
+	}
+	return body, err
+}
+
+func (iex *IEXCloudClient) doRequest(req *http.Request) ([]byte, error) {
+	resp, err := iex.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: iex.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: iex.Name(), Message: "failed to read response", Err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, iex.handleIEXError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// Rate Limiting and Health
+func (iex *IEXCloudClient) checkRateLimit(ctx context.Context, endpoint string) error {
+	if iex.rateLimiter == nil {
+		return nil
+	}
+	return iex.rateLimiter.Wait(ctx, endpoint)
+}
+
+func (iex *IEXCloudClient) GetAPIHealth(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := iex.GetQuote(ctx, "SPY"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Error Handling
+
+// handleIEXError classifies a failed response. IEX answers errors with a plain
+// text body: 404 "Unknown symbol", 402 when the message quota is used up and
+// 429 when requests come too fast.
+func (iex *IEXCloudClient) handleIEXError(statusCode int, body []byte) error {
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	providerErr := &ProviderError{Provider: iex.Name(), StatusCode: statusCode, Message: message}
+
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusPaymentRequired:
+		providerErr.Err = ErrRateLimited
+	case http.StatusNotFound:
+		providerErr.Err = ErrSymbolNotFound
+	}
+	return providerErr
+}
+
+// Data Validation
+func validateIEXData(data *models.MarketData) error {
+	if data.Symbol == "" {
+		return errors.New("missing symbol")
+	}
+	if data.Price.Sign() <= 0 {
+		return fmt.Errorf("non-positive price %s", data.Price)
+	}
+	if data.High.Sign() > 0 && data.Low.Sign() > 0 && data.High.LessThan(data.Low) {
+		return fmt.Errorf("high %s below low %s", data.High, data.Low)
+	}
+	return nil
+}
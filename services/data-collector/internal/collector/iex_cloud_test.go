@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIEXFixtureClient serves recorded IEX responses from testdata/iex by path
+func newIEXFixtureClient(t *testing.T) (*IEXCloudClient, *[]*http.Request) {
+	fixtures := map[string]string{
+		"/stock/aapl/quote":           "quote_aapl.json",
+		"/stock/market/batch":         "batch_quotes.json",
+		"/stock/aapl/chart/1m":        "chart_1m.json",
+		"/stock/aapl/intraday-prices": "intraday_prices.json",
+		"/stock/aapl/company":         "company_aapl.json",
+		"/ref-data/symbols":           "ref_symbols.json",
+		"/stock/aapl/splits/5y":       "splits_aapl.json",
+		"/stock/aapl/dividends/5y":    "dividends_aapl.json",
+	}
+
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch r.URL.Path {
+		case "/stock/nope/quote":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("Unknown symbol"))
+			return
+		case "/stock/msft/quote":
+			w.WriteHeader(http.StatusPaymentRequired)
+			w.Write([]byte("You have exceeded your allotted message quota. Please enable pay-as-you-go to regain access"))
+			return
+		}
+
+		name, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "iex", name))
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewIEXCloudClient("test-token")
+	client.baseURL = server.URL
+	client.breaker = nil
+	return client, &requests
+}
+
+func TestIEXCloudQuotes(t *testing.T) {
+	client, requests := newIEXFixtureClient(t)
+	ctx := context.Background()
+
+	quote, err := client.GetQuote(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", quote.Symbol)
+	assert.Equal(t, decimal.MustParse("181.92"), quote.Price)
+	assert.Equal(t, decimal.MustParse("181.92"), quote.Close) // close is null until the session ends
+	assert.Equal(t, int64(24310554), quote.Volume)
+	assert.Equal(t, decimal.MustParse("1.286"), quote.ChangePercent)
+	assert.Equal(t, time.UnixMilli(1709311327112).UTC(), quote.Timestamp)
+	assert.Equal(t, "iex", quote.Source)
+	assert.Equal(t, "test-token", (*requests)[0].URL.Query().Get("token"))
+
+	quotes, err := client.GetMultipleQuotes(ctx, []string{"AAPL", "MSFT", "HALT"})
+	require.NoError(t, err)
+	require.Len(t, quotes, 2) // HALT has no price and is dropped
+	assert.Equal(t, "AAPL", quotes[0].Symbol)
+	assert.Equal(t, "MSFT", quotes[1].Symbol)
+	assert.Equal(t, int64(9877341), quotes[1].Volume)
+	assert.Equal(t, "aapl,msft,halt", (*requests)[1].URL.Query().Get("symbols"))
+}
+
+func TestIEXCloudCharts(t *testing.T) {
+	client, _ := newIEXFixtureClient(t)
+	ctx := context.Background()
+
+	daily, err := client.GetChart(ctx, "AAPL", "1m")
+	require.NoError(t, err)
+	require.Len(t, daily, 3)
+	// Sorted oldest first and stamped at midnight New York time
+	assert.Equal(t, time.Date(2024, 2, 28, 5, 0, 0, 0, time.UTC), daily[0].Timestamp)
+	assert.Equal(t, decimal.MustParse("181.42"), daily[2].Close)
+	assert.Equal(t, int64(53805353), daily[2].Volume)
+
+	intraday, err := client.GetIntradayData(ctx, "AAPL", "5m")
+	require.NoError(t, err)
+	require.Len(t, intraday, 2) // the empty 09:35 bar is skipped
+	assert.Equal(t, time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC), intraday[0].Timestamp)
+	assert.Equal(t, "AAPL", intraday[1].Symbol)
+
+	_, err = client.GetIntradayData(ctx, "AAPL", "2m")
+	assert.Error(t, err)
+}
+
+func TestIEXCloudReferenceData(t *testing.T) {
+	client, _ := newIEXFixtureClient(t)
+	ctx := context.Background()
+
+	company, err := client.GetCompanyProfile(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, "Apple Inc", company.Name)
+	assert.Equal(t, "Timothy Donald Cook", company.CEO)
+	assert.Equal(t, 161000, company.Employees)
+
+	symbols, err := client.GetSymbols(ctx)
+	require.NoError(t, err)
+	require.Len(t, symbols, 3)
+	assert.Equal(t, "AAPL", symbols[1].Symbol)
+	assert.True(t, symbols[1].Enabled)
+	assert.False(t, symbols[2].Enabled)
+
+	actions, err := client.GetCorporateActions(ctx, "AAPL", time.Now().AddDate(-5, 0, 0))
+	require.NoError(t, err)
+	require.Len(t, actions, 3)
+	assert.Equal(t, models.ActionSplit, actions[0].Type)
+	assert.Equal(t, decimal.FromInt(4), actions[0].Ratio)
+	assert.Equal(t, time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC), actions[0].ExDate)
+	assert.Equal(t, models.ActionCashDividend, actions[1].Type)
+	assert.Equal(t, decimal.MustParse("0.25"), actions[1].Amount)
+	assert.Equal(t, time.Date(2024, 8, 15, 0, 0, 0, 0, time.UTC), actions[1].PayDate)
+	assert.Equal(t, "USD", actions[1].Currency)
+	assert.Equal(t, "3m", iexActionRange(time.Now().AddDate(0, 0, -90), time.Now()))
+}
+
+func TestIEXCloudErrorClassification(t *testing.T) {
+	client, _ := newIEXFixtureClient(t)
+	ctx := context.Background()
+
+	_, err := client.GetQuote(ctx, "NOPE")
+	require.ErrorIs(t, err, ErrSymbolNotFound)
+	var providerErr *ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, "Unknown symbol", providerErr.Message)
+
+	// An exhausted message quota is a rate limit: the pool fails over and the breaker counts it
+	_, err = client.GetQuote(ctx, "MSFT")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
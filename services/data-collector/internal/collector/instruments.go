@@ -0,0 +1,28 @@
+package collector
+
+import (
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/instruments"
+)
+
+// UseInstruments resolves collected symbols through the instrument master:
+// quotes carry the primary exchange it lists, and symbols are scheduled on
+// that exchange's calendar. Call it before starting the collection loops.
+func (dc *DataCollector) UseInstruments(master *instruments.Master) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.instruments = master
+}
+
+// instrumentMaster returns the instrument master, nil when none is used
+func (dc *DataCollector) instrumentMaster() *instruments.Master {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.instruments
+}
+
+// exchangeOf returns the exchange symbol trades on: its primary listing in
+// the instrument master, or else the one its suffix suggests
+func (dc *DataCollector) exchangeOf(cal *calendar.Calendar, symbol string) *calendar.Exchange {
+	return cal.Resolve(dc.instrumentMaster().Exchange(symbol, ""), symbol)
+}
@@ -0,0 +1,177 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// newsPageSize is how many of each source's latest articles a pass reads
+const newsPageSize = 50
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// newNewsClients builds NewsAPI when its key is set and the feed reader when
+// feeds are configured
+func newNewsClients(cfg *config.Config, limiters map[string]*RateLimiter) map[string]NewsClient {
+	clients := make(map[string]NewsClient)
+	if cfg.NewsAPIKey != "" {
+		client := NewNewsAPIClient(cfg.NewsAPIKey)
+		client.rateLimiter = limiters[client.Name()]
+		clients[client.Name()] = client
+	}
+	if len(cfg.NewsFeeds) > 0 {
+		client := NewFeedClient(cfg.NewsFeeds)
+		clients[client.Name()] = client
+	}
+	return clients
+}
+
+// newsSources returns the configured news clients by name
+func (dc *DataCollector) newsSources() []NewsClient {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+
+	clients := make([]NewsClient, 0, len(dc.newsClients))
+	for _, client := range dc.newsClients {
+		clients = append(clients, client)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Name() < clients[j].Name() })
+	return clients
+}
+
+// CollectNews reads the latest articles from every news client at once,
+// drops stories another source already supplied this pass, and stores and
+// publishes the ones not seen before. Fetching is bounded by NewsInterval so
+// a source waiting on its rate limit cannot hold up the next pass; it is
+// simply read again then.
+func (dc *DataCollector) CollectNews(ctx context.Context) error {
+	if dc.db == nil {
+		return errors.New("news collection needs a database")
+	}
+	clients := dc.newsSources()
+	if len(clients) == 0 {
+		return nil
+	}
+	if err := dc.db.EnsureNewsArticles(ctx); err != nil {
+		return err
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, dc.currentConfig().NewsInterval)
+	defer cancel()
+
+	results := make([][]*models.NewsArticle, len(clients))
+	errs := make([]error, len(clients))
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func(i int, client NewsClient) {
+			defer wg.Done()
+			results[i], errs[i] = client.GetLatestNews(fetchCtx, newsPageSize)
+		}(i, client)
+	}
+	wg.Wait()
+
+	var failed []string
+	var firstErr error
+	seen := make(map[string]bool)
+	stored := 0
+	for i, client := range clients {
+		if err := errs[i]; err != nil {
+			if errors.Is(err, ErrRateLimited) && ctx.Err() == nil {
+				log.Printf("Skipping %s news this pass: %v", client.Name(), err)
+				continue
+			}
+			failed = append(failed, client.Name())
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		for _, article := range results[i] {
+			urlKey, titleKey := "url:"+article.URL, "title:"+strings.ToLower(article.Title)
+			if seen[urlKey] || seen[titleKey] {
+				continue
+			}
+			seen[urlKey], seen[titleKey] = true, true
+
+			processed, err := dc.ProcessNewsArticle(ctx, article)
+			if err != nil {
+				return err
+			}
+			if processed == nil {
+				continue
+			}
+			stored++
+			if dc.producer != nil {
+				if err := dc.producer.PublishNewsArticle(ctx, processed); err != nil {
+					log.Printf("Failed to publish news article %s: %v", processed.URL, err)
+				}
+			}
+		}
+	}
+
+	if stored > 0 {
+		log.Printf("Stored %d new news articles", stored)
+	}
+	if firstErr != nil {
+		return fmt.Errorf("news sources %v failed: %w", failed, firstErr)
+	}
+	return nil
+}
+
+// normalizeArticle trims the article's text, strips markup from its
+// description and canonicalizes its URL. It returns nil for articles
+// without a title or a usable link.
+func normalizeArticle(article *models.NewsArticle) *models.NewsArticle {
+	article.Title = plainText(article.Title)
+	article.Description = plainText(article.Description)
+	article.Author = strings.TrimSpace(article.Author)
+	article.Source = strings.TrimSpace(article.Source)
+	article.Category = strings.ToLower(strings.TrimSpace(article.Category))
+	if article.Category == "" {
+		article.Category = "general"
+	}
+	article.PublishedAt = article.PublishedAt.UTC()
+
+	link, err := url.Parse(strings.TrimSpace(article.URL))
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" || article.Title == "" {
+		return nil
+	}
+	// Tracking parameters make one story look like many
+	link.Host = strings.ToLower(link.Host)
+	link.Fragment = ""
+	query := link.Query()
+	for key := range query {
+		if strings.HasPrefix(key, "utm_") {
+			query.Del(key)
+		}
+	}
+	link.RawQuery = query.Encode()
+	article.URL = link.String()
+	return article
+}
+
+// plainText strips HTML tags and entities and collapses whitespace
+func plainText(s string) string {
+	s = htmlTag.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// sortArticles orders articles newest first
+func sortArticles(articles []*models.NewsArticle) {
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].PublishedAt.After(articles[j].PublishedAt)
+	})
+}
@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveNewsFixture(t *testing.T, w http.ResponseWriter, name string) {
+	body, err := os.ReadFile(filepath.Join("testdata", "news", name))
+	require.NoError(t, err)
+	w.Write(body)
+}
+
+func TestNewsAPIClient(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.URL.Path == "/everything" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"rateLimited","message":"You have made too many requests recently."}`))
+			return
+		}
+		serveNewsFixture(t, w, "newsapi_top_headlines.json")
+	}))
+	defer server.Close()
+
+	client := NewNewsAPIClient("test-key")
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	articles, err := client.GetLatestNews(ctx, 20)
+	require.NoError(t, err)
+	require.Len(t, articles, 2) // the "[Removed]" placeholder is dropped
+	assert.Equal(t, "CNBC", articles[0].Source)
+	assert.Equal(t, "The S&P 500 closed at a record.", articles[0].Description)
+	assert.Equal(t, "https://www.reuters.com/markets/us/fed-holds-rates-2024-03-20/", articles[1].URL)
+	assert.Equal(t, "business", articles[1].Category)
+	assert.Equal(t, time.Date(2024, 3, 20, 18, 5, 0, 0, time.UTC), articles[1].PublishedAt)
+
+	assert.Equal(t, "test-key", requests[0].Header.Get("X-Api-Key"))
+	assert.Equal(t, "business", requests[0].URL.Query().Get("category"))
+	assert.Equal(t, "20", requests[0].URL.Query().Get("pageSize"))
+
+	_, err = client.SearchNews(ctx, "AAPL", 10)
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	_, err = client.GetNewsByCategory(ctx, "markets", 10)
+	assert.Error(t, err)
+}
+
+func TestFeedClientReadsRSSAndAtom(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/markets.xml":
+			serveNewsFixture(t, w, "markets_rss.xml")
+		case "/newsroom.atom":
+			serveNewsFixture(t, w, "filings_atom.xml")
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewFeedClient([]string{server.URL + "/markets.xml", server.URL + "/broken", server.URL + "/newsroom.atom"})
+	ctx := context.Background()
+
+	// The broken feed is skipped; the link-less RSS item is dropped
+	articles, err := client.GetLatestNews(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, articles, 3)
+
+	atom := articles[0]
+	assert.Equal(t, "Company reports record quarterly revenue", atom.Title)
+	assert.Equal(t, "https://news.example.com/releases/record-revenue", atom.URL)
+	assert.Equal(t, "Revenue rose 12% to $94.8 billion.", atom.Description)
+	assert.Equal(t, "Company Newsroom", atom.Source)
+	assert.Equal(t, "Investor Relations", atom.Author)
+	assert.Equal(t, "earnings", atom.Category)
+
+	// -0400 is normalized to UTC, which puts this item ahead of the earlier GMT one
+	rss := articles[1]
+	assert.Equal(t, "Treasury yields fall & the dollar slips", rss.Title)
+	assert.Equal(t, "https://www.marketwatch.com/story/treasury-yields-fall-2024-03-20", rss.URL)
+	assert.Equal(t, time.Date(2024, 3, 20, 23, 2, 0, 0, time.UTC), rss.PublishedAt)
+	assert.Equal(t, "general", rss.Category)
+
+	rss = articles[2]
+	assert.Equal(t, "https://www.marketwatch.com/story/stocks-rally-fed-2024-03-20?mod=mw_rss_topstories", rss.URL)
+	assert.Equal(t, "The Dow, S&P 500 and Nasdaq all finished at records.", rss.Description)
+	assert.Equal(t, "Joy Wiltermuth", rss.Author)
+	assert.Equal(t, "MarketWatch.com - Top Stories", rss.Source)
+
+	found, err := client.SearchNews(ctx, "Quarterly DIVIDEND", 10)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, time.Date(2024, 3, 19, 8, 0, 0, 0, time.UTC), found[0].PublishedAt)
+
+	found, err = client.GetNewsByCategory(ctx, "Markets", 10)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	_, err = NewFeedClient([]string{server.URL + "/broken"}).GetLatestNews(ctx, 10)
+	assert.Error(t, err)
+}
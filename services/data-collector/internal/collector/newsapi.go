@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+// newsAPICategories are the categories top-headlines accepts
+var newsAPICategories = map[string]bool{
+	"business": true, "entertainment": true, "general": true, "health": true,
+	"science": true, "sports": true, "technology": true,
+}
+
+// NewsAPIClient reads newsapi.org. Latest news is the US business headlines;
+// search covers every indexed source.
+type NewsAPIClient struct {
+	httpClient  *http.Client
+	baseURL     string
+	keyMu       sync.RWMutex
+	apiKey      string
+	rateLimiter *RateLimiter
+}
+
+func NewNewsAPIClient(apiKey string) *NewsAPIClient {
+	return &NewsAPIClient{
+		httpClient: newHTTPClient(15 * time.Second),
+		baseURL:    "https://newsapi.org/v2",
+		apiKey:     apiKey,
+	}
+}
+
+// SetAPIKey rotates the key; requests already built keep the old one
+func (n *NewsAPIClient) SetAPIKey(apiKey string) {
+	n.keyMu.Lock()
+	defer n.keyMu.Unlock()
+	n.apiKey = apiKey
+}
+
+// Name identifies the client in configuration and rate limits
+func (n *NewsAPIClient) Name() string {
+	return "newsapi"
+}
+
+func (n *NewsAPIClient) GetLatestNews(ctx context.Context, limit int) ([]*models.NewsArticle, error) {
+	return n.GetNewsByCategory(ctx, "business", limit)
+}
+
+func (n *NewsAPIClient) SearchNews(ctx context.Context, query string, limit int) ([]*models.NewsArticle, error) {
+	body, err := n.makeRequest(ctx, "/everything", map[string]string{
+		"q":        query,
+		"language": "en",
+		"sortBy":   "publishedAt",
+		"pageSize": newsAPIPageSize(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n.parseArticles(body, "")
+}
+
+func (n *NewsAPIClient) GetNewsByCategory(ctx context.Context, category string, limit int) ([]*models.NewsArticle, error) {
+	if !newsAPICategories[category] {
+		return nil, fmt.Errorf("unsupported %s category: %s", n.Name(), category)
+	}
+
+	body, err := n.makeRequest(ctx, "/top-headlines", map[string]string{
+		"category": category,
+		"country":  "us",
+		"pageSize": newsAPIPageSize(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n.parseArticles(body, category)
+}
+
+func newsAPIPageSize(limit int) string {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	return strconv.Itoa(limit)
+}
+
+// Response Parsing
+func (n *NewsAPIClient) parseArticles(response []byte, category string) ([]*models.NewsArticle, error) {
+	var parsed struct {
+		Articles []struct {
+			Source struct {
+				Name string `json:"name"`
+			} `json:"source"`
+			Author      string    `json:"author"`
+			Title       string    `json:"title"`
+			Description string    `json:"description"`
+			URL         string    `json:"url"`
+			PublishedAt time.Time `json:"publishedAt"`
+		} `json:"articles"`
+	}
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode NewsAPI articles: %w", err)
+	}
+
+	articles := make([]*models.NewsArticle, 0, len(parsed.Articles))
+	for _, a := range parsed.Articles {
+		// Articles pulled by the publisher stay listed with "[Removed]" everywhere
+		if a.Title == "[Removed]" {
+			continue
+		}
+		source := a.Source.Name
+		if source == "" {
+			source = n.Name()
+		}
+
+		article := normalizeArticle(&models.NewsArticle{
+			Title:       a.Title,
+			Description: a.Description,
+			URL:         a.URL,
+			Source:      source,
+			Author:      a.Author,
+			PublishedAt: a.PublishedAt,
+			Category:    category,
+		})
+		if article != nil {
+			articles = append(articles, article)
+		}
+	}
+	sortArticles(articles)
+	return articles, nil
+}
+
+// HTTP Request Handling
+func (n *NewsAPIClient) makeRequest(ctx context.Context, endpoint string, params map[string]string) ([]byte, error) {
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NewsAPI request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	n.keyMu.RLock()
+	req.Header.Set("X-Api-Key", n.apiKey)
+	n.keyMu.RUnlock()
+
+	if n.rateLimiter != nil {
+		if err := n.rateLimiter.Wait(ctx, endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: n.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: n.Name(), Message: "failed to read response", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, n.handleNewsAPIError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// Error Handling
+
+// handleNewsAPIError classifies a failed response. NewsAPI answers errors with
+// {"status": "error", "code": "rateLimited", "message": "..."}.
+func (n *NewsAPIClient) handleNewsAPIError(statusCode int, body []byte) error {
+	var apiErr struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	message := http.StatusText(statusCode)
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+		message = apiErr.Message
+	}
+	providerErr := &ProviderError{Provider: n.Name(), StatusCode: statusCode, Message: message}
+
+	if statusCode == http.StatusTooManyRequests || apiErr.Code == "rateLimited" {
+		providerErr.Err = ErrRateLimited
+	}
+	return providerErr
+}
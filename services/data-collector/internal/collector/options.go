@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/options"
+)
+
+// StartOptionsCollection collects the option chains of OptionsSymbols every
+// OptionsInterval. It belongs to the market service and pauses with it; it
+// idles while no symbols are configured or no quote provider quotes options.
+func (dc *DataCollector) StartOptionsCollection(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	warned := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		cfg := dc.currentConfig()
+		timer.Reset(cfg.OptionsInterval)
+		if len(cfg.OptionsSymbols) == 0 || dc.isPaused(ServiceMarket) {
+			continue
+		}
+
+		err := dc.CollectOptionsData(ctx, cfg.OptionsSymbols)
+		switch {
+		case errors.Is(err, ErrNoProviders):
+			if !warned {
+				log.Printf("Options collection idle: %v", err)
+				warned = true
+			}
+		case err != nil && ctx.Err() == nil:
+			dc.HandleCollectionError(ctx, err, "options", cfg.OptionsSymbols)
+		}
+	}
+}
+
+// CollectOptionsData stores the chains of the nearest OptionsExpirations
+// expirations of each underlying, with implied volatility and Greeks
+// computed from their mid prices. Underlyings the instrument master lists
+// as futures are valued with Black-76, the rest with Black-Scholes and no
+// dividend yield. Each expiry is discounted at the latest Treasury curve's
+// rate for its term, or OptionsRiskFreeRate while no curve is stored.
+func (dc *DataCollector) CollectOptionsData(ctx context.Context, underlyingSymbols []string) error {
+	if dc.db == nil {
+		return errors.New("options collection needs a database")
+	}
+	if err := dc.db.EnsureOptionQuotes(ctx); err != nil {
+		return err
+	}
+	cfg := dc.currentConfig()
+	pool := dc.providerPool()
+	now := time.Now().UTC()
+
+	curve, err := dc.db.GetYieldCurve(ctx, now)
+	if err != nil {
+		log.Printf("Valuing options at the configured rate: %v", err)
+	}
+	rate := func(years float64) float64 {
+		if r, ok := options.CurveRate(curve, years); ok {
+			return r
+		}
+		return cfg.OptionsRiskFreeRate
+	}
+
+	var failed []string
+	var firstErr error
+	stored := 0
+	for _, symbol := range underlyingSymbols {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := dc.collectOptionChains(ctx, pool, symbol, cfg.OptionsExpirations, rate, now)
+		stored += n
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrNoProviders):
+			return err
+		case errors.Is(err, ErrSymbolNotFound):
+			log.Printf("Skipping options for %s: %v", symbol, err)
+		default:
+			failed = append(failed, symbol)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if stored > 0 {
+		log.Printf("Stored %d option quotes for %d underlyings", stored, len(underlyingSymbols)-len(failed))
+	}
+	if len(failed) > 0 {
+		return &CollectionError{Source: "options", Symbols: failed, Err: firstErr}
+	}
+	return nil
+}
+
+// collectOptionChains stores symbol's chains for its nearest expirations and
+// returns how many contracts it stored
+func (dc *DataCollector) collectOptionChains(ctx context.Context, pool *ProviderPool, symbol string, expirations int,
+	rate func(years float64) float64, now time.Time) (int, error) {
+	// Without an expiration the provider answers with the nearest one and
+	// lists the others
+	nearest, err := pool.GetOptionsChain(ctx, symbol, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	assetClass := ""
+	if inst, ok := dc.instrumentMaster().Resolve(symbol); ok {
+		assetClass = inst.AssetClass
+	}
+	model := options.ModelFor(assetClass)
+
+	stored := 0
+	for _, expiration := range nearestExpirations(nearest, expirations, now) {
+		chain := nearest
+		if !expiration.Equal(nearest.Expiration) {
+			if chain, err = pool.GetOptionsChain(ctx, symbol, expiration); err != nil {
+				return stored, err
+			}
+		}
+		chain.Underlying = symbol
+		for _, contract := range chain.Contracts {
+			contract.Underlying = symbol
+		}
+
+		years := options.YearsToExpiry(expiration, now)
+		options.Value(chain, options.Valuation{Model: model, Rate: rate(years), Now: now})
+		if err := dc.db.SaveOptionChain(ctx, chain); err != nil {
+			return stored, err
+		}
+		stored += len(chain.Contracts)
+	}
+	return stored, nil
+}
+
+// nearestExpirations returns up to n of chain's listed expirations that have
+// not expired yet, earliest first
+func nearestExpirations(chain *models.OptionChain, n int, now time.Time) []time.Time {
+	listed := chain.Expirations
+	if len(listed) == 0 {
+		listed = []time.Time{chain.Expiration}
+	}
+	var upcoming []time.Time
+	for _, expiration := range listed {
+		if len(upcoming) == n {
+			break
+		}
+		if options.YearsToExpiry(expiration, now) > 0 {
+			upcoming = append(upcoming, expiration)
+		}
+	}
+	return upcoming
+}
@@ -0,0 +1,676 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+// QuoteProvider is the common surface every market data source exposes to the collector
+type QuoteProvider interface {
+	Name() string
+	GetQuote(ctx context.Context, symbol string) (*models.MarketData, error)
+	GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error)
+	GetHistoricalData(ctx context.Context, symbol string, period string, interval string) ([]*models.MarketData, error)
+	GetAPIHealth(ctx context.Context) (bool, error)
+}
+
+// Provider selection strategies
+const (
+	SelectByPriority = "priority"
+	SelectByWeight   = "weight"
+)
+
+var (
+	ErrNoProviders    = errors.New("no quote providers available")
+	ErrRateLimited    = errors.New("provider rate limit exceeded")
+	ErrSymbolNotFound = errors.New("symbol not found")
+	ErrStaleData      = errors.New("stale market data")
+	ErrNoHistory      = errors.New("no provider has history in range")
+)
+
+// ProviderError describes a failed call to an upstream data provider
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: HTTP %d: %s", e.Provider, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Provider, e.Message)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+type providerEntry struct {
+	provider QuoteProvider
+	priority int
+	weight   int
+	paused   bool
+}
+
+// ProviderPool routes quote requests across registered providers and fails over
+// to the next one when a provider errors or only has stale data
+type ProviderPool struct {
+	mu          sync.RWMutex
+	entries     []*providerEntry
+	strategy    string
+	maxQuoteAge time.Duration
+	rng         *rand.Rand
+	now         func() time.Time
+}
+
+func NewProviderPool(strategy string, maxQuoteAge time.Duration) *ProviderPool {
+	if strategy != SelectByWeight {
+		strategy = SelectByPriority
+	}
+
+	return &ProviderPool{
+		strategy:    strategy,
+		maxQuoteAge: maxQuoteAge,
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:         time.Now,
+	}
+}
+
+// Register adds a provider. Lower priority values are tried first; weight is
+// only used by the weighted strategy and is clamped to at least 1.
+func (p *ProviderPool) Register(provider QuoteProvider, priority, weight int) {
+	if weight < 1 {
+		weight = 1
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, entry := range p.entries {
+		if entry.provider.Name() == provider.Name() {
+			entry.provider = provider
+			entry.priority = priority
+			entry.weight = weight
+			return
+		}
+	}
+	p.entries = append(p.entries, &providerEntry{provider: provider, priority: priority, weight: weight})
+}
+
+// Get returns a registered provider by name
+func (p *ProviderPool) Get(name string) (QuoteProvider, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, entry := range p.entries {
+		if entry.provider.Name() == name {
+			return entry.provider, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns provider names in priority order
+func (p *ProviderPool) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	entries := p.byPriority()
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.provider.Name()
+	}
+	return names
+}
+
+// order returns the unpaused providers in the sequence they should be attempted
+func (p *ProviderPool) order() []QuoteProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var entries []*providerEntry
+	if p.strategy == SelectByWeight {
+		entries = p.byWeight()
+	} else {
+		entries = p.byPriority()
+	}
+
+	providers := make([]QuoteProvider, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.paused {
+			providers = append(providers, entry.provider)
+		}
+	}
+	return providers
+}
+
+// SetPaused takes a provider out of (or back into) rotation without
+// unregistering it. It reports whether the provider is registered.
+func (p *ProviderPool) SetPaused(name string, paused bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, entry := range p.entries {
+		if entry.provider.Name() == name {
+			entry.paused = paused
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ProviderPool) byPriority() []*providerEntry {
+	entries := make([]*providerEntry, len(p.entries))
+	copy(entries, p.entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].priority < entries[j].priority
+	})
+	return entries
+}
+
+// byWeight draws providers without replacement, each draw proportional to weight,
+// so the heaviest provider usually goes first but the others still get traffic
+func (p *ProviderPool) byWeight() []*providerEntry {
+	remaining := make([]*providerEntry, len(p.entries))
+	copy(remaining, p.entries)
+
+	ordered := make([]*providerEntry, 0, len(remaining))
+	for len(remaining) > 0 {
+		total := 0
+		for _, entry := range remaining {
+			total += entry.weight
+		}
+
+		pick := p.rng.Intn(total)
+		for i, entry := range remaining {
+			pick -= entry.weight
+			if pick < 0 {
+				ordered = append(ordered, entry)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	return ordered
+}
+
+// staleLookback bounds how far back isStale counts trading time; a month of
+// sessions is far more than any sensible maximum quote age
+const staleLookback = 30 * 24 * time.Hour
+
+// isStale reports whether data is older than maxQuoteAge. Age is counted in
+// regular trading time on the symbol's exchange, so the last price before a
+// close stays fresh overnight, over weekends and holidays; always-open venues
+// count wall-clock time.
+func (p *ProviderPool) isStale(data *models.MarketData) bool {
+	if p.maxQuoteAge <= 0 {
+		return false
+	}
+	if data.Timestamp.IsZero() {
+		return true
+	}
+
+	now := p.now()
+	if now.Sub(data.Timestamp) <= p.maxQuoteAge {
+		return false
+	}
+	exchange := calendar.Default().Resolve(data.Exchange, data.Symbol)
+	if exchange.AlwaysOpen {
+		return true
+	}
+
+	from := data.Timestamp
+	if earliest := now.Add(-staleLookback); from.Before(earliest) {
+		from = earliest
+	}
+	var traded time.Duration
+	for _, session := range exchange.RegularSessions(from, now) {
+		traded += session.End.Sub(session.Start)
+	}
+	return traded > p.maxQuoteAge
+}
+
+// GetQuote returns a quote from the first provider that answers with fresh data.
+// If every provider answers but only with stale data, the freshest stale quote is returned.
+func (p *ProviderPool) GetQuote(ctx context.Context, symbol string) (*models.MarketData, error) {
+	providers := p.order()
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	var stale *models.MarketData
+	var errs []string
+	for _, provider := range providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		data, err := provider.GetQuote(ctx, symbol)
+		if err != nil {
+			errs = append(errs, err.Error())
+			log.Printf("Quote provider %s failed for %s: %v", provider.Name(), symbol, err)
+			continue
+		}
+
+		data.Source = provider.Name()
+		if p.isStale(data) {
+			errs = append(errs, fmt.Sprintf("%s: %v", provider.Name(), ErrStaleData))
+			if stale == nil || data.Timestamp.After(stale.Timestamp) {
+				stale = data
+			}
+			continue
+		}
+		return data, nil
+	}
+
+	if stale != nil {
+		return stale, nil
+	}
+	return nil, fmt.Errorf("all providers failed for %s: %s", symbol, strings.Join(errs, "; "))
+}
+
+// GetMultipleQuotes asks each provider in turn for the symbols still missing a
+// fresh quote, so a partial batch from one provider is completed by the next
+func (p *ProviderPool) GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error) {
+	providers := p.order()
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	fresh := make(map[string]*models.MarketData, len(symbols))
+	stale := make(map[string]*models.MarketData)
+	pending := symbols
+	var errs []string
+
+	for _, provider := range providers {
+		if len(pending) == 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		quotes, err := provider.GetMultipleQuotes(ctx, pending)
+		if err != nil {
+			errs = append(errs, err.Error())
+			log.Printf("Quote provider %s failed for %d symbols: %v", provider.Name(), len(pending), err)
+		}
+
+		for _, data := range quotes {
+			if data == nil {
+				continue
+			}
+			data.Source = provider.Name()
+			if p.isStale(data) {
+				if prev, ok := stale[data.Symbol]; !ok || data.Timestamp.After(prev.Timestamp) {
+					stale[data.Symbol] = data
+				}
+				continue
+			}
+			fresh[data.Symbol] = data
+		}
+
+		next := pending[:0:0]
+		for _, symbol := range pending {
+			if _, ok := fresh[symbol]; !ok {
+				next = append(next, symbol)
+			}
+		}
+		pending = next
+	}
+
+	results := make([]*models.MarketData, 0, len(symbols))
+	var missing []string
+	for _, symbol := range symbols {
+		if data, ok := fresh[symbol]; ok {
+			results = append(results, data)
+		} else if data, ok := stale[symbol]; ok {
+			results = append(results, data)
+		} else {
+			missing = append(missing, symbol)
+		}
+	}
+
+	if len(missing) > 0 {
+		return results, fmt.Errorf("no provider returned data for %s: %s",
+			strings.Join(missing, ","), strings.Join(errs, "; "))
+	}
+	return results, nil
+}
+
+// CrossCheck asks a second provider for up to sample of the quoted symbols,
+// picked at random on each call so every symbol is cross-checked over time.
+// Each goes to the first provider in order that did not quote it and whose
+// circuit breaker, if it has one, is closed; each provider is asked once for
+// all of its share. The answers are returned for reconciliation. A provider
+// that fails leaves its share unchecked.
+func (p *ProviderPool) CrossCheck(ctx context.Context, quotes []*models.MarketData, sample int) []*models.MarketData {
+	providers := p.order()
+	if sample <= 0 || len(quotes) == 0 || len(providers) < 2 {
+		return nil
+	}
+
+	p.mu.Lock()
+	picked := p.rng.Perm(len(quotes))
+	p.mu.Unlock()
+	if len(picked) > sample {
+		picked = picked[:sample]
+	}
+
+	shares := make(map[string][]string)
+	for _, i := range picked {
+		quote := quotes[i]
+		for _, provider := range providers {
+			if provider.Name() != quote.Source && healthy(provider) {
+				shares[provider.Name()] = append(shares[provider.Name()], quote.Symbol)
+				break
+			}
+		}
+	}
+
+	var checks []*models.MarketData
+	for _, provider := range providers {
+		symbols := shares[provider.Name()]
+		if len(symbols) == 0 {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		answers, err := provider.GetMultipleQuotes(ctx, symbols)
+		if err != nil {
+			log.Printf("Cross-check with %s failed for %d symbols: %v", provider.Name(), len(symbols), err)
+		}
+		for _, data := range answers {
+			if data != nil {
+				data.Source = provider.Name()
+				checks = append(checks, data)
+			}
+		}
+	}
+	return checks
+}
+
+// healthy reports whether provider's circuit breaker, if it has one, is closed
+func healthy(provider QuoteProvider) bool {
+	bp, ok := provider.(breakerProvider)
+	return !ok || bp.CircuitBreaker() == nil || bp.CircuitBreaker().State() == CircuitClosed
+}
+
+// GetHistoricalData returns the first non-empty history any provider can serve
+func (p *ProviderPool) GetHistoricalData(ctx context.Context, symbol string, period string, interval string) ([]*models.MarketData, error) {
+	providers := p.order()
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	var errs []string
+	for _, provider := range providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		history, err := provider.GetHistoricalData(ctx, symbol, period, interval)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if len(history) == 0 {
+			errs = append(errs, fmt.Sprintf("%s: empty history", provider.Name()))
+			continue
+		}
+
+		for _, data := range history {
+			data.Source = provider.Name()
+		}
+		return history, nil
+	}
+
+	return nil, fmt.Errorf("all providers failed history for %s: %s", symbol, strings.Join(errs, "; "))
+}
+
+// rangeProvider is implemented by providers that can fetch history between two
+// instants instead of a period ending now
+type rangeProvider interface {
+	GetHistoricalRange(ctx context.Context, symbol string, start, end time.Time, interval string) ([]*models.MarketData, error)
+}
+
+// GetHistoricalRange returns the bars in [start, end) from the first provider
+// that has any. Providers without range support are asked for the shortest
+// period reaching back to start and the result is trimmed.
+func (p *ProviderPool) GetHistoricalRange(ctx context.Context, symbol string, start, end time.Time, interval string) ([]*models.MarketData, error) {
+	providers := p.order()
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	var errs []string
+	for _, provider := range providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var history []*models.MarketData
+		var err error
+		if rp, ok := provider.(rangeProvider); ok {
+			history, err = rp.GetHistoricalRange(ctx, symbol, start, end, interval)
+		} else {
+			history, err = provider.GetHistoricalData(ctx, symbol, backfillPeriod(start, time.Now()), interval)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		bars := make([]*models.MarketData, 0, len(history))
+		for _, data := range history {
+			if !data.Timestamp.Before(start) && data.Timestamp.Before(end) {
+				data.Symbol = symbol
+				data.Source = provider.Name()
+				bars = append(bars, data)
+			}
+		}
+		if len(bars) == 0 {
+			errs = append(errs, fmt.Sprintf("%s: no data in range", provider.Name()))
+			continue
+		}
+		return bars, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s to %s: %s", ErrNoHistory, symbol,
+		start.Format(time.RFC3339), end.Format(time.RFC3339), strings.Join(errs, "; "))
+}
+
+// actionProvider is implemented by providers that report corporate actions
+type actionProvider interface {
+	GetCorporateActions(ctx context.Context, symbol string, from time.Time) ([]*models.CorporateAction, error)
+}
+
+// GetCorporateActions returns the symbol's corporate actions since from, as
+// reported by the first provider that can. It fails with ErrNoProviders when
+// none of the available providers reports them.
+func (p *ProviderPool) GetCorporateActions(ctx context.Context, symbol string, from time.Time) ([]*models.CorporateAction, error) {
+	var errs []string
+	for _, provider := range p.order() {
+		ap, ok := provider.(actionProvider)
+		if !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		actions, err := ap.GetCorporateActions(ctx, symbol, from)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		return actions, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%w: none reports corporate actions", ErrNoProviders)
+	}
+	return nil, fmt.Errorf("all providers failed corporate actions for %s: %s", symbol, strings.Join(errs, "; "))
+}
+
+// optionProvider is implemented by providers that quote option chains
+type optionProvider interface {
+	GetOptionsChain(ctx context.Context, symbol string, expiration time.Time) (*models.OptionChain, error)
+}
+
+// GetOptionsChain returns the symbol's option chain for the expiration, the
+// nearest one when expiration is zero, from the first provider that quotes
+// it. It fails with ErrNoProviders when none of the available providers
+// quotes options.
+func (p *ProviderPool) GetOptionsChain(ctx context.Context, symbol string, expiration time.Time) (*models.OptionChain, error) {
+	var errs []string
+	for _, provider := range p.order() {
+		op, ok := provider.(optionProvider)
+		if !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		chain, err := op.GetOptionsChain(ctx, symbol, expiration)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		return chain, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%w: none quotes options", ErrNoProviders)
+	}
+	return nil, fmt.Errorf("all providers failed the option chain for %s: %s", symbol, strings.Join(errs, "; "))
+}
+
+// breakerProvider is implemented by providers that guard their calls with a CircuitBreaker
+type breakerProvider interface {
+	CircuitBreaker() *CircuitBreaker
+}
+
+// Breakers returns the circuit breaker of every registered provider that has one
+func (p *ProviderPool) Breakers() map[string]*CircuitBreaker {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	breakers := make(map[string]*CircuitBreaker)
+	for _, entry := range p.entries {
+		if bp, ok := entry.provider.(breakerProvider); ok && bp.CircuitBreaker() != nil {
+			breakers[entry.provider.Name()] = bp.CircuitBreaker()
+		}
+	}
+	return breakers
+}
+
+// NewProviderPoolFromConfig builds the configured provider pool for tools that
+// run outside the collector, sharing its Redis rate limit buckets when cache
+// is set and RateLimitMode is "redis"
+func NewProviderPoolFromConfig(cfg *config.Config, cache *storage.RedisCache) *ProviderPool {
+	return newProviderPool(cfg, newRateLimiters(cfg, cache))
+}
+
+// newProviderPool builds the quote provider pool from configuration, handing
+// each client its shared rate limiter and the configured breaker thresholds
+func newProviderPool(cfg *config.Config, limiters map[string]*RateLimiter) *ProviderPool {
+	pool := NewProviderPool(cfg.QuoteProviderStrategy, cfg.MaxQuoteAge)
+	breakerConfig := circuitBreakerConfigFrom(cfg)
+
+	for priority, name := range cfg.QuoteProviders {
+		var provider QuoteProvider
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "yahoo":
+			client := NewYahooFinanceClient()
+			client.rateLimiter = limiters[client.Name()]
+			client.breaker.SetConfig(breakerConfig)
+			provider = client
+		case "alphavantage":
+			if cfg.AlphaVantageAPIKey == "" {
+				log.Printf("Skipping alphavantage provider: ALPHA_VANTAGE_API_KEY not set")
+				continue
+			}
+			client := NewAlphaVantageClient(cfg.AlphaVantageAPIKey)
+			client.rateLimiter = limiters[client.Name()]
+			client.breaker.SetConfig(breakerConfig)
+			provider = client
+		case "iex":
+			if cfg.IEXCloudAPIKey == "" {
+				log.Printf("Skipping iex provider: IEX_CLOUD_API_KEY not set")
+				continue
+			}
+			client := NewIEXCloudClient(cfg.IEXCloudAPIKey)
+			client.rateLimiter = limiters[client.Name()]
+			client.breaker.SetConfig(breakerConfig)
+			provider = client
+		case "synthetic":
+			provider = NewSyntheticProvider(SyntheticOptionsFrom(cfg))
+		default:
+			log.Printf("Unknown quote provider %q in configuration", name)
+			continue
+		}
+
+		pool.Register(provider, priority, cfg.QuoteProviderWeights[provider.Name()])
+	}
+
+	return pool
+}
+
+// backfillPeriod returns the shortest provider period that reaches back to start
+func backfillPeriod(start, now time.Time) string {
+	for _, period := range []string{"5d", "1mo", "3mo", "6mo", "1y", "2y", "5y", "10y"} {
+		from, _ := periodStart(period, now)
+		if !from.After(start) {
+			return period
+		}
+	}
+	return "max"
+}
+
+// periodStart converts a Yahoo-style period (1d, 5d, 1mo, ..., ytd, max) into a start time
+func periodStart(period string, now time.Time) (time.Time, error) {
+	switch period {
+	case "1d":
+		return now.AddDate(0, 0, -1), nil
+	case "5d":
+		return now.AddDate(0, 0, -5), nil
+	case "1mo":
+		return now.AddDate(0, -1, 0), nil
+	case "3mo":
+		return now.AddDate(0, -3, 0), nil
+	case "6mo":
+		return now.AddDate(0, -6, 0), nil
+	case "1y":
+		return now.AddDate(-1, 0, 0), nil
+	case "2y":
+		return now.AddDate(-2, 0, 0), nil
+	case "5y":
+		return now.AddDate(-5, 0, 0), nil
+	case "10y":
+		return now.AddDate(-10, 0, 0), nil
+	case "ytd":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), nil
+	case "max":
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported period: %s", period)
+	}
+}
@@ -0,0 +1,240 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A Friday, half an hour into the NYSE session
+var poolNow = time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+// fakeProvider quotes the symbols in prices, stamped age before poolNow, and
+// records the symbols of each call
+type fakeProvider struct {
+	name   string
+	prices map[string]string
+	age    time.Duration
+	err    error
+	calls  [][]string
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) quote(symbol string) (*models.MarketData, bool) {
+	price, ok := f.prices[symbol]
+	if !ok {
+		return nil, false
+	}
+	return &models.MarketData{Symbol: symbol, Price: decimal.MustParse(price), Timestamp: poolNow.Add(-f.age)}, true
+}
+
+func (f *fakeProvider) GetQuote(ctx context.Context, symbol string) (*models.MarketData, error) {
+	f.calls = append(f.calls, []string{symbol})
+	if f.err != nil {
+		return nil, f.err
+	}
+	data, ok := f.quote(symbol)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s: %w", f.name, symbol, ErrSymbolNotFound)
+	}
+	return data, nil
+}
+
+func (f *fakeProvider) GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error) {
+	f.calls = append(f.calls, append([]string(nil), symbols...))
+	if f.err != nil {
+		return nil, f.err
+	}
+	var quotes []*models.MarketData
+	for _, symbol := range symbols {
+		if data, ok := f.quote(symbol); ok {
+			quotes = append(quotes, data)
+		}
+	}
+	return quotes, nil
+}
+
+func (f *fakeProvider) GetHistoricalData(ctx context.Context, symbol, period, interval string) ([]*models.MarketData, error) {
+	return nil, f.err
+}
+
+func (f *fakeProvider) GetAPIHealth(ctx context.Context) (bool, error) {
+	return f.err == nil, f.err
+}
+
+func newTestPool(strategy string, providers ...*fakeProvider) *ProviderPool {
+	pool := NewProviderPool(strategy, 15*time.Minute)
+	pool.rng = rand.New(rand.NewSource(1))
+	pool.now = func() time.Time { return poolNow }
+	for i, provider := range providers {
+		pool.Register(provider, i, 1)
+	}
+	return pool
+}
+
+func TestProviderPoolOrder(t *testing.T) {
+	pool := newTestPool(SelectByPriority)
+	pool.Register(&fakeProvider{name: "iex"}, 2, 1)
+	pool.Register(&fakeProvider{name: "yahoo"}, 0, 1)
+	pool.Register(&fakeProvider{name: "alphavantage"}, 1, 1)
+	assert.Equal(t, []string{"yahoo", "alphavantage", "iex"}, pool.Names())
+
+	pool.SetPaused("alphavantage", true)
+	var names []string
+	for _, provider := range pool.order() {
+		names = append(names, provider.Name())
+	}
+	assert.Equal(t, []string{"yahoo", "iex"}, names)
+
+	// Weighted, the heavier provider goes first in proportion to its weight
+	pool = newTestPool(SelectByWeight)
+	pool.Register(&fakeProvider{name: "yahoo"}, 0, 3)
+	pool.Register(&fakeProvider{name: "alphavantage"}, 1, 1)
+	first := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		order := pool.order()
+		require.Len(t, order, 2)
+		first[order[0].Name()]++
+	}
+	assert.InDelta(t, 3000, first["yahoo"], 150)
+	assert.InDelta(t, 1000, first["alphavantage"], 150)
+}
+
+func TestProviderPoolGetQuote(t *testing.T) {
+	down := errors.New("connection refused")
+	tests := []struct {
+		name       string
+		providers  []*fakeProvider
+		wantSource string
+		wantPrice  string
+		wantErr    string
+	}{
+		{
+			name: "first provider answers",
+			providers: []*fakeProvider{
+				{name: "yahoo", prices: map[string]string{"AAPL": "180.00"}},
+				{name: "alphavantage", prices: map[string]string{"AAPL": "180.10"}},
+			},
+			wantSource: "yahoo", wantPrice: "180.00",
+		},
+		{
+			name: "error fails over",
+			providers: []*fakeProvider{
+				{name: "yahoo", err: down},
+				{name: "alphavantage", prices: map[string]string{"AAPL": "180.10"}},
+			},
+			wantSource: "alphavantage", wantPrice: "180.10",
+		},
+		{
+			name: "stale quote fails over",
+			providers: []*fakeProvider{
+				{name: "yahoo", prices: map[string]string{"AAPL": "179.00"}, age: 20 * time.Minute},
+				{name: "alphavantage", prices: map[string]string{"AAPL": "180.10"}, age: time.Minute},
+			},
+			wantSource: "alphavantage", wantPrice: "180.10",
+		},
+		{
+			name: "freshest stale quote when nothing is fresh",
+			providers: []*fakeProvider{
+				{name: "yahoo", prices: map[string]string{"AAPL": "179.00"}, age: 25 * time.Minute},
+				{name: "alphavantage", prices: map[string]string{"AAPL": "179.50"}, age: 20 * time.Minute},
+				{name: "iex", err: down},
+			},
+			wantSource: "alphavantage", wantPrice: "179.50",
+		},
+		{
+			name: "every provider fails",
+			providers: []*fakeProvider{
+				{name: "yahoo", err: down},
+				{name: "alphavantage", prices: map[string]string{}},
+			},
+			wantErr: "connection refused; alphavantage: AAPL: symbol not found",
+		},
+		{
+			name:    "no providers",
+			wantErr: ErrNoProviders.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newTestPool(SelectByPriority, tt.providers...)
+			data, err := pool.GetQuote(context.Background(), "AAPL")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSource, data.Source)
+			assert.Equal(t, decimal.MustParse(tt.wantPrice), data.Price)
+		})
+	}
+}
+
+func TestProviderPoolGetMultipleQuotes(t *testing.T) {
+	yahoo := &fakeProvider{name: "yahoo", prices: map[string]string{"AAPL": "180.00", "MSFT": "410.00"}}
+	alphavantage := &fakeProvider{name: "alphavantage", prices: map[string]string{"MSFT": "410.20", "NVDA": "820.00"}}
+
+	// Yahoo has neither NVDA nor IBM; only those go to Alpha Vantage
+	pool := newTestPool(SelectByPriority, yahoo, alphavantage)
+	quotes, err := pool.GetMultipleQuotes(context.Background(), []string{"AAPL", "MSFT", "NVDA", "IBM"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "IBM")
+	require.Len(t, quotes, 3)
+	assert.Equal(t, [][]string{{"AAPL", "MSFT", "NVDA", "IBM"}}, yahoo.calls)
+	assert.Equal(t, [][]string{{"NVDA", "IBM"}}, alphavantage.calls)
+
+	sources := map[string]string{}
+	for _, quote := range quotes {
+		sources[quote.Symbol] = quote.Source
+	}
+	assert.Equal(t, map[string]string{"AAPL": "yahoo", "MSFT": "yahoo", "NVDA": "alphavantage"}, sources)
+
+	// Once every symbol is fresh the remaining providers are not asked
+	alphavantage.calls = nil
+	quotes, err = pool.GetMultipleQuotes(context.Background(), []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	assert.Len(t, quotes, 2)
+	assert.Empty(t, alphavantage.calls)
+
+	_, err = newTestPool(SelectByPriority).GetMultipleQuotes(context.Background(), []string{"AAPL"})
+	assert.ErrorIs(t, err, ErrNoProviders)
+}
+
+func TestProviderPoolStaleness(t *testing.T) {
+	pool := newTestPool(SelectByPriority)
+	friday := time.Date(2024, 3, 1, 21, 0, 0, 0, time.UTC) // 16:00 New York close
+	quote := &models.MarketData{Symbol: "AAPL", Timestamp: friday}
+
+	monday := time.Date(2024, 3, 4, 14, 30, 0, 0, time.UTC) // the next open
+	tests := []struct {
+		name  string
+		now   time.Time
+		stale bool
+	}{
+		{"just after the close", friday.Add(10 * time.Minute), false},
+		{"after hours", friday.Add(3 * time.Hour), false},
+		{"over the weekend", monday.Add(10 * time.Minute), false},
+		{"twenty minutes into the next session", monday.Add(20 * time.Minute), true},
+		{"a year later", monday.AddDate(1, 0, 0), true},
+	}
+	for _, tt := range tests {
+		pool.now = func() time.Time { return tt.now }
+		assert.Equal(t, tt.stale, pool.isStale(quote), tt.name)
+	}
+
+	// Crypto trades around the clock
+	pool.now = func() time.Time { return friday.Add(20 * time.Minute) }
+	assert.True(t, pool.isStale(&models.MarketData{Symbol: "BTC-USD", Timestamp: friday}))
+	assert.True(t, pool.isStale(&models.MarketData{Symbol: "AAPL"}), "quotes without a time are stale")
+}
@@ -0,0 +1,256 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+// RateLimiter enforces a token bucket per provider plus optional tighter buckets
+// per endpoint. In distributed mode the buckets live in Redis so every collector
+// replica draws from the same allowance; in local mode they are per process.
+type RateLimiter struct {
+	name      string
+	limit     config.RateLimit
+	endpoints map[string]config.RateLimit
+	cache     *storage.RedisCache // nil in local mode
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+
+	// Last observed state of the provider-wide bucket, for status reporting
+	lastRemaining float64
+	lastSeen      time.Time
+	now           func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a process-local limiter
+func NewRateLimiter(name string, limit config.RateLimit) *RateLimiter {
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+
+	return &RateLimiter{
+		name:          name,
+		limit:         limit,
+		endpoints:     make(map[string]config.RateLimit),
+		buckets:       make(map[string]*tokenBucket),
+		lastRemaining: float64(limit.Burst),
+		now:           time.Now,
+	}
+}
+
+// NewDistributedRateLimiter creates a limiter whose buckets are shared through Redis
+func NewDistributedRateLimiter(name string, limit config.RateLimit, cache *storage.RedisCache) *RateLimiter {
+	rl := NewRateLimiter(name, limit)
+	rl.cache = cache
+	return rl
+}
+
+// SetEndpointLimit adds a bucket that applies to one endpoint in addition to the provider bucket
+func (rl *RateLimiter) SetEndpointLimit(endpoint string, limit config.RateLimit) {
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Requests
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.endpoints[endpoint] = limit
+}
+
+// Wait blocks until both the endpoint bucket (if any) and the provider bucket
+// grant a token, or the context ends. Both tokens are taken together, so a
+// wait given up on the provider bucket leaves the endpoint's allowance intact.
+func (rl *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+	rl.mu.Lock()
+	endpointLimit, hasEndpoint := rl.endpoints[endpoint]
+	rl.mu.Unlock()
+
+	// The provider bucket goes last: take reports its level for Status
+	buckets := []bucketLimit{{rl.name, rl.limit}}
+	if hasEndpoint {
+		buckets = []bucketLimit{{rl.name + ":" + endpoint, endpointLimit}, {rl.name, rl.limit}}
+	}
+
+	for {
+		allowed, wait, err := rl.take(ctx, buckets)
+		if err != nil {
+			// Fail closed: without the shared bucket we cannot prove the
+			// cluster-wide limit holds, so the request is not made
+			return err
+		}
+		if allowed {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &ProviderError{Provider: rl.name, Message: "rate limit wait cancelled", Err: ErrRateLimited}
+		case <-timer.C:
+		}
+	}
+}
+
+// Allow takes a token from the provider bucket without blocking and reports
+// how long to wait when none is available
+func (rl *RateLimiter) Allow(ctx context.Context) (bool, time.Duration, error) {
+	return rl.take(ctx, []bucketLimit{{rl.name, rl.limit}})
+}
+
+// bucketLimit names a bucket and its allowance
+type bucketLimit struct {
+	name  string
+	limit config.RateLimit
+}
+
+func (b bucketLimit) refillPerSecond() float64 {
+	return float64(b.limit.Requests) / b.limit.Per.Seconds()
+}
+
+// take draws a token from every bucket or, when any of them is empty, from
+// none. The last bucket must be the provider bucket.
+func (rl *RateLimiter) take(ctx context.Context, buckets []bucketLimit) (bool, time.Duration, error) {
+	var allowed bool
+	var remaining float64
+	var wait time.Duration
+
+	if rl.cache != nil {
+		shared := make([]storage.TokenBucket, len(buckets))
+		for i, b := range buckets {
+			shared[i] = storage.TokenBucket{Name: b.name, Capacity: b.limit.Burst, RefillPerSecond: b.refillPerSecond()}
+		}
+		var err error
+		allowed, remaining, wait, err = rl.cache.TakeTokens(ctx, shared, 1)
+		if err != nil {
+			log.Printf("Distributed rate limiter for %s unavailable: %v", rl.name, err)
+			return false, 0, fmt.Errorf("rate limiter %s: %w", rl.name, err)
+		}
+	} else {
+		allowed, remaining, wait = rl.takeLocal(buckets)
+	}
+
+	rl.mu.Lock()
+	rl.lastRemaining = remaining
+	rl.lastSeen = rl.now()
+	rl.mu.Unlock()
+
+	// Never spin on a zero wait
+	if !allowed && wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+	return allowed, wait, nil
+}
+
+func (rl *RateLimiter) takeLocal(buckets []bucketLimit) (bool, float64, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	states := make([]*tokenBucket, len(buckets))
+	short := false
+	var wait time.Duration
+	for i, b := range buckets {
+		state, ok := rl.buckets[b.name]
+		if !ok {
+			state = &tokenBucket{tokens: float64(b.limit.Burst), last: now}
+			rl.buckets[b.name] = state
+		}
+
+		elapsed := now.Sub(state.last).Seconds()
+		if elapsed > 0 {
+			state.tokens = math.Min(float64(b.limit.Burst), state.tokens+elapsed*b.refillPerSecond())
+			state.last = now
+		}
+		if state.tokens < 1 {
+			short = true
+			if w := time.Duration((1 - state.tokens) / b.refillPerSecond() * float64(time.Second)); w > wait {
+				wait = w
+			}
+		}
+		states[i] = state
+	}
+
+	provider := states[len(states)-1]
+	if short {
+		return false, provider.tokens, wait
+	}
+	for _, state := range states {
+		state.tokens--
+	}
+	return true, provider.tokens, 0
+}
+
+// Status reports the provider bucket as of the last request: tokens spent,
+// when it will be full again, and the burst size
+func (rl *RateLimiter) Status() (requests int, resetTime time.Time, limit int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	refillPerSecond := float64(rl.limit.Requests) / rl.limit.Per.Seconds()
+	remaining := rl.lastRemaining
+	if !rl.lastSeen.IsZero() {
+		remaining = math.Min(float64(rl.limit.Burst), remaining+rl.now().Sub(rl.lastSeen).Seconds()*refillPerSecond)
+	}
+
+	missing := float64(rl.limit.Burst) - remaining
+	resetTime = rl.now().Add(time.Duration(missing / refillPerSecond * float64(time.Second)))
+	return int(math.Ceil(missing)), resetTime, rl.limit.Burst
+}
+
+// newRateLimiters builds one limiter per configured provider, attaching any
+// "provider:endpoint" limits to their provider
+func newRateLimiters(cfg *config.Config, cache *storage.RedisCache) map[string]*RateLimiter {
+	limiters := make(map[string]*RateLimiter)
+	distributed := cfg.RateLimitMode == "redis" && cache != nil
+
+	build := func(name string) *RateLimiter {
+		if rl, ok := limiters[name]; ok {
+			return rl
+		}
+		limit, ok := cfg.RateLimits[name]
+		if !ok || limit.Requests <= 0 || limit.Per <= 0 {
+			perSecond := cfg.MaxRequestsPerSecond
+			if perSecond <= 0 {
+				perSecond = 1
+			}
+			limit = config.RateLimit{Requests: perSecond, Per: time.Second, Burst: perSecond}
+		}
+
+		var rl *RateLimiter
+		if distributed {
+			rl = NewDistributedRateLimiter(name, limit, cache)
+		} else {
+			rl = NewRateLimiter(name, limit)
+		}
+		limiters[name] = rl
+		return rl
+	}
+
+	for _, name := range cfg.QuoteProviders {
+		build(strings.ToLower(strings.TrimSpace(name)))
+	}
+	for key, limit := range cfg.RateLimits {
+		provider, endpoint, ok := strings.Cut(key, ":")
+		if !ok || limit.Requests <= 0 || limit.Per <= 0 {
+			build(provider)
+			continue
+		}
+		build(provider).SetEndpointLimit(endpoint, limit)
+	}
+
+	return limiters
+}
@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(limit config.RateLimit) (*RateLimiter, *time.Time) {
+	now := time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)
+	rl := NewRateLimiter("test", limit)
+	rl.now = func() time.Time { return now }
+	return rl, &now
+}
+
+func TestRateLimiter_AlphaVantageFreeTier(t *testing.T) {
+	rl, now := newTestLimiter(config.RateLimit{Requests: 5, Per: time.Minute, Burst: 1})
+	ctx := context.Background()
+
+	allowed, _, err := rl.Allow(ctx)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// Second call in the same instant must wait a full 12s refill
+	allowed, wait, err := rl.Allow(ctx)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 12*time.Second, wait)
+
+	// Walk one minute forward in 1s steps: never more than 5 grants
+	granted := 0
+	for i := 0; i < 60; i++ {
+		*now = now.Add(time.Second)
+		if ok, _, _ := rl.Allow(ctx); ok {
+			granted++
+		}
+	}
+	assert.Equal(t, 5, granted)
+}
+
+func TestRateLimiter_EndpointBucketIsSeparate(t *testing.T) {
+	rl, _ := newTestLimiter(config.RateLimit{Requests: 10, Per: time.Second})
+	rl.SetEndpointLimit("/v8/finance/chart", config.RateLimit{Requests: 1, Per: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, rl.Wait(ctx, "/v8/finance/chart"))
+
+	// The endpoint bucket is empty, so this waits until the context expires
+	err := rl.Wait(ctx, "/v8/finance/chart")
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	// Other endpoints still draw from the provider bucket only
+	require.NoError(t, rl.Wait(context.Background(), "/v7/finance/quote"))
+
+	used, _, limit := rl.Status()
+	assert.Equal(t, 2, used)
+	assert.Equal(t, 10, limit)
+}
+
+func TestRateLimiter_CancelledWaitKeepsEndpointToken(t *testing.T) {
+	rl, _ := newTestLimiter(config.RateLimit{Requests: 1, Per: time.Minute})
+	rl.SetEndpointLimit("/v7/finance/options", config.RateLimit{Requests: 2, Per: time.Minute})
+
+	require.NoError(t, rl.Wait(context.Background(), "/v7/finance/options"))
+
+	// The provider bucket is empty; giving up must not spend the endpoint's
+	// remaining token
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, rl.Wait(ctx, "/v7/finance/options"), ErrRateLimited)
+	assert.Equal(t, 1.0, rl.buckets["test:/v7/finance/options"].tokens)
+}
+
+func TestRateLimiter_DistributedBucketIsShared(t *testing.T) {
+	server := miniredis.RunT(t)
+	now := time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)
+	server.SetTime(now)
+	cache, err := storage.NewRedisCache("redis://" + server.Addr())
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// Two replicas of the Alpha Vantage free tier draw on one allowance
+	limit := config.RateLimit{Requests: 5, Per: time.Minute}
+	replicas := []*RateLimiter{
+		NewDistributedRateLimiter("alphavantage", limit, cache),
+		NewDistributedRateLimiter("alphavantage", limit, cache),
+	}
+	ctx := context.Background()
+
+	granted := 0
+	for i := 0; i < 10; i++ {
+		if ok, _, err := replicas[i%2].Allow(ctx); assert.NoError(t, err) && ok {
+			granted++
+		}
+	}
+	assert.Equal(t, 5, granted)
+
+	allowed, wait, err := replicas[1].Allow(ctx)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 12*time.Second, wait)
+
+	// Time comes from Redis: one refill later either replica gets the token
+	server.SetTime(now.Add(12 * time.Second))
+	allowed, _, err = replicas[0].Allow(ctx)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, _, err = replicas[1].Allow(ctx)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// With the shared provider bucket drained, a wait given up on leaves the
+	// endpoint bucket untouched
+	for _, rl := range replicas {
+		rl.SetEndpointLimit("/query", config.RateLimit{Requests: 2, Per: time.Minute})
+	}
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, replicas[1].Wait(short, "/query"), ErrRateLimited)
+	assert.Equal(t, "2", server.HGet("ratelimit:bucket:alphavantage:/query", "tokens"))
+}
@@ -0,0 +1,416 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+// Replay clock alignments
+const (
+	ReplayClockNow      = "now"      // ticks are stamped with the moment they are replayed
+	ReplayClockRecorded = "recorded" // ticks keep the timestamps they were recorded with
+)
+
+// ReplayProvider is a quote provider that plays back recorded ticks and bars
+// for offline runs. Next releases the recorded moments in order, paced by the
+// replay speed; the quote methods then answer with the latest tick released
+// for each symbol, the way a live provider answers with the last trade.
+type ReplayProvider struct {
+	ticks []*models.MarketData // by recorded timestamp
+	speed float64              // multiple of real time, 0 for as fast as possible
+	clock string
+	now   func() time.Time
+
+	mu       sync.RWMutex
+	next     int       // first tick not released yet
+	started  time.Time // wall time the first moment was released
+	current  time.Time // the replay clock at the last release
+	latest   map[string]*models.MarketData
+	released map[string][]*models.MarketData // per symbol, oldest first
+}
+
+// NewReplayProvider plays back ticks at speed times real time, or as fast as
+// they are asked for when speed is 0. Ticks without a symbol or timestamp
+// are dropped; ticks without a source are attributed to the replay.
+func NewReplayProvider(ticks []*models.MarketData, speed float64, clock string) *ReplayProvider {
+	sorted := make([]*models.MarketData, 0, len(ticks))
+	for _, tick := range ticks {
+		if tick == nil || tick.Symbol == "" || tick.Timestamp.IsZero() {
+			continue
+		}
+		recorded := *tick
+		if recorded.Source == "" {
+			recorded.Source = "replay"
+		}
+		sorted = append(sorted, &recorded)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	return &ReplayProvider{
+		ticks:    sorted,
+		speed:    speed,
+		clock:    clock,
+		now:      time.Now,
+		latest:   make(map[string]*models.MarketData),
+		released: make(map[string][]*models.MarketData),
+	}
+}
+
+// NewReplayProviderFromConfig loads ReplayFile and plays it back at
+// ReplaySpeed on the ReplayClock
+func NewReplayProviderFromConfig(cfg *config.Config) (*ReplayProvider, error) {
+	speed, err := config.ParseReplaySpeed(cfg.ReplaySpeed)
+	if err != nil {
+		return nil, err
+	}
+	ticks, err := LoadReplayFile(cfg.ReplayFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(ticks) == 0 {
+		return nil, fmt.Errorf("replay file %s has no ticks", cfg.ReplayFile)
+	}
+	return NewReplayProvider(ticks, speed, cfg.ReplayClock), nil
+}
+
+// Name identifies the source in MarketData.Source and in configuration
+func (r *ReplayProvider) Name() string {
+	return "replay"
+}
+
+// Len returns the number of ticks in the recording
+func (r *ReplayProvider) Len() int {
+	return len(r.ticks)
+}
+
+// Next waits until the next recorded moment is due and releases its ticks,
+// returning their symbols. Moments are due at their recorded offset from the
+// first one divided by the speed, or at once when replaying as fast as
+// possible. It returns io.EOF once the recording is exhausted.
+func (r *ReplayProvider) Next(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	if r.next >= len(r.ticks) {
+		r.mu.Unlock()
+		return nil, io.EOF
+	}
+	if r.started.IsZero() {
+		r.started = r.now()
+	}
+	at := r.ticks[r.next].Timestamp
+	due := r.started
+	if r.speed > 0 {
+		due = r.started.Add(time.Duration(float64(at.Sub(r.ticks[0].Timestamp)) / r.speed))
+	}
+	r.mu.Unlock()
+
+	if wait := due.Sub(r.now()); r.speed > 0 && wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stamp := at
+	if r.clock != ReplayClockRecorded {
+		stamp = due
+		if r.speed == 0 {
+			stamp = r.now()
+		}
+	}
+	r.current = stamp
+
+	var symbols []string
+	for ; r.next < len(r.ticks) && r.ticks[r.next].Timestamp.Equal(at); r.next++ {
+		tick := *r.ticks[r.next]
+		tick.Timestamp = stamp
+		if !containsSymbol(symbols, tick.Symbol) {
+			symbols = append(symbols, tick.Symbol)
+		}
+		r.latest[tick.Symbol] = &tick
+		r.released[tick.Symbol] = append(r.released[tick.Symbol], &tick)
+	}
+	return symbols, nil
+}
+
+func containsSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ReplayProvider) GetQuote(ctx context.Context, symbol string) (*models.MarketData, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tick, ok := r.latest[symbol]
+	if !ok {
+		return nil, &ProviderError{Provider: r.Name(), Message: symbol + " has not been replayed yet", Err: ErrSymbolNotFound}
+	}
+	quote := *tick
+	return &quote, nil
+}
+
+// GetMultipleQuotes returns the symbols replayed so far and leaves the
+// others out
+func (r *ReplayProvider) GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var quotes []*models.MarketData
+	for _, symbol := range symbols {
+		if tick, ok := r.latest[symbol]; ok {
+			quote := *tick
+			quotes = append(quotes, &quote)
+		}
+	}
+	return quotes, nil
+}
+
+// GetHistoricalData returns the symbol's ticks released within period of the
+// replay clock, at the recording's own resolution whatever the interval
+func (r *ReplayProvider) GetHistoricalData(ctx context.Context, symbol string, period string, interval string) ([]*models.MarketData, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	from, err := periodStart(period, r.current)
+	if err != nil {
+		return nil, err
+	}
+	var history []*models.MarketData
+	for _, tick := range r.released[symbol] {
+		if !tick.Timestamp.Before(from) {
+			bar := *tick
+			history = append(history, &bar)
+		}
+	}
+	return history, nil
+}
+
+// GetAPIHealth is always healthy: the recording is in memory
+func (r *ReplayProvider) GetAPIHealth(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// replayPool is the provider pool of a replayed run: the recording is its
+// only provider, and quotes are as fresh as the replay clock says
+func replayPool(replay *ReplayProvider) *ProviderPool {
+	pool := NewProviderPool(SelectByPriority, 0)
+	pool.Register(replay, 0, 1)
+	return pool
+}
+
+// UseReplay switches the collector to an offline run: replay becomes the only
+// quote provider and drives equity collection in place of the market
+// calendar. Call it before starting the collection loops.
+func (dc *DataCollector) UseReplay(replay *ReplayProvider) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.replay = replay
+	dc.providers = replayPool(replay)
+}
+
+func (dc *DataCollector) replaySource() *ReplayProvider {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.replay
+}
+
+// runReplay collects each recorded moment through CollectStockData as soon as
+// the replay releases it, so replayed quotes are processed, logged to the
+// WAL, cached, stored and published like polled ones. Every recorded symbol
+// is collected, whether or not it is in StockSymbols.
+func (dc *DataCollector) runReplay(ctx context.Context, replay *ReplayProvider) {
+	cfg := dc.currentConfig()
+	log.Printf("Replaying %d recorded ticks from %s at %s, clock %s", replay.Len(), cfg.ReplayFile, cfg.ReplaySpeed, cfg.ReplayClock)
+
+	moments := 0
+	for {
+		symbols, err := replay.Next(ctx)
+		if err == io.EOF {
+			log.Printf("Replay finished after %d moments", moments)
+			<-ctx.Done()
+			return
+		}
+		if err != nil {
+			return
+		}
+		moments++
+
+		if dc.isPaused(ServiceMarket) {
+			continue
+		}
+		if err := dc.CollectStockData(ctx, symbols); err != nil && ctx.Err() == nil {
+			dc.HandleCollectionError(ctx, err, "stocks", symbols)
+		}
+	}
+}
+
+// Recording Formats
+
+// LoadReplayFile reads a recording, choosing the format by extension: .csv,
+// .ndjson or .jsonl (one MarketData JSON object per line, as published to
+// Kafka), or .wal for a BadgerWAL export. Bars without a price are priced at
+// their close.
+func LoadReplayFile(path string) ([]*models.MarketData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return parseReplayCSV(f)
+	case ".ndjson", ".jsonl", ".json":
+		return parseReplayNDJSON(f)
+	case ".wal":
+		return storage.ReadWALExport(f)
+	default:
+		return nil, fmt.Errorf("unknown replay file format %q: use .csv, .ndjson, .jsonl or .wal", ext)
+	}
+}
+
+// parseReplayCSV reads a CSV with a header naming its columns: symbol and
+// timestamp, price or close, and optionally open, high, low, volume, change,
+// change_percent, market_cap, bid, ask, bid_size, ask_size, prev_close,
+// exchange and source
+func parseReplayCSV(r io.Reader) ([]*models.MarketData, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["time"]; ok {
+		columns["timestamp"] = columns["time"]
+	}
+	for _, required := range []string{"symbol", "timestamp"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("replay CSV has no %s column", required)
+		}
+	}
+	_, hasPrice := columns["price"]
+	_, hasClose := columns["close"]
+	if !hasPrice && !hasClose {
+		return nil, fmt.Errorf("replay CSV has neither a price nor a close column")
+	}
+
+	var ticks []*models.MarketData
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return ticks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("replay CSV line %d: %w", line, err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) float64 {
+			value, _ := strconv.ParseFloat(field(name), 64)
+			return value
+		}
+		price := func(name string) decimal.Decimal {
+			value, _ := decimal.Parse(field(name))
+			return value
+		}
+
+		timestamp, err := parseReplayTime(field("timestamp"))
+		if err != nil {
+			return nil, fmt.Errorf("replay CSV line %d: %w", line, err)
+		}
+		tick := &models.MarketData{
+			Symbol:        strings.ToUpper(field("symbol")),
+			Price:         price("price"),
+			Volume:        int64(number("volume")),
+			High:          price("high"),
+			Low:           price("low"),
+			Open:          price("open"),
+			Close:         price("close"),
+			Change:        price("change"),
+			ChangePercent: price("change_percent"),
+			MarketCap:     int64(number("market_cap")),
+			Bid:           price("bid"),
+			Ask:           price("ask"),
+			BidSize:       int64(number("bid_size")),
+			AskSize:       int64(number("ask_size")),
+			PrevClose:     price("prev_close"),
+			Exchange:      field("exchange"),
+			Timestamp:     timestamp,
+			Source:        field("source"),
+		}
+		if tick.Price.IsZero() {
+			tick.Price = tick.Close
+		}
+		ticks = append(ticks, tick)
+	}
+}
+
+// parseReplayTime accepts RFC 3339, "2006-01-02 15:04:05" and plain dates in
+// UTC, and Unix seconds or milliseconds
+func parseReplayTime(value string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if unix > 1e11 {
+			return time.UnixMilli(unix).UTC(), nil
+		}
+		return time.Unix(unix, 0).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
+
+func parseReplayNDJSON(r io.Reader) ([]*models.MarketData, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	var ticks []*models.MarketData
+	for {
+		var tick models.MarketData
+		if err := decoder.Decode(&tick); err == io.EOF {
+			return ticks, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("replay NDJSON record %d: %w", len(ticks)+1, err)
+		}
+		if tick.Price.IsZero() {
+			tick.Price = tick.Close
+		}
+		ticks = append(ticks, &tick)
+	}
+}
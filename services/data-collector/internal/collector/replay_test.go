@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReplayFile(t *testing.T) {
+	ticks, err := LoadReplayFile(filepath.Join("testdata", "replay", "ticks.csv"))
+	require.NoError(t, err)
+	require.Len(t, ticks, 5)
+	assert.Equal(t, "AAPL", ticks[0].Symbol)
+	assert.Equal(t, decimal.MustParse("227.48"), ticks[0].Price)
+	assert.Equal(t, int64(1200), ticks[0].Volume)
+	assert.Equal(t, time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC), ticks[0].Timestamp)
+	// Unix milliseconds
+	assert.Equal(t, time.Date(2025, 3, 10, 14, 30, 3, 0, time.UTC), ticks[4].Timestamp)
+	assert.Equal(t, "iex", ticks[4].Source)
+
+	bars, err := LoadReplayFile(filepath.Join("testdata", "replay", "bars.ndjson"))
+	require.NoError(t, err)
+	require.Len(t, bars, 2)
+	assert.Equal(t, decimal.MustParse("574.08"), bars[0].Price, "bars are priced at their close")
+	assert.Equal(t, decimal.MustParse("576.90"), bars[1].High)
+
+	_, err = LoadReplayFile(filepath.Join("testdata", "replay", "ticks.txt"))
+	assert.Error(t, err)
+}
+
+func TestReplayProviderRecordedClock(t *testing.T) {
+	ticks, err := LoadReplayFile(filepath.Join("testdata", "replay", "ticks.csv"))
+	require.NoError(t, err)
+	replay := NewReplayProvider(ticks, 0, ReplayClockRecorded)
+	ctx := context.Background()
+
+	_, err = replay.GetQuote(ctx, "AAPL")
+	assert.True(t, errors.Is(err, ErrSymbolNotFound), "nothing is quoted before the first moment")
+
+	symbols, err := replay.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, symbols)
+
+	symbols, err = replay.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL"}, symbols)
+	quote, err := replay.GetQuote(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, decimal.MustParse("227.52"), quote.Price)
+	assert.Equal(t, time.Date(2025, 3, 10, 14, 30, 1, 0, time.UTC), quote.Timestamp)
+
+	symbols, err = replay.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, symbols)
+	quotes, err := replay.GetMultipleQuotes(ctx, []string{"AAPL", "MSFT", "TSLA"})
+	require.NoError(t, err)
+	require.Len(t, quotes, 2)
+	assert.Equal(t, decimal.MustParse("388.70"), quotes[1].Price)
+
+	history, err := replay.GetHistoricalData(ctx, "AAPL", "1d", "1m")
+	require.NoError(t, err)
+	assert.Len(t, history, 3)
+
+	_, err = replay.Next(ctx)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReplayProviderPacing(t *testing.T) {
+	start := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	ticks, err := LoadReplayFile(filepath.Join("testdata", "replay", "ticks.csv"))
+	require.NoError(t, err)
+	// 3s of recording at 100x takes 30ms
+	replay := NewReplayProvider(ticks, 100, ReplayClockNow)
+	ctx := context.Background()
+
+	began := time.Now()
+	var stamps []time.Time
+	for {
+		if _, err := replay.Next(ctx); err == io.EOF {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+		quote, err := replay.GetQuote(ctx, "AAPL")
+		require.NoError(t, err)
+		stamps = append(stamps, quote.Timestamp)
+	}
+	assert.GreaterOrEqual(t, time.Since(began), 30*time.Millisecond)
+
+	require.Len(t, stamps, 3)
+	assert.True(t, stamps[0].After(start), "ticks are stamped with the wall clock")
+	assert.Equal(t, 10*time.Millisecond, stamps[1].Sub(stamps[0]))
+	assert.Equal(t, 30*time.Millisecond, stamps[2].Sub(stamps[0]))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	slow := NewReplayProvider(ticks, 1, ReplayClockNow)
+	_, err = slow.Next(cancelled)
+	require.NoError(t, err, "the first moment is due at once")
+	_, err = slow.Next(cancelled)
+	assert.Equal(t, context.Canceled, err)
+}
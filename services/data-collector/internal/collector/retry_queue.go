@@ -0,0 +1,756 @@
+package collector
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v3"
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// Retry operations understood by RetryFailedCollection
+const (
+	RetryOpQuotes           = "quotes"
+	RetryOpCryptoPrices     = "crypto_prices"
+	RetryOpSeries           = "series"
+	RetryOpFilings          = "filings"
+	RetryOpCorporateActions = "corporate_actions"
+	RetryOpOptionChains     = "option_chains"
+)
+
+// retryOperations is the operation a failed collection from each source is
+// retried with; failures from other sources are not queued
+var retryOperations = map[string]string{
+	"stocks":            RetryOpQuotes,
+	"crypto":            RetryOpCryptoPrices,
+	"economic":          RetryOpSeries,
+	"filings":           RetryOpFilings,
+	"corporate_actions": RetryOpCorporateActions,
+	"options":           RetryOpOptionChains,
+}
+
+// Higher priority retries are attempted first when several are due
+var retryPriorities = map[string]int{
+	"stocks":            3,
+	"crypto":            2,
+	"options":           2,
+	"economic":          1,
+	"filings":           1,
+	"corporate_actions": 1,
+	"news":              0,
+}
+
+var (
+	ErrRetryItemNotFound  = errors.New("retry item not found")
+	ErrRetryQueueDisabled = errors.New("retry queue not configured")
+)
+
+var (
+	retryItemPrefix = []byte("retry:item:")
+	retryDuePrefix  = []byte("retry:due:")
+	retryDeadPrefix = []byte("retry:dead:")
+)
+
+// CollectionError reports which symbols a collection run could not fetch, so
+// the caller can queue exactly those for retry
+type CollectionError struct {
+	Source  string
+	Symbols []string
+	Err     error
+}
+
+func (e *CollectionError) Error() string {
+	return fmt.Sprintf("%s collection failed for %d symbols: %v", e.Source, len(e.Symbols), e.Err)
+}
+
+func (e *CollectionError) Unwrap() error {
+	return e.Err
+}
+
+// RetryQueueConfig controls backoff and when an item is given up on
+type RetryQueueConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	TTL         time.Duration
+}
+
+// RetryQueueConfigFrom reads the queue settings from the service configuration
+func RetryQueueConfigFrom(cfg *config.Config) RetryQueueConfig {
+	return RetryQueueConfig{
+		MaxAttempts: cfg.RetryMaxAttempts,
+		BaseDelay:   cfg.RetryBaseDelay,
+		MaxDelay:    cfg.RetryMaxDelay,
+		TTL:         cfg.RetryItemTTL,
+	}
+}
+
+// DeadLetter is a retry item that ran out of attempts or expired
+type DeadLetter struct {
+	Item   RetryItem `json:"item"`
+	Reason string    `json:"reason"`
+	DeadAt time.Time `json:"dead_at"`
+}
+
+// RetryQueueStats are the queue health metrics
+type RetryQueueStats struct {
+	Depth       int           `json:"depth"`
+	Due         int           `json:"due"`
+	OldestAge   time.Duration `json:"oldest_age"`
+	DeadLetters int           `json:"dead_letters"`
+}
+
+// RetryQueue persists failed collections in BadgerDB so they survive restarts.
+// Items are indexed by next attempt time; dead letters are kept under their own
+// prefix until inspected and requeued or deleted.
+//
+// Key layout:
+//
+//	retry:item:<id>                 -> RetryItem JSON
+//	retry:due:<nextAttempt ns><id>  -> empty, ordered by due time
+//	retry:dead:<id>                 -> DeadLetter JSON
+type RetryQueue struct {
+	db  *badger.DB
+	cfg RetryQueueConfig
+
+	mu  sync.Mutex // serialises read-modify-write of items and the rng
+	rng *rand.Rand
+	now func() time.Time
+}
+
+// NewRetryQueue opens the queue at path; an empty path keeps it in memory
+func NewRetryQueue(path string, cfg RetryQueueConfig) (*RetryQueue, error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = 30 * time.Second
+	}
+	if cfg.MaxDelay < cfg.BaseDelay {
+		cfg.MaxDelay = cfg.BaseDelay
+	}
+
+	// Unlike the WAL hot path, retries are rare and must survive a crash
+	opts := badger.DefaultOptions(path).
+		WithSyncWrites(true).
+		WithLogger(nil)
+	if path == "" {
+		opts = opts.WithInMemory(true)
+	}
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open retry queue: %w", err)
+	}
+
+	return &RetryQueue{
+		db:  db,
+		cfg: cfg,
+		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		now: time.Now,
+	}, nil
+}
+
+// SetConfig applies new limits to attempts and expiries computed from now on
+func (q *RetryQueue) SetConfig(cfg RetryQueueConfig) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = q.cfg.MaxAttempts
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = q.cfg.BaseDelay
+	}
+	if cfg.MaxDelay < cfg.BaseDelay {
+		cfg.MaxDelay = cfg.BaseDelay
+	}
+	q.cfg = cfg
+}
+
+func (q *RetryQueue) Close() error {
+	return q.db.Close()
+}
+
+// Enqueue stores a new item, filling in ID, limits and the first attempt time
+func (q *RetryQueue) Enqueue(item *RetryItem) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	if item.ID == "" {
+		item.ID = q.newID(now)
+	}
+	if item.MaxAttempts <= 0 {
+		item.MaxAttempts = q.cfg.MaxAttempts
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = now
+	}
+	if item.ExpiresAt.IsZero() && q.cfg.TTL > 0 {
+		item.ExpiresAt = item.CreatedAt.Add(q.cfg.TTL)
+	}
+	if item.NextAttempt.IsZero() {
+		item.NextAttempt = now.Add(q.backoff(item.Attempts))
+	}
+
+	return q.db.Update(func(txn *badger.Txn) error {
+		return q.save(txn, item)
+	})
+}
+
+// Due returns up to limit items whose next attempt has arrived, highest priority
+// first. Expired items found along the way are moved to the dead-letter store.
+func (q *RetryQueue) Due(limit int) ([]*RetryItem, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var due, expired []*RetryItem
+
+	err := q.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = retryDuePrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(retryDuePrefix); it.ValidForPrefix(retryDuePrefix); it.Next() {
+			key := it.Item().Key()
+			at := int64(binary.BigEndian.Uint64(key[len(retryDuePrefix):]))
+			if at > now.UnixNano() {
+				break
+			}
+
+			item, err := q.load(txn, string(key[len(retryDuePrefix)+8:]))
+			if errors.Is(err, ErrRetryItemNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if !item.ExpiresAt.IsZero() && now.After(item.ExpiresAt) {
+				expired = append(expired, item)
+				continue
+			}
+			due = append(due, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan retry queue: %w", err)
+	}
+
+	for _, item := range expired {
+		if err := q.deadLetter(item, "expired", now); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		if due[i].Priority != due[j].Priority {
+			return due[i].Priority > due[j].Priority
+		}
+		return due[i].NextAttempt.Before(due[j].NextAttempt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+// Complete removes an item that has been retried successfully
+func (q *RetryQueue) Complete(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.db.Update(func(txn *badger.Txn) error {
+		item, err := q.load(txn, id)
+		if err != nil {
+			return err
+		}
+		return q.remove(txn, item)
+	})
+}
+
+// Fail records a failed attempt and reschedules the item with backoff, or moves
+// it to the dead-letter store once it is out of attempts or past its expiry.
+// It reports whether the item was dead-lettered.
+func (q *RetryQueue) Fail(item *RetryItem, cause error) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	item.Attempts++
+	item.LastAttempt = now
+	if cause != nil {
+		item.LastError = cause.Error()
+	}
+
+	switch {
+	case item.Attempts >= item.MaxAttempts:
+		return true, q.deadLetter(item, fmt.Sprintf("gave up after %d attempts", item.Attempts), now)
+	case !item.ExpiresAt.IsZero() && now.After(item.ExpiresAt):
+		return true, q.deadLetter(item, "expired", now)
+	}
+
+	item.NextAttempt = now.Add(q.backoff(item.Attempts))
+	return false, q.db.Update(func(txn *badger.Txn) error {
+		return q.save(txn, item)
+	})
+}
+
+// Defer pushes an item back without counting an attempt, e.g. while every
+// provider's circuit breaker is open
+func (q *RetryQueue) Defer(item *RetryItem, until time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item.NextAttempt = until
+	return q.db.Update(func(txn *badger.Txn) error {
+		return q.save(txn, item)
+	})
+}
+
+// Stats reports queue depth, how many items are due and the age of the oldest item
+func (q *RetryQueue) Stats() (RetryQueueStats, error) {
+	var stats RetryQueueStats
+	now := q.now()
+
+	err := q.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = retryItemPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(retryItemPrefix); it.ValidForPrefix(retryItemPrefix); it.Next() {
+			var item RetryItem
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &item)
+			}); err != nil {
+				return err
+			}
+
+			stats.Depth++
+			if !item.NextAttempt.After(now) {
+				stats.Due++
+			}
+			if age := now.Sub(item.CreatedAt); age > stats.OldestAge {
+				stats.OldestAge = age
+			}
+		}
+
+		deadOpts := badger.DefaultIteratorOptions
+		deadOpts.PrefetchValues = false
+		deadOpts.Prefix = retryDeadPrefix
+		dead := txn.NewIterator(deadOpts)
+		defer dead.Close()
+
+		for dead.Seek(retryDeadPrefix); dead.ValidForPrefix(retryDeadPrefix); dead.Next() {
+			stats.DeadLetters++
+		}
+		return nil
+	})
+
+	return stats, err
+}
+
+// DeadLetters lists up to limit dead-lettered items, oldest first
+func (q *RetryQueue) DeadLetters(limit int) ([]*DeadLetter, error) {
+	var letters []*DeadLetter
+
+	err := q.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = retryDeadPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(retryDeadPrefix); it.ValidForPrefix(retryDeadPrefix); it.Next() {
+			var letter DeadLetter
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &letter)
+			}); err != nil {
+				return err
+			}
+			letters = append(letters, &letter)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].DeadAt.Before(letters[j].DeadAt)
+	})
+	if limit > 0 && len(letters) > limit {
+		letters = letters[:limit]
+	}
+	return letters, nil
+}
+
+// RequeueDeadLetter puts a dead-lettered item back on the queue with a fresh
+// attempt budget and expiry
+func (q *RetryQueue) RequeueDeadLetter(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	return q.db.Update(func(txn *badger.Txn) error {
+		entry, err := txn.Get(deadKey(id))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrRetryItemNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var letter DeadLetter
+		if err := entry.Value(func(val []byte) error {
+			return json.Unmarshal(val, &letter)
+		}); err != nil {
+			return err
+		}
+
+		item := letter.Item
+		item.Attempts = 0
+		item.CreatedAt = now
+		item.NextAttempt = now
+		item.ExpiresAt = time.Time{}
+		if q.cfg.TTL > 0 {
+			item.ExpiresAt = now.Add(q.cfg.TTL)
+		}
+
+		if err := txn.Delete(deadKey(id)); err != nil {
+			return err
+		}
+		return q.save(txn, &item)
+	})
+}
+
+// DeleteDeadLetter discards a dead-lettered item for good
+func (q *RetryQueue) DeleteDeadLetter(id string) error {
+	return q.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(deadKey(id)); errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrRetryItemNotFound
+		}
+		return txn.Delete(deadKey(id))
+	})
+}
+
+// backoff is exponential in the attempt count, capped at MaxDelay, with
+// "equal jitter": half the delay is fixed and half is random, so replicas that
+// failed together do not retry together. Must be called with mu held.
+func (q *RetryQueue) backoff(attempt int) time.Duration {
+	delay := q.cfg.BaseDelay
+	for i := 0; i < attempt && delay < q.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > q.cfg.MaxDelay {
+		delay = q.cfg.MaxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(q.rng.Int63n(int64(half)+1))
+}
+
+// deadLetter must be called with mu held
+func (q *RetryQueue) deadLetter(item *RetryItem, reason string, now time.Time) error {
+	letter := DeadLetter{Item: *item, Reason: reason, DeadAt: now}
+	payload, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to serialize dead letter %s: %w", item.ID, err)
+	}
+
+	return q.db.Update(func(txn *badger.Txn) error {
+		if err := q.remove(txn, item); err != nil && !errors.Is(err, ErrRetryItemNotFound) {
+			return err
+		}
+		return txn.Set(deadKey(item.ID), payload)
+	})
+}
+
+// save writes the item and moves its due-index entry to the current NextAttempt
+func (q *RetryQueue) save(txn *badger.Txn, item *RetryItem) error {
+	if previous, err := q.load(txn, item.ID); err == nil {
+		if err := txn.Delete(dueKey(previous.NextAttempt, previous.ID)); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, ErrRetryItemNotFound) {
+		return err
+	}
+
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to serialize retry item %s: %w", item.ID, err)
+	}
+	if err := txn.Set(itemKey(item.ID), payload); err != nil {
+		return err
+	}
+	return txn.Set(dueKey(item.NextAttempt, item.ID), nil)
+}
+
+func (q *RetryQueue) remove(txn *badger.Txn, item *RetryItem) error {
+	stored, err := q.load(txn, item.ID)
+	if err != nil {
+		return err
+	}
+	if err := txn.Delete(dueKey(stored.NextAttempt, stored.ID)); err != nil {
+		return err
+	}
+	return txn.Delete(itemKey(stored.ID))
+}
+
+func (q *RetryQueue) load(txn *badger.Txn, id string) (*RetryItem, error) {
+	entry, err := txn.Get(itemKey(id))
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, ErrRetryItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var item RetryItem
+	if err := entry.Value(func(val []byte) error {
+		return json.Unmarshal(val, &item)
+	}); err != nil {
+		return nil, fmt.Errorf("corrupt retry item %s: %w", id, err)
+	}
+	return &item, nil
+}
+
+// newID is time-prefixed so IDs sort roughly by creation. Must be called with mu held.
+func (q *RetryQueue) newID(now time.Time) string {
+	suffix := make([]byte, 4)
+	q.rng.Read(suffix)
+	return fmt.Sprintf("%016x-%s", now.UnixNano(), hex.EncodeToString(suffix))
+}
+
+func itemKey(id string) []byte {
+	return append(append([]byte{}, retryItemPrefix...), id...)
+}
+
+func deadKey(id string) []byte {
+	return append(append([]byte{}, retryDeadPrefix...), id...)
+}
+
+func dueKey(at time.Time, id string) []byte {
+	key := make([]byte, len(retryDuePrefix)+8, len(retryDuePrefix)+8+len(id))
+	copy(key, retryDuePrefix)
+	binary.BigEndian.PutUint64(key[len(retryDuePrefix):], uint64(at.UnixNano()))
+	return append(key, id...)
+}
+
+// StartRetryProcessing drains due retries every RetryInterval and publishes the
+// queue metrics until ctx is cancelled
+func (dc *DataCollector) StartRetryProcessing(ctx context.Context) {
+	if dc.retryQueue == nil {
+		return
+	}
+
+	interval := dc.currentConfig().RetryInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dc.RetryFailedCollection(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Retry processing failed: %v", err)
+			}
+			dc.publishRetryMetrics(ctx)
+		}
+	}
+}
+
+// RetryQueueStats exposes the durable retry queue metrics
+func (dc *DataCollector) RetryQueueStats() (RetryQueueStats, error) {
+	if dc.retryQueue == nil {
+		return RetryQueueStats{}, nil
+	}
+	return dc.retryQueue.Stats()
+}
+
+// DeadLetters lists up to limit dead-lettered retries, oldest first
+func (dc *DataCollector) DeadLetters(limit int) ([]*DeadLetter, error) {
+	if dc.retryQueue == nil {
+		return nil, ErrRetryQueueDisabled
+	}
+	return dc.retryQueue.DeadLetters(limit)
+}
+
+// RequeueDeadLetter gives a dead-lettered retry a fresh set of attempts
+func (dc *DataCollector) RequeueDeadLetter(id string) error {
+	if dc.retryQueue == nil {
+		return ErrRetryQueueDisabled
+	}
+	return dc.retryQueue.RequeueDeadLetter(id)
+}
+
+// DeleteDeadLetter discards a dead-lettered retry
+func (dc *DataCollector) DeleteDeadLetter(id string) error {
+	if dc.retryQueue == nil {
+		return ErrRetryQueueDisabled
+	}
+	return dc.retryQueue.DeleteDeadLetter(id)
+}
+
+func (dc *DataCollector) publishRetryMetrics(ctx context.Context) {
+	stats, err := dc.retryQueue.Stats()
+	if err != nil {
+		log.Printf("Failed to read retry queue stats: %v", err)
+		return
+	}
+	if dc.producer == nil {
+		return
+	}
+
+	metrics := map[string]float64{
+		"retry_queue_depth":              float64(stats.Depth),
+		"retry_queue_due":                float64(stats.Due),
+		"retry_queue_oldest_age_seconds": stats.OldestAge.Seconds(),
+		"retry_queue_dead_letters":       float64(stats.DeadLetters),
+	}
+	for metric, value := range metrics {
+		if err := dc.producer.PublishSystemMetric(ctx, "data-collector", metric, value, nil); err != nil {
+			log.Printf("Failed to publish %s: %v", metric, err)
+		}
+	}
+}
+
+// runRetry replays the original request of a retry item
+func (dc *DataCollector) runRetry(ctx context.Context, item *RetryItem) error {
+	if op, ok := retryOperations[item.Source]; !ok || item.Operation != op {
+		return fmt.Errorf("unsupported retry operation %s/%s", item.Source, item.Operation)
+	}
+
+	switch item.Source {
+	case "stocks":
+		return dc.CollectStockData(ctx, item.Symbols)
+	case "crypto":
+		return dc.CollectCryptoData(ctx, item.Symbols)
+	case "economic":
+		return dc.CollectEconomicData(ctx, item.Symbols)
+	case "filings":
+		return dc.CollectFilings(ctx, item.Symbols)
+	case "corporate_actions":
+		return dc.CollectCorporateActions(ctx, item.Symbols)
+	default:
+		return dc.CollectOptionsData(ctx, item.Symbols)
+	}
+}
+
+// retriedThrough reports whether a retry of source goes to provider. Stocks
+// go to every quote provider, corporate actions and option chains only to
+// those that report them; the other sources have clients of their own.
+func retriedThrough(source string, provider QuoteProvider) bool {
+	switch source {
+	case "stocks":
+		return true
+	case "corporate_actions":
+		_, ok := provider.(actionProvider)
+		return ok
+	case "options":
+		_, ok := provider.(optionProvider)
+		return ok
+	default:
+		return false
+	}
+}
+
+// providersDown reports whether the breaker of every available provider a
+// retry of source would go to is open and, if so, when the first one will
+// next be probed. A provider without a breaker is never down.
+func (dc *DataCollector) providersDown(source string) (time.Time, bool) {
+	var next time.Time
+	for _, provider := range dc.providerPool().order() {
+		if !retriedThrough(source, provider) {
+			continue
+		}
+		bp, ok := provider.(breakerProvider)
+		if !ok || bp.CircuitBreaker() == nil {
+			return time.Time{}, false
+		}
+		stats := bp.CircuitBreaker().Stats()
+		if stats.State == CircuitClosed {
+			return time.Time{}, false
+		}
+		if next.IsZero() || stats.NextProbeAt.Before(next) {
+			next = stats.NextProbeAt
+		}
+	}
+	if next.IsZero() {
+		return time.Time{}, false
+	}
+
+	// A probe may already be due or in flight; don't spin on it
+	if earliest := time.Now().Add(5 * time.Second); next.Before(earliest) {
+		next = earliest
+	}
+	return next, true
+}
+
+// newRetryItem builds the retry request for a failed collection. Symbols from a
+// CollectionError take precedence so only the missing ones are retried.
+func newRetryItem(source string, data interface{}, collErr *CollectionError) *RetryItem {
+	if item, ok := data.(*RetryItem); ok {
+		return item
+	}
+	operation, ok := retryOperations[source]
+	if !ok {
+		return nil
+	}
+
+	var symbols []string
+	switch v := data.(type) {
+	case []string:
+		symbols = v
+	case string:
+		symbols = []string{v}
+	}
+	if collErr != nil && len(collErr.Symbols) > 0 {
+		symbols = collErr.Symbols
+	}
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	return &RetryItem{
+		Source:    source,
+		Operation: operation,
+		Symbols:   append([]string(nil), symbols...),
+		Priority:  retryPriorities[source],
+	}
+}
+
+// missingSymbols returns the requested symbols that have no quote
+func missingSymbols(symbols []string, quotes []*models.MarketData) []string {
+	got := make(map[string]bool, len(quotes))
+	for _, quote := range quotes {
+		got[quote.Symbol] = true
+	}
+
+	var missing []string
+	for _, symbol := range symbols {
+		if !got[symbol] {
+			missing = append(missing, symbol)
+		}
+	}
+	return missing
+}
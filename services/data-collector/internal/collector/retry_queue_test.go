@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRetryQueue(t *testing.T, path string) (*RetryQueue, *time.Time) {
+	q, err := NewRetryQueue(path, RetryQueueConfig{
+		MaxAttempts: 3,
+		BaseDelay:   10 * time.Second,
+		MaxDelay:    time.Minute,
+		TTL:         time.Hour,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { q.Close() })
+
+	now := time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+func TestRetryQueue_BackoffAndDeadLetter(t *testing.T) {
+	q, now := newTestRetryQueue(t, "")
+
+	item := &RetryItem{Source: "stocks", Operation: RetryOpQuotes, Symbols: []string{"AAPL"}}
+	require.NoError(t, q.Enqueue(item))
+	require.NotEmpty(t, item.ID)
+	assert.Equal(t, 3, item.MaxAttempts)
+
+	// First attempt waits 5-10s (equal jitter on the 10s base)
+	delay := item.NextAttempt.Sub(*now)
+	assert.GreaterOrEqual(t, delay, 5*time.Second)
+	assert.LessOrEqual(t, delay, 10*time.Second)
+
+	due, err := q.Due(10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	*now = now.Add(10 * time.Second)
+	due, err = q.Due(10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+
+	dead, err := q.Fail(due[0], errors.New("HTTP 503"))
+	require.NoError(t, err)
+	assert.False(t, dead)
+	delay = due[0].NextAttempt.Sub(*now)
+	assert.GreaterOrEqual(t, delay, 10*time.Second)
+	assert.LessOrEqual(t, delay, 20*time.Second)
+
+	*now = now.Add(20 * time.Second)
+	due, err = q.Due(10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	_, err = q.Fail(due[0], errors.New("HTTP 503"))
+	require.NoError(t, err)
+
+	*now = now.Add(40 * time.Second)
+	due, err = q.Due(10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	dead, err = q.Fail(due[0], errors.New("HTTP 503"))
+	require.NoError(t, err)
+	assert.True(t, dead)
+
+	stats, err := q.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Depth)
+	assert.Equal(t, 1, stats.DeadLetters)
+
+	letters, err := q.DeadLetters(0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "HTTP 503", letters[0].Item.LastError)
+	assert.Equal(t, 3, letters[0].Item.Attempts)
+
+	require.NoError(t, q.RequeueDeadLetter(item.ID))
+	due, err = q.Due(10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 0, due[0].Attempts)
+}
+
+func TestRetryQueue_PriorityAndExpiry(t *testing.T) {
+	q, now := newTestRetryQueue(t, "")
+
+	news := &RetryItem{Source: "news", Operation: RetryOpQuotes, Symbols: []string{"X"}, Priority: 0, NextAttempt: *now}
+	stocks := &RetryItem{Source: "stocks", Operation: RetryOpQuotes, Symbols: []string{"MSFT"}, Priority: 3, NextAttempt: now.Add(time.Second)}
+	require.NoError(t, q.Enqueue(news))
+	require.NoError(t, q.Enqueue(stocks))
+
+	*now = now.Add(time.Minute)
+	due, err := q.Due(10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, stocks.ID, due[0].ID)
+
+	stats, err := q.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Depth)
+	assert.Equal(t, time.Minute, stats.OldestAge)
+
+	*now = now.Add(2 * time.Hour)
+	due, err = q.Due(10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	letters, err := q.DeadLetters(0)
+	require.NoError(t, err)
+	assert.Len(t, letters, 2)
+	assert.Equal(t, "expired", letters[0].Reason)
+}
+
+func TestRetryQueue_SurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := NewRetryQueue(dir, RetryQueueConfig{})
+	require.NoError(t, err)
+	item := &RetryItem{Source: "stocks", Operation: RetryOpQuotes, Symbols: []string{"AAPL", "TSLA"}, NextAttempt: time.Now().Add(-time.Second)}
+	require.NoError(t, q.Enqueue(item))
+	require.NoError(t, q.Close())
+
+	reopened, err := NewRetryQueue(dir, RetryQueueConfig{})
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	due, err := reopened.Due(10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, []string{"AAPL", "TSLA"}, due[0].Symbols)
+
+	require.NoError(t, reopened.Complete(item.ID))
+	stats, err := reopened.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Depth)
+}
+
+func TestRetryRoutingBySource(t *testing.T) {
+	dc := newTestCollector()
+	ctx := context.Background()
+
+	item := newRetryItem("crypto", []string{"BTC-USD"}, nil)
+	require.NotNil(t, item)
+	assert.Equal(t, RetryOpCryptoPrices, item.Operation)
+	assert.Equal(t, RetryOpOptionChains, newRetryItem("options", "AAPL", nil).Operation)
+	assert.Nil(t, newRetryItem("news", nil, nil))
+	assert.Nil(t, newRetryItem("treasury", []string{"10Y"}, nil), "sources without a retry operation are not queued")
+
+	err := dc.runRetry(ctx, &RetryItem{Source: "crypto", Operation: RetryOpQuotes, Symbols: []string{"BTC-USD"}})
+	assert.ErrorContains(t, err, "unsupported retry operation crypto/quotes")
+
+	// Only retries that go through Yahoo wait for its breaker
+	breaker := dc.providerPool().Breakers()["yahoo"]
+	require.NotNil(t, breaker)
+	for i := 0; i < 3; i++ {
+		breaker.Record(ctx, time.Millisecond, rateLimitedErr())
+	}
+	require.Equal(t, CircuitOpen, breaker.State())
+
+	// Yahoo quotes option chains but does not report corporate actions
+	for source, down := range map[string]bool{
+		"stocks": true, "options": true, "corporate_actions": false,
+		"crypto": false, "economic": false, "filings": false,
+	} {
+		_, got := dc.providersDown(source)
+		assert.Equal(t, down, got, source)
+	}
+
+	// A provider without a breaker keeps stocks going
+	dc.providerPool().Register(&fakeProvider{name: "synthetic"}, 1, 1)
+	_, down := dc.providersDown("stocks")
+	assert.False(t, down)
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"time"
+
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// pollInterval is how often a symbol is fetched in a session; zero means not at all
+func pollInterval(cfg *config.Config, session calendar.Session) time.Duration {
+	switch session {
+	case calendar.SessionRegular:
+		return cfg.MarketDataInterval
+	case calendar.SessionPreMarket, calendar.SessionAfterHours, calendar.SessionBreak:
+		return cfg.ExtendedHoursInterval
+	default:
+		return 0
+	}
+}
+
+// quoteSession is the MarketData session of a calendar session; lunch breaks
+// and closed markets have none
+func quoteSession(session calendar.Session) models.MarketSession {
+	switch session {
+	case calendar.SessionPreMarket:
+		return models.SessionPreMarket
+	case calendar.SessionRegular:
+		return models.SessionRegular
+	case calendar.SessionAfterHours:
+		return models.SessionAfterHours
+	default:
+		return models.SessionUnknown
+	}
+}
+
+// dueStockSymbols returns the tracked symbols whose exchange is in session and
+// whose poll interval has elapsed, plus the time the scheduler should wake next:
+// the earliest of the next poll, the next regular open (intervals shorten) and,
+// for closed exchanges, the start of their next session.
+func (dc *DataCollector) dueStockSymbols(cal *calendar.Calendar, now time.Time, lastPolled map[string]time.Time) ([]string, time.Time) {
+	var due []string
+	var wake time.Time
+	consider := func(t time.Time) {
+		if !t.IsZero() && (wake.IsZero() || t.Before(wake)) {
+			wake = t
+		}
+	}
+
+	cfg := dc.currentConfig()
+	for _, symbol := range cfg.StockSymbols {
+		ex := dc.exchangeOf(cal, symbol)
+		session := ex.Session(now)
+		interval := pollInterval(cfg, session)
+
+		if interval <= 0 {
+			if session == calendar.SessionClosed {
+				consider(ex.NextActive(now))
+			}
+			// Extended-hours polling disabled: sleep until the regular open
+			consider(ex.NextOpen(now))
+			continue
+		}
+
+		next := lastPolled[symbol].Add(interval)
+		if !next.After(now) {
+			due = append(due, symbol)
+			next = now.Add(interval)
+		}
+		consider(next)
+		if session != calendar.SessionRegular {
+			consider(ex.NextOpen(now))
+		}
+	}
+
+	// No symbols, or calendars beyond the holiday tables: check back later
+	if wake.IsZero() {
+		wake = now.Add(time.Hour)
+	}
+	return due, wake
+}
@@ -0,0 +1,473 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// streamWriteTimeout bounds every message and ping sent on a stream
+	streamWriteTimeout = 10 * time.Second
+
+	defaultStreamPingInterval = 20 * time.Second
+	defaultStreamReadTimeout  = time.Minute
+	defaultStreamMinBackoff   = time.Second
+	defaultStreamMaxBackoff   = time.Minute
+
+	// streamFlushInterval and streamFlushSize bound how long and how many
+	// streamed quotes are held before they are stored as one batch
+	streamFlushInterval = time.Second
+	streamFlushSize     = 500
+)
+
+// StreamFeed is the protocol of a websocket push feed: how a connection is
+// authenticated, how symbols are subscribed and how messages decode into
+// quotes. StreamConnector owns the connection itself.
+type StreamFeed interface {
+	Name() string
+
+	// Authenticate runs on every new connection before anything is
+	// subscribed; feeds that authenticate in the URL or handshake headers
+	// return nil
+	Authenticate(conn *StreamConn) error
+
+	Subscribe(conn *StreamConn, symbols []string) error
+	Unsubscribe(conn *StreamConn, symbols []string) error
+
+	// Decode returns the quotes in a message, none for acknowledgements and
+	// other control messages. An error skips the message.
+	Decode(message []byte) ([]*models.MarketData, error)
+}
+
+// StreamHeartbeater is a feed that keeps its connection alive with its own
+// heartbeat message, sent every ping interval in place of a websocket ping
+type StreamHeartbeater interface {
+	Heartbeat(conn *StreamConn) error
+}
+
+// StreamConn is a connection handed to a StreamFeed. Sends are safe to make
+// concurrently with the connector's pings.
+type StreamConn struct {
+	ws          *websocket.Conn
+	readTimeout time.Duration
+
+	writeMu sync.Mutex
+}
+
+// SendJSON sends v as a JSON text message
+func (c *StreamConn) SendJSON(v interface{}) error {
+	message, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Send(message)
+}
+
+// Send sends a text message
+func (c *StreamConn) Send(message []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return c.ws.WriteMessage(websocket.TextMessage, message)
+}
+
+// Receive reads the next message. It is for handshakes in Authenticate;
+// once subscribed, messages go to Decode.
+func (c *StreamConn) Receive() ([]byte, error) {
+	c.ws.SetReadDeadline(time.Now().Add(c.readTimeout))
+	_, message, err := c.ws.ReadMessage()
+	return message, err
+}
+
+func (c *StreamConn) ping() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+}
+
+// StreamOptions configures a StreamConnector. Zero durations take defaults.
+type StreamOptions struct {
+	URL          string
+	Header       http.Header   // sent with the websocket handshake
+	PingInterval time.Duration // how often a ping (or the feed's heartbeat) is sent
+	ReadTimeout  time.Duration // a connection silent this long, pongs included, is dropped
+	MinBackoff   time.Duration // first reconnect delay, doubled up to MaxBackoff
+	MaxBackoff   time.Duration
+}
+
+// StreamOptionsFrom returns the stock stream options in cfg
+func StreamOptionsFrom(cfg *config.Config) StreamOptions {
+	return StreamOptions{
+		URL:          cfg.StockStreamURL,
+		PingInterval: cfg.StreamPingInterval,
+		ReadTimeout:  cfg.StreamReadTimeout,
+	}
+}
+
+// StreamConnector keeps a StreamFeed connected: it dials, authenticates,
+// subscribes the current symbols, pings, and on any failure reconnects with
+// exponential backoff and subscribes again. Symbols can be added and removed
+// while it runs.
+type StreamConnector struct {
+	feed   StreamFeed
+	opts   StreamOptions
+	dialer *websocket.Dialer
+
+	mu          sync.Mutex
+	symbols     map[string]bool
+	conn        *StreamConn // nil while disconnected
+	lastMessage time.Time
+}
+
+func NewStreamConnector(feed StreamFeed, opts StreamOptions) *StreamConnector {
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = defaultStreamPingInterval
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = defaultStreamReadTimeout
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = defaultStreamMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = defaultStreamMaxBackoff
+	}
+	return &StreamConnector{
+		feed: feed,
+		opts: opts,
+		dialer: &websocket.Dialer{
+			HandshakeTimeout: 10 * time.Second,
+		},
+		symbols: make(map[string]bool),
+	}
+}
+
+// Name identifies the feed in MarketData.Source and in logs
+func (s *StreamConnector) Name() string {
+	return s.feed.Name()
+}
+
+// Subscribe adds symbols, subscribing them at once when connected and on
+// every reconnect
+func (s *StreamConnector) Subscribe(symbols ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var added []string
+	for _, symbol := range symbols {
+		if !s.symbols[symbol] {
+			s.symbols[symbol] = true
+			added = append(added, symbol)
+		}
+	}
+	if len(added) == 0 || s.conn == nil {
+		return nil
+	}
+	return s.feed.Subscribe(s.conn, added)
+}
+
+// Unsubscribe removes symbols
+func (s *StreamConnector) Unsubscribe(symbols ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []string
+	for _, symbol := range symbols {
+		if s.symbols[symbol] {
+			delete(s.symbols, symbol)
+			removed = append(removed, symbol)
+		}
+	}
+	if len(removed) == 0 || s.conn == nil {
+		return nil
+	}
+	return s.feed.Unsubscribe(s.conn, removed)
+}
+
+// Symbols returns the subscribed symbols in order
+func (s *StreamConnector) Symbols() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedSymbols()
+}
+
+func (s *StreamConnector) sortedSymbols() []string {
+	symbols := make([]string, 0, len(s.symbols))
+	for symbol := range s.symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Connected reports whether the stream is subscribed and has delivered a
+// message within the read timeout
+func (s *StreamConnector) Connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil && time.Since(s.lastMessage) < s.opts.ReadTimeout
+}
+
+// Run streams decoded quotes to out until ctx ends, reconnecting whenever the
+// connection drops. The backoff resets once a connection delivers a message.
+func (s *StreamConnector) Run(ctx context.Context, out chan<- *models.MarketData) error {
+	backoff := s.opts.MinBackoff
+	for {
+		received, err := s.runOnce(ctx, out)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			backoff = s.opts.MinBackoff
+		}
+		log.Printf("%s stream disconnected, reconnecting in %s: %v", s.Name(), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > s.opts.MaxBackoff {
+			backoff = s.opts.MaxBackoff
+		}
+	}
+}
+
+// runOnce reads one connection until it fails, reporting whether any message
+// arrived on it
+func (s *StreamConnector) runOnce(ctx context.Context, out chan<- *models.MarketData) (bool, error) {
+	ws, _, err := s.dialer.DialContext(ctx, s.opts.URL, s.opts.Header)
+	if err != nil {
+		return false, &ProviderError{Provider: s.Name(), Message: "dial failed: " + err.Error(), Err: err}
+	}
+	defer ws.Close()
+	conn := &StreamConn{ws: ws, readTimeout: s.opts.ReadTimeout}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	if err := s.feed.Authenticate(conn); err != nil {
+		return false, &ProviderError{Provider: s.Name(), Message: "authentication failed: " + err.Error(), Err: err}
+	}
+
+	// Subscribing and publishing conn under one lock means a concurrent
+	// Subscribe is either in this batch or sent on its own, never lost
+	s.mu.Lock()
+	if symbols := s.sortedSymbols(); len(symbols) > 0 {
+		err = s.feed.Subscribe(conn, symbols)
+	}
+	if err == nil {
+		s.conn = conn
+		s.lastMessage = time.Now()
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return false, &ProviderError{Provider: s.Name(), Message: "subscribe failed: " + err.Error(), Err: err}
+	}
+	defer s.disconnected()
+
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(s.opts.ReadTimeout))
+	})
+	go s.keepAlive(conn, done)
+
+	received := false
+	for {
+		ws.SetReadDeadline(time.Now().Add(s.opts.ReadTimeout))
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		received = true
+		s.mu.Lock()
+		s.lastMessage = time.Now()
+		s.mu.Unlock()
+
+		quotes, err := s.feed.Decode(message)
+		if err != nil {
+			log.Printf("Skipping %s message: %v", s.Name(), err)
+			continue
+		}
+		for _, quote := range quotes {
+			select {
+			case out <- quote:
+			case <-ctx.Done():
+				return received, ctx.Err()
+			}
+		}
+	}
+}
+
+// keepAlive pings every ping interval until done. A failed ping closes the
+// connection so the reader reconnects; a missing pong is caught by the read
+// deadline.
+func (s *StreamConnector) keepAlive(conn *StreamConn, done <-chan struct{}) {
+	ticker := time.NewTicker(s.opts.PingInterval)
+	defer ticker.Stop()
+
+	heartbeater, custom := s.feed.(StreamHeartbeater)
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		var err error
+		if custom {
+			err = heartbeater.Heartbeat(conn)
+		} else {
+			err = conn.ping()
+		}
+		if err != nil {
+			conn.ws.Close()
+			return
+		}
+	}
+}
+
+func (s *StreamConnector) disconnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conn = nil
+}
+
+// newStockStream returns the configured stock stream, nil when none is
+func newStockStream(cfg *config.Config) *StreamConnector {
+	if cfg.AlpacaKeyID == "" || cfg.StockStreamURL == "" {
+		return nil
+	}
+	return NewStreamConnector(NewAlpacaStreamFeed(cfg.AlpacaKeyID, cfg.AlpacaSecretKey), StreamOptionsFrom(cfg))
+}
+
+// StartStreamCollection runs the stock stream until ctx ends, subscribing
+// the tracked symbols, and stores streamed quotes through the same pipeline
+// as polled ones in batches of up to streamFlushSize every
+// streamFlushInterval. Symbol changes are subscribed on the live connection;
+// a new URL, credentials or timing restarts the stream. Offline replays
+// don't stream.
+func (dc *DataCollector) StartStreamCollection(ctx context.Context) {
+	if dc.replaySource() != nil {
+		return
+	}
+
+	updates := make(chan *models.MarketData, 4096)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	var stream *StreamConnector
+	var streamCfg *config.Config
+	stopStream := func() {}
+	defer func() { stopStream() }()
+
+	apply := func(cfg *config.Config) {
+		if stream == nil || streamChanged(streamCfg, cfg) {
+			stopStream()
+			stream, streamCfg, stopStream = newStockStream(cfg), cfg, func() {}
+			if stream == nil {
+				return
+			}
+			stream.Subscribe(cfg.StockSymbols...)
+
+			var streamCtx context.Context
+			streamCtx, stopStream = context.WithCancel(ctx)
+			running.Add(1)
+			go func(stream *StreamConnector) {
+				defer running.Done()
+				if err := stream.Run(streamCtx, updates); err != nil && streamCtx.Err() == nil {
+					log.Printf("%s stream stopped: %v", stream.Name(), err)
+				}
+			}(stream)
+			log.Printf("Streaming %d symbols from %s", len(cfg.StockSymbols), stream.Name())
+			return
+		}
+
+		streamCfg = cfg
+		tracked := make(map[string]bool, len(cfg.StockSymbols))
+		for _, symbol := range cfg.StockSymbols {
+			tracked[symbol] = true
+		}
+		var removed []string
+		for _, symbol := range stream.Symbols() {
+			if !tracked[symbol] {
+				removed = append(removed, symbol)
+			}
+		}
+		if err := stream.Unsubscribe(removed...); err != nil {
+			log.Printf("Failed to unsubscribe %v from %s: %v", removed, stream.Name(), err)
+		}
+		if err := stream.Subscribe(cfg.StockSymbols...); err != nil {
+			log.Printf("Failed to subscribe %v on %s: %v", cfg.StockSymbols, stream.Name(), err)
+		}
+	}
+
+	cfg := dc.currentConfig()
+	apply(cfg)
+
+	check := time.NewTicker(cfg.MarketDataInterval)
+	defer check.Stop()
+	flush := time.NewTicker(streamFlushInterval)
+	defer flush.Stop()
+
+	var batch []*models.MarketData
+	store := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := dc.storeQuotes(ctx, batch); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to store %d streamed quotes: %v", len(batch), err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case quote := <-updates:
+			if dc.isPaused(ServiceMarket) || dc.isPaused(quote.Source) {
+				continue
+			}
+			if _, err := dc.ProcessMarketData(ctx, quote); err != nil {
+				log.Printf("Rejected streamed quote: %v", err)
+				continue
+			}
+			if batch = append(batch, quote); len(batch) >= streamFlushSize {
+				store()
+			}
+
+		case <-flush.C:
+			store()
+
+		case <-check.C:
+			apply(dc.currentConfig())
+		}
+	}
+}
+
+// streamChanged reports whether the stream must reconnect to apply cfg
+func streamChanged(old, cfg *config.Config) bool {
+	return old.StockStreamURL != cfg.StockStreamURL ||
+		old.AlpacaKeyID != cfg.AlpacaKeyID ||
+		old.AlpacaSecretKey != cfg.AlpacaSecretKey ||
+		old.StreamPingInterval != cfg.StreamPingInterval ||
+		old.StreamReadTimeout != cfg.StreamReadTimeout
+}
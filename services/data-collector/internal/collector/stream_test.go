@@ -0,0 +1,176 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standInConn is a connection to the Alpaca stand-in server, with the
+// messages the client sent on it
+type standInConn struct {
+	ws       *websocket.Conn
+	messages chan map[string]interface{}
+}
+
+func (c *standInConn) next(t *testing.T) map[string]interface{} {
+	select {
+	case message := <-c.messages:
+		return message
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message from the client")
+		return nil
+	}
+}
+
+// alpacaStandIn serves Alpaca's stream handshake and hands each
+// authenticated connection to the test. While mute is set it stops
+// answering pings.
+func alpacaStandIn(t *testing.T, mute *atomic.Bool) (string, <-chan *standInConn, *atomic.Int32) {
+	upgrader := websocket.Upgrader{}
+	conns := make(chan *standInConn, 4)
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		attempts.Add(1)
+
+		ws.WriteMessage(websocket.TextMessage, []byte(`[{"T":"success","msg":"connected"}]`))
+		var auth map[string]string
+		if err := ws.ReadJSON(&auth); err != nil {
+			ws.Close()
+			return
+		}
+		if auth["action"] != "auth" || auth["secret"] != "secret" {
+			ws.WriteMessage(websocket.TextMessage, []byte(`[{"T":"error","code":402,"msg":"auth failed"}]`))
+			ws.Close()
+			return
+		}
+		ws.WriteMessage(websocket.TextMessage, []byte(`[{"T":"success","msg":"authenticated"}]`))
+
+		conn := &standInConn{ws: ws, messages: make(chan map[string]interface{}, 16)}
+		ws.SetPingHandler(func(data string) error {
+			if mute.Load() {
+				return nil
+			}
+			return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		go func() {
+			for {
+				var message map[string]interface{}
+				if err := ws.ReadJSON(&message); err != nil {
+					return
+				}
+				conn.messages <- message
+			}
+		}()
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http"), conns, &attempts
+}
+
+func subscription(action string, symbols ...string) map[string]interface{} {
+	list := make([]interface{}, len(symbols))
+	for i, symbol := range symbols {
+		list[i] = symbol
+	}
+	return map[string]interface{}{"action": action, "trades": list, "dailyBars": list}
+}
+
+func TestStreamConnector(t *testing.T) {
+	var mute atomic.Bool
+	url, conns, _ := alpacaStandIn(t, &mute)
+	stream := NewStreamConnector(NewAlpacaStreamFeed("key", "secret"), StreamOptions{
+		URL:          url,
+		PingInterval: 20 * time.Millisecond,
+		ReadTimeout:  300 * time.Millisecond,
+		MinBackoff:   10 * time.Millisecond,
+		MaxBackoff:   50 * time.Millisecond,
+	})
+	require.NoError(t, stream.Subscribe("MSFT", "AAPL"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan *models.MarketData, 10)
+	done := make(chan error, 1)
+	go func() { done <- stream.Run(ctx, out) }()
+
+	conn := <-conns
+	assert.Equal(t, subscription("subscribe", "AAPL", "MSFT"), conn.next(t))
+	require.NoError(t, conn.ws.WriteMessage(websocket.TextMessage, []byte(`[{"T":"subscription","trades":["AAPL","MSFT"],"quotes":[],"bars":[],"dailyBars":["AAPL","MSFT"]}]`)))
+	require.NoError(t, conn.ws.WriteMessage(websocket.TextMessage, []byte(
+		`[{"T":"d","S":"AAPL","o":235.54,"h":236.16,"l":224.22,"c":227.4,"v":71893114,"t":"2025-03-10T04:00:00Z","n":1031582,"vw":229.01},`+
+			`{"T":"t","S":"AAPL","i":52983525029461,"x":"V","p":227.48,"s":100,"c":["@"],"z":"C","t":"2025-03-10T19:59:59.954Z"},`+
+			`{"T":"t","S":"MSFT","i":52983525028814,"x":"V","p":380.16,"s":20,"c":["@","I"],"z":"C","t":"2025-03-10T19:59:59.81Z"}]`)))
+
+	quote := <-out
+	assert.Equal(t, "AAPL", quote.Symbol)
+	assert.Equal(t, decimal.MustParse("227.48"), quote.Price)
+	assert.Equal(t, decimal.MustParse("235.54"), quote.Open)
+	assert.Equal(t, decimal.MustParse("224.22"), quote.Low)
+	assert.Equal(t, int64(71893114), quote.Volume)
+	assert.Equal(t, time.Date(2025, 3, 10, 19, 59, 59, 954000000, time.UTC), quote.Timestamp)
+	assert.Equal(t, "alpaca", quote.Source)
+	quote = <-out
+	assert.Equal(t, "MSFT", quote.Symbol)
+	assert.Zero(t, quote.Volume, "no daily bar yet")
+	assert.True(t, stream.Connected())
+
+	// Symbol changes go out on the live connection
+	require.NoError(t, stream.Subscribe("TSLA", "AAPL"))
+	assert.Equal(t, subscription("subscribe", "TSLA"), conn.next(t))
+	require.NoError(t, stream.Unsubscribe("MSFT"))
+	assert.Equal(t, subscription("unsubscribe", "MSFT"), conn.next(t))
+
+	// A dropped connection is redialed and resubscribed
+	conn.ws.Close()
+	conn = <-conns
+	assert.Equal(t, subscription("subscribe", "AAPL", "TSLA"), conn.next(t))
+
+	// and so is one that stops answering pings
+	mute.Store(true)
+	select {
+	case <-conns:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a silent connection was not redialed")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.False(t, stream.Connected())
+}
+
+func TestStreamConnectorAuthFailure(t *testing.T) {
+	var mute atomic.Bool
+	url, conns, attempts := alpacaStandIn(t, &mute)
+	stream := NewStreamConnector(NewAlpacaStreamFeed("key", "wrong"), StreamOptions{URL: url, MinBackoff: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := stream.Run(ctx, make(chan *models.MarketData))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, conns)
+	assert.Greater(t, attempts.Load(), int32(1), "failed logins are retried with backoff")
+	assert.False(t, stream.Connected())
+
+	feed := NewAlpacaStreamFeed("key", "secret")
+	_, err = feed.Decode([]byte(`[{"T":"error","code":406,"msg":"connection limit exceeded"}]`))
+	assert.ErrorIs(t, err, ErrRateLimited)
+	quotes, err := feed.Decode([]byte(`[{"T":"success","msg":"authenticated"}]`))
+	require.NoError(t, err)
+	assert.Empty(t, quotes)
+	_, err = feed.Decode(json.RawMessage(`{"T":"t"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,443 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+const (
+	// Volatility and drift are annualized over regular trading time
+	tradingDay  = 6*time.Hour + 30*time.Minute
+	tradingYear = 252 * tradingDay
+
+	// offHoursActivity scales variance and volume outside regular hours, so
+	// load runs at night and on weekends still see prices move
+	offHoursActivity = 0.2
+
+	// syntheticMaxStep is the longest simulation step over short gaps, so
+	// halts and the volume curve are sampled at least every minute
+	syntheticMaxStep = time.Minute
+	// syntheticMaxSteps bounds the steps taken to catch up over a long gap
+	syntheticMaxSteps = 60
+)
+
+// SyntheticOptions shape the market a SyntheticProvider generates
+type SyntheticOptions struct {
+	Volatility   float64       // annualized volatility of every symbol
+	Drift        float64       // annualized drift
+	Correlation  float64       // pairwise correlation of returns through a common market factor, 0 to 1
+	JumpsPerDay  float64       // expected jumps per symbol per trading day
+	JumpSize     float64       // standard deviation of a jump's log return
+	HaltsPerDay  float64       // expected trading halts per symbol per trading day
+	HaltDuration time.Duration // how long a halt lasts
+	SpreadBps    float64       // bid/ask spread around the mid in regular hours
+	Seed         int64         // 0 seeds from the clock
+}
+
+// SyntheticOptionsFrom reads the Synthetic* settings of cfg
+func SyntheticOptionsFrom(cfg *config.Config) SyntheticOptions {
+	return SyntheticOptions{
+		Volatility:   cfg.SyntheticVolatility,
+		Correlation:  cfg.SyntheticCorrelation,
+		JumpsPerDay:  cfg.SyntheticJumpsPerDay,
+		JumpSize:     0.04,
+		HaltsPerDay:  cfg.SyntheticHaltsPerDay,
+		HaltDuration: cfg.SyntheticHaltDuration,
+		SpreadBps:    cfg.SyntheticSpreadBps,
+		Seed:         int64(cfg.SyntheticSeed),
+	}
+}
+
+// SyntheticSymbols names n symbols for load runs: SYN00000, SYN00001, ...
+func SyntheticSymbols(n int) []string {
+	symbols := make([]string, n)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYN%05d", i)
+	}
+	return symbols
+}
+
+// syntheticSymbol is the simulated state of one symbol
+type syntheticSymbol struct {
+	mid, last, bid, ask        float64
+	bidSize, askSize           int64
+	open, high, low, prevClose float64
+	volume                     float64 // shares traded in the current session
+	dailyVolume                float64 // average shares traded per regular session
+	shares                     float64 // shares outstanding
+	day                        string  // exchange-local date of the current session
+	haltedUntil                time.Time
+	updated                    time.Time // last trade
+	sequence                   uint64    // trades so far
+}
+
+// SyntheticProvider generates quotes for any symbol asked for, so the
+// collector and its storage can be driven at volumes no free API allows. Mid
+// prices follow geometric Brownian motion, correlated through a common market
+// factor, with occasional jumps; symbols are occasionally halted, and their
+// quotes go stale until the halt ends. Volume follows the U-shaped intraday
+// curve of the NYSE session. Trades print at the bid or the ask of the
+// simulated spread, so prices bounce the way real last-sale prices do.
+//
+// Each symbol's starting price and volume derive from its name and the seed,
+// and the simulation advances with the wall clock on every call: with a fixed
+// seed and the same calls at the same times, runs repeat exactly.
+type SyntheticProvider struct {
+	opts     SyntheticOptions
+	exchange *calendar.Exchange
+	now      func() time.Time
+
+	mu      sync.Mutex
+	rng     *rand.Rand
+	symbols map[string]*syntheticSymbol
+	order   []string  // in creation order, so seeded runs repeat
+	clock   time.Time // the simulation has advanced to here
+}
+
+func NewSyntheticProvider(opts SyntheticOptions) *SyntheticProvider {
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	return &SyntheticProvider{
+		opts:     opts,
+		exchange: calendar.Default().Resolve("NYSE", ""),
+		now:      time.Now,
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		symbols:  make(map[string]*syntheticSymbol),
+	}
+}
+
+// Name identifies the source in MarketData.Source and in configuration
+func (p *SyntheticProvider) Name() string {
+	return "synthetic"
+}
+
+func (p *SyntheticProvider) GetQuote(ctx context.Context, symbol string) (*models.MarketData, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.symbol(symbol)
+	p.advance(p.now())
+	return p.quote(symbol, s), nil
+}
+
+func (p *SyntheticProvider) GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, symbol := range symbols {
+		p.symbol(symbol)
+	}
+	p.advance(p.now())
+
+	quotes := make([]*models.MarketData, 0, len(symbols))
+	for _, symbol := range symbols {
+		quotes = append(quotes, p.quote(symbol, p.symbols[symbol]))
+	}
+	return quotes, nil
+}
+
+func (p *SyntheticProvider) GetHistoricalData(ctx context.Context, symbol string, period string, interval string) ([]*models.MarketData, error) {
+	now := p.now()
+	start, err := periodStart(period, now)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() {
+		start = now.AddDate(-10, 0, 0)
+	}
+	return p.GetHistoricalRange(ctx, symbol, start, now, interval)
+}
+
+// syntheticBarSizes are the supported bar intervals
+var syntheticBarSizes = map[string]time.Duration{
+	"1m": time.Minute, "2m": 2 * time.Minute, "5m": 5 * time.Minute, "15m": 15 * time.Minute,
+	"30m": 30 * time.Minute, "60m": time.Hour, "90m": 90 * time.Minute, "1h": time.Hour,
+	"1d": 24 * time.Hour, "1wk": 7 * 24 * time.Hour, "1mo": 30 * 24 * time.Hour,
+}
+
+// GetHistoricalRange generates bars in [start, end): intraday bars within
+// regular sessions, daily bars on trading days. The path is fixed by the
+// symbol, the range and the seed, and is scaled to end at the symbol's
+// current mid.
+func (p *SyntheticProvider) GetHistoricalRange(ctx context.Context, symbol string, start, end time.Time, interval string) ([]*models.MarketData, error) {
+	size, ok := syntheticBarSizes[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval: %s", interval)
+	}
+
+	p.mu.Lock()
+	s := p.symbol(symbol)
+	p.advance(p.now())
+	mid, dailyVolume := s.mid, s.dailyVolume
+	p.mu.Unlock()
+
+	// Trading days per bar
+	var barDays float64
+	var times []time.Time
+	if size < 24*time.Hour {
+		barDays = float64(size) / float64(tradingDay)
+		for _, window := range p.exchange.RegularSessions(start, end) {
+			for t := window.Start; t.Before(window.End); t = t.Add(size) {
+				times = append(times, t)
+			}
+		}
+	} else {
+		y, m, d := start.In(p.exchange.Location).Date()
+		for day := time.Date(y, m, d, 0, 0, 0, 0, p.exchange.Location); day.Before(end); {
+			if !day.Before(start) && (interval != "1d" || p.exchange.IsTradingDay(day)) {
+				times = append(times, day)
+			}
+			switch interval {
+			case "1wk":
+				barDays = 5
+				day = day.AddDate(0, 0, 7)
+			case "1mo":
+				barDays = 21
+				day = day.AddDate(0, 1, 0)
+			default:
+				barDays = 1
+				day = day.AddDate(0, 0, 1)
+			}
+		}
+	}
+	if len(times) == 0 {
+		return nil, nil
+	}
+
+	rng := rand.New(rand.NewSource(p.opts.Seed ^ symbolSeed(symbol) ^ start.UnixNano()/int64(size)))
+	years := barDays / 252
+	diffusion := p.opts.Volatility * math.Sqrt(years)
+	drift := (p.opts.Drift - p.opts.Volatility*p.opts.Volatility/2) * years
+
+	type ohlc struct{ open, high, low, close float64 }
+	walk := make([]ohlc, len(times))
+	bars := make([]*models.MarketData, len(times))
+	price := 1.0
+	for i, t := range times {
+		open := price
+		price *= math.Exp(drift + diffusion*rng.NormFloat64())
+		share := barDays
+		if size < 24*time.Hour {
+			share *= volumeCurve(p.sessionProgress(t))
+		}
+		walk[i] = ohlc{
+			open:  open,
+			close: price,
+			high:  math.Max(open, price) * math.Exp(math.Abs(rng.NormFloat64())*diffusion/2),
+			low:   math.Min(open, price) * math.Exp(-math.Abs(rng.NormFloat64())*diffusion/2),
+		}
+		bars[i] = &models.MarketData{
+			Symbol:    symbol,
+			Volume:    int64(dailyVolume * share * (0.5 + rng.Float64())),
+			Timestamp: t,
+			Source:    p.Name(),
+		}
+	}
+
+	scale := mid / price
+	for i, bar := range bars {
+		bar.Open = cents(walk[i].open * scale)
+		bar.Close = cents(walk[i].close * scale)
+		bar.High = cents(walk[i].high * scale)
+		bar.Low = cents(walk[i].low * scale)
+		bar.Price = bar.Close
+	}
+	return bars, nil
+}
+
+// GetAPIHealth is always healthy: nothing leaves the process
+func (p *SyntheticProvider) GetAPIHealth(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// symbol returns the state of symbol, starting it at a price of 10 to 500 and
+// a daily volume of 200k to 50M shares derived from its name
+func (p *SyntheticProvider) symbol(symbol string) *syntheticSymbol {
+	if s, ok := p.symbols[symbol]; ok {
+		return s
+	}
+
+	rng := rand.New(rand.NewSource(p.opts.Seed ^ symbolSeed(symbol)))
+	price := roundCents(10 * math.Exp(rng.Float64()*math.Log(50)))
+	dailyVolume := 2e5 * math.Exp(rng.Float64()*math.Log(250))
+	t := p.clock
+	if t.IsZero() {
+		t = p.now()
+	}
+
+	s := &syntheticSymbol{
+		mid: price, last: price, bid: price, ask: price,
+		open: price, high: price, low: price, prevClose: price,
+		dailyVolume: dailyVolume,
+		shares:      dailyVolume * 250,
+		day:         t.In(p.exchange.Location).Format("2006-01-02"),
+		updated:     t,
+	}
+	p.symbols[symbol] = s
+	p.order = append(p.order, symbol)
+	return s
+}
+
+func symbolSeed(symbol string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(symbol))
+	return int64(h.Sum64())
+}
+
+// advance runs the simulation up to now: in steps of a minute over short
+// gaps, and in at most syntheticMaxSteps steps over long ones
+func (p *SyntheticProvider) advance(now time.Time) {
+	if p.clock.IsZero() {
+		p.clock = now
+		return
+	}
+	gap := now.Sub(p.clock)
+	if gap <= 0 {
+		return
+	}
+	size := syntheticMaxStep
+	if gap/syntheticMaxSteps > size {
+		size = gap / syntheticMaxSteps
+	}
+	for p.clock.Before(now) {
+		step := size
+		if remaining := now.Sub(p.clock); remaining < step {
+			step = remaining
+		}
+		p.clock = p.clock.Add(step)
+		p.step(p.clock, step)
+	}
+}
+
+// step moves every symbol over the dt ending at t
+func (p *SyntheticProvider) step(t time.Time, dt time.Duration) {
+	progress := p.sessionProgress(t)
+	activity := offHoursActivity
+	if progress >= 0 {
+		activity = 1
+	}
+
+	years := float64(dt) / float64(tradingYear) * activity
+	days := years * 252
+	drift := (p.opts.Drift - p.opts.Volatility*p.opts.Volatility/2) * years
+	diffusion := p.opts.Volatility * math.Sqrt(years)
+	volumeShare := float64(dt) / float64(tradingDay) * activity * volumeCurve(progress)
+	market := p.rng.NormFloat64()
+	marketWeight := math.Sqrt(p.opts.Correlation)
+	ownWeight := math.Sqrt(1 - p.opts.Correlation)
+
+	// Spreads are widest outside regular hours (progress -1) and in the
+	// first fifteen minutes
+	halfSpread := p.opts.SpreadBps / 20000
+	if progress < 15.0/390 {
+		halfSpread *= 3
+	}
+
+	day := t.In(p.exchange.Location).Format("2006-01-02")
+	for _, symbol := range p.order {
+		s := p.symbols[symbol]
+		if s.day != day {
+			s.day = day
+			s.prevClose, s.open, s.high, s.low = s.last, s.last, s.last, s.last
+			s.volume = 0
+		}
+		if t.Before(s.haltedUntil) {
+			continue
+		}
+
+		logReturn := drift + diffusion*(marketWeight*market+ownWeight*p.rng.NormFloat64())
+		if p.rng.Float64() < p.opts.JumpsPerDay*days {
+			logReturn += p.opts.JumpSize * p.rng.NormFloat64()
+		}
+		s.mid *= math.Exp(logReturn)
+		s.volume += s.dailyVolume * volumeShare * (0.5 + p.rng.Float64())
+
+		s.bid = roundCents(s.mid * (1 - halfSpread))
+		s.ask = roundCents(s.mid * (1 + halfSpread))
+		if s.ask <= s.bid {
+			s.ask = s.bid + 0.01
+		}
+		s.bidSize = 100 * int64(1+p.rng.Intn(9))
+		s.askSize = 100 * int64(1+p.rng.Intn(9))
+		if p.rng.Intn(2) == 0 {
+			s.last = s.bid
+		} else {
+			s.last = s.ask
+		}
+		s.high = math.Max(s.high, s.last)
+		s.low = math.Min(s.low, s.last)
+		s.updated = t
+		s.sequence++
+
+		if p.rng.Float64() < p.opts.HaltsPerDay*days {
+			s.haltedUntil = t.Add(p.opts.HaltDuration)
+		}
+	}
+}
+
+// sessionProgress returns how far through the regular session t is, from 0
+// at the open to 1 at the close, or -1 outside regular hours
+func (p *SyntheticProvider) sessionProgress(t time.Time) float64 {
+	for _, window := range p.exchange.RegularSessions(t.Add(-tradingDay), t.Add(tradingDay)) {
+		if !t.Before(window.Start) && t.Before(window.End) {
+			return float64(t.Sub(window.Start)) / float64(window.End.Sub(window.Start))
+		}
+	}
+	return -1
+}
+
+// volumeCurve is the share of average volume traded at a point of the
+// session relative to a flat profile: heavy at the open, light at midday,
+// rising into the close. It averages 1 over the session, and is 1 outside it.
+func volumeCurve(progress float64) float64 {
+	if progress < 0 {
+		return 1
+	}
+	return (1 + 2*math.Exp(-13*progress) + 1.5*math.Exp(-13*(1-progress))) / (1 + 3.5/13)
+}
+
+func (p *SyntheticProvider) quote(symbol string, s *syntheticSymbol) *models.MarketData {
+	last, prevClose := cents(s.last), cents(s.prevClose)
+	return &models.MarketData{
+		Symbol:        symbol,
+		Price:         last,
+		Volume:        int64(s.volume),
+		High:          cents(s.high),
+		Low:           cents(s.low),
+		Open:          cents(s.open),
+		Close:         last,
+		Change:        last.Sub(prevClose),
+		ChangePercent: decimal.PercentChange(prevClose, last).Round(4),
+		MarketCap:     int64(s.shares * s.last),
+		Bid:           cents(s.bid),
+		Ask:           cents(s.ask),
+		BidSize:       s.bidSize,
+		AskSize:       s.askSize,
+		PrevClose:     prevClose,
+		Session:       quoteSession(p.exchange.Session(s.updated)),
+		Exchange:      p.exchange.Code,
+		Sequence:      s.sequence,
+		Timestamp:     s.updated,
+		Source:        p.Name(),
+	}
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// cents is a simulated price as the decimal a quote carries
+func cents(value float64) decimal.Decimal {
+	return decimal.FromFloat(roundCents(value))
+}
@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// steppedSynthetic returns a provider whose clock starts at start and moves
+// by step on every call
+func steppedSynthetic(opts SyntheticOptions, start time.Time, step time.Duration) *SyntheticProvider {
+	p := NewSyntheticProvider(opts)
+	clock := start
+	p.now = func() time.Time {
+		clock = clock.Add(step)
+		return clock
+	}
+	return p
+}
+
+func TestSyntheticProviderQuotes(t *testing.T) {
+	// 10:30 in New York
+	start := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	opts := SyntheticOptions{Volatility: 0.3, Correlation: 0.4, SpreadBps: 5, Seed: 42}
+	ctx := context.Background()
+	symbols := SyntheticSymbols(3)
+	assert.Equal(t, []string{"SYN00000", "SYN00001", "SYN00002"}, symbols)
+
+	first := steppedSynthetic(opts, start, time.Minute)
+	second := steppedSynthetic(opts, start, time.Minute)
+	for i := 0; i < 30; i++ {
+		a, err := first.GetMultipleQuotes(ctx, symbols)
+		require.NoError(t, err)
+		b, err := second.GetMultipleQuotes(ctx, symbols)
+		require.NoError(t, err)
+		require.Equal(t, a, b, "the same seed and calls repeat the same market")
+	}
+
+	quote, err := first.GetQuote(ctx, "SYN00001")
+	require.NoError(t, err)
+	assert.Equal(t, "synthetic", quote.Source)
+	assert.Positive(t, quote.Volume)
+	assert.False(t, quote.High.LessThan(quote.Price))
+	assert.False(t, quote.Low.GreaterThan(quote.Price))
+
+	s := first.symbols["SYN00001"]
+	assert.Less(t, s.bid, s.ask)
+	assert.Contains(t, []float64{s.bid, s.ask}, s.last, "trades print at the bid or the ask")
+	assert.InDelta(t, 5, (s.ask-s.bid)/s.mid*1e4, 1.5)
+}
+
+func TestSyntheticProviderCorrelation(t *testing.T) {
+	start := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	correlation := func(rho float64) float64 {
+		p := steppedSynthetic(SyntheticOptions{Volatility: 0.3, Correlation: rho, Seed: 7}, start, time.Minute)
+		p.GetMultipleQuotes(context.Background(), []string{"A", "B"})
+
+		var xs, ys []float64
+		for i := 0; i < 3000; i++ {
+			a, b := p.symbols["A"].mid, p.symbols["B"].mid
+			p.advance(p.now())
+			xs = append(xs, math.Log(p.symbols["A"].mid/a))
+			ys = append(ys, math.Log(p.symbols["B"].mid/b))
+		}
+		return sampleCorrelation(xs, ys)
+	}
+
+	assert.InDelta(t, 0.8, correlation(0.8), 0.1)
+	assert.InDelta(t, 0, correlation(0), 0.1)
+}
+
+func sampleCorrelation(xs, ys []float64) float64 {
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i] / float64(len(xs))
+		my += ys[i] / float64(len(ys))
+	}
+	var sxy, sxx, syy float64
+	for i := range xs {
+		sxy += (xs[i] - mx) * (ys[i] - my)
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		syy += (ys[i] - my) * (ys[i] - my)
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+func TestSyntheticProviderHalts(t *testing.T) {
+	start := time.Date(2025, 3, 10, 14, 30, 0, 0, time.UTC)
+	// A halt rate this high halts every symbol on the first step
+	p := steppedSynthetic(SyntheticOptions{Volatility: 0.3, HaltsPerDay: 1e6, HaltDuration: 5 * time.Minute, Seed: 1}, start, time.Minute)
+	ctx := context.Background()
+
+	p.GetQuote(ctx, "HALT")
+	halted, err := p.GetQuote(ctx, "HALT")
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		quote, err := p.GetQuote(ctx, "HALT")
+		require.NoError(t, err)
+		assert.Equal(t, halted.Price, quote.Price)
+		assert.Equal(t, halted.Timestamp, quote.Timestamp, "quotes go stale while halted")
+	}
+	quote, err := p.GetQuote(ctx, "HALT")
+	require.NoError(t, err)
+	assert.True(t, quote.Timestamp.After(halted.Timestamp), "trading resumes after the halt")
+}
+
+func TestSyntheticProviderHistory(t *testing.T) {
+	now := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)
+	p := steppedSynthetic(SyntheticOptions{Volatility: 0.3, Seed: 3}, now, 0)
+	ctx := context.Background()
+
+	daily, err := p.GetHistoricalRange(ctx, "SYN00000", now.AddDate(0, 0, -14), now, "1d")
+	require.NoError(t, err)
+	// Two weeks of trading days, no weekends
+	require.Len(t, daily, 10)
+	for _, bar := range daily {
+		assert.NotEqual(t, time.Saturday, bar.Timestamp.Weekday())
+		assert.NotEqual(t, time.Sunday, bar.Timestamp.Weekday())
+		assert.False(t, bar.High.LessThan(decimal.Max(bar.Open, bar.Close)))
+		assert.False(t, bar.Low.GreaterThan(decimal.Min(bar.Open, bar.Close)))
+	}
+	assert.InDelta(t, p.symbols["SYN00000"].mid, daily[len(daily)-1].Close.Float64(), 0.01, "history ends at the current price")
+
+	intraday, err := p.GetHistoricalRange(ctx, "SYN00000", now.Add(-24*time.Hour), now, "5m")
+	require.NoError(t, err)
+	// 09:30 to 16:00 on Friday the 14th
+	assert.Len(t, intraday, 78)
+
+	_, err = p.GetHistoricalData(ctx, "SYN00000", "5d", "3m")
+	assert.Error(t, err)
+
+	var total float64
+	for i := 0; i < 1000; i++ {
+		total += volumeCurve((float64(i)+0.5)/1000) / 1000
+	}
+	assert.InDelta(t, 1, total, 0.01, "the volume curve averages to a flat profile")
+	assert.Greater(t, volumeCurve(0), volumeCurve(0.5))
+	assert.Greater(t, volumeCurve(1), volumeCurve(0.5))
+}
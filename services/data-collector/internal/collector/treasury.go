@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+const treasuryDateLayout = "01/02/2006"
+
+// TreasuryClient reads the daily Treasury par yield curve rates published by
+// the US Treasury. The rates are public and need no key; the optional
+// "treasury" rate limit applies.
+type TreasuryClient struct {
+	httpClient  *http.Client
+	baseURL     string
+	rateLimiter *RateLimiter
+}
+
+func NewTreasuryClient() *TreasuryClient {
+	return &TreasuryClient{
+		httpClient: newHTTPClient(30 * time.Second),
+		baseURL:    "https://home.treasury.gov/resource-center/data-chart-center/interest-rates",
+	}
+}
+
+// Name identifies the source in YieldCurve.Source and in configuration
+func (t *TreasuryClient) Name() string {
+	return "treasury"
+}
+
+// GetYieldCurves returns the par yield curve of every business day of a
+// calendar year published so far, oldest first
+func (t *TreasuryClient) GetYieldCurves(ctx context.Context, year int) ([]*models.YieldCurve, error) {
+	requestURL := fmt.Sprintf("%s/daily-treasury-rates.csv/%d/all?type=daily_treasury_yield_curve&field_tdr_date_value=%d&page&_format=csv",
+		t.baseURL, year, year)
+	body, err := t.makeRequest(ctx, requestURL)
+	if err != nil {
+		return nil, err
+	}
+	return t.parseYieldCurves(body)
+}
+
+// Response Parsing
+
+// parseYieldCurves reads the CSV download: a header of tenor labels
+// ("Date","1 Mo",...,"30 Yr") and a row per day, newest first. Columns are
+// matched by label since the Treasury adds tenors from time to time; ones
+// outside models.YieldCurveTenors, such as "1.5 Month", are skipped.
+func (t *TreasuryClient) parseYieldCurves(response []byte) ([]*models.YieldCurve, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(response, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Treasury yield curve header: %w", err)
+	}
+	if len(header) == 0 || !strings.EqualFold(strings.TrimSpace(header[0]), "Date") {
+		return nil, fmt.Errorf("unexpected Treasury yield curve header %q", strings.Join(header, ","))
+	}
+	tenors := make([]string, len(header))
+	for i, label := range header[1:] {
+		tenors[i+1] = treasuryTenor(label)
+	}
+
+	var curves []*models.YieldCurve
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Treasury yield curve: %w", err)
+		}
+
+		date, err := time.Parse(treasuryDateLayout, strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid Treasury yield curve date %q", record[0])
+		}
+		curve := &models.YieldCurve{Date: date, Yields: make(map[string]float64), Source: t.Name()}
+		for i := 1; i < len(record) && i < len(tenors); i++ {
+			if tenors[i] == "" {
+				continue
+			}
+			// Tenors not quoted that day are blank or "N/A"
+			yield, err := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			if err != nil {
+				continue
+			}
+			curve.Yields[tenors[i]] = yield
+		}
+		if len(curve.Yields) > 0 {
+			curves = append(curves, curve)
+		}
+	}
+
+	sort.Slice(curves, func(i, j int) bool { return curves[i].Date.Before(curves[j].Date) })
+	return curves, nil
+}
+
+// treasuryTenor maps a column label such as "3 Mo", "2 Month" or "10 Yr" to
+// its tenor in models.YieldCurveTenors, "" for any other column
+func treasuryTenor(label string) string {
+	fields := strings.Fields(label)
+	if len(fields) != 2 {
+		return ""
+	}
+	var unit string
+	switch strings.ToLower(fields[1]) {
+	case "mo", "month", "months":
+		unit = "M"
+	case "yr", "year", "years":
+		unit = "Y"
+	default:
+		return ""
+	}
+	tenor := fields[0] + unit
+	for _, known := range models.YieldCurveTenors {
+		if tenor == known {
+			return tenor
+		}
+	}
+	return ""
+}
+
+// HTTP Request Handling
+func (t *TreasuryClient) makeRequest(ctx context.Context, requestURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Treasury request: %w", err)
+	}
+	req.Header.Set("Accept", "text/csv")
+
+	if t.rateLimiter != nil {
+		if err := t.rateLimiter.Wait(ctx, "/daily-treasury-rates"); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: t.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: t.Name(), Message: "failed to read response", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, t.handleTreasuryError(resp.StatusCode)
+	}
+	return body, nil
+}
+
+// Error Handling
+func (t *TreasuryClient) handleTreasuryError(statusCode int) error {
+	providerErr := &ProviderError{Provider: t.Name(), StatusCode: statusCode, Message: http.StatusText(statusCode)}
+
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusForbidden:
+		providerErr.Err = ErrRateLimited
+	case http.StatusNotFound:
+		providerErr.Err = ErrSymbolNotFound
+	}
+	return providerErr
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTreasuryClient(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.URL.Path != "/daily-treasury-rates.csv/2025/all" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := os.ReadFile(filepath.Join("testdata", "treasury", "daily_treasury_yield_curve_2025.csv"))
+		require.NoError(t, err)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewTreasuryClient()
+	client.baseURL = server.URL
+	ctx := context.Background()
+
+	curves, err := client.GetYieldCurves(ctx, 2025)
+	require.NoError(t, err)
+	require.Len(t, curves, 4)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "daily_treasury_yield_curve", requests[0].URL.Query().Get("type"))
+	assert.Equal(t, "2025", requests[0].URL.Query().Get("field_tdr_date_value"))
+
+	// Oldest first, and the 1.5-month column is not one of our tenors
+	assert.Equal(t, time.Date(2025, 2, 18, 0, 0, 0, 0, time.UTC), curves[0].Date)
+	latest := curves[3]
+	assert.Equal(t, time.Date(2025, 2, 21, 0, 0, 0, 0, time.UTC), latest.Date)
+	assert.Equal(t, "treasury", latest.Source)
+	assert.Len(t, latest.Yields, 13)
+	assert.Equal(t, 4.34, latest.Yields["3M"])
+	assert.Equal(t, 4.67, latest.Yields["30Y"])
+
+	spread, ok := curveSpread(latest, "2Y", "10Y")
+	require.True(t, ok)
+	assert.Equal(t, 0.23, spread)
+	spread, ok = curveSpread(latest, "3M", "10Y")
+	require.True(t, ok)
+	assert.Equal(t, 0.09, spread)
+
+	_, err = client.GetYieldCurves(ctx, 1989)
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+func TestParseYieldCurvesMissingTenors(t *testing.T) {
+	// Before October 2022 the 4-month column is present but blank
+	csv := "\xef\xbb\xbfDate,\"1 Mo\",\"2 Mo\",\"3 Mo\",\"4 Mo\",\"6 Mo\",\"1 Yr\",\"2 Yr\",\"3 Yr\",\"5 Yr\",\"7 Yr\",\"10 Yr\",\"20 Yr\",\"30 Yr\"\n" +
+		"10/19/2022,3.35,3.68,3.99,4.19,4.38,4.53,4.55,4.55,4.34,4.24,4.13,4.45,4.10\n" +
+		"10/18/2022,3.32,3.64,3.95,,4.35,4.49,4.44,4.45,4.22,4.13,4.01,4.34,3.99\n"
+
+	curves, err := NewTreasuryClient().parseYieldCurves([]byte(csv))
+	require.NoError(t, err)
+	require.Len(t, curves, 2)
+
+	_, quoted := curves[0].Yields["4M"]
+	assert.False(t, quoted)
+	assert.Len(t, curves[0].Yields, 12)
+	assert.Equal(t, 4.19, curves[1].Yields["4M"])
+
+	// An inverted curve has a negative spread
+	spread, ok := curveSpread(curves[0], "2Y", "10Y")
+	require.True(t, ok)
+	assert.Equal(t, -0.43, spread)
+	_, ok = curveSpread(curves[0], "4M", "10Y")
+	assert.False(t, ok)
+}
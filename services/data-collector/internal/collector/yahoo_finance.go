@@ -4,69 +4,124 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"tradecaptain/data-collector/internal/decimal"
 	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/options"
 )
 
 type YahooFinanceClient struct {
 	httpClient  *http.Client
 	baseURL     string
 	rateLimiter *RateLimiter
+	breaker     *CircuitBreaker
 	userAgent   string
 }
 
 func NewYahooFinanceClient() *YahooFinanceClient {
-	// TODO: Initialize Yahoo Finance client
-	// - Set up HTTP client with appropriate timeouts
-	// - Configure rate limiting (Yahoo Finance has informal limits)
-	// - Set up proper User-Agent to avoid blocking
-	// - Initialize retry logic and circuit breaker
-	// - Set up request/response logging for debugging
-	panic("TODO: Implement Yahoo Finance client initialization")
+	yf := &YahooFinanceClient{
+		httpClient: newHTTPClient(10 * time.Second),
+		baseURL:    "https://query1.finance.yahoo.com",
+		userAgent:  "Mozilla/5.0 (compatible; TradeCaptain/1.0)",
+	}
+	yf.breaker = NewCircuitBreaker(yf.Name(), DefaultCircuitBreakerConfig(), yf.GetAPIHealth)
+	return yf
+}
+
+// CircuitBreaker exposes the client's breaker so the collector can observe transitions
+func (yf *YahooFinanceClient) CircuitBreaker() *CircuitBreaker {
+	return yf.breaker
+}
+
+// Name identifies the provider in MarketData.Source and in configuration
+func (yf *YahooFinanceClient) Name() string {
+	return "yahoo"
 }
 
 // Current Market Data
 func (yf *YahooFinanceClient) GetQuote(ctx context.Context, symbol string) (*models.MarketData, error) {
-	// TODO: Get current quote for a single symbol
-	// - Build Yahoo Finance API URL for single quote
-	// - Add required query parameters and headers
-	// - Make HTTP request with timeout and retry logic
-	// - Parse Yahoo Finance JSON response format
-	// - Handle Yahoo Finance specific data fields
-	// - Convert Yahoo timestamp format to standard time
-	// - Map Yahoo response to MarketData model
-	// - Handle errors and rate limiting responses
-	// - Add data quality validation
-	panic("TODO: Implement single quote retrieval from Yahoo Finance")
+	quotes, err := yf.GetMultipleQuotes(ctx, []string{symbol})
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, &ProviderError{Provider: yf.Name(), Message: "no quote for " + symbol, Err: ErrSymbolNotFound}
+	}
+	return quotes[0], nil
 }
 
 func (yf *YahooFinanceClient) GetMultipleQuotes(ctx context.Context, symbols []string) ([]*models.MarketData, error) {
-	// TODO: Get quotes for multiple symbols in single request
-	// - Batch symbols into Yahoo Finance multi-quote format
-	// - Handle Yahoo's limit on symbols per request (~100)
-	// - Build comma-separated symbol string for URL
-	// - Parse multi-quote JSON response structure
-	// - Handle partial failures (some symbols invalid)
-	// - Maintain symbol-to-data mapping accuracy
-	// - Implement proper error handling for missing data
-	panic("TODO: Implement multiple quotes retrieval from Yahoo Finance")
+	const maxSymbolsPerRequest = 100
+
+	var results []*models.MarketData
+	for start := 0; start < len(symbols); start += maxSymbolsPerRequest {
+		end := start + maxSymbolsPerRequest
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+
+		batch := make([]string, 0, end-start)
+		for _, symbol := range symbols[start:end] {
+			batch = append(batch, yf.normalizeSymbol(symbol))
+		}
+
+		body, err := yf.makeRequest(ctx, yf.buildRequestURL("/v7/finance/quote", map[string]string{
+			"symbols": strings.Join(batch, ","),
+		}))
+		if err != nil {
+			return results, err
+		}
+
+		quotes, err := yf.parseYahooQuotes(body)
+		if err != nil {
+			return results, err
+		}
+
+		// Invalid symbols are simply absent from Yahoo's response
+		for _, quote := range quotes {
+			if err := yf.validateMarketData(quote); err != nil {
+				log.Printf("Dropping invalid Yahoo quote for %s: %v", quote.Symbol, err)
+				continue
+			}
+			results = append(results, quote)
+		}
+	}
+
+	return results, nil
 }
 
 func (yf *YahooFinanceClient) GetHistoricalData(ctx context.Context, symbol string, period string, interval string) ([]*models.MarketData, error) {
-	// TODO: Get historical OHLCV data from Yahoo Finance
-	// - Convert period parameters to Yahoo Finance format
-	// - Handle different intervals (1m, 5m, 1h, 1d, etc.)
-	// - Build historical data API endpoint URL
-	// - Parse Yahoo's historical data JSON structure
-	// - Handle timezone conversions for market hours
-	// - Process dividend and split adjustments
-	// - Convert Yahoo timestamp formats consistently
-	// - Validate data completeness and order
-	// - Handle market holiday gaps in data
-	panic("TODO: Implement historical data retrieval from Yahoo Finance")
+	body, err := yf.makeRequest(ctx, yf.buildRequestURL("/v8/finance/chart/"+url.PathEscape(yf.normalizeSymbol(symbol)), map[string]string{
+		"range":    period,
+		"interval": interval,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return yf.parseYahooChart(body)
+}
+
+// GetHistoricalRange fetches bars between start and end. Yahoo caps how far
+// back intraday intervals go (1m: 30 days, 7 per request; under 1h: 60 days).
+func (yf *YahooFinanceClient) GetHistoricalRange(ctx context.Context, symbol string, start, end time.Time, interval string) ([]*models.MarketData, error) {
+	body, err := yf.makeRequest(ctx, yf.buildRequestURL("/v8/finance/chart/"+url.PathEscape(yf.normalizeSymbol(symbol)), map[string]string{
+		"period1":  strconv.FormatInt(start.Unix(), 10),
+		"period2":  strconv.FormatInt(end.Unix(), 10),
+		"interval": interval,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return yf.parseYahooChart(body)
 }
 
 func (yf *YahooFinanceClient) GetIntradayData(ctx context.Context, symbol string, interval string) ([]*models.MarketData, error) {
@@ -111,16 +166,23 @@ func (yf *YahooFinanceClient) GetFinancialData(ctx context.Context, symbol strin
 	panic("TODO: Implement financial data retrieval from Yahoo Finance")
 }
 
-// Options Data (if available)
-func (yf *YahooFinanceClient) GetOptionsChain(ctx context.Context, symbol string, expiration string) (map[string]interface{}, error) {
-	// TODO: Get options chain data from Yahoo Finance
-	// - Build options chain API endpoint URL
-	// - Handle different expiration dates
-	// - Parse options call and put data
-	// - Extract implied volatility and Greeks
-	// - Handle options-specific data validation
-	// - Process bid/ask spreads and volume data
-	panic("TODO: Implement options chain retrieval from Yahoo Finance")
+// Options Data
+// GetOptionsChain returns symbol's option chain for the expiration, the
+// nearest one when expiration is zero, with the underlying's price and every
+// listed expiration. Yahoo's own implied volatilities are not used.
+func (yf *YahooFinanceClient) GetOptionsChain(ctx context.Context, symbol string, expiration time.Time) (*models.OptionChain, error) {
+	params := map[string]string{}
+	if !expiration.IsZero() {
+		// Yahoo keys expirations by their date's midnight UTC
+		y, m, d := expiration.Date()
+		params["date"] = strconv.FormatInt(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix(), 10)
+	}
+	body, err := yf.makeRequest(ctx, yf.buildRequestURL("/v7/finance/options/"+url.PathEscape(yf.normalizeSymbol(symbol)), params))
+	if err != nil {
+		return nil, err
+	}
+
+	return yf.parseYahooOptions(body)
 }
 
 // Search and Discovery
@@ -147,110 +209,404 @@ func (yf *YahooFinanceClient) GetTrendingSymbols(ctx context.Context, region str
 
 // Data Processing and Helpers
 func (yf *YahooFinanceClient) parseYahooResponse(response []byte) (*models.MarketData, error) {
-	// TODO: Parse Yahoo Finance JSON response to MarketData
-	// - Define Yahoo Finance response structure
-	// - Handle nested JSON objects and arrays
-	// - Extract OHLCV data from Yahoo format
-	// - Convert Yahoo timestamp to standard time.Time
-	// - Handle missing or null values gracefully
-	// - Validate numeric data ranges and types
-	// - Map Yahoo fields to MarketData struct fields
-	panic("TODO: Implement Yahoo Finance response parsing")
+	quotes, err := yf.parseYahooQuotes(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, &ProviderError{Provider: yf.Name(), Message: "empty quote response", Err: ErrSymbolNotFound}
+	}
+	return quotes[0], nil
+}
+
+type yahooQuoteResponse struct {
+	QuoteResponse struct {
+		Result []yahooQuote `json:"result"`
+		Error  *yahooError  `json:"error"`
+	} `json:"quoteResponse"`
+}
+
+type yahooQuote struct {
+	Symbol                     string          `json:"symbol"`
+	RegularMarketPrice         decimal.Decimal `json:"regularMarketPrice"`
+	RegularMarketVolume        int64           `json:"regularMarketVolume"`
+	RegularMarketDayHigh       decimal.Decimal `json:"regularMarketDayHigh"`
+	RegularMarketDayLow        decimal.Decimal `json:"regularMarketDayLow"`
+	RegularMarketOpen          decimal.Decimal `json:"regularMarketOpen"`
+	RegularMarketPreviousClose decimal.Decimal `json:"regularMarketPreviousClose"`
+	RegularMarketChange        decimal.Decimal `json:"regularMarketChange"`
+	RegularMarketChangePercent decimal.Decimal `json:"regularMarketChangePercent"`
+	RegularMarketTime          int64           `json:"regularMarketTime"`
+	MarketCap                  int64           `json:"marketCap"`
+	Bid                        decimal.Decimal `json:"bid"`
+	Ask                        decimal.Decimal `json:"ask"`
+	BidSize                    int64           `json:"bidSize"`
+	AskSize                    int64           `json:"askSize"`
+	Exchange                   string          `json:"exchange"`
+	MarketState                string          `json:"marketState"`
+}
+
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				Symbol string `json:"symbol"`
+			} `json:"meta"`
+			Timestamp  []int64 `json:"timestamp"`
+			Indicators struct {
+				Quote []struct {
+					Open   []*decimal.Decimal `json:"open"`
+					High   []*decimal.Decimal `json:"high"`
+					Low    []*decimal.Decimal `json:"low"`
+					Close  []*decimal.Decimal `json:"close"`
+					Volume []*int64           `json:"volume"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+		Error *yahooError `json:"error"`
+	} `json:"chart"`
+}
+
+type yahooOptionsResponse struct {
+	OptionChain struct {
+		Result []struct {
+			UnderlyingSymbol string  `json:"underlyingSymbol"`
+			ExpirationDates  []int64 `json:"expirationDates"`
+			Quote            struct {
+				RegularMarketPrice decimal.Decimal `json:"regularMarketPrice"`
+				RegularMarketTime  int64           `json:"regularMarketTime"`
+			} `json:"quote"`
+			Options []struct {
+				ExpirationDate int64              `json:"expirationDate"`
+				Calls          []yahooOptionQuote `json:"calls"`
+				Puts           []yahooOptionQuote `json:"puts"`
+			} `json:"options"`
+		} `json:"result"`
+		Error *yahooError `json:"error"`
+	} `json:"optionChain"`
+}
+
+type yahooOptionQuote struct {
+	ContractSymbol string          `json:"contractSymbol"`
+	Strike         decimal.Decimal `json:"strike"`
+	LastPrice      decimal.Decimal `json:"lastPrice"`
+	Bid            decimal.Decimal `json:"bid"`
+	Ask            decimal.Decimal `json:"ask"`
+	Volume         int64           `json:"volume"`
+	OpenInterest   int64           `json:"openInterest"`
+	ContractSize   string          `json:"contractSize"`
+	Expiration     int64           `json:"expiration"`
+}
+
+type yahooError struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+func (yf *YahooFinanceClient) parseYahooQuotes(response []byte) ([]*models.MarketData, error) {
+	var parsed yahooQuoteResponse
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode Yahoo quote response: %w", err)
+	}
+	if parsed.QuoteResponse.Error != nil {
+		return nil, &ProviderError{Provider: yf.Name(), Message: parsed.QuoteResponse.Error.Description}
+	}
+
+	results := make([]*models.MarketData, 0, len(parsed.QuoteResponse.Result))
+	for _, q := range parsed.QuoteResponse.Result {
+		results = append(results, &models.MarketData{
+			Symbol:        q.Symbol,
+			Price:         q.RegularMarketPrice,
+			Volume:        q.RegularMarketVolume,
+			High:          q.RegularMarketDayHigh,
+			Low:           q.RegularMarketDayLow,
+			Open:          q.RegularMarketOpen,
+			Close:         q.RegularMarketPrice,
+			Change:        q.RegularMarketChange,
+			ChangePercent: q.RegularMarketChangePercent,
+			MarketCap:     q.MarketCap,
+			PrevClose:     q.RegularMarketPreviousClose,
+			Bid:           q.Bid,
+			Ask:           q.Ask,
+			BidSize:       q.BidSize,
+			AskSize:       q.AskSize,
+			Session:       yahooSession(q.MarketState),
+			Exchange:      q.Exchange,
+			Timestamp:     time.Unix(q.RegularMarketTime, 0).UTC(),
+			Source:        yf.Name(),
+		})
+	}
+
+	return results, nil
+}
+
+// yahooSession maps Yahoo's marketState. CLOSED and the overnight PREPRE
+// and POSTPOST states have no session.
+func yahooSession(state string) models.MarketSession {
+	switch state {
+	case "PRE":
+		return models.SessionPreMarket
+	case "REGULAR":
+		return models.SessionRegular
+	case "POST":
+		return models.SessionAfterHours
+	}
+	return models.SessionUnknown
+}
+
+func (yf *YahooFinanceClient) parseYahooChart(response []byte) ([]*models.MarketData, error) {
+	var parsed yahooChartResponse
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode Yahoo chart response: %w", err)
+	}
+	if parsed.Chart.Error != nil {
+		return nil, &ProviderError{Provider: yf.Name(), Message: parsed.Chart.Error.Description}
+	}
+	if len(parsed.Chart.Result) == 0 {
+		return nil, &ProviderError{Provider: yf.Name(), Message: "empty chart response", Err: ErrSymbolNotFound}
+	}
+
+	result := parsed.Chart.Result[0]
+	if len(result.Indicators.Quote) == 0 {
+		return nil, nil
+	}
+	bars := result.Indicators.Quote[0]
+
+	history := make([]*models.MarketData, 0, len(result.Timestamp))
+	for i, ts := range result.Timestamp {
+		// Yahoo emits nulls for intervals with no trades (halts, holidays)
+		if i >= len(bars.Close) || bars.Close[i] == nil {
+			continue
+		}
+
+		data := &models.MarketData{
+			Symbol:    result.Meta.Symbol,
+			Price:     *bars.Close[i],
+			Close:     *bars.Close[i],
+			Timestamp: time.Unix(ts, 0).UTC(),
+			Source:    yf.Name(),
+		}
+		if i < len(bars.Open) && bars.Open[i] != nil {
+			data.Open = *bars.Open[i]
+		}
+		if i < len(bars.High) && bars.High[i] != nil {
+			data.High = *bars.High[i]
+		}
+		if i < len(bars.Low) && bars.Low[i] != nil {
+			data.Low = *bars.Low[i]
+		}
+		if i < len(bars.Volume) && bars.Volume[i] != nil {
+			data.Volume = *bars.Volume[i]
+		}
+		history = append(history, data)
+	}
+
+	return history, nil
+}
+
+// parseYahooOptions reads an options response. Contracts are identified by
+// their OCC symbol; mini options and those whose symbol does not parse are
+// dropped.
+func (yf *YahooFinanceClient) parseYahooOptions(response []byte) (*models.OptionChain, error) {
+	var parsed yahooOptionsResponse
+	if err := json.Unmarshal(response, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode Yahoo options response: %w", err)
+	}
+	if parsed.OptionChain.Error != nil {
+		return nil, &ProviderError{Provider: yf.Name(), Message: parsed.OptionChain.Error.Description}
+	}
+	if len(parsed.OptionChain.Result) == 0 || len(parsed.OptionChain.Result[0].Options) == 0 {
+		return nil, &ProviderError{Provider: yf.Name(), Message: "empty options response", Err: ErrSymbolNotFound}
+	}
+
+	result := parsed.OptionChain.Result[0]
+	timestamp := time.Unix(result.Quote.RegularMarketTime, 0).UTC()
+	chain := &models.OptionChain{
+		Underlying:      result.UnderlyingSymbol,
+		Expiration:      time.Unix(result.Options[0].ExpirationDate, 0).UTC(),
+		UnderlyingPrice: result.Quote.RegularMarketPrice,
+		Timestamp:       timestamp,
+	}
+	for _, date := range result.ExpirationDates {
+		chain.Expirations = append(chain.Expirations, time.Unix(date, 0).UTC())
+	}
+
+	for _, quotes := range [][]yahooOptionQuote{result.Options[0].Calls, result.Options[0].Puts} {
+		for _, q := range quotes {
+			if q.ContractSize != "" && q.ContractSize != "REGULAR" {
+				continue
+			}
+			contract, err := options.ParseSymbol(q.ContractSymbol)
+			if err != nil {
+				log.Printf("Dropping Yahoo option quote: %v", err)
+				continue
+			}
+			contract.Underlying = result.UnderlyingSymbol
+			contract.Bid = q.Bid
+			contract.Ask = q.Ask
+			contract.Last = q.LastPrice
+			contract.Volume = q.Volume
+			contract.OpenInterest = q.OpenInterest
+			contract.UnderlyingPrice = chain.UnderlyingPrice
+			contract.Timestamp = timestamp
+			contract.Source = yf.Name()
+			chain.Contracts = append(chain.Contracts, contract)
+		}
+	}
+	options.SortContracts(chain.Contracts)
+	return chain, nil
 }
 
 func (yf *YahooFinanceClient) buildRequestURL(endpoint string, params map[string]string) string {
-	// TODO: Build Yahoo Finance API request URLs
-	// - Construct base URL with proper endpoint
-	// - Add required query parameters
-	// - Handle URL encoding for special characters
-	// - Add timestamp and version parameters if needed
-	// - Validate URL format and length limits
-	panic("TODO: Implement Yahoo Finance URL building")
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	if len(query) == 0 {
+		return yf.baseURL + endpoint
+	}
+	return yf.baseURL + endpoint + "?" + query.Encode()
 }
 
 func (yf *YahooFinanceClient) makeRequest(ctx context.Context, url string) ([]byte, error) {
-	// TODO: Make HTTP request to Yahoo Finance API
-	// - Create HTTP request with proper headers
-	// - Add User-Agent and other required headers
-	// - Implement request timeout and cancellation
-	// - Handle HTTP errors and status codes
-	// - Implement retry logic with exponential backoff
-	// - Handle rate limiting responses (429 status)
-	// - Log requests and responses for debugging
-	// - Return response body or appropriate error
-	panic("TODO: Implement Yahoo Finance HTTP request handling")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Yahoo request: %w", err)
+	}
+
+	// Chart and options endpoints embed the symbol in the path; limit each
+	// as one endpoint
+	endpoint := req.URL.Path
+	for _, prefix := range []string{"/v8/finance/chart", "/v7/finance/options"} {
+		if strings.HasPrefix(endpoint, prefix+"/") {
+			endpoint = prefix
+		}
+	}
+	// Check the breaker before spending a rate limit token on a provider we won't call
+	if yf.breaker != nil {
+		if err := yf.breaker.Allow(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := yf.checkRateLimit(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", yf.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	body, err := yf.doRequest(req)
+	if yf.breaker != nil {
+		yf.breaker.Record(ctx, time.Since(start), err)
+	}
+	return body, err
+}
+
+func (yf *YahooFinanceClient) doRequest(req *http.Request) ([]byte, error) {
+	resp, err := yf.httpClient.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Provider: yf.Name(), Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProviderError{Provider: yf.Name(), Message: "failed to read response", Err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, yf.handleYahooError(resp, body)
+	}
+
+	return body, nil
 }
 
 // Rate Limiting and Health
-func (yf *YahooFinanceClient) checkRateLimit(ctx context.Context) error {
-	// TODO: Check rate limiting before making requests
-	// - Implement token bucket or sliding window rate limiting
-	// - Handle Yahoo Finance informal rate limits
-	// - Wait for rate limit reset if needed
-	// - Return error if rate limit exceeded
-	// - Log rate limiting events for monitoring
-	panic("TODO: Implement rate limiting check for Yahoo Finance")
+func (yf *YahooFinanceClient) checkRateLimit(ctx context.Context, endpoint string) error {
+	if yf.rateLimiter == nil {
+		return nil
+	}
+	return yf.rateLimiter.Wait(ctx, endpoint)
 }
 
 func (yf *YahooFinanceClient) GetAPIHealth(ctx context.Context) (bool, error) {
-	// TODO: Check Yahoo Finance API health status
-	// - Make test request to Yahoo Finance API
-	// - Verify response format and data quality
-	// - Check response times and availability
-	// - Handle temporary outages or maintenance
-	// - Return health status with details
-	panic("TODO: Implement Yahoo Finance API health check")
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, err := yf.GetQuote(ctx, "SPY"); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (yf *YahooFinanceClient) GetRateLimitStatus() (requests int, resetTime time.Time, limit int) {
-	// TODO: Get current rate limit status
-	// - Return current request count in window
-	// - Calculate time until rate limit reset
-	// - Return maximum requests allowed per window
-	// - Handle rate limit window tracking
-	panic("TODO: Implement rate limit status retrieval")
+	if yf.rateLimiter == nil {
+		return 0, time.Now(), 0
+	}
+	return yf.rateLimiter.Status()
 }
 
 // Error Handling
 func (yf *YahooFinanceClient) handleYahooError(response *http.Response, body []byte) error {
-	// TODO: Handle Yahoo Finance specific errors
-	// - Parse Yahoo error response format
-	// - Map HTTP status codes to specific errors
-	// - Handle rate limiting (429) responses
-	// - Process API maintenance notifications
-	// - Handle symbol not found errors
-	// - Return appropriate error types for different scenarios
-	panic("TODO: Implement Yahoo Finance error handling")
+	providerErr := &ProviderError{
+		Provider:   yf.Name(),
+		StatusCode: response.StatusCode,
+		Message:    http.StatusText(response.StatusCode),
+	}
+
+	// Yahoo wraps errors in either {"finance":{"error":...}} or {"chart":{"error":...}}
+	var envelope struct {
+		Finance struct {
+			Error *yahooError `json:"error"`
+		} `json:"finance"`
+		Chart struct {
+			Error *yahooError `json:"error"`
+		} `json:"chart"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		if e := envelope.Finance.Error; e != nil {
+			providerErr.Message = e.Description
+		} else if e := envelope.Chart.Error; e != nil {
+			providerErr.Message = e.Description
+		}
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests:
+		providerErr.Err = ErrRateLimited
+	case http.StatusNotFound:
+		providerErr.Err = ErrSymbolNotFound
+	}
+
+	return providerErr
 }
 
 func (yf *YahooFinanceClient) isRetryableError(err error) bool {
-	// TODO: Determine if error is retryable
-	// - Identify temporary network errors
-	// - Handle server errors (5xx) as retryable
-	// - Mark rate limiting as retryable with delay
-	// - Consider client errors (4xx) as non-retryable
-	// - Handle timeout errors as retryable
-	panic("TODO: Implement retryable error detection")
+	return retryable(err)
 }
 
 // Data Validation
 func (yf *YahooFinanceClient) validateMarketData(data *models.MarketData) error {
-	// TODO: Validate Yahoo Finance market data
-	// - Check required fields are present and non-zero
-	// - Validate price ranges and relationships (high >= low)
-	// - Verify timestamp is within reasonable range
-	// - Check volume is non-negative
-	// - Validate symbol format and characters
-	// - Detect and flag potential data anomalies
-	panic("TODO: Implement market data validation")
+	if data.Symbol == "" {
+		return fmt.Errorf("missing symbol")
+	}
+	if data.Price.Sign() <= 0 {
+		return fmt.Errorf("non-positive price %s", data.Price)
+	}
+	if data.High.Sign() > 0 && data.Low.Sign() > 0 && data.High.LessThan(data.Low) {
+		return fmt.Errorf("high %s below low %s", data.High, data.Low)
+	}
+	if data.Volume < 0 {
+		return fmt.Errorf("negative volume %d", data.Volume)
+	}
+	if data.Timestamp.After(time.Now().Add(5 * time.Minute)) {
+		return fmt.Errorf("timestamp %s in the future", data.Timestamp)
+	}
+	return nil
 }
 
 func (yf *YahooFinanceClient) normalizeSymbol(symbol string) string {
-	// TODO: Normalize symbol format for Yahoo Finance
-	// - Convert symbol to Yahoo Finance format
-	// - Handle different exchange suffixes (.TO, .L, etc.)
-	// - Process special characters and spacing
-	// - Validate symbol length and format
-	// - Return standardized symbol format
-	panic("TODO: Implement symbol normalization for Yahoo Finance")
-}
\ No newline at end of file
+	// Yahoo uses dashes for share classes (BRK-B) and keeps dots for exchange suffixes (VOD.L)
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(symbol)), "/", "-")
+}
@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+// treasuryHistoryYears is how many calendar years before the current one
+// the first pass loads
+const treasuryHistoryYears = 1
+
+// yieldSpread is a standard yield curve spread, Long minus Short, kept as its
+// own daily economic series
+type yieldSpread struct {
+	Series string
+	Title  string
+	Short  string
+	Long   string
+}
+
+var yieldSpreads = []yieldSpread{
+	{Series: "UST2S10S", Title: "10-Year minus 2-Year Treasury Par Yield", Short: "2Y", Long: "10Y"},
+	{Series: "UST3M10Y", Title: "10-Year minus 3-Month Treasury Par Yield", Short: "3M", Long: "10Y"},
+}
+
+// StartTreasuryCollection collects the Treasury par yield curve every
+// TreasuryInterval. It belongs to the economic service and pauses with it; a
+// failed pass is simply repeated on the next one.
+func (dc *DataCollector) StartTreasuryCollection(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		timer.Reset(dc.currentConfig().TreasuryInterval)
+		if dc.isPaused(ServiceEconomic) {
+			continue
+		}
+
+		if err := dc.CollectYieldCurves(ctx); err != nil && ctx.Err() == nil {
+			dc.HandleCollectionError(ctx, err, "treasury", nil)
+		}
+	}
+}
+
+// CollectYieldCurves stores the par yield curves published since the newest
+// stored one, and corrections to curves of the years it reads. Each new
+// curve's spreads are stored as economic series and published as releases,
+// along with an inversion or disinversion whenever a spread changes sign
+// from the previous business day. The first pass only fills history back to
+// the start of the previous year, without publishing.
+func (dc *DataCollector) CollectYieldCurves(ctx context.Context) error {
+	client := dc.yieldCurveClient()
+	if dc.db == nil {
+		return errors.New("yield curve collection needs a database")
+	}
+	if err := dc.db.EnsureYieldCurves(ctx); err != nil {
+		return err
+	}
+	if err := dc.db.EnsureEconomicIndicators(ctx); err != nil {
+		return err
+	}
+
+	latest, err := dc.db.LatestYieldCurveDate(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	firstLoad := latest.IsZero()
+	// From the newest stored curve's year, so early January still sees the
+	// last days of December and the curve before the first new one
+	from := latest.Year()
+	if firstLoad {
+		from = now.Year() - treasuryHistoryYears
+	}
+
+	var curves []*models.YieldCurve
+	for year := from; year <= now.Year(); year++ {
+		yearCurves, err := client.GetYieldCurves(ctx, year)
+		if err != nil && !errors.Is(err, ErrSymbolNotFound) {
+			return fmt.Errorf("failed to read %d yield curves: %w", year, err)
+		}
+		curves = append(curves, yearCurves...)
+	}
+
+	stored := 0
+	var previous *models.YieldCurve
+	for _, curve := range curves {
+		curve.UpdatedAt = now
+		if curve.Date.After(latest) {
+			// Spreads go first: if saving the curve fails, the next pass sees
+			// it as new again and the stored spreads are not published twice
+			if err := dc.processYieldSpreads(ctx, curve, previous, !firstLoad); err != nil {
+				return err
+			}
+		}
+		added, err := dc.db.SaveYieldCurve(ctx, curve)
+		if err != nil {
+			return err
+		}
+		if added {
+			stored++
+		}
+		previous = curve
+	}
+
+	if firstLoad {
+		log.Printf("Loaded %d Treasury yield curves", stored)
+	} else if stored > 0 {
+		log.Printf("Stored %d new Treasury yield curves", stored)
+	}
+	return nil
+}
+
+// processYieldSpreads stores a curve's spreads and, when publish is set,
+// publishes the new ones and any change of sign from the previous curve
+func (dc *DataCollector) processYieldSpreads(ctx context.Context, curve, previous *models.YieldCurve, publish bool) error {
+	for _, spread := range yieldSpreads {
+		value, ok := curveSpread(curve, spread.Short, spread.Long)
+		if !ok {
+			continue
+		}
+		indicator, err := dc.ProcessEconomicData(ctx, &models.EconomicIndicator{
+			Series:        spread.Series,
+			Title:         spread.Title,
+			Value:         value,
+			Date:          curve.Date,
+			Units:         "Percent",
+			Frequency:     "Daily",
+			Source:        curve.Source,
+			LastUpdated:   curve.UpdatedAt,
+			RealtimeStart: curve.Date,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", spread.Series, err)
+		}
+		if indicator == nil || !publish || dc.producer == nil {
+			continue
+		}
+
+		if err := dc.producer.PublishEconomicEvent(ctx, indicator); err != nil {
+			log.Printf("Failed to publish %s economic event: %v", spread.Series, err)
+		}
+		if previous == nil {
+			continue
+		}
+		before, ok := curveSpread(previous, spread.Short, spread.Long)
+		if !ok || (before < 0) == (value < 0) {
+			continue
+		}
+		log.Printf("%s changed sign on %s: %.2f -> %.2f", spread.Series, curve.Date.Format("2006-01-02"), before, value)
+		if err := dc.producer.PublishCurveInversion(ctx, indicator, value < 0); err != nil {
+			log.Printf("Failed to publish %s inversion event: %v", spread.Series, err)
+		}
+	}
+	return nil
+}
+
+// curveSpread returns the yield of long minus that of short in percentage
+// points, rounded to the curve's two decimals, and false if either tenor was
+// not quoted
+func curveSpread(curve *models.YieldCurve, short, long string) (float64, bool) {
+	shortYield, ok := curve.Yields[short]
+	if !ok {
+		return 0, false
+	}
+	longYield, ok := curve.Yields[long]
+	if !ok {
+		return 0, false
+	}
+	return math.Round((longYield-shortYield)*100) / 100, true
+}
@@ -0,0 +1,268 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Change is one field that differs between two configurations. Secret values
+// are never included; a changed key shows up as "rotated".
+type Change struct {
+	Field           string `json:"field"`
+	Old             string `json:"old"`
+	New             string `json:"new"`
+	RestartRequired bool   `json:"restart_required,omitempty"`
+}
+
+// Diff describes what a configuration reload changes
+type Diff struct {
+	StocksAdded   []string `json:"stocks_added,omitempty"`
+	StocksRemoved []string `json:"stocks_removed,omitempty"`
+	CryptoAdded   []string `json:"crypto_added,omitempty"`
+	CryptoRemoved []string `json:"crypto_removed,omitempty"`
+	Changes       []Change `json:"changes,omitempty"`
+}
+
+func (d Diff) Empty() bool {
+	return len(d.StocksAdded) == 0 && len(d.StocksRemoved) == 0 &&
+		len(d.CryptoAdded) == 0 && len(d.CryptoRemoved) == 0 && len(d.Changes) == 0
+}
+
+// Changed reports whether any of the named fields changed
+func (d Diff) Changed(fields ...string) bool {
+	for _, change := range d.Changes {
+		for _, field := range fields {
+			if change.Field == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d Diff) String() string {
+	var parts []string
+	if len(d.StocksAdded) > 0 {
+		parts = append(parts, "stocks added: "+strings.Join(d.StocksAdded, ","))
+	}
+	if len(d.StocksRemoved) > 0 {
+		parts = append(parts, "stocks removed: "+strings.Join(d.StocksRemoved, ","))
+	}
+	if len(d.CryptoAdded) > 0 {
+		parts = append(parts, "crypto added: "+strings.Join(d.CryptoAdded, ","))
+	}
+	if len(d.CryptoRemoved) > 0 {
+		parts = append(parts, "crypto removed: "+strings.Join(d.CryptoRemoved, ","))
+	}
+	for _, change := range d.Changes {
+		part := fmt.Sprintf("%s: %s -> %s", change.Field, change.Old, change.New)
+		if change.RestartRequired {
+			part += " (restart required)"
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, "; ")
+}
+
+type diffField struct {
+	name    string
+	value   func(*Config) string
+	secret  bool
+	restart bool
+}
+
+// diffFields lists every compared and reported field. Connection settings are only read at
+// startup, so changing them is reported but needs a restart.
+var diffFields = []diffField{
+	{name: "database_url", value: func(c *Config) string { return c.DatabaseURL }, secret: true, restart: true},
+	{name: "redis_url", value: func(c *Config) string { return c.RedisURL }, secret: true, restart: true},
+	{name: "questdb_url", value: func(c *Config) string { return c.QuestDBURL }, secret: true, restart: true},
+	{name: "kafka_bootstrap_servers", value: func(c *Config) string { return c.KafkaBootstrapServers }, restart: true},
+	{name: "retry.queue_path", value: func(c *Config) string { return c.RetryQueuePath }, restart: true},
+	{name: "admin.addr", value: func(c *Config) string { return c.AdminAddr }, restart: true},
+	{name: "admin.token", value: func(c *Config) string { return c.AdminToken }, secret: true, restart: true},
+	{name: "replay.file", value: func(c *Config) string { return c.ReplayFile }, restart: true},
+	{name: "replay.speed", value: func(c *Config) string { return c.ReplaySpeed }, restart: true},
+	{name: "replay.clock", value: func(c *Config) string { return c.ReplayClock }, restart: true},
+	{name: "http_fixtures.mode", value: func(c *Config) string { return c.HTTPFixtureMode }, restart: true},
+	{name: "http_fixtures.dir", value: func(c *Config) string { return c.HTTPFixtureDir }, restart: true},
+
+	{name: "api_keys.alpha_vantage", value: func(c *Config) string { return c.AlphaVantageAPIKey }, secret: true},
+	{name: "api_keys.iex_cloud", value: func(c *Config) string { return c.IEXCloudAPIKey }, secret: true},
+	{name: "api_keys.news_api", value: func(c *Config) string { return c.NewsAPIKey }, secret: true},
+	{name: "api_keys.fred", value: func(c *Config) string { return c.FREDAPIKey }, secret: true},
+	{name: "api_keys.coingecko", value: func(c *Config) string { return c.CoinGeckoAPIKey }, secret: true},
+	{name: "api_keys.alpaca_key_id", value: func(c *Config) string { return c.AlpacaKeyID }, secret: true},
+	{name: "api_keys.alpaca_secret_key", value: func(c *Config) string { return c.AlpacaSecretKey }, secret: true},
+
+	{name: "intervals.market_data", value: func(c *Config) string { return c.MarketDataInterval.String() }},
+	{name: "intervals.news", value: func(c *Config) string { return c.NewsInterval.String() }},
+	{name: "intervals.economic_data", value: func(c *Config) string { return c.EconomicDataInterval.String() }},
+	{name: "intervals.extended_hours", value: func(c *Config) string { return c.ExtendedHoursInterval.String() }},
+	{name: "intervals.filings", value: func(c *Config) string { return c.FilingsInterval.String() }},
+	{name: "intervals.treasury", value: func(c *Config) string { return c.TreasuryInterval.String() }},
+	{name: "intervals.corporate_actions", value: func(c *Config) string { return c.CorporateActionsInterval.String() }},
+	{name: "intervals.options", value: func(c *Config) string { return c.OptionsInterval.String() }},
+
+	{name: "stream.url", value: func(c *Config) string { return c.StockStreamURL }},
+	{name: "stream.ping_interval", value: func(c *Config) string { return c.StreamPingInterval.String() }},
+	{name: "stream.read_timeout", value: func(c *Config) string { return c.StreamReadTimeout.String() }},
+
+	{name: "crypto.stream_url", value: func(c *Config) string { return c.CryptoStreamURL }},
+
+	{name: "news.feeds", value: func(c *Config) string { return strings.Join(c.NewsFeeds, ",") }},
+
+	{name: "economic.series", value: func(c *Config) string { return strings.Join(c.EconomicSeries, ",") }},
+	{name: "economic.revision_window", value: func(c *Config) string { return c.EconomicRevisionWindow.String() }},
+
+	{name: "filings.user_agent", value: func(c *Config) string { return c.SECUserAgent }},
+	{name: "filings.lookback", value: func(c *Config) string { return c.FilingsLookback.String() }},
+	{name: "filings.event_forms", value: func(c *Config) string { return strings.Join(c.FilingEventForms, ",") }},
+
+	{name: "options.symbols", value: func(c *Config) string { return strings.Join(c.OptionsSymbols, ",") }},
+	{name: "options.expirations", value: func(c *Config) string { return fmt.Sprint(c.OptionsExpirations) }},
+	{name: "options.risk_free_rate", value: func(c *Config) string { return fmt.Sprint(c.OptionsRiskFreeRate) }},
+
+	{name: "rate_limits.max_requests_per_second", value: func(c *Config) string { return fmt.Sprint(c.MaxRequestsPerSecond) }},
+	{name: "rate_limits.mode", value: func(c *Config) string { return c.RateLimitMode }},
+	{name: "rate_limits.providers", value: func(c *Config) string { return formatRateLimits(c.RateLimits) }},
+
+	{name: "quote_providers.order", value: func(c *Config) string { return strings.Join(c.QuoteProviders, ",") }},
+	{name: "quote_providers.weights", value: func(c *Config) string { return formatIntMap(c.QuoteProviderWeights) }},
+	{name: "quote_providers.strategy", value: func(c *Config) string { return c.QuoteProviderStrategy }},
+	{name: "quote_providers.max_quote_age", value: func(c *Config) string { return c.MaxQuoteAge.String() }},
+
+	{name: "synthetic.volatility", value: func(c *Config) string { return fmt.Sprint(c.SyntheticVolatility) }},
+	{name: "synthetic.correlation", value: func(c *Config) string { return fmt.Sprint(c.SyntheticCorrelation) }},
+	{name: "synthetic.jumps_per_day", value: func(c *Config) string { return fmt.Sprint(c.SyntheticJumpsPerDay) }},
+	{name: "synthetic.halts_per_day", value: func(c *Config) string { return fmt.Sprint(c.SyntheticHaltsPerDay) }},
+	{name: "synthetic.halt_duration", value: func(c *Config) string { return c.SyntheticHaltDuration.String() }},
+	{name: "synthetic.spread_bps", value: func(c *Config) string { return fmt.Sprint(c.SyntheticSpreadBps) }},
+	{name: "synthetic.seed", value: func(c *Config) string { return fmt.Sprint(c.SyntheticSeed) }},
+
+	{name: "circuit_breaker.error_rate", value: func(c *Config) string { return fmt.Sprint(c.BreakerErrorRate) }},
+	{name: "circuit_breaker.slow_call_latency", value: func(c *Config) string { return c.BreakerSlowCallLatency.String() }},
+	{name: "circuit_breaker.rate_limit_count", value: func(c *Config) string { return fmt.Sprint(c.BreakerRateLimitCount) }},
+	{name: "circuit_breaker.open_timeout", value: func(c *Config) string { return c.BreakerOpenTimeout.String() }},
+
+	{name: "retry.max_attempts", value: func(c *Config) string { return fmt.Sprint(c.RetryMaxAttempts) }},
+	{name: "retry.base_delay", value: func(c *Config) string { return c.RetryBaseDelay.String() }},
+	{name: "retry.max_delay", value: func(c *Config) string { return c.RetryMaxDelay.String() }},
+	{name: "retry.item_ttl", value: func(c *Config) string { return c.RetryItemTTL.String() }},
+	{name: "retry.interval", value: func(c *Config) string { return c.RetryInterval.String() }},
+	{name: "retry.batch_size", value: func(c *Config) string { return fmt.Sprint(c.RetryBatchSize) }},
+
+	{name: "consensus.window", value: func(c *Config) string { return c.ConsensusWindow.String() }},
+	{name: "consensus.tolerance_bps", value: func(c *Config) string { return fmt.Sprint(c.ConsensusToleranceBps) }},
+	{name: "consensus.method", value: func(c *Config) string { return c.ConsensusMethod }},
+	{name: "consensus.sample_size", value: func(c *Config) string { return fmt.Sprint(c.ConsensusSampleSize) }},
+
+	{name: "gap_detection.scan_interval", value: func(c *Config) string { return c.GapScanInterval.String() }},
+	{name: "gap_detection.lookback", value: func(c *Config) string { return c.GapLookback.String() }},
+	{name: "gap_detection.intraday_lookback", value: func(c *Config) string { return c.GapIntradayLookback.String() }},
+	{name: "gap_detection.max_fills", value: func(c *Config) string { return fmt.Sprint(c.GapMaxFills) }},
+}
+
+// Compare returns the differences going from old to new
+func Compare(old, new *Config) Diff {
+	var d Diff
+	d.StocksAdded, d.StocksRemoved = symbolChanges(old.StockSymbols, new.StockSymbols)
+	d.CryptoAdded, d.CryptoRemoved = symbolChanges(old.CryptoSymbols, new.CryptoSymbols)
+
+	for _, field := range diffFields {
+		before, after := field.value(old), field.value(new)
+		if before == after {
+			continue
+		}
+
+		change := Change{Field: field.name, Old: before, New: after, RestartRequired: field.restart}
+		if field.secret {
+			change.Old, change.New = redact(before, after)
+		}
+		d.Changes = append(d.Changes, change)
+	}
+	return d
+}
+
+// Effective returns the configuration keyed by the same field names Compare
+// reports, with secrets reduced to "set" or "unset"
+func (c *Config) Effective() map[string]interface{} {
+	fields := make(map[string]interface{}, len(diffFields)+2)
+	for _, field := range diffFields {
+		value := field.value(c)
+		if field.secret {
+			value = "unset"
+			if field.value(c) != "" {
+				value = "set"
+			}
+		}
+		fields[field.name] = value
+	}
+	fields["symbols.stocks"] = c.StockSymbols
+	fields["symbols.crypto"] = c.CryptoSymbols
+	return fields
+}
+
+func redact(before, after string) (string, string) {
+	switch {
+	case before == "":
+		return "unset", "set"
+	case after == "":
+		return "set", "unset"
+	default:
+		return "previous", "rotated"
+	}
+}
+
+func symbolChanges(before, after []string) (added, removed []string) {
+	had := make(map[string]bool, len(before))
+	for _, symbol := range before {
+		had[symbol] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, symbol := range after {
+		has[symbol] = true
+		if !had[symbol] {
+			added = append(added, symbol)
+		}
+	}
+	for _, symbol := range before {
+		if !has[symbol] {
+			removed = append(removed, symbol)
+		}
+	}
+	return added, removed
+}
+
+func formatRateLimits(limits map[string]RateLimit) string {
+	keys := make([]string, 0, len(limits))
+	for key := range limits {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		limit := limits[key]
+		parts[i] = fmt.Sprintf("%s=%d/%s:%d", key, limit.Requests, limit.Per, limit.Burst)
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatIntMap(values map[string]int) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s=%d", key, values[key])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,423 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the on-disk shape of the collector config. Every field is
+// optional; whatever is set replaces the environment value. JSON files are
+// accepted as well since JSON is valid YAML.
+type fileConfig struct {
+	DatabaseURL           *string `yaml:"database_url"`
+	RedisURL              *string `yaml:"redis_url"`
+	QuestDBURL            *string `yaml:"questdb_url"`
+	KafkaBootstrapServers *string `yaml:"kafka_bootstrap_servers"`
+
+	APIKeys struct {
+		AlphaVantage *string `yaml:"alpha_vantage"`
+		IEXCloud     *string `yaml:"iex_cloud"`
+		NewsAPI      *string `yaml:"news_api"`
+		FRED         *string `yaml:"fred"`
+		CoinGecko    *string `yaml:"coingecko"`
+		AlpacaKeyID  *string `yaml:"alpaca_key_id"`
+		AlpacaSecret *string `yaml:"alpaca_secret_key"`
+	} `yaml:"api_keys"`
+
+	Intervals struct {
+		MarketData       *time.Duration `yaml:"market_data"`
+		News             *time.Duration `yaml:"news"`
+		EconomicData     *time.Duration `yaml:"economic_data"`
+		ExtendedHours    *time.Duration `yaml:"extended_hours"`
+		Filings          *time.Duration `yaml:"filings"`
+		Treasury         *time.Duration `yaml:"treasury"`
+		CorporateActions *time.Duration `yaml:"corporate_actions"`
+		Options          *time.Duration `yaml:"options"`
+	} `yaml:"intervals"`
+
+	Symbols struct {
+		Stocks []string `yaml:"stocks"`
+		Crypto []string `yaml:"crypto"`
+	} `yaml:"symbols"`
+
+	Stream struct {
+		URL          *string        `yaml:"url"`
+		PingInterval *time.Duration `yaml:"ping_interval"`
+		ReadTimeout  *time.Duration `yaml:"read_timeout"`
+	} `yaml:"stream"`
+
+	Crypto struct {
+		StreamURL *string `yaml:"stream_url"`
+	} `yaml:"crypto"`
+
+	News struct {
+		Feeds []string `yaml:"feeds"`
+	} `yaml:"news"`
+
+	Economic struct {
+		Series         []string       `yaml:"series"`
+		RevisionWindow *time.Duration `yaml:"revision_window"`
+	} `yaml:"economic"`
+
+	Filings struct {
+		UserAgent  *string        `yaml:"user_agent"`
+		Lookback   *time.Duration `yaml:"lookback"`
+		EventForms []string       `yaml:"event_forms"`
+	} `yaml:"filings"`
+
+	Options struct {
+		Symbols      []string `yaml:"symbols"`
+		Expirations  *int     `yaml:"expirations"`
+		RiskFreeRate *float64 `yaml:"risk_free_rate"`
+	} `yaml:"options"`
+
+	Replay struct {
+		File  *string `yaml:"file"`
+		Speed *string `yaml:"speed"`
+		Clock *string `yaml:"clock"`
+	} `yaml:"replay"`
+
+	HTTPFixtures struct {
+		Mode *string `yaml:"mode"`
+		Dir  *string `yaml:"dir"`
+	} `yaml:"http_fixtures"`
+
+	RateLimits struct {
+		MaxRequestsPerSecond *int              `yaml:"max_requests_per_second"`
+		Mode                 *string           `yaml:"mode"`
+		Providers            map[string]string `yaml:"providers"`
+	} `yaml:"rate_limits"`
+
+	QuoteProviders struct {
+		Order       []string       `yaml:"order"`
+		Weights     map[string]int `yaml:"weights"`
+		Strategy    *string        `yaml:"strategy"`
+		MaxQuoteAge *time.Duration `yaml:"max_quote_age"`
+	} `yaml:"quote_providers"`
+
+	Synthetic struct {
+		Volatility   *float64       `yaml:"volatility"`
+		Correlation  *float64       `yaml:"correlation"`
+		JumpsPerDay  *float64       `yaml:"jumps_per_day"`
+		HaltsPerDay  *float64       `yaml:"halts_per_day"`
+		HaltDuration *time.Duration `yaml:"halt_duration"`
+		SpreadBps    *float64       `yaml:"spread_bps"`
+		Seed         *int           `yaml:"seed"`
+	} `yaml:"synthetic"`
+
+	CircuitBreaker struct {
+		ErrorRate       *float64       `yaml:"error_rate"`
+		SlowCallLatency *time.Duration `yaml:"slow_call_latency"`
+		RateLimitCount  *int           `yaml:"rate_limit_count"`
+		OpenTimeout     *time.Duration `yaml:"open_timeout"`
+	} `yaml:"circuit_breaker"`
+
+	Retry struct {
+		QueuePath   *string        `yaml:"queue_path"`
+		MaxAttempts *int           `yaml:"max_attempts"`
+		BaseDelay   *time.Duration `yaml:"base_delay"`
+		MaxDelay    *time.Duration `yaml:"max_delay"`
+		ItemTTL     *time.Duration `yaml:"item_ttl"`
+		Interval    *time.Duration `yaml:"interval"`
+		BatchSize   *int           `yaml:"batch_size"`
+	} `yaml:"retry"`
+
+	Consensus struct {
+		Window       *time.Duration `yaml:"window"`
+		ToleranceBps *float64       `yaml:"tolerance_bps"`
+		Method       *string        `yaml:"method"`
+		SampleSize   *int           `yaml:"sample_size"`
+	} `yaml:"consensus"`
+
+	GapDetection struct {
+		ScanInterval     *time.Duration `yaml:"scan_interval"`
+		Lookback         *time.Duration `yaml:"lookback"`
+		IntradayLookback *time.Duration `yaml:"intraday_lookback"`
+		MaxFills         *int           `yaml:"max_fills"`
+	} `yaml:"gap_detection"`
+
+	Admin struct {
+		Addr  *string `yaml:"addr"`
+		Token *string `yaml:"token"`
+	} `yaml:"admin"`
+}
+
+// LoadFile reads the environment configuration and layers the file at path on
+// top of it. An empty path returns the environment configuration alone.
+func LoadFile(path string) (*Config, error) {
+	cfg := Load()
+	if path == "" {
+		return cfg, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var fc fileConfig
+	if err := yaml.Unmarshal(raw, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := fc.apply(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (fc *fileConfig) apply(cfg *Config) error {
+	setString(&cfg.DatabaseURL, fc.DatabaseURL)
+	setString(&cfg.RedisURL, fc.RedisURL)
+	setString(&cfg.QuestDBURL, fc.QuestDBURL)
+	setString(&cfg.KafkaBootstrapServers, fc.KafkaBootstrapServers)
+
+	setString(&cfg.AlphaVantageAPIKey, fc.APIKeys.AlphaVantage)
+	setString(&cfg.IEXCloudAPIKey, fc.APIKeys.IEXCloud)
+	setString(&cfg.NewsAPIKey, fc.APIKeys.NewsAPI)
+	setString(&cfg.FREDAPIKey, fc.APIKeys.FRED)
+	setString(&cfg.CoinGeckoAPIKey, fc.APIKeys.CoinGecko)
+	setString(&cfg.AlpacaKeyID, fc.APIKeys.AlpacaKeyID)
+	setString(&cfg.AlpacaSecretKey, fc.APIKeys.AlpacaSecret)
+
+	setDuration(&cfg.MarketDataInterval, fc.Intervals.MarketData)
+	setDuration(&cfg.NewsInterval, fc.Intervals.News)
+	setDuration(&cfg.EconomicDataInterval, fc.Intervals.EconomicData)
+	setDuration(&cfg.ExtendedHoursInterval, fc.Intervals.ExtendedHours)
+	setDuration(&cfg.FilingsInterval, fc.Intervals.Filings)
+	setDuration(&cfg.TreasuryInterval, fc.Intervals.Treasury)
+	setDuration(&cfg.CorporateActionsInterval, fc.Intervals.CorporateActions)
+	setDuration(&cfg.OptionsInterval, fc.Intervals.Options)
+
+	if fc.Symbols.Stocks != nil {
+		cfg.StockSymbols = fc.Symbols.Stocks
+	}
+	if fc.Symbols.Crypto != nil {
+		cfg.CryptoSymbols = fc.Symbols.Crypto
+	}
+
+	setString(&cfg.StockStreamURL, fc.Stream.URL)
+	setDuration(&cfg.StreamPingInterval, fc.Stream.PingInterval)
+	setDuration(&cfg.StreamReadTimeout, fc.Stream.ReadTimeout)
+
+	setString(&cfg.CryptoStreamURL, fc.Crypto.StreamURL)
+
+	if fc.News.Feeds != nil {
+		cfg.NewsFeeds = fc.News.Feeds
+	}
+
+	if fc.Economic.Series != nil {
+		cfg.EconomicSeries = fc.Economic.Series
+	}
+	setDuration(&cfg.EconomicRevisionWindow, fc.Economic.RevisionWindow)
+
+	setString(&cfg.SECUserAgent, fc.Filings.UserAgent)
+	setDuration(&cfg.FilingsLookback, fc.Filings.Lookback)
+	if fc.Filings.EventForms != nil {
+		cfg.FilingEventForms = fc.Filings.EventForms
+	}
+
+	if fc.Options.Symbols != nil {
+		cfg.OptionsSymbols = fc.Options.Symbols
+	}
+	setInt(&cfg.OptionsExpirations, fc.Options.Expirations)
+	setFloat(&cfg.OptionsRiskFreeRate, fc.Options.RiskFreeRate)
+
+	setString(&cfg.ReplayFile, fc.Replay.File)
+	setString(&cfg.ReplaySpeed, fc.Replay.Speed)
+	setString(&cfg.ReplayClock, fc.Replay.Clock)
+	setString(&cfg.HTTPFixtureMode, fc.HTTPFixtures.Mode)
+	setString(&cfg.HTTPFixtureDir, fc.HTTPFixtures.Dir)
+
+	setInt(&cfg.MaxRequestsPerSecond, fc.RateLimits.MaxRequestsPerSecond)
+	setString(&cfg.RateLimitMode, fc.RateLimits.Mode)
+	for name, spec := range fc.RateLimits.Providers {
+		limit, err := ParseRateLimit(spec)
+		if err != nil {
+			return err
+		}
+		cfg.RateLimits[name] = limit
+	}
+
+	if fc.QuoteProviders.Order != nil {
+		cfg.QuoteProviders = fc.QuoteProviders.Order
+	}
+	if fc.QuoteProviders.Weights != nil {
+		cfg.QuoteProviderWeights = fc.QuoteProviders.Weights
+	}
+	setString(&cfg.QuoteProviderStrategy, fc.QuoteProviders.Strategy)
+	setDuration(&cfg.MaxQuoteAge, fc.QuoteProviders.MaxQuoteAge)
+
+	setFloat(&cfg.SyntheticVolatility, fc.Synthetic.Volatility)
+	setFloat(&cfg.SyntheticCorrelation, fc.Synthetic.Correlation)
+	setFloat(&cfg.SyntheticJumpsPerDay, fc.Synthetic.JumpsPerDay)
+	setFloat(&cfg.SyntheticHaltsPerDay, fc.Synthetic.HaltsPerDay)
+	setDuration(&cfg.SyntheticHaltDuration, fc.Synthetic.HaltDuration)
+	setFloat(&cfg.SyntheticSpreadBps, fc.Synthetic.SpreadBps)
+	setInt(&cfg.SyntheticSeed, fc.Synthetic.Seed)
+
+	if fc.CircuitBreaker.ErrorRate != nil {
+		cfg.BreakerErrorRate = *fc.CircuitBreaker.ErrorRate
+	}
+	setDuration(&cfg.BreakerSlowCallLatency, fc.CircuitBreaker.SlowCallLatency)
+	setInt(&cfg.BreakerRateLimitCount, fc.CircuitBreaker.RateLimitCount)
+	setDuration(&cfg.BreakerOpenTimeout, fc.CircuitBreaker.OpenTimeout)
+
+	setString(&cfg.RetryQueuePath, fc.Retry.QueuePath)
+	setInt(&cfg.RetryMaxAttempts, fc.Retry.MaxAttempts)
+	setDuration(&cfg.RetryBaseDelay, fc.Retry.BaseDelay)
+	setDuration(&cfg.RetryMaxDelay, fc.Retry.MaxDelay)
+	setDuration(&cfg.RetryItemTTL, fc.Retry.ItemTTL)
+	setDuration(&cfg.RetryInterval, fc.Retry.Interval)
+	setInt(&cfg.RetryBatchSize, fc.Retry.BatchSize)
+
+	setDuration(&cfg.ConsensusWindow, fc.Consensus.Window)
+	if fc.Consensus.ToleranceBps != nil {
+		cfg.ConsensusToleranceBps = *fc.Consensus.ToleranceBps
+	}
+	setString(&cfg.ConsensusMethod, fc.Consensus.Method)
+	setInt(&cfg.ConsensusSampleSize, fc.Consensus.SampleSize)
+
+	setDuration(&cfg.GapScanInterval, fc.GapDetection.ScanInterval)
+	setDuration(&cfg.GapLookback, fc.GapDetection.Lookback)
+	setDuration(&cfg.GapIntradayLookback, fc.GapDetection.IntradayLookback)
+	setInt(&cfg.GapMaxFills, fc.GapDetection.MaxFills)
+
+	setString(&cfg.AdminAddr, fc.Admin.Addr)
+	setString(&cfg.AdminToken, fc.Admin.Token)
+	return nil
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setInt(dst *int, src *int) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setFloat(dst *float64, src *float64) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setDuration(dst *time.Duration, src *time.Duration) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// Validate rejects configurations the collector cannot run with
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.DatabaseURL != "", "database_url is required")
+	check(c.RedisURL != "", "redis_url is required")
+	check(c.KafkaBootstrapServers != "", "kafka_bootstrap_servers is required")
+
+	check(c.MarketDataInterval >= time.Second, "market data interval must be at least 1s, got %s", c.MarketDataInterval)
+	check(c.NewsInterval >= time.Second, "news interval must be at least 1s, got %s", c.NewsInterval)
+	check(c.EconomicDataInterval >= time.Second, "economic data interval must be at least 1s, got %s", c.EconomicDataInterval)
+	check(c.ExtendedHoursInterval >= 0, "extended hours interval must not be negative")
+	check(c.FilingsInterval >= time.Minute, "filings interval must be at least 1m, got %s", c.FilingsInterval)
+	check(c.TreasuryInterval >= time.Minute, "treasury interval must be at least 1m, got %s", c.TreasuryInterval)
+	check(c.CorporateActionsInterval >= time.Minute, "corporate actions interval must be at least 1m, got %s", c.CorporateActionsInterval)
+	check(c.OptionsInterval >= time.Minute, "options interval must be at least 1m, got %s", c.OptionsInterval)
+
+	check(len(c.StockSymbols)+len(c.CryptoSymbols) > 0, "at least one stock or crypto symbol is required")
+	for kind, symbols := range map[string][]string{"stock": c.StockSymbols, "crypto": c.CryptoSymbols} {
+		seen := make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			check(strings.TrimSpace(symbol) != "", "empty %s symbol", kind)
+			check(!seen[symbol], "duplicate %s symbol %s", kind, symbol)
+			seen[symbol] = true
+		}
+	}
+
+	if c.AlpacaKeyID != "" {
+		check(c.AlpacaSecretKey != "", "the Alpaca stream needs a secret key with the key ID")
+		u, err := url.Parse(c.StockStreamURL)
+		check(err == nil && (u.Scheme == "ws" || u.Scheme == "wss") && u.Host != "", "invalid stock stream URL %q", c.StockStreamURL)
+	}
+	check(c.StreamPingInterval >= time.Second, "stream ping interval must be at least 1s, got %s", c.StreamPingInterval)
+	check(c.StreamReadTimeout > c.StreamPingInterval, "stream read timeout must be longer than the ping interval, got %s", c.StreamReadTimeout)
+	if c.CryptoStreamURL != "" {
+		u, err := url.Parse(c.CryptoStreamURL)
+		check(err == nil && (u.Scheme == "ws" || u.Scheme == "wss") && u.Host != "", "invalid crypto stream URL %q", c.CryptoStreamURL)
+	}
+	for _, feed := range c.NewsFeeds {
+		u, err := url.Parse(feed)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "invalid news feed URL %q", feed)
+	}
+	for _, series := range c.EconomicSeries {
+		check(strings.TrimSpace(series) != "", "empty economic series")
+	}
+	check(c.EconomicRevisionWindow >= 24*time.Hour, "economic revision window must be at least 24h, got %s", c.EconomicRevisionWindow)
+
+	check(c.SECUserAgent == "" || strings.Contains(c.SECUserAgent, "@"), "SEC user agent must include a contact email, got %q", c.SECUserAgent)
+	check(c.FilingsLookback >= 0, "filings lookback must not be negative")
+
+	for _, symbol := range c.OptionsSymbols {
+		check(strings.TrimSpace(symbol) != "", "empty options symbol")
+	}
+	check(c.OptionsExpirations >= 1, "options expirations must be at least 1, got %d", c.OptionsExpirations)
+	check(c.OptionsRiskFreeRate > -0.05 && c.OptionsRiskFreeRate < 0.5, "options risk-free rate is a fraction, got %g", c.OptionsRiskFreeRate)
+	for _, form := range c.FilingEventForms {
+		check(strings.TrimSpace(form) != "", "empty filing event form")
+	}
+
+	_, err := ParseReplaySpeed(c.ReplaySpeed)
+	check(err == nil, "%v", err)
+	check(c.ReplayClock == "now" || c.ReplayClock == "recorded", "replay clock must be now or recorded, got %q", c.ReplayClock)
+	check(c.HTTPFixtureMode == "" || c.HTTPFixtureMode == "record" || c.HTTPFixtureMode == "replay",
+		"HTTP fixture mode must be empty, record or replay, got %q", c.HTTPFixtureMode)
+	check(c.HTTPFixtureMode == "" || c.HTTPFixtureDir != "", "HTTP fixtures need a directory")
+
+	check(c.RateLimitMode == "redis" || c.RateLimitMode == "local", "rate limit mode must be redis or local, got %q", c.RateLimitMode)
+	for name, limit := range c.RateLimits {
+		check(limit.Requests > 0 && limit.Per > 0, "rate limit %s must allow at least one request per period", name)
+	}
+
+	check(len(c.QuoteProviders) > 0, "at least one quote provider is required")
+	check(c.QuoteProviderStrategy == "priority" || c.QuoteProviderStrategy == "weight",
+		"quote provider strategy must be priority or weight, got %q", c.QuoteProviderStrategy)
+	for name, weight := range c.QuoteProviderWeights {
+		check(weight >= 0, "quote provider weight for %s must not be negative", name)
+	}
+	check(c.SyntheticVolatility >= 0, "synthetic volatility must not be negative, got %v", c.SyntheticVolatility)
+	check(c.SyntheticCorrelation >= 0 && c.SyntheticCorrelation <= 1, "synthetic correlation must be in [0, 1], got %v", c.SyntheticCorrelation)
+	check(c.SyntheticJumpsPerDay >= 0 && c.SyntheticHaltsPerDay >= 0, "synthetic jump and halt rates must not be negative")
+	check(c.SyntheticSpreadBps >= 0, "synthetic spread must not be negative, got %v bps", c.SyntheticSpreadBps)
+
+	check(c.BreakerErrorRate > 0 && c.BreakerErrorRate <= 1, "circuit breaker error rate must be in (0, 1], got %v", c.BreakerErrorRate)
+	check(c.RetryMaxAttempts >= 1, "retry max attempts must be at least 1")
+	check(c.RetryMaxDelay >= c.RetryBaseDelay, "retry max delay %s is below base delay %s", c.RetryMaxDelay, c.RetryBaseDelay)
+	check(c.ConsensusWindow > 0, "consensus window must be positive, got %s", c.ConsensusWindow)
+	check(c.ConsensusToleranceBps > 0, "consensus tolerance must be positive, got %v bps", c.ConsensusToleranceBps)
+	check(c.ConsensusMethod == "median" || c.ConsensusMethod == "trimmed_mean",
+		"consensus method must be median or trimmed_mean, got %q", c.ConsensusMethod)
+	check(c.ConsensusSampleSize >= 0, "consensus sample size must not be negative")
+	check(c.GapScanInterval == 0 || c.GapScanInterval >= time.Minute, "gap scan interval must be 0 or at least 1m, got %s", c.GapScanInterval)
+	check(c.GapMaxFills >= 0, "gap max fills must not be negative")
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadFileOverridesEnvironment(t *testing.T) {
+	t.Setenv("MARKET_DATA_INTERVAL", "45s")
+	t.Setenv("NEWS_INTERVAL", "10m")
+
+	path := writeConfigFile(t, "collector.yaml", `
+intervals:
+  market_data: 15s
+symbols:
+  stocks: [AAPL, VOD.L]
+rate_limits:
+  providers:
+    alphavantage: "75/1m"
+`)
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Second, cfg.MarketDataInterval)
+	assert.Equal(t, 10*time.Minute, cfg.NewsInterval, "unset file fields keep the environment value")
+	assert.Equal(t, []string{"AAPL", "VOD.L"}, cfg.StockSymbols)
+	assert.Equal(t, RateLimit{Requests: 75, Per: time.Minute, Burst: 75}, cfg.RateLimits["alphavantage"])
+	assert.Equal(t, 2000, cfg.RateLimits["yahoo"].Requests)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadFileAcceptsJSON(t *testing.T) {
+	path := writeConfigFile(t, "collector.json", `{"symbols": {"stocks": ["MSFT"]}, "quote_providers": {"strategy": "weight"}}`)
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MSFT"}, cfg.StockSymbols)
+	assert.Equal(t, "weight", cfg.QuoteProviderStrategy)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Load()
+	cfg.MarketDataInterval = 0
+	cfg.StockSymbols = []string{"AAPL", "AAPL"}
+	cfg.QuoteProviderStrategy = "random"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "market data interval")
+	assert.Contains(t, err.Error(), "duplicate stock symbol AAPL")
+	assert.Contains(t, err.Error(), "strategy")
+}
+
+func TestCompare(t *testing.T) {
+	old := Load()
+	old.StockSymbols = []string{"AAPL", "MSFT"}
+	old.AlphaVantageAPIKey = "old-key"
+
+	updated := *old
+	updated.StockSymbols = []string{"MSFT", "NVDA"}
+	updated.AlphaVantageAPIKey = "new-key"
+	updated.MarketDataInterval = time.Minute
+	updated.RedisURL = "redis://other:6379"
+
+	diff := Compare(old, &updated)
+	assert.Equal(t, []string{"NVDA"}, diff.StocksAdded)
+	assert.Equal(t, []string{"AAPL"}, diff.StocksRemoved)
+	assert.True(t, diff.Changed("api_keys.alpha_vantage", "intervals.market_data"))
+
+	for _, change := range diff.Changes {
+		assert.NotContains(t, change.Old+change.New, "key", "secrets must not appear in diffs")
+		if change.Field == "redis_url" {
+			assert.True(t, change.RestartRequired)
+		}
+	}
+	assert.True(t, Compare(old, old).Empty())
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Watcher reloads the config file on SIGHUP or when its contents change and
+// hands every valid result to OnReload. Invalid files are logged and ignored,
+// so a bad edit never replaces a working configuration.
+//
+// The file is polled rather than watched with inotify: Kubernetes ConfigMap
+// updates swap a symlink, which inotify on the file itself never sees.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	onReload func(*Config) error
+	lastHash [sha256.Size]byte
+}
+
+func NewWatcher(path string, interval time.Duration, onReload func(*Config) error) *Watcher {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	w := &Watcher{path: path, interval: interval, onReload: onReload}
+	if raw, err := os.ReadFile(path); err == nil {
+		w.lastHash = sha256.Sum256(raw)
+	}
+	return w
+}
+
+// Run blocks until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Printf("SIGHUP received, reloading %s", w.path)
+			w.reload(true)
+		case <-ticker.C:
+			w.reload(false)
+		}
+	}
+}
+
+// reload loads and validates the file; unless forced it only proceeds when the
+// contents changed since the last successful look
+func (w *Watcher) reload(force bool) {
+	raw, err := os.ReadFile(w.path)
+	if err != nil {
+		log.Printf("Config reload: %v", err)
+		return
+	}
+
+	hash := sha256.Sum256(raw)
+	if !force && hash == w.lastHash {
+		return
+	}
+	w.lastHash = hash
+
+	cfg, err := LoadFile(w.path)
+	if err != nil {
+		log.Printf("Config reload rejected: %v", err)
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Printf("Config reload rejected: %v", err)
+		return
+	}
+	if err := w.onReload(cfg); err != nil {
+		log.Printf("Config reload failed to apply: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package corpactions keeps the corporate actions (splits, dividends,
+// spin-offs and symbol changes) collected from providers and edited by
+// operations, and back-adjusts price series for them.
+package corpactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// SourceManual marks actions entered by operations, which collection leaves alone
+const SourceManual = "manual"
+
+var (
+	ErrNotFound = errors.New("corporate action not found")
+	ErrInvalid  = errors.New("invalid corporate action")
+)
+
+// Store persists corporate actions
+type Store interface {
+	// GetCorporateActions returns a symbol's actions by ex-date, cancelled
+	// ones included
+	GetCorporateActions(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+	// GetCorporateAction returns nil when there is no action with the ID
+	GetCorporateAction(ctx context.Context, id int64) (*models.CorporateAction, error)
+	// SaveCorporateAction inserts an action without an ID, taking over a
+	// stored one of the same symbol, type and ex-date, or replaces the
+	// action with its ID
+	SaveCorporateAction(ctx context.Context, action *models.CorporateAction) error
+}
+
+// Book is the operations view of the stored corporate actions: every edit
+// through it is validated and marked manual, so the next collection pass
+// does not undo it.
+type Book struct {
+	store Store
+}
+
+func NewBook(store Store) *Book {
+	return &Book{store: store}
+}
+
+// List returns a symbol's actions by ex-date
+func (b *Book) List(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: no symbol", ErrInvalid)
+	}
+	return b.store.GetCorporateActions(ctx, symbol)
+}
+
+// Get returns the action with the ID
+func (b *Book) Get(ctx context.Context, id int64) (*models.CorporateAction, error) {
+	action, err := b.store.GetCorporateAction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if action == nil {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return action, nil
+}
+
+// Save stores an action: one without an ID is added, or replaces the
+// provider's action of the same symbol, type and ex-date; one with an ID
+// replaces that action
+func (b *Book) Save(ctx context.Context, action *models.CorporateAction) error {
+	if err := Normalize(action); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if action.ID != 0 {
+		if _, err := b.Get(ctx, action.ID); err != nil {
+			return err
+		}
+	}
+	action.Source = SourceManual
+	action.UpdatedAt = time.Now().UTC()
+	return b.store.SaveCorporateAction(ctx, action)
+}
+
+// Cancel marks the action with the ID cancelled. It stays stored, so
+// collection does not bring it back, but no longer adjusts prices.
+func (b *Book) Cancel(ctx context.Context, id int64) (*models.CorporateAction, error) {
+	action, err := b.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	action.Cancelled = true
+	action.Source = SourceManual
+	action.UpdatedAt = time.Now().UTC()
+	if err := b.store.SaveCorporateAction(ctx, action); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+var one = decimal.FromInt(1)
+
+// Normalize canonicalizes action in place and checks it, returning what's
+// wrong with it if anything. Dates become UTC midnights.
+func Normalize(action *models.CorporateAction) error {
+	upper := func(s string) string { return strings.ToUpper(strings.TrimSpace(s)) }
+	action.Symbol = upper(action.Symbol)
+	action.Type = strings.ToLower(strings.TrimSpace(action.Type))
+	action.NewSymbol = upper(action.NewSymbol)
+	action.Currency = upper(action.Currency)
+	action.Note = strings.TrimSpace(action.Note)
+	action.ExDate = day(action.ExDate)
+	action.RecordDate = day(action.RecordDate)
+	action.PayDate = day(action.PayDate)
+
+	if action.Symbol == "" {
+		return errors.New("no symbol")
+	}
+	if action.ExDate.IsZero() {
+		return fmt.Errorf("%s: no ex-date", action.Symbol)
+	}
+	if action.Ratio.Sign() < 0 || action.Amount.Sign() < 0 {
+		return fmt.Errorf("%s: negative ratio or amount", action.Symbol)
+	}
+
+	switch action.Type {
+	case models.ActionSplit:
+		if !action.Ratio.GreaterThan(one) {
+			return fmt.Errorf("%s: a split needs a ratio above 1", action.Symbol)
+		}
+	case models.ActionReverseSplit:
+		if action.Ratio.Sign() == 0 || !action.Ratio.LessThan(one) {
+			return fmt.Errorf("%s: a reverse split needs a ratio between 0 and 1", action.Symbol)
+		}
+	case models.ActionStockDividend:
+		if action.Ratio.Sign() == 0 {
+			return fmt.Errorf("%s: a stock dividend needs a ratio", action.Symbol)
+		}
+	case models.ActionCashDividend:
+		if action.Amount.Sign() == 0 {
+			return fmt.Errorf("%s: a cash dividend needs an amount", action.Symbol)
+		}
+	case models.ActionSpinOff:
+		if action.NewSymbol == "" || action.Ratio.Sign() == 0 {
+			return fmt.Errorf("%s: a spin-off needs the new symbol and a ratio", action.Symbol)
+		}
+	case models.ActionSymbolChange:
+		if action.NewSymbol == "" || action.NewSymbol == action.Symbol {
+			return fmt.Errorf("%s: a symbol change needs a different new symbol", action.Symbol)
+		}
+	default:
+		return fmt.Errorf("%s: unknown action type %q", action.Symbol, action.Type)
+	}
+	return nil
+}
+
+func day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,141 @@
+package corpactions
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// CloseLookup returns a symbol's last traded price before a time, unadjusted.
+// Cash dividends and spin-offs are valued against it.
+type CloseLookup func(symbol string, before time.Time) (decimal.Decimal, bool)
+
+// SeriesCloses looks prices up in bars themselves, ordered oldest first, for
+// callers holding the whole unadjusted series. Every symbol finds the same
+// bars.
+func SeriesCloses(bars []*models.MarketData) CloseLookup {
+	return func(symbol string, before time.Time) (decimal.Decimal, bool) {
+		i := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(before) })
+		if i == 0 {
+			return decimal.Zero, false
+		}
+		return bars[i-1].Price, true
+	}
+}
+
+// Listing is a symbol an instrument traded under from Since until Until,
+// either of which is zero where it is open ended
+type Listing struct {
+	Symbol string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Listings returns the symbols the instrument now trading as symbol has had,
+// newest first, following its symbol changes back through actions. An
+// instrument that never changed symbol has the one listing.
+func Listings(symbol string, actions []*models.CorporateAction) []Listing {
+	listings := []Listing{{Symbol: symbol}}
+	seen := map[string]bool{symbol: true}
+	for {
+		current := &listings[len(listings)-1]
+		var change *models.CorporateAction
+		for _, action := range actions {
+			if action.Type != models.ActionSymbolChange || action.Cancelled || action.NewSymbol != current.Symbol {
+				continue
+			}
+			if !current.Until.IsZero() && !action.ExDate.Before(current.Until) {
+				continue
+			}
+			if change == nil || action.ExDate.After(change.ExDate) {
+				change = action
+			}
+		}
+		if change == nil || seen[change.Symbol] {
+			return listings
+		}
+		seen[change.Symbol] = true
+		current.Since = change.ExDate
+		listings = append(listings, Listing{Symbol: change.Symbol, Until: change.ExDate})
+	}
+}
+
+// Adjust back-adjusts bars, ordered oldest first, in place: each bar before
+// an action's ex-date is scaled by that action, so the newest bars are as
+// traded. Splits, reverse splits and stock dividends divide prices and
+// multiply volumes by the change in share count. For AdjustTotalReturn cash
+// dividends and spin-offs also scale prices by one less the amount over the
+// last price before the ex-date as closes gives it, and are skipped where it
+// has none. Cancelled actions are skipped. AdjustRaw leaves bars alone.
+func Adjust(bars []*models.MarketData, actions []*models.CorporateAction, mode models.PriceAdjustment, closes CloseLookup) {
+	if mode == models.AdjustRaw || len(bars) == 0 {
+		return
+	}
+
+	var applied []*models.CorporateAction
+	for _, action := range actions {
+		if !action.Cancelled && adjusts(action.Type, mode) {
+			applied = append(applied, action)
+		}
+	}
+	sort.SliceStable(applied, func(i, j int) bool {
+		return applied[i].ExDate.After(applied[j].ExDate)
+	})
+
+	// Newest first, picking up each action from the last bar before it
+	shares, value := one, one
+	next := 0
+	for i := len(bars) - 1; i >= 0; i-- {
+		bar := bars[i]
+		for ; next < len(applied) && bar.Timestamp.Before(applied[next].ExDate); next++ {
+			shares, value = apply(applied[next], shares, value, closes)
+		}
+		if shares.Cmp(one) != 0 || value.Cmp(one) != 0 {
+			adjustBar(bar, shares, value)
+		}
+	}
+}
+
+// adjusts reports whether actions of the type change prices under mode
+func adjusts(actionType string, mode models.PriceAdjustment) bool {
+	switch actionType {
+	case models.ActionSplit, models.ActionReverseSplit, models.ActionStockDividend:
+		return true
+	case models.ActionCashDividend, models.ActionSpinOff:
+		return mode == models.AdjustTotalReturn
+	}
+	return false
+}
+
+// apply adds action to the cumulative share count and value factors
+func apply(action *models.CorporateAction, shares, value decimal.Decimal, closes CloseLookup) (decimal.Decimal, decimal.Decimal) {
+	switch action.Type {
+	case models.ActionSplit, models.ActionReverseSplit:
+		return shares.Mul(action.Ratio), value
+	case models.ActionStockDividend:
+		return shares.Mul(one.Add(action.Ratio)), value
+	}
+
+	if action.Amount.Sign() == 0 || closes == nil {
+		return shares, value
+	}
+	price, ok := closes(action.Symbol, action.ExDate)
+	if !ok || !price.GreaterThan(action.Amount) {
+		return shares, value
+	}
+	return shares, value.Mul(price.Sub(action.Amount).Div(price))
+}
+
+func adjustBar(bar *models.MarketData, shares, value decimal.Decimal) {
+	for _, price := range []*decimal.Decimal{&bar.Price, &bar.Open, &bar.High, &bar.Low, &bar.Close,
+		&bar.Bid, &bar.Ask, &bar.PrevClose, &bar.Change} {
+		*price = price.Mul(value).Div(shares)
+	}
+	factor := shares.Float64()
+	for _, size := range []*int64{&bar.Volume, &bar.BidSize, &bar.AskSize} {
+		*size = int64(math.Round(float64(*size) * factor))
+	}
+}
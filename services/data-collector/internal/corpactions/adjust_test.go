@@ -0,0 +1,161 @@
+package corpactions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func june(day int) time.Time {
+	return time.Date(2024, time.June, day, 0, 0, 0, 0, time.UTC)
+}
+
+// testBars are daily closes through a 2-for-1 split on the 3rd and a $1
+// dividend on the 5th
+func testBars() []*models.MarketData {
+	var bars []*models.MarketData
+	for i, price := range []string{"100", "100", "50", "50", "49", "49"} {
+		bars = append(bars, &models.MarketData{
+			Symbol:    "XYZ",
+			Price:     decimal.MustParse(price),
+			Close:     decimal.MustParse(price),
+			Volume:    1000,
+			Timestamp: june(i + 1).Add(20 * time.Hour),
+		})
+	}
+	return bars
+}
+
+var testActions = []*models.CorporateAction{
+	{Symbol: "XYZ", Type: models.ActionCashDividend, ExDate: june(5), Amount: decimal.MustParse("1")},
+	{Symbol: "XYZ", Type: models.ActionSplit, ExDate: june(3), Ratio: decimal.FromInt(2)},
+	{Symbol: "XYZ", Type: models.ActionSplit, ExDate: june(4), Ratio: decimal.FromInt(3), Cancelled: true},
+}
+
+func prices(bars []*models.MarketData) []string {
+	var out []string
+	for _, bar := range bars {
+		out = append(out, bar.Close.String())
+	}
+	return out
+}
+
+func TestAdjust(t *testing.T) {
+	bars := testBars()
+	Adjust(bars, testActions, models.AdjustRaw, SeriesCloses(bars))
+	assert.Equal(t, []string{"100", "100", "50", "50", "49", "49"}, prices(bars))
+
+	Adjust(bars, testActions, models.AdjustSplits, nil)
+	assert.Equal(t, []string{"50", "50", "50", "50", "49", "49"}, prices(bars))
+	assert.Equal(t, int64(2000), bars[0].Volume)
+	assert.Equal(t, int64(1000), bars[2].Volume)
+	assert.Equal(t, decimal.MustParse("50"), bars[0].Price)
+
+	// The dividend takes 2% of the $50 close before it
+	bars = testBars()
+	Adjust(bars, testActions, models.AdjustTotalReturn, SeriesCloses(bars))
+	assert.Equal(t, []string{"49", "49", "49", "49", "49", "49"}, prices(bars))
+
+	// Without a close to value it against, the dividend is left out
+	bars = testBars()
+	Adjust(bars, testActions, models.AdjustTotalReturn, nil)
+	assert.Equal(t, []string{"50", "50", "50", "50", "49", "49"}, prices(bars))
+
+	bars = testBars()
+	Adjust(bars, []*models.CorporateAction{
+		{Symbol: "XYZ", Type: models.ActionReverseSplit, ExDate: june(6), Ratio: decimal.MustParse("0.1")},
+		{Symbol: "XYZ", Type: models.ActionStockDividend, ExDate: june(2), Ratio: decimal.MustParse("0.25")},
+	}, models.AdjustSplits, nil)
+	assert.Equal(t, []string{"800", "1000", "500", "500", "490", "49"}, prices(bars))
+	assert.Equal(t, []int64{125, 100, 100, 100, 100, 1000}, []int64{bars[0].Volume, bars[1].Volume,
+		bars[2].Volume, bars[3].Volume, bars[4].Volume, bars[5].Volume})
+}
+
+func TestListings(t *testing.T) {
+	actions := []*models.CorporateAction{
+		{Symbol: "FB", Type: models.ActionSymbolChange, ExDate: june(9), NewSymbol: "META"},
+		{Symbol: "TFBI", Type: models.ActionSymbolChange, ExDate: june(2), NewSymbol: "FB"},
+		{Symbol: "META", Type: models.ActionSymbolChange, ExDate: june(12), NewSymbol: "FB", Cancelled: true},
+		{Symbol: "FB", Type: models.ActionCashDividend, ExDate: june(1), Amount: decimal.MustParse("0.5")},
+	}
+	assert.Equal(t, []Listing{
+		{Symbol: "META", Since: june(9)},
+		{Symbol: "FB", Since: june(2), Until: june(9)},
+		{Symbol: "TFBI", Until: june(2)},
+	}, Listings("META", actions))
+	assert.Equal(t, []Listing{{Symbol: "AAPL"}}, Listings("AAPL", actions))
+}
+
+type memoryStore map[int64]models.CorporateAction
+
+func (s memoryStore) GetCorporateActions(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	var actions []*models.CorporateAction
+	for _, action := range s {
+		if action.Symbol == symbol {
+			action := action
+			actions = append(actions, &action)
+		}
+	}
+	return actions, nil
+}
+
+func (s memoryStore) GetCorporateAction(ctx context.Context, id int64) (*models.CorporateAction, error) {
+	action, ok := s[id]
+	if !ok {
+		return nil, nil
+	}
+	return &action, nil
+}
+
+func (s memoryStore) SaveCorporateAction(ctx context.Context, action *models.CorporateAction) error {
+	if action.ID == 0 {
+		action.ID = int64(len(s) + 1)
+	}
+	s[action.ID] = *action
+	return nil
+}
+
+func TestBook(t *testing.T) {
+	store := memoryStore{}
+	book := NewBook(store)
+	ctx := context.Background()
+
+	split := &models.CorporateAction{Symbol: " nvda", Type: "SPLIT", Ratio: decimal.FromInt(10),
+		ExDate: time.Date(2024, time.June, 10, 9, 30, 0, 0, time.FixedZone("EDT", -4*3600))}
+	require.NoError(t, book.Save(ctx, split))
+	assert.Equal(t, "NVDA", store[split.ID].Symbol)
+	assert.Equal(t, june(10), store[split.ID].ExDate)
+	assert.Equal(t, SourceManual, store[split.ID].Source)
+
+	cancelled, err := book.Cancel(ctx, split.ID)
+	require.NoError(t, err)
+	assert.True(t, cancelled.Cancelled)
+	actions, err := book.List(ctx, "nvda")
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.True(t, actions[0].Cancelled)
+
+	_, err = book.Cancel(ctx, 42)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, book.Save(ctx, &models.CorporateAction{ID: 42, Symbol: "NVDA", Type: "split",
+		ExDate: june(10), Ratio: decimal.FromInt(10)}), ErrNotFound)
+
+	for _, invalid := range []models.CorporateAction{
+		{Symbol: "NVDA", Type: "split", Ratio: decimal.FromInt(10)},
+		{Symbol: "NVDA", Type: "split", ExDate: june(10), Ratio: decimal.MustParse("0.1")},
+		{Symbol: "NVDA", Type: "reverse_split", ExDate: june(10), Ratio: decimal.FromInt(10)},
+		{Symbol: "NVDA", Type: "cash_dividend", ExDate: june(10)},
+		{Symbol: "NVDA", Type: "spin_off", ExDate: june(10), Ratio: decimal.FromInt(1)},
+		{Symbol: "NVDA", Type: "symbol_change", ExDate: june(10), NewSymbol: "nvda"},
+		{Symbol: "NVDA", Type: "merger", ExDate: june(10)},
+	} {
+		invalid := invalid
+		assert.ErrorIs(t, book.Save(ctx, &invalid), ErrInvalid, invalid.Type)
+	}
+}
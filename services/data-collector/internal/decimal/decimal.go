@@ -0,0 +1,430 @@
+// Package decimal is the fixed-point number prices are kept in from the
+// provider response to storage. Sums, differences and percentages of
+// Decimals are exact to the last place, where float64 arithmetic drifts:
+// 0.1 + 0.2 is 0.3, and a change recomputed from price and previous close
+// matches the one the provider reported.
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Places is how many decimal places a Decimal keeps: enough for sub-cent
+// crypto prices. The fixed-point prices of schemas/hft_messages.capnp keep
+// six; see Micros.
+const Places = 8
+
+const scale = 100_000_000 // units per 1
+
+var errOverflow = errors.New("decimal overflow")
+
+// Decimal is a number with Places decimal places, held as an integer count
+// of 1e-8. The zero value is 0. The range is about ±92 billion, which covers
+// prices and share quantities but not market capitalizations.
+//
+// Arithmetic is exact where the result fits in Places; Mul and Div round
+// half away from zero. A result out of range panics, like an integer
+// division by zero.
+type Decimal struct {
+	units int64
+}
+
+// Zero is 0
+var Zero = Decimal{}
+
+// New returns value × 10^exp, so New(22748, -2) is 227.48. Places beyond
+// the eighth are rounded half away from zero.
+func New(value int64, exp int) Decimal {
+	d, err := parse(strconv.FormatInt(value, 10) + "e" + strconv.Itoa(exp))
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// FromInt returns n
+func FromInt(n int64) Decimal {
+	return Decimal{mulDiv(n, scale, 1)}
+}
+
+// FromUnits returns units × 1e-8
+func FromUnits(units int64) Decimal {
+	return Decimal{units}
+}
+
+// FromMicros returns micros × 1e-6, the fixed point of the Cap'n Proto
+// messages
+func FromMicros(micros int64) Decimal {
+	return Decimal{mulDiv(micros, 100, 1)}
+}
+
+// FromFloat returns the decimal f prints as, so FromFloat(0.1) is exactly
+// 0.1. NaN, infinities and values out of range give zero, which the
+// collectors' validation rejects as a price.
+func FromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Zero
+	}
+	d, err := parse(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		return Zero
+	}
+	return d
+}
+
+// Parse reads a decimal number such as "227.48", "-0.0625", "+3" or
+// "1.5e-7", rounding places beyond the eighth half away from zero
+func Parse(s string) (Decimal, error) {
+	d, err := parse(s)
+	if err != nil {
+		return Zero, fmt.Errorf("invalid decimal %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// MustParse is Parse for constants; it panics if s is not a decimal
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func parse(s string) (Decimal, error) {
+	i := 0
+	negative := false
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		negative = s[i] == '-'
+		i++
+	}
+
+	// digits holds the mantissa without its point; the point sits after
+	// point of them
+	var digits []byte
+	point := -1
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '.' && point < 0 {
+			point = len(digits)
+			continue
+		}
+		if c < '0' || c > '9' {
+			break
+		}
+		digits = append(digits, c)
+	}
+	if len(digits) == 0 {
+		return Zero, errors.New("no digits")
+	}
+	if point < 0 {
+		point = len(digits)
+	}
+	if i < len(s) {
+		if s[i] != 'e' && s[i] != 'E' {
+			return Zero, fmt.Errorf("unexpected %q", s[i])
+		}
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Zero, errors.New("bad exponent")
+		}
+		// Past any sensible digit count the result is zero or out of
+		// range either way
+		point += max(-1000, min(exp, 1000))
+	}
+
+	for len(digits) > 0 && digits[0] == '0' {
+		digits = digits[1:]
+		point--
+	}
+	if len(digits) == 0 {
+		return Zero, nil
+	}
+
+	// The first keep digits make up the units; the one after rounds them
+	keep := point + Places
+	if keep > 19 {
+		return Zero, errOverflow
+	}
+	var units uint64
+	for k := 0; k < keep; k++ {
+		units *= 10
+		if k < len(digits) {
+			units += uint64(digits[k] - '0')
+		}
+	}
+	if keep >= 0 && keep < len(digits) && digits[keep] >= '5' {
+		units++
+	}
+	if units > math.MaxInt64 {
+		return Zero, errOverflow
+	}
+	if negative {
+		return Decimal{-int64(units)}, nil
+	}
+	return Decimal{int64(units)}, nil
+}
+
+// Units returns d as an integer count of 1e-8
+func (d Decimal) Units() int64 {
+	return d.units
+}
+
+// Micros returns d as an integer count of 1e-6, rounded half away from
+// zero, for the Cap'n Proto messages
+func (d Decimal) Micros() int64 {
+	return d.Round(6).units / 100
+}
+
+// Float64 returns the float64 nearest to d, for analytics that work in
+// floating point
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / scale
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Sign returns -1, 0 or +1
+func (d Decimal) Sign() int {
+	switch {
+	case d.units < 0:
+		return -1
+	case d.units > 0:
+		return 1
+	}
+	return 0
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than e
+func (d Decimal) Cmp(e Decimal) int {
+	switch {
+	case d.units < e.units:
+		return -1
+	case d.units > e.units:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) LessThan(e Decimal) bool {
+	return d.units < e.units
+}
+
+func (d Decimal) GreaterThan(e Decimal) bool {
+	return d.units > e.units
+}
+
+func (d Decimal) Add(e Decimal) Decimal {
+	sum := d.units + e.units
+	if (sum > d.units) != (e.units > 0) {
+		panic(errOverflow)
+	}
+	return Decimal{sum}
+}
+
+func (d Decimal) Sub(e Decimal) Decimal {
+	diff := d.units - e.units
+	if (diff < d.units) != (e.units > 0) {
+		panic(errOverflow)
+	}
+	return Decimal{diff}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Zero.Sub(d)
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Mul returns d × e rounded to Places
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{mulDiv(d.units, e.units, scale)}
+}
+
+// Div returns d ÷ e rounded to Places. It panics if e is zero.
+func (d Decimal) Div(e Decimal) Decimal {
+	if e.units == 0 {
+		panic("decimal division by zero")
+	}
+	return Decimal{mulDiv(d.units, scale, e.units)}
+}
+
+// Round returns d rounded half away from zero to places decimal places,
+// 0 to Places
+func (d Decimal) Round(places int) Decimal {
+	if places >= Places {
+		return d
+	}
+	factor := int64(math.Pow10(Places - max(places, 0)))
+	return Decimal{mulDiv(d.units, 1, factor) * factor}
+}
+
+// PercentChange returns the change from from to to in percent, zero when
+// from is zero
+func PercentChange(from, to Decimal) Decimal {
+	if from.IsZero() {
+		return Zero
+	}
+	return Decimal{mulDiv(to.Sub(from).units, 100*scale, from.units)}
+}
+
+func Min(a, b Decimal) Decimal {
+	if b.units < a.units {
+		return b
+	}
+	return a
+}
+
+func Max(a, b Decimal) Decimal {
+	if b.units > a.units {
+		return b
+	}
+	return a
+}
+
+// mulDiv returns a × b ÷ c rounded half away from zero, through a 128-bit
+// product so nothing is lost in between
+func mulDiv(a, b, c int64) int64 {
+	negative := (a < 0) != (b < 0) != (c < 0)
+	hi, lo := bits.Mul64(magnitude(a), magnitude(b))
+	divisor := magnitude(c)
+	if hi >= divisor {
+		panic(errOverflow)
+	}
+	q, r := bits.Div64(hi, lo, divisor)
+	if r >= divisor-r {
+		q++
+	}
+	if q > math.MaxInt64 {
+		panic(errOverflow)
+	}
+	if negative {
+		return -int64(q)
+	}
+	return int64(q)
+}
+
+func magnitude(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
+
+// String formats d without trailing zeros: "227.48", "-0.5", "3"
+func (d Decimal) String() string {
+	return string(d.appendTo(nil))
+}
+
+func (d Decimal) appendTo(b []byte) []byte {
+	if d.units < 0 {
+		b = append(b, '-')
+	}
+	u := magnitude(d.units)
+	b = strconv.AppendUint(b, u/scale, 10)
+	if frac := u % scale; frac != 0 {
+		// Adding scale pads the fraction to Places digits behind a 1
+		digits := strconv.AppendUint(nil, frac+scale, 10)[1:]
+		for digits[len(digits)-1] == '0' {
+			digits = digits[:len(digits)-1]
+		}
+		b = append(append(b, '.'), digits...)
+	}
+	return b
+}
+
+// MarshalJSON writes d as a JSON number, so consumers reading prices as
+// numbers keep working
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return d.appendTo(nil), nil
+}
+
+// UnmarshalJSON reads a number or a string holding one, as some providers
+// quote prices. null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("invalid decimal %s", s)
+		}
+		s = unquoted
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// EncodeMsgpack writes d as its string, like Value, so a reader can't
+// mistake the scale
+func (d Decimal) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.EncodeString(d.String())
+}
+
+// DecodeMsgpack reads a string, integer or float
+func (d *Decimal) DecodeMsgpack(dec *msgpack.Decoder) error {
+	v, err := dec.DecodeInterfaceLoose()
+	if err != nil {
+		return err
+	}
+	return d.Scan(v)
+}
+
+// Value stores d as its string, which PostgreSQL reads exactly into a
+// NUMERIC column and converts for DOUBLE PRECISION ones
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a NUMERIC, DOUBLE PRECISION or integer column. NULL is zero.
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+	case float64:
+		*d = FromFloat(v)
+	case int64:
+		parsed, err := Parse(strconv.FormatInt(v, 10))
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	case uint64:
+		parsed, err := Parse(strconv.FormatUint(v, 10))
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	case []byte:
+		return d.Scan(string(v))
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into a decimal", src)
+	}
+	return nil
+}
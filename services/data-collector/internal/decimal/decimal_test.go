@@ -0,0 +1,139 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestParse(t *testing.T) {
+	for input, want := range map[string]string{
+		"227.48":          "227.48",
+		"-0.0625":         "-0.0625",
+		"+3":              "3",
+		"007.10":          "7.1",
+		".5":              "0.5",
+		"1.5e-7":          "0.00000015",
+		"2.5E3":           "2500",
+		"0.000000014":     "0.00000001",
+		"0.000000015":     "0.00000002", // half away from zero
+		"-0.000000015":    "-0.00000002",
+		"0.000000004":     "0",
+		"1e-1000000":      "0",
+		"0e1000000":       "0",
+		"92233720368.5":   "92233720368.5",
+		"0.0000123456789": "0.00001235",
+	} {
+		d, err := Parse(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, d.String(), input)
+	}
+
+	for _, input := range []string{"", "-", ".", "1.2.3", "12a", "1e", "1e+", " 1", "1,000", "NaN", "92233720369", "1e12"} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	d := MustParse
+
+	assert.Equal(t, d("0.3"), d("0.1").Add(d("0.2")))
+	assert.Equal(t, d("-11.59"), d("227.48").Sub(d("239.07")))
+	assert.Equal(t, d("22748"), d("227.48").Mul(FromInt(100)))
+	assert.Equal(t, d("0.33333333"), FromInt(1).Div(FromInt(3)))
+	assert.Equal(t, d("-0.66666667"), FromInt(-2).Div(FromInt(3)))
+	assert.Equal(t, d("-4.84795248"), PercentChange(d("239.07"), d("227.48")))
+	assert.Equal(t, d("-4.848"), PercentChange(d("239.07"), d("227.48")).Round(3))
+	assert.Equal(t, d("-2"), d("-1.5").Round(0))
+	assert.Equal(t, Zero, PercentChange(Zero, d("1")))
+	assert.Equal(t, d("227.48"), New(22748, -2))
+	assert.Equal(t, d("227.48"), Max(d("227.48"), d("-300")))
+	assert.Equal(t, d("-300"), Min(d("227.48"), d("-300")))
+	assert.True(t, d("0.1").LessThan(d("0.10000001")))
+	assert.Equal(t, 1, d("-1").Abs().Sign())
+
+	// Summing a cent a million times stays exact
+	sum := Zero
+	for i := 0; i < 1_000_000; i++ {
+		sum = sum.Add(d("0.01"))
+	}
+	assert.Equal(t, FromInt(10000), sum)
+
+	// Intermediate products beyond int64 don't lose anything
+	big := d("90000000000")
+	assert.Equal(t, d("45000000000"), big.Mul(d("0.5")))
+	assert.Equal(t, d("0.00000001"), d("0.00000001").Mul(big).Div(big))
+
+	assert.Panics(t, func() { big.Add(big) })
+	assert.Panics(t, func() { big.Mul(FromInt(2)) })
+	assert.Panics(t, func() { big.Div(Zero) })
+}
+
+func TestConversions(t *testing.T) {
+	assert.Equal(t, MustParse("0.1"), FromFloat(0.1))
+	assert.Equal(t, MustParse("227.48"), FromFloat(227.48))
+	assert.Equal(t, MustParse("0.00001234"), FromFloat(1.234e-5))
+	assert.Equal(t, Zero, FromFloat(1e12), "out of range")
+	assert.Equal(t, 227.48, MustParse("227.48").Float64())
+
+	// The Cap'n Proto prices are micros
+	assert.Equal(t, int64(227480000), MustParse("227.48").Micros())
+	assert.Equal(t, int64(1), MustParse("0.0000005").Micros())
+	assert.Equal(t, MustParse("227.48"), FromMicros(227480000))
+	assert.Equal(t, int64(22748000000), MustParse("227.48").Units())
+	assert.Equal(t, MustParse("227.48"), FromUnits(22748000000))
+}
+
+func TestEncodings(t *testing.T) {
+	type quote struct {
+		Price Decimal  `json:"price" msgpack:"price"`
+		Bid   Decimal  `json:"bid" msgpack:"bid"`
+		Size  *Decimal `json:"size,omitempty" msgpack:"size,omitempty"`
+	}
+	q := quote{Price: MustParse("227.48"), Bid: MustParse("-0.00000001")}
+
+	encoded, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"price":227.48,"bid":-0.00000001}`, string(encoded))
+	var decoded quote
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, q, decoded)
+
+	// Providers that quote prices as strings, and nulls
+	require.NoError(t, json.Unmarshal([]byte(`{"price":"227.48","bid":null}`), &decoded))
+	assert.Equal(t, MustParse("227.48"), decoded.Price)
+	assert.Equal(t, q.Bid, decoded.Bid, "null leaves the field alone")
+	assert.Error(t, json.Unmarshal([]byte(`{"price":"n/a"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"price":true}`), &decoded))
+
+	packed, err := msgpack.Marshal(q)
+	require.NoError(t, err)
+	decoded = quote{}
+	require.NoError(t, msgpack.Unmarshal(packed, &decoded))
+	assert.Equal(t, q, decoded)
+
+	// Entries packed when prices were floats
+	packed, err = msgpack.Marshal(map[string]interface{}{"price": 227.48, "bid": 227})
+	require.NoError(t, err)
+	require.NoError(t, msgpack.Unmarshal(packed, &decoded))
+	assert.Equal(t, MustParse("227.48"), decoded.Price)
+	assert.Equal(t, FromInt(227), decoded.Bid)
+
+	value, err := q.Price.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "227.48", value)
+	var scanned Decimal
+	for _, src := range []interface{}{[]byte("227.48"), "227.48", 227.48} {
+		require.NoError(t, scanned.Scan(src))
+		assert.Equal(t, q.Price, scanned)
+	}
+	require.NoError(t, scanned.Scan(int64(227)))
+	assert.Equal(t, FromInt(227), scanned)
+	require.NoError(t, scanned.Scan(nil))
+	assert.Equal(t, Zero, scanned)
+	assert.Error(t, scanned.Scan(true))
+}
@@ -0,0 +1,137 @@
+package instruments
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// csvColumns are the columns ReadCSV understands, each setting one field
+var csvColumns = map[string]func(inst *models.Instrument, value string) error{
+	"id": func(inst *models.Instrument, value string) error {
+		if value == "" {
+			return nil
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("id %q is not a positive integer", value)
+		}
+		inst.ID = id
+		return nil
+	},
+	"symbol":      func(inst *models.Instrument, value string) error { inst.Symbol = value; return nil },
+	"name":        func(inst *models.Instrument, value string) error { inst.Name = value; return nil },
+	"asset_class": func(inst *models.Instrument, value string) error { inst.AssetClass = value; return nil },
+	"exchange":    func(inst *models.Instrument, value string) error { inst.Exchange = value; return nil },
+	"currency":    func(inst *models.Instrument, value string) error { inst.Currency = value; return nil },
+	"isin":        func(inst *models.Instrument, value string) error { inst.ISIN = value; return nil },
+	"cusip":       func(inst *models.Instrument, value string) error { inst.CUSIP = value; return nil },
+	"figi":        func(inst *models.Instrument, value string) error { inst.FIGI = value; return nil },
+	"lot_size": func(inst *models.Instrument, value string) error {
+		if value == "" {
+			return nil
+		}
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("lot size %q is not an integer", value)
+		}
+		inst.LotSize = size
+		return nil
+	},
+	"tick_size": func(inst *models.Instrument, value string) error {
+		if value == "" {
+			return nil
+		}
+		size, err := decimal.Parse(value)
+		if err != nil {
+			return fmt.Errorf("tick size %q is not a number", value)
+		}
+		inst.TickSize = size
+		return nil
+	},
+	"sector":   func(inst *models.Instrument, value string) error { inst.Sector = value; return nil },
+	"industry": func(inst *models.Instrument, value string) error { inst.Industry = value; return nil },
+	"status":   func(inst *models.Instrument, value string) error { inst.Status = value; return nil },
+	"tickers": func(inst *models.Instrument, value string) error {
+		for _, pair := range strings.Split(value, ";") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			venue, ticker, ok := strings.Cut(pair, ":")
+			if !ok {
+				return fmt.Errorf("ticker %q is not VENUE:TICKER", pair)
+			}
+			inst.Tickers = append(inst.Tickers, models.VenueTicker{Venue: venue, Ticker: ticker})
+		}
+		return nil
+	},
+}
+
+// ReadCSV reads instruments from CSV whose header row names the columns, in
+// any order:
+//
+//	symbol,name,asset_class,exchange,currency,isin,cusip,figi,lot_size,tick_size,sector,industry,status,tickers
+//
+// Only symbol is required. id updates the instrument with that internal ID,
+// and tickers lists venue tickers as VENUE:TICKER separated by semicolons,
+// e.g. "XNAS:AAPL;XLON:0R2V". Empty cells take the defaults: equity, lot size
+// 1, active. Lines starting with # are skipped. The instruments come back
+// normalized; an invalid one fails the whole file, naming its line.
+func ReadCSV(r io.Reader) ([]*models.Instrument, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, invalidf("empty instrument file")
+	}
+	if err != nil {
+		return nil, invalidf("%v", err)
+	}
+	setters := make([]func(*models.Instrument, string) error, len(header))
+	hasSymbol := false
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if setters[i] = csvColumns[column]; setters[i] == nil {
+			return nil, invalidf("unknown column %q", column)
+		}
+		hasSymbol = hasSymbol || column == "symbol"
+	}
+	if !hasSymbol {
+		return nil, invalidf("no symbol column")
+	}
+
+	var instruments []*models.Instrument
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return instruments, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, invalidf("%v", parseErr)
+			}
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		inst := &models.Instrument{}
+		for i, value := range record {
+			if err := setters[i](inst, strings.TrimSpace(value)); err != nil {
+				return nil, invalidf("line %d: %v", line, err)
+			}
+		}
+		if err := normalize(inst); err != nil {
+			return nil, invalidf("line %d: %v", line, err)
+		}
+		instruments = append(instruments, inst)
+	}
+}
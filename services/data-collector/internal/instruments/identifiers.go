@@ -0,0 +1,194 @@
+package instruments
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/models"
+)
+
+var assetClasses = map[string]bool{
+	"equity": true, "etf": true, "fund": true, "index": true, "crypto": true,
+	"fx": true, "future": true, "option": true, "bond": true,
+}
+
+var statuses = map[string]bool{"active": true, "suspended": true, "delisted": true}
+
+func invalidf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalid}, args...)...)
+}
+
+// normalize canonicalizes inst in place, fills in defaults and checks it,
+// returning what's wrong with it if anything.
+// Exchange codes the market calendar knows by an alias (XNAS, NMS) become
+// its code (NASDAQ), so the exchange stored with quotes is the same whatever
+// the provider called it.
+func normalize(inst *models.Instrument) error {
+	upper := func(s string) string { return strings.ToUpper(strings.TrimSpace(s)) }
+	inst.Symbol = upper(inst.Symbol)
+	inst.Name = strings.TrimSpace(inst.Name)
+	inst.AssetClass = strings.ToLower(strings.TrimSpace(inst.AssetClass))
+	inst.Exchange = upper(inst.Exchange)
+	inst.Currency = upper(inst.Currency)
+	inst.ISIN, inst.CUSIP, inst.FIGI = upper(inst.ISIN), upper(inst.CUSIP), upper(inst.FIGI)
+	inst.Sector = strings.TrimSpace(inst.Sector)
+	inst.Industry = strings.TrimSpace(inst.Industry)
+	inst.Status = strings.ToLower(strings.TrimSpace(inst.Status))
+
+	if inst.Symbol == "" {
+		return errors.New("no symbol")
+	}
+	if len(inst.Symbol) > 32 || strings.ContainsAny(inst.Symbol, " \t,:;") {
+		return fmt.Errorf("symbol %q is not a ticker", inst.Symbol)
+	}
+	if inst.AssetClass == "" {
+		inst.AssetClass = "equity"
+	}
+	if !assetClasses[inst.AssetClass] {
+		return fmt.Errorf("%s: unknown asset class %q", inst.Symbol, inst.AssetClass)
+	}
+	if ex, ok := calendar.Default().Exchange(inst.Exchange); ok {
+		inst.Exchange = ex.Code
+	}
+	if inst.Currency != "" && !isCurrency(inst.Currency) {
+		return fmt.Errorf("%s: currency %q is not a currency code", inst.Symbol, inst.Currency)
+	}
+	if inst.ISIN != "" && !ValidISIN(inst.ISIN) {
+		return fmt.Errorf("%s: %q is not a valid ISIN", inst.Symbol, inst.ISIN)
+	}
+	if inst.CUSIP != "" && !ValidCUSIP(inst.CUSIP) {
+		return fmt.Errorf("%s: %q is not a valid CUSIP", inst.Symbol, inst.CUSIP)
+	}
+	if inst.FIGI != "" && !ValidFIGI(inst.FIGI) {
+		return fmt.Errorf("%s: %q is not a valid FIGI", inst.Symbol, inst.FIGI)
+	}
+	if inst.LotSize == 0 {
+		inst.LotSize = 1
+	}
+	if inst.LotSize < 0 {
+		return fmt.Errorf("%s: negative lot size", inst.Symbol)
+	}
+	if inst.TickSize.Sign() < 0 {
+		return fmt.Errorf("%s: negative tick size", inst.Symbol)
+	}
+	if inst.Status == "" {
+		inst.Status = "active"
+	}
+	if !statuses[inst.Status] {
+		return fmt.Errorf("%s: unknown status %q", inst.Symbol, inst.Status)
+	}
+
+	seen := make(map[models.VenueTicker]bool, len(inst.Tickers))
+	var tickers []models.VenueTicker
+	for _, ticker := range inst.Tickers {
+		ticker.Venue, ticker.Ticker = upper(ticker.Venue), upper(ticker.Ticker)
+		if ticker.Venue == "" || ticker.Ticker == "" {
+			return fmt.Errorf("%s: venue ticker without a venue or ticker", inst.Symbol)
+		}
+		if !seen[ticker] {
+			seen[ticker] = true
+			tickers = append(tickers, ticker)
+		}
+	}
+	inst.Tickers = tickers
+	return nil
+}
+
+// isCurrency accepts ISO 4217 codes and the longer crypto ones (USDT)
+func isCurrency(code string) bool {
+	if len(code) < 3 || len(code) > 5 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidISIN reports whether isin is a well-formed ISIN: a country code, nine
+// alphanumerics and a Luhn check digit over all of them, letters counting as
+// 10 to 35
+func ValidISIN(isin string) bool {
+	if len(isin) != 12 || !isLetter(isin[0]) || !isLetter(isin[1]) || !isDigit(isin[11]) {
+		return false
+	}
+	var digits []byte
+	for i := 0; i < len(isin); i++ {
+		v, ok := charValue(isin[i])
+		if !ok {
+			return false
+		}
+		digits = strconv.AppendInt(digits, int64(v), 10)
+	}
+
+	sum := 0
+	for i := range digits {
+		v := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			v *= 2
+		}
+		sum += v/10 + v%10
+	}
+	return sum%10 == 0
+}
+
+// ValidCUSIP reports whether cusip is nine alphanumerics ending in the right
+// check digit
+func ValidCUSIP(cusip string) bool {
+	return len(cusip) == 9 && checkDigit(cusip[:8]) == cusip[8]
+}
+
+// ValidFIGI reports whether figi is a well-formed Financial Instrument Global
+// Identifier: two consonants, G, eight alphanumerics and a check digit
+// computed as for a CUSIP
+func ValidFIGI(figi string) bool {
+	if len(figi) != 12 || figi[2] != 'G' || !isConsonant(figi[0]) || !isConsonant(figi[1]) {
+		return false
+	}
+	return checkDigit(figi[:11]) == figi[11]
+}
+
+// checkDigit is the modified Luhn digit CUSIPs and FIGIs end in: every
+// second character's value doubled and the digits of it all summed. It
+// returns 0 for a payload with anything but digits and capitals.
+func checkDigit(payload string) byte {
+	sum := 0
+	for i := 0; i < len(payload); i++ {
+		v, ok := charValue(payload[i])
+		if !ok {
+			return 0
+		}
+		if i%2 == 1 {
+			v *= 2
+		}
+		sum += v/10 + v%10
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func charValue(c byte) (int, bool) {
+	switch {
+	case isDigit(c):
+		return int(c - '0'), true
+	case isLetter(c):
+		return int(c-'A') + 10, true
+	}
+	return 0, false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isConsonant(c byte) bool {
+	return isLetter(c) && !strings.ContainsRune("AEIOU", rune(c))
+}
@@ -0,0 +1,440 @@
+// Package instruments is the instrument master: the reference data saying
+// what a symbol is, with its identifiers, listing, currency and trading
+// conventions. The master is kept in Postgres and held in memory, so the
+// collectors and storage can resolve every quote's symbol through it.
+package instruments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tradecaptain/data-collector/internal/models"
+)
+
+var (
+	ErrNotFound = errors.New("instrument not found")
+	ErrInvalid  = errors.New("invalid instrument")
+)
+
+// Identifier kinds for Lookup; venue tickers go through LookupTicker
+const (
+	BySymbol = "symbol"
+	ByISIN   = "isin"
+	ByCUSIP  = "cusip"
+	ByFIGI   = "figi"
+)
+
+// Store is where the master is kept; *storage.PostgresDB is one
+type Store interface {
+	GetInstruments(ctx context.Context) ([]*models.Instrument, error)
+	SaveInstruments(ctx context.Context, instruments []*models.Instrument) error
+}
+
+// Match is a search result. Relevance runs from 0 to 1, an exact symbol match
+// of a listed instrument being 1.
+type Match struct {
+	Instrument *models.Instrument `json:"instrument"`
+	Relevance  float64            `json:"relevance"`
+}
+
+// Master is the in-memory instrument master. Instruments it returns are
+// shared: copy one before changing it, and save changes through Save.
+//
+// A nil Master resolves nothing, so callers without a master configured
+// fall back to what the provider reported.
+type Master struct {
+	store Store
+
+	// writeMu serializes Save and Import from the check to the swap
+	writeMu sync.Mutex
+
+	mu    sync.RWMutex
+	index *index
+}
+
+type index struct {
+	all      []*models.Instrument // by ID
+	byID     map[int64]*models.Instrument
+	bySymbol map[string]*models.Instrument
+	byVenue  map[models.VenueTicker]*models.Instrument
+	byTicker map[string][]*models.Instrument // venue tickers on any venue
+	byISIN   map[string]*models.Instrument
+	byCUSIP  map[string]*models.Instrument
+	byFIGI   map[string]*models.Instrument
+}
+
+// NewMaster returns an empty master kept in store; Load fills it
+func NewMaster(store Store) *Master {
+	m := &Master{store: store}
+	m.index, _ = buildIndex(nil)
+	return m
+}
+
+// Load replaces the master with what the store holds
+func (m *Master) Load(ctx context.Context) error {
+	all, err := m.store.GetInstruments(ctx)
+	if err != nil {
+		return err
+	}
+	idx, err := buildIndex(all)
+	if err != nil {
+		// The database's constraints make this unlikely; serve what we can
+		log.Printf("Instrument master: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.index = idx
+	return nil
+}
+
+// Refresh reloads the master every interval until ctx is cancelled, so
+// edits made through other replicas show up here
+func (m *Master) Refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Load(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to reload instrument master: %v", err)
+			}
+		}
+	}
+}
+
+func (m *Master) current() *index {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.index
+}
+
+// Len returns the number of instruments
+func (m *Master) Len() int {
+	if idx := m.current(); idx != nil {
+		return len(idx.all)
+	}
+	return 0
+}
+
+// Get returns the instrument with internal ID id
+func (m *Master) Get(id int64) (*models.Instrument, bool) {
+	idx := m.current()
+	if idx == nil {
+		return nil, false
+	}
+	inst, ok := idx.byID[id]
+	return inst, ok
+}
+
+// Resolve returns the instrument a collected symbol refers to: the one
+// listed under that symbol, or else the one instrument trading under it on
+// some venue. A ticker several instruments use on different venues resolves
+// to none of them.
+func (m *Master) Resolve(symbol string) (*models.Instrument, bool) {
+	idx := m.current()
+	if idx == nil {
+		return nil, false
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if inst, ok := idx.bySymbol[symbol]; ok {
+		return inst, true
+	}
+	if candidates := idx.byTicker[symbol]; len(candidates) == 1 {
+		return candidates[0], true
+	}
+	return nil, false
+}
+
+// Lookup finds an instrument by symbol, ISIN, CUSIP or FIGI
+func (m *Master) Lookup(kind, value string) (*models.Instrument, bool) {
+	idx := m.current()
+	if idx == nil {
+		return nil, false
+	}
+	value = strings.ToUpper(strings.TrimSpace(value))
+	var inst *models.Instrument
+	switch kind {
+	case BySymbol:
+		inst = idx.bySymbol[value]
+	case ByISIN:
+		inst = idx.byISIN[value]
+	case ByCUSIP:
+		inst = idx.byCUSIP[value]
+	case ByFIGI:
+		inst = idx.byFIGI[value]
+	}
+	return inst, inst != nil
+}
+
+// LookupTicker finds the instrument trading under ticker on venue, or listed
+// under ticker with venue as its exchange
+func (m *Master) LookupTicker(venue, ticker string) (*models.Instrument, bool) {
+	idx := m.current()
+	if idx == nil {
+		return nil, false
+	}
+	key := models.VenueTicker{Venue: strings.ToUpper(strings.TrimSpace(venue)), Ticker: strings.ToUpper(strings.TrimSpace(ticker))}
+	if inst, ok := idx.byVenue[key]; ok {
+		return inst, true
+	}
+	probe := models.Instrument{Symbol: key.Ticker, Exchange: key.Venue}
+	if normalize(&probe) == nil {
+		if inst, ok := idx.bySymbol[probe.Symbol]; ok && inst.Exchange == probe.Exchange {
+			return inst, true
+		}
+	}
+	return nil, false
+}
+
+// Exchange returns the primary exchange of the instrument symbol resolves
+// to, or reported when the master doesn't list one for it
+func (m *Master) Exchange(symbol, reported string) string {
+	if inst, ok := m.Resolve(symbol); ok && inst.Exchange != "" {
+		return inst.Exchange
+	}
+	return reported
+}
+
+// Search finds instruments by symbol, venue ticker, identifier or name,
+// best match first. Delisted instruments rank below listed ones.
+func (m *Master) Search(query string, limit int) []Match {
+	idx := m.current()
+	query = strings.ToUpper(strings.TrimSpace(query))
+	if idx == nil || query == "" || limit <= 0 {
+		return nil
+	}
+
+	var matches []Match
+	for _, inst := range idx.all {
+		relevance := matchRelevance(inst, query)
+		if relevance == 0 {
+			continue
+		}
+		if inst.Status == "delisted" {
+			relevance /= 2
+		}
+		matches = append(matches, Match{Instrument: inst, Relevance: relevance})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Relevance != matches[j].Relevance {
+			return matches[i].Relevance > matches[j].Relevance
+		}
+		return matches[i].Instrument.Symbol < matches[j].Instrument.Symbol
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func matchRelevance(inst *models.Instrument, query string) float64 {
+	if inst.Symbol == query {
+		return 1
+	}
+	if query == inst.ISIN || query == inst.CUSIP || query == inst.FIGI {
+		return 0.95
+	}
+	for _, ticker := range inst.Tickers {
+		if ticker.Ticker == query {
+			return 0.9
+		}
+	}
+	if strings.HasPrefix(inst.Symbol, query) {
+		// AAP ranks AAPL above AAPLW
+		return 0.6 + 0.2*float64(len(query))/float64(len(inst.Symbol))
+	}
+	name := strings.ToUpper(inst.Name)
+	for _, word := range strings.Fields(name) {
+		if strings.HasPrefix(word, query) {
+			return 0.5
+		}
+	}
+	if strings.Contains(name, query) {
+		return 0.3
+	}
+	return 0
+}
+
+// Save adds inst to the master when its ID is zero, setting the ID, and
+// otherwise replaces the instrument with its ID. inst is normalized first:
+// see the package's identifier checks.
+func (m *Master) Save(ctx context.Context, inst *models.Instrument) error {
+	if err := normalize(inst); err != nil {
+		return invalidf("%v", err)
+	}
+	_, err := m.save(ctx, []*models.Instrument{inst}, false)
+	return err
+}
+
+// Import reads instruments from CSV (see ReadCSV) and saves them all or,
+// when any is invalid or conflicts with another, none. Rows without an id
+// update the instrument with the same FIGI, ISIN, CUSIP or symbol, checked
+// in that order, so a ticker change updates the instrument rather than
+// adding a second one; the others are added. It returns how many
+// instruments were added and how many updated.
+func (m *Master) Import(ctx context.Context, r io.Reader) (added, updated int, err error) {
+	rows, err := ReadCSV(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	if added, err = m.save(ctx, rows, true); err != nil {
+		return 0, 0, err
+	}
+	return added, len(rows) - added, nil
+}
+
+// save stores batch and swaps it into the index, returning how many
+// instruments were added. With match set, instruments without an ID take
+// the ID of the instrument they match, if any.
+func (m *Master) save(ctx context.Context, batch []*models.Instrument, match bool) (int, error) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	idx := m.current()
+
+	added := 0
+	for _, inst := range batch {
+		if inst.ID == 0 && match {
+			for _, existing := range []*models.Instrument{idx.byFIGI[inst.FIGI], idx.byISIN[inst.ISIN], idx.byCUSIP[inst.CUSIP], idx.bySymbol[inst.Symbol]} {
+				if existing != nil {
+					inst.ID = existing.ID
+					break
+				}
+			}
+		}
+		if inst.ID == 0 {
+			added++
+		}
+	}
+
+	// Check the master as it would be, so conflicts come back as invalid
+	// instruments rather than constraint violations
+	byID := make(map[int64]*models.Instrument, len(batch))
+	for _, inst := range batch {
+		if inst.ID == 0 {
+			continue
+		}
+		if _, ok := idx.byID[inst.ID]; !ok {
+			return 0, fmt.Errorf("%w: id %d", ErrNotFound, inst.ID)
+		}
+		if _, dup := byID[inst.ID]; dup {
+			return 0, invalidf("%s appears twice", describe(idx.byID[inst.ID]))
+		}
+		byID[inst.ID] = inst
+	}
+	var next []*models.Instrument
+	for _, inst := range idx.all {
+		if _, replaced := byID[inst.ID]; !replaced {
+			next = append(next, inst)
+		}
+	}
+	next = append(next, batch...)
+	if _, err := buildIndex(next); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	saved := make([]*models.Instrument, len(batch))
+	for i, inst := range batch {
+		inst.UpdatedAt = now
+		copied := *inst
+		saved[i] = &copied
+	}
+	if err := m.store.SaveInstruments(ctx, saved); err != nil {
+		return 0, err
+	}
+	for i, inst := range batch {
+		inst.ID = saved[i].ID
+	}
+
+	next = next[:len(next)-len(batch)]
+	next = append(next, saved...)
+	updated, err := buildIndex(next)
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	m.index = updated
+	m.mu.Unlock()
+	return added, nil
+}
+
+// buildIndex indexes instruments, returning an error naming the first two
+// that share a symbol, identifier or venue ticker. The index is complete
+// but for the later of each such pair.
+func buildIndex(instruments []*models.Instrument) (*index, error) {
+	idx := &index{
+		byID:     make(map[int64]*models.Instrument, len(instruments)),
+		bySymbol: make(map[string]*models.Instrument, len(instruments)),
+		byVenue:  make(map[models.VenueTicker]*models.Instrument),
+		byTicker: make(map[string][]*models.Instrument),
+		byISIN:   make(map[string]*models.Instrument),
+		byCUSIP:  make(map[string]*models.Instrument),
+		byFIGI:   make(map[string]*models.Instrument),
+	}
+
+	var conflict error
+	claim := func(inst *models.Instrument, index map[string]*models.Instrument, kind, key string) bool {
+		if key == "" {
+			return true
+		}
+		if other, taken := index[key]; taken {
+			if conflict == nil {
+				conflict = invalidf("%s %s is both %s and %s", kind, key, describe(other), describe(inst))
+			}
+			return false
+		}
+		index[key] = inst
+		return true
+	}
+
+	for _, inst := range instruments {
+		if !claim(inst, idx.bySymbol, "symbol", inst.Symbol) {
+			continue
+		}
+		claim(inst, idx.byISIN, "ISIN", inst.ISIN)
+		claim(inst, idx.byCUSIP, "CUSIP", inst.CUSIP)
+		claim(inst, idx.byFIGI, "FIGI", inst.FIGI)
+		for _, ticker := range inst.Tickers {
+			if other, taken := idx.byVenue[ticker]; taken {
+				if conflict == nil {
+					conflict = invalidf("ticker %s:%s is both %s and %s", ticker.Venue, ticker.Ticker, describe(other), describe(inst))
+				}
+				continue
+			}
+			idx.byVenue[ticker] = inst
+			if ticker.Ticker != inst.Symbol {
+				idx.byTicker[ticker.Ticker] = append(idx.byTicker[ticker.Ticker], inst)
+			}
+		}
+		if inst.ID != 0 {
+			idx.byID[inst.ID] = inst
+		}
+		idx.all = append(idx.all, inst)
+	}
+
+	sort.Slice(idx.all, func(i, j int) bool { return idx.all[i].ID < idx.all[j].ID })
+	return idx, conflict
+}
+
+func describe(inst *models.Instrument) string {
+	if inst.ID == 0 {
+		return "new instrument " + inst.Symbol
+	}
+	return "instrument " + strconv.FormatInt(inst.ID, 10) + " (" + inst.Symbol + ")"
+}
@@ -0,0 +1,172 @@
+package instruments
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	saved  map[int64]models.Instrument
+	nextID int64
+}
+
+func (s *memoryStore) GetInstruments(ctx context.Context) ([]*models.Instrument, error) {
+	var all []*models.Instrument
+	for _, inst := range s.saved {
+		inst := inst
+		all = append(all, &inst)
+	}
+	return all, nil
+}
+
+func (s *memoryStore) SaveInstruments(ctx context.Context, instruments []*models.Instrument) error {
+	for _, inst := range instruments {
+		if inst.ID == 0 {
+			s.nextID++
+			inst.ID = s.nextID
+		}
+		s.saved[inst.ID] = *inst
+	}
+	return nil
+}
+
+const testInstruments = `symbol,name,asset_class,exchange,currency,isin,cusip,figi,lot_size,tick_size,sector,industry,tickers
+# US listings
+AAPL,Apple Inc.,equity,NMS,USD,US0378331005,037833100,BBG000B9XRY4,,0.01,Technology,Consumer Electronics,XNAS:AAPL;XLON:0R2V
+FB,"Meta Platforms, Inc.",equity,XNAS,usd,US30303M1027,30303M102,,,0.01,Communication Services,Internet Content & Information,
+VOD.L,Vodafone Group Plc,equity,LSE,GBP,GB00BH4HKS39,,,,0.02,Communication Services,Telecom Services,XLON:VOD
+BTC-USD,Bitcoin,crypto,CRYPTO,USD,,,,,0.01,,,
+`
+
+func TestImport(t *testing.T) {
+	store := &memoryStore{saved: make(map[int64]models.Instrument)}
+	master := NewMaster(store)
+	ctx := context.Background()
+
+	added, updated, err := master.Import(ctx, strings.NewReader(testInstruments))
+	require.NoError(t, err)
+	assert.Equal(t, 4, added)
+	assert.Equal(t, 0, updated)
+	assert.Len(t, store.saved, 4)
+
+	apple, ok := master.Resolve("aapl")
+	require.True(t, ok)
+	assert.Equal(t, "NASDAQ", apple.Exchange, "exchange aliases become calendar codes")
+	assert.Equal(t, int64(1), apple.LotSize)
+	assert.Equal(t, decimal.MustParse("0.01"), apple.TickSize)
+	assert.Equal(t, "active", apple.Status)
+	assert.Equal(t, "Technology", apple.Sector)
+
+	for kind, value := range map[string]string{ByISIN: "US0378331005", ByCUSIP: "037833100", ByFIGI: "bbg000b9xry4", BySymbol: "AAPL"} {
+		found, ok := master.Lookup(kind, value)
+		if assert.True(t, ok, kind) {
+			assert.Equal(t, apple.ID, found.ID, kind)
+		}
+	}
+	found, ok := master.LookupTicker("XLON", "0R2V")
+	require.True(t, ok)
+	assert.Equal(t, apple.ID, found.ID)
+	found, ok = master.LookupTicker("XNAS", "FB")
+	require.True(t, ok, "the symbol on its own exchange")
+	assert.Equal(t, "FB", found.Symbol)
+	_, ok = master.LookupTicker("XNYS", "FB")
+	assert.False(t, ok)
+
+	// Venue tickers resolve when only one instrument trades under them
+	vodafone, ok := master.Resolve("VOD")
+	require.True(t, ok)
+	assert.Equal(t, "VOD.L", vodafone.Symbol)
+	assert.Equal(t, "LSE", master.Exchange("VOD.L", "LON"))
+	assert.Equal(t, "NYQ", master.Exchange("IBM", "NYQ"), "unknown symbols keep the reported exchange")
+
+	// A ticker change matched by ISIN updates the instrument
+	added, updated, err = master.Import(ctx, strings.NewReader("symbol,isin,exchange\nMETA,US30303M1027,NMS\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+	assert.Equal(t, 1, updated)
+	meta, ok := master.Resolve("META")
+	require.True(t, ok)
+	_, ok = master.Resolve("FB")
+	assert.False(t, ok)
+	assert.Len(t, store.saved, 4)
+	assert.Equal(t, "META", store.saved[meta.ID].Symbol)
+
+	// The whole file fails on one bad row, naming it
+	_, _, err = master.Import(ctx, strings.NewReader("symbol,isin\nMSFT,US5949181045\nIBM,US4592001015\n"))
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.ErrorContains(t, err, "line 3")
+	_, ok = master.Resolve("MSFT")
+	assert.False(t, ok)
+
+	// And on two rows for one instrument
+	_, _, err = master.Import(ctx, strings.NewReader("symbol,cusip\nAPPL,037833100\nAAPL,037833100\n"))
+	assert.ErrorIs(t, err, ErrInvalid)
+	err = master.Save(ctx, &models.Instrument{Symbol: "APPL", Tickers: []models.VenueTicker{{Venue: "xlon", Ticker: "0r2v"}}})
+	assert.ErrorContains(t, err, "ticker XLON:0R2V")
+	assert.ErrorIs(t, master.Save(ctx, &models.Instrument{ID: 99, Symbol: "X"}), ErrNotFound)
+	assert.Equal(t, 4, master.Len())
+
+	_, _, err = master.Import(ctx, strings.NewReader("ticker,name\nAAPL,Apple\n"))
+	assert.ErrorContains(t, err, `unknown column "ticker"`)
+
+	// Another master on the same store sees it all
+	reloaded := NewMaster(store)
+	require.NoError(t, reloaded.Load(ctx))
+	found, ok = reloaded.Lookup(ByFIGI, "BBG000B9XRY4")
+	require.True(t, ok)
+	assert.Equal(t, []models.VenueTicker{{Venue: "XNAS", Ticker: "AAPL"}, {Venue: "XLON", Ticker: "0R2V"}}, found.Tickers)
+}
+
+func TestSearch(t *testing.T) {
+	master := NewMaster(&memoryStore{saved: make(map[int64]models.Instrument)})
+	_, _, err := master.Import(context.Background(), strings.NewReader(testInstruments+
+		"AAPLW,Apple Warrants,equity,NMS,USD,,,,,,,,\nAPPLX,Old Apple Fund,fund,,USD,,,,,,,,\n"))
+	require.NoError(t, err)
+
+	var symbols []string
+	for _, match := range master.Search("aap", 10) {
+		symbols = append(symbols, match.Instrument.Symbol)
+	}
+	assert.Equal(t, []string{"AAPL", "AAPLW"}, symbols)
+
+	matches := master.Search("apple", 10)
+	require.Len(t, matches, 3)
+	assert.Equal(t, 0.5, matches[0].Relevance, "name word")
+
+	matches = master.Search("US0378331005", 10)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "AAPL", matches[0].Instrument.Symbol)
+	assert.Len(t, master.Search("a", 2), 2)
+	assert.Empty(t, master.Search(" ", 10))
+
+	var none *Master
+	_, ok := none.Resolve("AAPL")
+	assert.False(t, ok)
+	assert.Equal(t, "NMS", none.Exchange("AAPL", "NMS"))
+}
+
+func TestIdentifiers(t *testing.T) {
+	for _, isin := range []string{"US0378331005", "GB00BH4HKS39", "JP3633400001", "US30303M1027"} {
+		assert.True(t, ValidISIN(isin), isin)
+	}
+	for _, isin := range []string{"US0378331006", "US037833100", "0S0378331005", "US03783310O5", "us0378331005"} {
+		assert.False(t, ValidISIN(isin), isin)
+	}
+	for _, cusip := range []string{"037833100", "594918104", "30303M102"} {
+		assert.True(t, ValidCUSIP(cusip), cusip)
+	}
+	assert.False(t, ValidCUSIP("037833101"))
+	assert.False(t, ValidCUSIP("03783310"))
+	for _, figi := range []string{"BBG000B9XRY4", "BBG000BPH459"} {
+		assert.True(t, ValidFIGI(figi), figi)
+	}
+	assert.False(t, ValidFIGI("BBG000B9XRY5"))
+	assert.False(t, ValidFIGI("BAG000B9XRY4"), "vowel prefix")
+}
@@ -226,7 +226,7 @@ func (am *AeronMessaging) processMarketData(data *models.MarketData) {
 
 	// Example: Log high-volume trades
 	if data.Volume > 1000000 {
-		log.Printf("High volume trade: %s @ %.2f (Volume: %d)",
+		log.Printf("High volume trade: %s @ %s (Volume: %d)",
 			data.Symbol, data.Price, data.Volume)
 	}
 }
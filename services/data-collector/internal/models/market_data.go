@@ -1,34 +1,93 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
 
+	"tradecaptain/data-collector/internal/decimal"
+)
+
+// MarketData prices are decimals, exact as the provider quoted them; convert
+// with Float64 only for analytics that work in floating point
 type MarketData struct {
-	ID        int       `json:"id" db:"id"`
-	Symbol    string    `json:"symbol" db:"symbol"`
-	Price     float64   `json:"price" db:"price"`
-	Volume    int64     `json:"volume" db:"volume"`
-	High      float64   `json:"high" db:"high"`
-	Low       float64   `json:"low" db:"low"`
-	Open      float64   `json:"open" db:"open"`
-	Close     float64   `json:"close" db:"close"`
-	Change    float64   `json:"change" db:"change"`
-	ChangePercent float64 `json:"change_percent" db:"change_percent"`
-	MarketCap int64     `json:"market_cap" db:"market_cap"`
-	Timestamp time.Time `json:"timestamp" db:"timestamp"`
-	Source    string    `json:"source" db:"source"`
+	ID            int             `json:"id" db:"id"`
+	Symbol        string          `json:"symbol" db:"symbol"`
+	Price         decimal.Decimal `json:"price" db:"price"`
+	Volume        int64           `json:"volume" db:"volume"`
+	High          decimal.Decimal `json:"high" db:"high"`
+	Low           decimal.Decimal `json:"low" db:"low"`
+	Open          decimal.Decimal `json:"open" db:"open"`
+	Close         decimal.Decimal `json:"close" db:"close"`
+	Change        decimal.Decimal `json:"change" db:"change"`
+	ChangePercent decimal.Decimal `json:"change_percent" db:"change_percent"`
+	MarketCap     int64           `json:"market_cap" db:"market_cap"`
+	Timestamp     time.Time       `json:"timestamp" db:"timestamp"`
+	Source        string          `json:"source" db:"source"`
+
+	// Level 1 and session fields of MarketData in schemas/market_data.fbs;
+	// zero where the source doesn't report them
+	Bid       decimal.Decimal `json:"bid" db:"bid"`
+	Ask       decimal.Decimal `json:"ask" db:"ask"`
+	BidSize   int64           `json:"bid_size,omitempty" db:"bid_size"`
+	AskSize   int64           `json:"ask_size,omitempty" db:"ask_size"`
+	PrevClose decimal.Decimal `json:"prev_close" db:"prev_close"`
+	Session   MarketSession   `json:"session,omitempty" db:"session"`
+	Exchange  string          `json:"exchange,omitempty" db:"exchange"`
+	Sequence  uint64          `json:"sequence,omitempty" db:"sequence"` // orders updates from one source
 }
 
+// MarketSession is the session a quote was made in. The zero value is
+// unknown; the others are MarketSession in schemas/market_data.fbs plus one.
+type MarketSession uint8
+
+const (
+	SessionUnknown MarketSession = iota
+	SessionPreMarket
+	SessionRegular
+	SessionAfterHours
+)
+
+var sessionNames = map[MarketSession]string{
+	SessionPreMarket:  "pre_market",
+	SessionRegular:    "regular",
+	SessionAfterHours: "after_hours",
+}
+
+func (s MarketSession) String() string {
+	return sessionNames[s]
+}
+
+func (s MarketSession) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *MarketSession) UnmarshalText(text []byte) error {
+	for session, name := range sessionNames {
+		if name == string(text) {
+			*s = session
+			return nil
+		}
+	}
+	if len(text) != 0 {
+		return fmt.Errorf("unknown market session %q", text)
+	}
+	*s = SessionUnknown
+	return nil
+}
+
+// CryptoData volume and market cap are quote currency totals, too large for
+// a decimal and estimates anyway, so they stay floats
 type CryptoData struct {
-	ID           int       `json:"id" db:"id"`
-	Symbol       string    `json:"symbol" db:"symbol"`
-	Name         string    `json:"name" db:"name"`
-	Price        float64   `json:"price" db:"price"`
-	Volume24h    float64   `json:"volume_24h" db:"volume_24h"`
-	MarketCap    float64   `json:"market_cap" db:"market_cap"`
-	Change24h    float64   `json:"change_24h" db:"change_24h"`
-	ChangePercent24h float64 `json:"change_percent_24h" db:"change_percent_24h"`
-	Timestamp    time.Time `json:"timestamp" db:"timestamp"`
-	Source       string    `json:"source" db:"source"`
+	ID               int             `json:"id" db:"id"`
+	Symbol           string          `json:"symbol" db:"symbol"`
+	Name             string          `json:"name" db:"name"`
+	Price            decimal.Decimal `json:"price" db:"price"`
+	Volume24h        float64         `json:"volume_24h" db:"volume_24h"`
+	MarketCap        float64         `json:"market_cap" db:"market_cap"`
+	Change24h        decimal.Decimal `json:"change_24h" db:"change_24h"`
+	ChangePercent24h decimal.Decimal `json:"change_percent_24h" db:"change_percent_24h"`
+	Timestamp        time.Time       `json:"timestamp" db:"timestamp"`
+	Source           string          `json:"source" db:"source"`
 }
 
 type NewsArticle struct {
@@ -54,4 +113,268 @@ type EconomicIndicator struct {
 	Frequency   string    `json:"frequency" db:"frequency"`
 	Source      string    `json:"source" db:"source"`
 	LastUpdated time.Time `json:"last_updated" db:"last_updated"`
-}
\ No newline at end of file
+
+	// RealtimeStart is the vintage: the day this value was published. A
+	// revision is stored as a new vintage next to the values it replaces.
+	RealtimeStart time.Time `json:"realtime_start" db:"realtime_start"`
+	// PreviousValue is the value a revision replaces, nil for a first release
+	PreviousValue *float64 `json:"previous_value,omitempty" db:"-"`
+}
+
+// DataGap is a stretch of a symbol's stored series with no data where the
+// exchange calendar expects some, and what was done about it
+type DataGap struct {
+	ID         int64     `json:"id" db:"id"`
+	Symbol     string    `json:"symbol" db:"symbol"`
+	Interval   string    `json:"interval" db:"interval"` // "1d" for missing days, "5m" for intraday holes
+	Start      time.Time `json:"start" db:"gap_start"`
+	End        time.Time `json:"end" db:"gap_end"`
+	Priority   float64   `json:"priority" db:"priority"`
+	Status     string    `json:"status" db:"status"` // detected, filled, unfillable or failed
+	Filled     int       `json:"filled" db:"filled"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	DetectedAt time.Time `json:"detected_at" db:"detected_at"`
+	FilledAt   time.Time `json:"filled_at,omitempty" db:"filled_at"`
+}
+
+// QuoteConsensus is the price the sources that recently quoted a symbol agree
+// on, with the ones that deviate from it beyond tolerance
+type QuoteConsensus struct {
+	Symbol    string                     `json:"symbol"`
+	Price     decimal.Decimal            `json:"price"`
+	Method    string                     `json:"method"` // "median" or "trimmed_mean"
+	Quotes    map[string]decimal.Decimal `json:"quotes"` // source -> price
+	Outliers  []SourceDeviation          `json:"outliers,omitempty"`
+	Timestamp time.Time                  `json:"timestamp"`
+}
+
+// SourceDeviation is how far one source's price is from the consensus
+type SourceDeviation struct {
+	Source       string          `json:"source"`
+	Price        decimal.Decimal `json:"price"`
+	DeviationBps float64         `json:"deviation_bps"`
+}
+
+// CompanyProfile is the reference description of an issuer
+type CompanyProfile struct {
+	Symbol      string   `json:"symbol"`
+	Name        string   `json:"name"`
+	Exchange    string   `json:"exchange"`
+	Industry    string   `json:"industry"`
+	Sector      string   `json:"sector"`
+	Website     string   `json:"website"`
+	Description string   `json:"description"`
+	CEO         string   `json:"ceo"`
+	IssueType   string   `json:"issue_type"`
+	Employees   int      `json:"employees"`
+	Country     string   `json:"country"`
+	Tags        []string `json:"tags,omitempty"`
+	Source      string   `json:"source"`
+}
+
+// ListedSymbol is one entry of a provider's tradable symbol list
+type ListedSymbol struct {
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Exchange string `json:"exchange"`
+	Type     string `json:"type"`
+	Region   string `json:"region"`
+	Currency string `json:"currency"`
+	Enabled  bool   `json:"enabled"`
+	Source   string `json:"source"`
+}
+
+// Filing is a document filed with the SEC by the company behind Symbol.
+// AcceptedAt and ReportDate are zero when EDGAR does not give them.
+type Filing struct {
+	ID              int64     `json:"id" db:"id"`
+	Symbol          string    `json:"symbol" db:"symbol"`
+	CIK             int       `json:"cik" db:"cik"`
+	Company         string    `json:"company" db:"company"`
+	FormType        string    `json:"form_type" db:"form_type"` // e.g. 10-K, 8-K/A, 4
+	AccessionNumber string    `json:"accession_number" db:"accession_number"`
+	FiledAt         time.Time `json:"filed_at" db:"filed_at"`
+	AcceptedAt      time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	ReportDate      time.Time `json:"report_date,omitempty" db:"report_date"` // period the filing covers
+	Items           string    `json:"items,omitempty" db:"items"`             // 8-K items, e.g. "2.02,9.01"
+	Description     string    `json:"description,omitempty" db:"description"`
+	DocumentURL     string    `json:"document_url" db:"document_url"`
+	IndexURL        string    `json:"index_url" db:"index_url"`
+	Source          string    `json:"source" db:"source"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// YieldCurveTenors are the maturities of the Treasury par yield curve,
+// shortest first
+var YieldCurveTenors = []string{"1M", "2M", "3M", "4M", "6M", "1Y", "2Y", "3Y", "5Y", "7Y", "10Y", "20Y", "30Y"}
+
+// YieldCurve is the Treasury par yield curve for one business day, in
+// percent by tenor. Tenors not quoted that day are missing: the 4-month bill
+// dates from October 2022 and no 30-year bond was issued 2002-2006.
+type YieldCurve struct {
+	Date      time.Time          `json:"date" db:"date"`
+	Yields    map[string]float64 `json:"yields" db:"-"`
+	Source    string             `json:"source" db:"source"`
+	UpdatedAt time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// Instrument is a security in the instrument master: what a symbol refers
+// to. ID is the internal identifier, stable across ticker changes; Symbol is
+// the ticker quotes are collected and stored under, and Tickers are the
+// instrument's symbols on individual venues where those differ.
+type Instrument struct {
+	ID         int64           `json:"id" db:"id"`
+	Symbol     string          `json:"symbol" db:"symbol"`
+	Name       string          `json:"name" db:"name"`
+	AssetClass string          `json:"asset_class" db:"asset_class"` // equity, etf, fund, index, crypto, fx, future, option or bond
+	Exchange   string          `json:"exchange" db:"exchange"`       // primary listing
+	Currency   string          `json:"currency" db:"currency"`
+	ISIN       string          `json:"isin,omitempty" db:"isin"`
+	CUSIP      string          `json:"cusip,omitempty" db:"cusip"`
+	FIGI       string          `json:"figi,omitempty" db:"figi"`
+	LotSize    int64           `json:"lot_size" db:"lot_size"`
+	TickSize   decimal.Decimal `json:"tick_size" db:"tick_size"` // zero when unknown
+	Sector     string          `json:"sector,omitempty" db:"sector"`
+	Industry   string          `json:"industry,omitempty" db:"industry"`
+	Status     string          `json:"status" db:"status"` // active, suspended or delisted
+	Tickers    []VenueTicker   `json:"tickers,omitempty" db:"-"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// VenueTicker is an instrument's symbol on one venue, e.g. Apple is AAPL on
+// XNAS and 0R2V on XLON
+type VenueTicker struct {
+	Venue  string `json:"venue"`
+	Ticker string `json:"ticker"`
+}
+
+// Corporate action types
+const (
+	ActionSplit         = "split"
+	ActionReverseSplit  = "reverse_split"
+	ActionCashDividend  = "cash_dividend"
+	ActionStockDividend = "stock_dividend"
+	ActionSpinOff       = "spin_off"
+	ActionSymbolChange  = "symbol_change"
+)
+
+// CorporateAction is an event that changes what a share of Symbol is, taking
+// effect at the open on ExDate. Ratio is new shares per share held for
+// splits (4 for a 4-for-1, 0.1 for a 1-for-10 reverse split), additional
+// shares per share for stock dividends and shares of NewSymbol per share
+// for spin-offs. Amount is the cash paid per share, or for a spin-off the
+// value distributed per share. A symbol change moves the instrument from
+// Symbol to NewSymbol.
+//
+// Actions entered by operations have Source "manual", and collection never
+// overwrites them; a cancelled action is kept but no longer adjusts prices.
+type CorporateAction struct {
+	ID         int64           `json:"id" db:"id"`
+	Symbol     string          `json:"symbol" db:"symbol"`
+	Type       string          `json:"type" db:"type"`
+	ExDate     time.Time       `json:"ex_date" db:"ex_date"`
+	RecordDate time.Time       `json:"record_date,omitempty" db:"record_date"`
+	PayDate    time.Time       `json:"pay_date,omitempty" db:"pay_date"`
+	Ratio      decimal.Decimal `json:"ratio" db:"ratio"`
+	Amount     decimal.Decimal `json:"amount" db:"amount"`
+	Currency   string          `json:"currency,omitempty" db:"currency"`
+	NewSymbol  string          `json:"new_symbol,omitempty" db:"new_symbol"`
+	Cancelled  bool            `json:"cancelled,omitempty" db:"cancelled"`
+	Note       string          `json:"note,omitempty" db:"note"`
+	Source     string          `json:"source" db:"source"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// PriceAdjustment is how a price series is adjusted for corporate actions.
+// Adjusted series are back-adjusted: the latest prices are as traded and
+// earlier ones are scaled to be comparable with them.
+type PriceAdjustment uint8
+
+const (
+	// AdjustRaw is prices as traded
+	AdjustRaw PriceAdjustment = iota
+	// AdjustSplits adjusts prices and volumes for splits, reverse splits and
+	// stock dividends
+	AdjustSplits
+	// AdjustTotalReturn also adjusts prices for cash dividends and spin-offs,
+	// as if they had been reinvested
+	AdjustTotalReturn
+)
+
+var adjustmentNames = map[PriceAdjustment]string{
+	AdjustRaw:         "raw",
+	AdjustSplits:      "split_adjusted",
+	AdjustTotalReturn: "total_return",
+}
+
+func (a PriceAdjustment) String() string {
+	return adjustmentNames[a]
+}
+
+func (a PriceAdjustment) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *PriceAdjustment) UnmarshalText(text []byte) error {
+	for adjustment, name := range adjustmentNames {
+		if name == string(text) {
+			*a = adjustment
+			return nil
+		}
+	}
+	if len(text) != 0 {
+		return fmt.Errorf("unknown price adjustment %q", text)
+	}
+	*a = AdjustRaw
+	return nil
+}
+
+// Option types
+const (
+	OptionCall = "call"
+	OptionPut  = "put"
+)
+
+// OptionContract is a listed option and its latest quote. Symbol is the OCC
+// option symbol without the root's padding: root, expiration as YYMMDD, C or
+// P and the strike in thousandths as eight digits, e.g. AAPL240621C00190000.
+// Prices are per share and a contract is for Multiplier shares.
+//
+// ImpliedVolatility (annualized, 0.25 being 25%) and the Greeks are computed
+// from the mid price against UnderlyingPrice, not taken from the provider,
+// and are zero where no volatility explains the price. Theta is per calendar
+// day, Vega and Rho per percentage point.
+type OptionContract struct {
+	Symbol            string          `json:"symbol" db:"symbol"`
+	Underlying        string          `json:"underlying" db:"underlying"`
+	Type              string          `json:"type" db:"type"`
+	Expiration        time.Time       `json:"expiration" db:"expiration"`
+	Strike            decimal.Decimal `json:"strike" db:"strike"`
+	Multiplier        int64           `json:"multiplier" db:"multiplier"`
+	Bid               decimal.Decimal `json:"bid" db:"bid"`
+	Ask               decimal.Decimal `json:"ask" db:"ask"`
+	Last              decimal.Decimal `json:"last" db:"last"`
+	Volume            int64           `json:"volume" db:"volume"`
+	OpenInterest      int64           `json:"open_interest" db:"open_interest"`
+	UnderlyingPrice   decimal.Decimal `json:"underlying_price" db:"underlying_price"`
+	ImpliedVolatility float64         `json:"implied_volatility" db:"implied_volatility"`
+	Delta             float64         `json:"delta" db:"delta"`
+	Gamma             float64         `json:"gamma" db:"gamma"`
+	Theta             float64         `json:"theta" db:"theta"`
+	Vega              float64         `json:"vega" db:"vega"`
+	Rho               float64         `json:"rho" db:"rho"`
+	Timestamp         time.Time       `json:"timestamp" db:"timestamp"`
+	Source            string          `json:"source" db:"source"`
+}
+
+// OptionChain is an underlying's contracts for one expiration, ordered by
+// strike with the call before the put. Expirations lists every expiration
+// the provider has for the underlying.
+type OptionChain struct {
+	Underlying      string            `json:"underlying"`
+	Expiration      time.Time         `json:"expiration"`
+	UnderlyingPrice decimal.Decimal   `json:"underlying_price"`
+	Expirations     []time.Time       `json:"expirations,omitempty"`
+	Contracts       []*OptionContract `json:"contracts"`
+	Timestamp       time.Time         `json:"timestamp"`
+}
@@ -0,0 +1,247 @@
+package models
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+)
+
+// marketDataVersion is the first byte of every binary MarketData. A layout
+// change gets a new version; UnmarshalBinary keeps reading the old ones.
+// Version 1 wrote prices as float64, version 2 as decimal units.
+const marketDataVersion = 2
+
+// marketDataFields is how many fields versions 1 and 2 encode
+const marketDataFields = 21
+
+var errTruncated = errors.New("truncated market data")
+
+// MarshalBinary encodes m as written to the WAL and sent over Aeron: the
+// version byte, a varint bitmap of the fields that are set, then those
+// fields in declaration order. Integers and decimal units are varints,
+// strings are length-prefixed and the timestamp is Unix nanoseconds, so it
+// decodes in UTC.
+func (m *MarketData) MarshalBinary() ([]byte, error) {
+	w := fieldWriter{body: make([]byte, 0, 128+len(m.Symbol)+len(m.Source)+len(m.Exchange))}
+	w.varint(int64(m.ID))
+	w.string(m.Symbol)
+	w.decimal(m.Price)
+	w.varint(m.Volume)
+	w.decimal(m.High)
+	w.decimal(m.Low)
+	w.decimal(m.Open)
+	w.decimal(m.Close)
+	w.decimal(m.Change)
+	w.decimal(m.ChangePercent)
+	w.varint(m.MarketCap)
+	w.timestamp(m.Timestamp)
+	w.string(m.Source)
+	w.decimal(m.Bid)
+	w.decimal(m.Ask)
+	w.varint(m.BidSize)
+	w.varint(m.AskSize)
+	w.decimal(m.PrevClose)
+	w.uvarint(uint64(m.Session))
+	w.string(m.Exchange)
+	w.uvarint(m.Sequence)
+
+	out := make([]byte, 0, 1+binary.MaxVarintLen64+len(w.body))
+	out = append(out, marketDataVersion)
+	out = binary.AppendUvarint(out, w.mask)
+	return append(out, w.body...), nil
+}
+
+// UnmarshalBinary decodes what MarshalBinary wrote. WAL entries written
+// before the binary encoding hold JSON and are decoded as such.
+func (m *MarketData) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errTruncated
+	}
+	switch data[0] {
+	case '{':
+		decoded := MarketData{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return fmt.Errorf("invalid JSON market data: %w", err)
+		}
+		*m = decoded
+		return nil
+	case 1, marketDataVersion:
+	default:
+		return fmt.Errorf("unsupported market data encoding version %d", data[0])
+	}
+
+	mask, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return errTruncated
+	}
+	if mask>>marketDataFields != 0 {
+		return fmt.Errorf("market data has fields unknown to version %d", data[0])
+	}
+
+	r := fieldReader{version: data[0], mask: mask, data: data[1+n:]}
+	var decoded MarketData
+	decoded.ID = int(r.varint())
+	decoded.Symbol = r.string()
+	decoded.Price = r.decimal()
+	decoded.Volume = r.varint()
+	decoded.High = r.decimal()
+	decoded.Low = r.decimal()
+	decoded.Open = r.decimal()
+	decoded.Close = r.decimal()
+	decoded.Change = r.decimal()
+	decoded.ChangePercent = r.decimal()
+	decoded.MarketCap = r.varint()
+	decoded.Timestamp = r.timestamp()
+	decoded.Source = r.string()
+	decoded.Bid = r.decimal()
+	decoded.Ask = r.decimal()
+	decoded.BidSize = r.varint()
+	decoded.AskSize = r.varint()
+	decoded.PrevClose = r.decimal()
+	decoded.Session = MarketSession(r.uvarint())
+	decoded.Exchange = r.string()
+	decoded.Sequence = r.uvarint()
+
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != 0 {
+		return fmt.Errorf("%d trailing bytes after market data", len(r.data))
+	}
+	*m = decoded
+	return nil
+}
+
+// fieldWriter appends the fields that are set and marks them in mask
+type fieldWriter struct {
+	mask uint64
+	bit  uint
+	body []byte
+}
+
+func (w *fieldWriter) next(set bool) bool {
+	if set {
+		w.mask |= 1 << w.bit
+	}
+	w.bit++
+	return set
+}
+
+// timestamp is set unless zero, so the Unix epoch survives a round trip
+func (w *fieldWriter) timestamp(t time.Time) {
+	if w.next(!t.IsZero()) {
+		w.body = binary.AppendVarint(w.body, t.UnixNano())
+	}
+}
+
+func (w *fieldWriter) decimal(v decimal.Decimal) {
+	if w.next(!v.IsZero()) {
+		w.body = binary.AppendVarint(w.body, v.Units())
+	}
+}
+
+func (w *fieldWriter) varint(v int64) {
+	if w.next(v != 0) {
+		w.body = binary.AppendVarint(w.body, v)
+	}
+}
+
+func (w *fieldWriter) uvarint(v uint64) {
+	if w.next(v != 0) {
+		w.body = binary.AppendUvarint(w.body, v)
+	}
+}
+
+func (w *fieldWriter) string(v string) {
+	if w.next(v != "") {
+		w.body = binary.AppendUvarint(w.body, uint64(len(v)))
+		w.body = append(w.body, v...)
+	}
+}
+
+// fieldReader reads fields in the order fieldWriter wrote them, returning
+// zero for those not in mask. The first error sticks.
+type fieldReader struct {
+	version byte
+	mask    uint64
+	bit     uint
+	data    []byte
+	err     error
+}
+
+func (r *fieldReader) next() bool {
+	set := r.mask&(1<<r.bit) != 0
+	r.bit++
+	return set && r.err == nil
+}
+
+// decimal reads version 1's float64 prices as the decimal they print as
+func (r *fieldReader) decimal() decimal.Decimal {
+	if r.version == 1 {
+		if !r.next() {
+			return decimal.Zero
+		}
+		if len(r.data) < 8 {
+			r.err = errTruncated
+			return decimal.Zero
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.data))
+		r.data = r.data[8:]
+		return decimal.FromFloat(v)
+	}
+	return decimal.FromUnits(r.varint())
+}
+
+func (r *fieldReader) varint() int64 {
+	if !r.next() {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *fieldReader) uvarint() uint64 {
+	if !r.next() {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *fieldReader) timestamp() time.Time {
+	set := r.mask&(1<<r.bit) != 0
+	nanos := r.varint()
+	if !set || r.err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
+
+func (r *fieldReader) string() string {
+	if !r.next() {
+		return ""
+	}
+	size, n := binary.Uvarint(r.data)
+	if n <= 0 || uint64(len(r.data)-n) < size {
+		r.err = errTruncated
+		return ""
+	}
+	v := string(r.data[n : n+int(size)])
+	r.data = r.data[n+int(size):]
+	return v
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketDataBinaryRoundTrip(t *testing.T) {
+	quotes := []MarketData{
+		{
+			ID:            42,
+			Symbol:        "AAPL",
+			Price:         decimal.MustParse("227.48"),
+			Volume:        72071199,
+			High:          decimal.MustParse("236.16"),
+			Low:           decimal.MustParse("224.22"),
+			Open:          decimal.MustParse("235.54"),
+			Close:         decimal.MustParse("227.48"),
+			Change:        decimal.MustParse("-11.59"),
+			ChangePercent: decimal.MustParse("-4.848379"),
+			MarketCap:     3417183838208,
+			Timestamp:     time.Date(2025, 3, 10, 20, 0, 1, 123456789, time.UTC),
+			Source:        "yahoo",
+			Bid:           decimal.MustParse("227.3"),
+			Ask:           decimal.MustParse("227.45"),
+			BidSize:       300,
+			AskSize:       200,
+			PrevClose:     decimal.MustParse("239.07"),
+			Session:       SessionAfterHours,
+			Exchange:      "NMS",
+			Sequence:      1 << 40,
+		},
+		{Symbol: "MSFT", Price: decimal.MustParse("380.16"), Timestamp: time.Unix(0, 0).UTC()},
+		{},
+	}
+
+	for _, quote := range quotes {
+		encoded, err := quote.MarshalBinary()
+		require.NoError(t, err)
+		var decoded MarketData
+		require.NoError(t, decoded.UnmarshalBinary(encoded))
+		assert.Equal(t, quote, decoded)
+	}
+
+	encoded, err := quotes[0].MarshalBinary()
+	require.NoError(t, err)
+	asJSON, err := json.Marshal(quotes[0])
+	require.NoError(t, err)
+	assert.Less(t, len(encoded), len(asJSON)/2)
+
+	// Timestamps decode in UTC
+	local := MarketData{Symbol: "7203.T", Timestamp: time.Date(2025, 3, 11, 9, 0, 0, 0, time.FixedZone("JST", 9*3600))}
+	encoded, err = local.MarshalBinary()
+	require.NoError(t, err)
+	var decoded MarketData
+	require.NoError(t, decoded.UnmarshalBinary(encoded))
+	assert.True(t, local.Timestamp.Equal(decoded.Timestamp))
+	assert.Equal(t, time.UTC, decoded.Timestamp.Location())
+}
+
+func TestMarketDataBinaryLegacyJSON(t *testing.T) {
+	// A WAL entry from before the binary encoding
+	legacy := `{"id":0,"symbol":"IBM","price":255.49,"volume":5453186,"high":261.96,"low":254.75,"open":259.75,"close":255.49,` +
+		`"change":-6.05,"change_percent":-2.3132,"market_cap":0,"timestamp":"2025-03-10T20:00:00Z","source":"alphavantage"}`
+
+	var decoded MarketData
+	require.NoError(t, decoded.UnmarshalBinary([]byte(legacy)))
+	assert.Equal(t, MarketData{
+		Symbol:        "IBM",
+		Price:         decimal.MustParse("255.49"),
+		Volume:        5453186,
+		High:          decimal.MustParse("261.96"),
+		Low:           decimal.MustParse("254.75"),
+		Open:          decimal.MustParse("259.75"),
+		Close:         decimal.MustParse("255.49"),
+		Change:        decimal.MustParse("-6.05"),
+		ChangePercent: decimal.MustParse("-2.3132"),
+		Timestamp:     time.Date(2025, 3, 10, 20, 0, 0, 0, time.UTC),
+		Source:        "alphavantage",
+	}, decoded)
+}
+
+func TestMarketDataBinaryVersion1(t *testing.T) {
+	// Symbol and price, with the price as a float64
+	encoded := []byte{1, 0b110, 4, 'A', 'A', 'P', 'L'}
+	encoded = binary.LittleEndian.AppendUint64(encoded, math.Float64bits(227.48))
+
+	var decoded MarketData
+	require.NoError(t, decoded.UnmarshalBinary(encoded))
+	assert.Equal(t, MarketData{Symbol: "AAPL", Price: decimal.MustParse("227.48")}, decoded)
+	assert.Error(t, decoded.UnmarshalBinary(encoded[:len(encoded)-1]))
+}
+
+func TestMarketDataBinaryErrors(t *testing.T) {
+	quote := MarketData{Symbol: "AAPL", Price: decimal.MustParse("227.48"), Exchange: "NMS", Timestamp: time.Now()}
+	encoded, err := quote.MarshalBinary()
+	require.NoError(t, err)
+
+	var decoded MarketData
+	for n := 0; n < len(encoded); n++ {
+		assert.Error(t, decoded.UnmarshalBinary(encoded[:n]), "truncated to %d bytes", n)
+	}
+	assert.Error(t, decoded.UnmarshalBinary(append(encoded, 0)), "trailing bytes")
+	assert.ErrorContains(t, decoded.UnmarshalBinary([]byte{3, 0}), "version 3")
+	assert.Error(t, decoded.UnmarshalBinary([]byte{marketDataVersion, 0x80, 0x80, 0x80, 0x01}), "unknown fields")
+}
+
+func TestMarketSessionJSON(t *testing.T) {
+	encoded, err := json.Marshal(MarketData{Symbol: "AAPL", Session: SessionPreMarket})
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"session":"pre_market"`)
+	assert.NotContains(t, string(encoded), `"bid_size"`, "unset level 1 sizes are omitted")
+
+	var decoded MarketData
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, SessionPreMarket, decoded.Session)
+	assert.Error(t, json.Unmarshal([]byte(`{"session":"overnight"}`), &decoded))
+}
@@ -0,0 +1,256 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+	_ "time/tzdata" // expiries are New York times wherever the collector runs
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/pricing"
+)
+
+// Listed options stop trading at the 16:00 New York close of their
+// expiration date; time to expiry counts to then, in years of 365 days
+var newYork = mustLoadLocation("America/New_York")
+
+const (
+	expiryHour   = 16
+	daysPerYear  = 365
+	hoursPerYear = daysPerYear * 24
+)
+
+// Valuation is what valuing a chain takes besides its quotes: the model, the
+// continuously compounded risk-free rate and, for Black-Scholes, the
+// underlying's continuous dividend yield, as fractions
+type Valuation struct {
+	Model    pricing.Model
+	Rate     float64
+	Dividend float64
+	Now      time.Time
+}
+
+// ModelFor returns the model an underlying of the instrument master's asset
+// class is valued with: Black-76 for futures, whose price already carries
+// the rate, and Black-Scholes for anything quoted spot
+func ModelFor(assetClass string) pricing.Model {
+	if assetClass == "future" {
+		return pricing.Black76
+	}
+	return pricing.BlackScholes
+}
+
+// Value sets the implied volatility and Greeks of chain's contracts from
+// their mid prices and the chain's underlying price, and returns how many
+// it could value. Contracts without a usable price, already expired or
+// priced outside the model's no-arbitrage bounds get zeros.
+func Value(chain *models.OptionChain, v Valuation) int {
+	valued := 0
+	spot := chain.UnderlyingPrice.Float64()
+	for _, contract := range chain.Contracts {
+		contract.UnderlyingPrice = chain.UnderlyingPrice
+		contract.ImpliedVolatility = 0
+		contract.Delta, contract.Gamma, contract.Theta, contract.Vega, contract.Rho = 0, 0, 0, 0, 0
+
+		price, ok := Mid(contract)
+		if !ok {
+			continue
+		}
+		in := pricing.Inputs{
+			Call:       contract.Type == models.OptionCall,
+			Underlying: spot,
+			Strike:     contract.Strike.Float64(),
+			Expiry:     YearsToExpiry(contract.Expiration, v.Now),
+			Rate:       v.Rate,
+			Dividend:   v.Dividend,
+		}
+		vol, err := v.Model.ImpliedVolatility(in, price)
+		if err != nil {
+			continue
+		}
+		in.Volatility = vol
+		greeks, err := v.Model.Greeks(in)
+		if err != nil {
+			continue
+		}
+
+		contract.ImpliedVolatility = vol
+		contract.Delta = greeks.Delta
+		contract.Gamma = greeks.Gamma
+		contract.Theta = greeks.Theta
+		contract.Vega = greeks.Vega
+		contract.Rho = greeks.Rho
+		valued++
+	}
+	return valued
+}
+
+// Mid returns the price a contract is valued at: halfway between bid and
+// ask when both are quoted and not crossed, otherwise the last trade
+func Mid(contract *models.OptionContract) (float64, bool) {
+	bid, ask := contract.Bid, contract.Ask
+	if bid.Sign() > 0 && ask.Sign() > 0 && !bid.GreaterThan(ask) {
+		return (bid.Float64() + ask.Float64()) / 2, true
+	}
+	if contract.Last.Sign() > 0 {
+		return contract.Last.Float64(), true
+	}
+	return 0, false
+}
+
+// YearsToExpiry returns the time from now to the close on expiration's
+// date, in years of 365 days; zero or less once expired
+func YearsToExpiry(expiration, now time.Time) float64 {
+	y, m, d := expiration.Date()
+	expiry := time.Date(y, m, d, expiryHour, 0, 0, 0, newYork)
+	return expiry.Sub(now).Hours() / hoursPerYear
+}
+
+// CurveRate returns the continuously compounded rate for a term of years,
+// interpolated linearly between the quoted tenors of a par yield curve and
+// held flat beyond its ends. It is false when the curve quotes no tenors.
+func CurveRate(curve *models.YieldCurve, years float64) (float64, bool) {
+	type point struct{ years, yield float64 }
+	var points []point
+	if curve != nil {
+		for tenor, yield := range curve.Yields {
+			if t, ok := tenorYears(tenor); ok {
+				points = append(points, point{t, yield})
+			}
+		}
+	}
+	if len(points) == 0 {
+		return 0, false
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].years < points[j].years })
+
+	yield := points[len(points)-1].yield
+	for i, p := range points {
+		if years > p.years {
+			continue
+		}
+		yield = p.yield
+		if i > 0 {
+			prev := points[i-1]
+			yield = prev.yield + (p.yield-prev.yield)*(years-prev.years)/(p.years-prev.years)
+		}
+		break
+	}
+	return math.Log(1 + yield/100), true
+}
+
+// tenorYears reads a yield curve tenor such as 3M or 10Y
+func tenorYears(tenor string) (float64, bool) {
+	if len(tenor) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(tenor[:len(tenor)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	switch tenor[len(tenor)-1] {
+	case 'M':
+		return float64(n) / 12, true
+	case 'Y':
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// Moneyness of a contract
+const (
+	InTheMoney    = "itm"
+	AtTheMoney    = "atm"
+	OutOfTheMoney = "otm"
+)
+
+// ATMBand is how far from the underlying price, as a fraction of it, a
+// strike still counts as at the money
+const ATMBand = 0.02
+
+// Moneyness returns whether the contract is in, at or out of the money with
+// the underlying at spot, or "" when spot is not positive
+func Moneyness(contract *models.OptionContract, spot decimal.Decimal) string {
+	if spot.Sign() <= 0 {
+		return ""
+	}
+	distance := (contract.Strike.Float64() - spot.Float64()) / spot.Float64()
+	switch {
+	case math.Abs(distance) <= ATMBand:
+		return AtTheMoney
+	case (distance < 0) == (contract.Type == models.OptionCall):
+		return InTheMoney
+	}
+	return OutOfTheMoney
+}
+
+// Filter selects the contracts of a chain. Moneyness is "itm", "atm",
+// "otm" or empty for any; zero strikes leave that end of the range open.
+type Filter struct {
+	Moneyness string
+	MinStrike decimal.Decimal
+	MaxStrike decimal.Decimal
+}
+
+// Validate checks the moneyness is known and the strike range not inverted
+func (f Filter) Validate() error {
+	switch f.Moneyness {
+	case "", InTheMoney, AtTheMoney, OutOfTheMoney:
+	default:
+		return fmt.Errorf("unknown moneyness %q: use itm, atm or otm", f.Moneyness)
+	}
+	if f.MinStrike.Sign() < 0 || f.MaxStrike.Sign() < 0 {
+		return errors.New("negative strike")
+	}
+	if f.MaxStrike.Sign() > 0 && f.MinStrike.GreaterThan(f.MaxStrike) {
+		return fmt.Errorf("min strike %s above max strike %s", f.MinStrike, f.MaxStrike)
+	}
+	return nil
+}
+
+// Apply returns a copy of chain with only the contracts f selects.
+// Moneyness is judged against the chain's underlying price.
+func (f Filter) Apply(chain *models.OptionChain) *models.OptionChain {
+	filtered := *chain
+	filtered.Contracts = nil
+	for _, contract := range chain.Contracts {
+		if f.MinStrike.Sign() > 0 && contract.Strike.LessThan(f.MinStrike) {
+			continue
+		}
+		if f.MaxStrike.Sign() > 0 && contract.Strike.GreaterThan(f.MaxStrike) {
+			continue
+		}
+		if f.Moneyness != "" && Moneyness(contract, chain.UnderlyingPrice) != f.Moneyness {
+			continue
+		}
+		filtered.Contracts = append(filtered.Contracts, contract)
+	}
+	return &filtered
+}
+
+// SortContracts orders contracts by expiration and strike, the call before
+// the put, the order OptionChain keeps them in
+func SortContracts(contracts []*models.OptionContract) {
+	sort.SliceStable(contracts, func(i, j int) bool {
+		a, b := contracts[i], contracts[j]
+		if !a.Expiration.Equal(b.Expiration) {
+			return a.Expiration.Before(b.Expiration)
+		}
+		if c := a.Strike.Cmp(b.Strike); c != 0 {
+			return c < 0
+		}
+		return a.Type == models.OptionCall && b.Type != models.OptionCall
+	})
+}
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("options: load %s: %v", name, err))
+	}
+	return loc
+}
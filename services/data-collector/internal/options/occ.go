@@ -0,0 +1,97 @@
+// Package options identifies listed options by their OCC symbols, values
+// option chains with the pricing package and filters them by moneyness and
+// strike.
+package options
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+)
+
+// StandardMultiplier is the shares per contract of standard equity options
+const StandardMultiplier = 100
+
+// OCC symbols are a root of up to six characters, the expiration as YYMMDD,
+// C or P and the strike in thousandths as eight digits. The padded form
+// fills the root out to six characters with spaces.
+const (
+	maxRootLength = 6
+	occSuffix     = 15 // YYMMDD + C/P + 8 strike digits
+	strikeDigits  = 8
+)
+
+var ErrInvalidSymbol = errors.New("invalid OCC option symbol")
+
+// Symbol returns the compact OCC symbol of the contract on underlying
+// expiring on expiration, e.g. AAPL240621C00190000. Strikes are kept to
+// thousandths.
+func Symbol(underlying string, expiration time.Time, optionType string, strike decimal.Decimal) string {
+	side := "C"
+	if optionType == models.OptionPut {
+		side = "P"
+	}
+	thousandths := strike.Round(3).Units() / 100_000
+	return fmt.Sprintf("%s%s%s%0*d", strings.ToUpper(strings.TrimSpace(underlying)),
+		expiration.Format("060102"), side, strikeDigits, thousandths)
+}
+
+// ParseSymbol reads an OCC symbol, compact or padded, into a contract with
+// its identity filled in: the compact symbol, underlying, type, expiration
+// and strike, and the standard multiplier. Adjusted contracts, whose root
+// has a digit appended (AAPL1), keep it as their underlying.
+func ParseSymbol(symbol string) (*models.OptionContract, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if len(symbol) <= occSuffix || len(symbol) > maxRootLength+occSuffix {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSymbol, symbol)
+	}
+
+	split := len(symbol) - occSuffix
+	root := strings.TrimRight(symbol[:split], " ")
+	suffix := symbol[split:]
+	if root == "" || strings.ContainsAny(root, " ") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSymbol, symbol)
+	}
+
+	expiration, err := time.Parse("060102", suffix[:6])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: bad expiration", ErrInvalidSymbol, symbol)
+	}
+	var optionType string
+	switch suffix[6] {
+	case 'C':
+		optionType = models.OptionCall
+	case 'P':
+		optionType = models.OptionPut
+	default:
+		return nil, fmt.Errorf("%w: %q: type must be C or P", ErrInvalidSymbol, symbol)
+	}
+	thousandths, err := strconv.ParseInt(suffix[7:], 10, 64)
+	if err != nil || thousandths <= 0 {
+		return nil, fmt.Errorf("%w: %q: bad strike", ErrInvalidSymbol, symbol)
+	}
+
+	return &models.OptionContract{
+		Symbol:     root + suffix,
+		Underlying: root,
+		Type:       optionType,
+		Expiration: expiration,
+		Strike:     decimal.New(thousandths, -3),
+		Multiplier: StandardMultiplier,
+	}, nil
+}
+
+// PaddedSymbol returns the 21-character form of a compact OCC symbol, with
+// the root padded to six characters, as clearing and most brokers use it
+func PaddedSymbol(symbol string) (string, error) {
+	contract, err := ParseSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%-*s%s", maxRootLength, contract.Underlying, contract.Symbol[len(contract.Underlying):]), nil
+}
@@ -0,0 +1,147 @@
+package options
+
+import (
+	"testing"
+	"time"
+
+	"tradecaptain/data-collector/internal/decimal"
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/pricing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var june21 = time.Date(2024, time.June, 21, 0, 0, 0, 0, time.UTC)
+
+func TestSymbols(t *testing.T) {
+	assert.Equal(t, "AAPL240621C00190000", Symbol("aapl", june21, models.OptionCall, decimal.FromInt(190)))
+	assert.Equal(t, "SPX240621P04512500", Symbol("SPX", june21, models.OptionPut, decimal.MustParse("4512.5")))
+
+	for _, symbol := range []string{"AAPL240621C00190000", "AAPL  240621C00190000", " aapl240621c00190000"} {
+		contract, err := ParseSymbol(symbol)
+		require.NoError(t, err, symbol)
+		assert.Equal(t, &models.OptionContract{
+			Symbol:     "AAPL240621C00190000",
+			Underlying: "AAPL",
+			Type:       models.OptionCall,
+			Expiration: june21,
+			Strike:     decimal.FromInt(190),
+			Multiplier: StandardMultiplier,
+		}, contract, symbol)
+	}
+
+	contract, err := ParseSymbol("BRKB1 240621P00412500")
+	require.NoError(t, err)
+	assert.Equal(t, "BRKB1", contract.Underlying)
+	assert.Equal(t, models.OptionPut, contract.Type)
+	assert.Equal(t, decimal.MustParse("412.5"), contract.Strike)
+
+	padded, err := PaddedSymbol("SPY240621C00500000")
+	require.NoError(t, err)
+	assert.Equal(t, "SPY   240621C00500000", padded)
+
+	for _, invalid := range []string{"", "240621C00190000", "AAPL241321C00190000", "AAPL240621X00190000",
+		"AAPL240621C0019000A", "TOOLONG240621C00190000", "AA PL240621C00190000"} {
+		_, err := ParseSymbol(invalid)
+		assert.ErrorIs(t, err, ErrInvalidSymbol, invalid)
+	}
+}
+
+// testChain is a chain a month out, with its quotes priced at 25% volatility
+func testChain(t *testing.T, now time.Time) *models.OptionChain {
+	expiration := now.AddDate(0, 1, 0)
+	chain := &models.OptionChain{Underlying: "XYZ", Expiration: expiration, UnderlyingPrice: decimal.FromInt(100)}
+	for _, strike := range []int64{90, 100, 110} {
+		for _, optionType := range []string{models.OptionCall, models.OptionPut} {
+			in := pricing.Inputs{Call: optionType == models.OptionCall, Underlying: 100, Strike: float64(strike),
+				Expiry: YearsToExpiry(expiration, now), Rate: 0.05, Volatility: 0.25}
+			price, err := pricing.BlackScholes.Price(in)
+			require.NoError(t, err)
+			// Quoted a cent either side of the model price
+			mid := decimal.FromFloat(price).Round(2)
+			chain.Contracts = append(chain.Contracts, &models.OptionContract{
+				Symbol: Symbol("XYZ", expiration, optionType, decimal.FromInt(strike)), Underlying: "XYZ",
+				Type: optionType, Expiration: expiration, Strike: decimal.FromInt(strike),
+				Bid: mid.Sub(decimal.MustParse("0.01")), Ask: mid.Add(decimal.MustParse("0.01")),
+			})
+		}
+	}
+	return chain
+}
+
+func TestValue(t *testing.T) {
+	now := time.Date(2024, time.May, 21, 14, 0, 0, 0, time.UTC)
+	chain := testChain(t, now)
+	// Far out of the money with no bid, and expired
+	chain.Contracts = append(chain.Contracts,
+		&models.OptionContract{Type: models.OptionCall, Expiration: chain.Expiration, Strike: decimal.FromInt(300),
+			Ask: decimal.MustParse("0.01")},
+		&models.OptionContract{Type: models.OptionCall, Expiration: now.AddDate(0, 0, -1), Strike: decimal.FromInt(100),
+			Last: decimal.MustParse("1.5")})
+
+	assert.Equal(t, 6, Value(chain, Valuation{Model: pricing.BlackScholes, Rate: 0.05, Now: now}))
+	for _, contract := range chain.Contracts[:6] {
+		assert.InDelta(t, 0.25, contract.ImpliedVolatility, 0.005, contract.Symbol)
+		assert.Equal(t, chain.UnderlyingPrice, contract.UnderlyingPrice)
+	}
+	call, put := chain.Contracts[2], chain.Contracts[3]
+	assert.InDelta(t, 0.54, call.Delta, 0.02)
+	assert.InDelta(t, -0.46, put.Delta, 0.02)
+	assert.InDelta(t, call.Gamma, put.Gamma, 1e-3)
+	assert.Less(t, call.Theta, 0.0)
+	assert.Greater(t, call.Vega, 0.0)
+	assert.Greater(t, call.Rho, 0.0)
+	assert.Less(t, put.Rho, 0.0)
+	for _, contract := range chain.Contracts[6:] {
+		assert.Zero(t, contract.ImpliedVolatility)
+		assert.Zero(t, contract.Delta)
+	}
+
+	price, ok := Mid(&models.OptionContract{Bid: decimal.MustParse("1.10"), Ask: decimal.MustParse("1.00"),
+		Last: decimal.MustParse("1.05")})
+	assert.True(t, ok)
+	assert.Equal(t, 1.05, price, "crossed quotes fall back to the last trade")
+
+	// 16:00 New York on the expiration date, 20:00 UTC in June
+	assert.InDelta(t, 1.0/365, YearsToExpiry(june21, time.Date(2024, time.June, 20, 20, 0, 0, 0, time.UTC)), 1e-9)
+	assert.Equal(t, pricing.Black76, ModelFor("future"))
+	assert.Equal(t, pricing.BlackScholes, ModelFor("equity"))
+}
+
+func TestCurveRate(t *testing.T) {
+	curve := &models.YieldCurve{Yields: map[string]float64{"1M": 5.0, "3M": 5.2, "1Y": 4.8, "10Y": 4.2}}
+	rate, ok := CurveRate(curve, 2.0/12)
+	require.True(t, ok)
+	assert.InDelta(t, 0.0500, rate, 1e-3)
+	rate, _ = CurveRate(curve, 0.01)
+	assert.InDelta(t, 0.04879, rate, 1e-5, "held flat before the shortest tenor")
+	rate, _ = CurveRate(curve, 30)
+	assert.InDelta(t, 0.04114, rate, 1e-5)
+	_, ok = CurveRate(&models.YieldCurve{}, 1)
+	assert.False(t, ok)
+}
+
+func TestFilter(t *testing.T) {
+	chain := testChain(t, time.Date(2024, time.May, 21, 14, 0, 0, 0, time.UTC))
+	symbols := func(chain *models.OptionChain) []string {
+		var out []string
+		for _, contract := range chain.Contracts {
+			out = append(out, contract.Symbol[len(contract.Symbol)-9:])
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"C00090000", "P00110000"}, symbols(Filter{Moneyness: InTheMoney}.Apply(chain)))
+	assert.Equal(t, []string{"C00100000", "P00100000"}, symbols(Filter{Moneyness: AtTheMoney}.Apply(chain)))
+	assert.Equal(t, []string{"P00090000", "C00110000"}, symbols(Filter{Moneyness: OutOfTheMoney}.Apply(chain)))
+	assert.Equal(t, []string{"C00100000", "P00100000", "C00110000", "P00110000"},
+		symbols(Filter{MinStrike: decimal.FromInt(95)}.Apply(chain)))
+	assert.Equal(t, []string{"C00090000"},
+		symbols(Filter{Moneyness: InTheMoney, MaxStrike: decimal.FromInt(100)}.Apply(chain)))
+	assert.Len(t, chain.Contracts, 6, "the chain itself is left alone")
+
+	assert.NoError(t, Filter{Moneyness: OutOfTheMoney, MinStrike: decimal.FromInt(90)}.Validate())
+	assert.Error(t, Filter{Moneyness: "deep"}.Validate())
+	assert.Error(t, Filter{MinStrike: decimal.FromInt(110), MaxStrike: decimal.FromInt(90)}.Validate())
+}
@@ -0,0 +1,146 @@
+// Package pricing values European options and their Greeks with the
+// Black-Scholes model (options on spot, with a continuous dividend yield)
+// and the Black-76 model (options on futures and forwards), and backs implied
+// volatility out of option prices.
+//
+// Both are the generalized Black-Scholes model with a cost of carry b:
+// r - q on spot, 0 on a future. Prices are float64; this is analytics, not
+// accounting. American equity options are priced as European, which
+// undervalues early exercise of deep in-the-money puts and of calls before
+// a large dividend.
+package pricing
+
+import (
+	"errors"
+	"math"
+)
+
+// Model is the option pricing model
+type Model uint8
+
+const (
+	// BlackScholes prices options on spot, Underlying being the spot price
+	BlackScholes Model = iota
+	// Black76 prices options on futures, Underlying being the futures price
+	Black76
+)
+
+func (m Model) String() string {
+	if m == Black76 {
+		return "black76"
+	}
+	return "black_scholes"
+}
+
+// Inputs describe one option. Expiry is in years (calendar days / 365),
+// Rate and Dividend are continuously compounded annual rates and
+// Volatility is annualized, all as fractions: 0.05 is 5%.
+type Inputs struct {
+	Call       bool
+	Underlying float64
+	Strike     float64
+	Expiry     float64
+	Rate       float64
+	Dividend   float64 // Black-Scholes only
+	Volatility float64
+}
+
+// Greeks are an option's sensitivities per unit of the underlying (Delta,
+// Gamma), per calendar day (Theta) and per percentage point of volatility
+// (Vega) or interest rate (Rho), the way they are usually quoted
+type Greeks struct {
+	Delta float64 `json:"delta"`
+	Gamma float64 `json:"gamma"`
+	Theta float64 `json:"theta"`
+	Vega  float64 `json:"vega"`
+	Rho   float64 `json:"rho"`
+}
+
+var ErrInvalidInputs = errors.New("option inputs out of range")
+
+func (in Inputs) valid() bool {
+	return in.Underlying > 0 && in.Strike > 0 && in.Expiry > 0 && in.Volatility > 0 &&
+		!math.IsInf(in.Underlying, 0) && !math.IsNaN(in.Rate) && !math.IsNaN(in.Dividend)
+}
+
+// carry is the cost of carry b of the underlying under m
+func (m Model) carry(in Inputs) float64 {
+	if m == Black76 {
+		return 0
+	}
+	return in.Rate - in.Dividend
+}
+
+// d1d2 are the Black-Scholes d1 and d2
+func d1d2(in Inputs, b float64) (float64, float64) {
+	stdev := in.Volatility * math.Sqrt(in.Expiry)
+	d1 := (math.Log(in.Underlying/in.Strike) + (b+in.Volatility*in.Volatility/2)*in.Expiry) / stdev
+	return d1, d1 - stdev
+}
+
+// Price returns the option's value, or ErrInvalidInputs unless the
+// underlying, strike, expiry and volatility are all positive
+func (m Model) Price(in Inputs) (float64, error) {
+	if !in.valid() {
+		return 0, ErrInvalidInputs
+	}
+	return m.price(in), nil
+}
+
+func (m Model) price(in Inputs) float64 {
+	b := m.carry(in)
+	d1, d2 := d1d2(in, b)
+	carried := in.Underlying * math.Exp((b-in.Rate)*in.Expiry)
+	discounted := in.Strike * math.Exp(-in.Rate*in.Expiry)
+	if in.Call {
+		return carried*normCDF(d1) - discounted*normCDF(d2)
+	}
+	return discounted*normCDF(-d2) - carried*normCDF(-d1)
+}
+
+// Greeks returns the option's Greeks, with the same input checks as Price
+func (m Model) Greeks(in Inputs) (Greeks, error) {
+	if !in.valid() {
+		return Greeks{}, ErrInvalidInputs
+	}
+
+	b := m.carry(in)
+	d1, d2 := d1d2(in, b)
+	sqrtT := math.Sqrt(in.Expiry)
+	carry := math.Exp((b - in.Rate) * in.Expiry)
+	discount := math.Exp(-in.Rate * in.Expiry)
+	density := normPDF(d1)
+
+	var g Greeks
+	g.Gamma = carry * density / (in.Underlying * in.Volatility * sqrtT)
+	vega := in.Underlying * carry * density * sqrtT
+	decay := -in.Underlying * carry * density * in.Volatility / (2 * sqrtT)
+
+	var theta, rho float64
+	if in.Call {
+		g.Delta = carry * normCDF(d1)
+		theta = decay - (b-in.Rate)*in.Underlying*carry*normCDF(d1) - in.Rate*in.Strike*discount*normCDF(d2)
+		rho = in.Expiry * in.Strike * discount * normCDF(d2)
+	} else {
+		g.Delta = carry * (normCDF(d1) - 1)
+		theta = decay + (b-in.Rate)*in.Underlying*carry*normCDF(-d1) + in.Rate*in.Strike*discount*normCDF(-d2)
+		rho = -in.Expiry * in.Strike * discount * normCDF(-d2)
+	}
+	if m == Black76 {
+		// The futures price does not move with rates; only discounting does
+		rho = -in.Expiry * m.price(in)
+	}
+
+	g.Theta = theta / 365
+	g.Vega = vega / 100
+	g.Rho = rho / 100
+	return g, nil
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
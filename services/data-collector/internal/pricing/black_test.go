@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// atm is the textbook at-the-money option: spot 100, a year out, 5% rates
+// and 20% volatility
+var atm = Inputs{Call: true, Underlying: 100, Strike: 100, Expiry: 1, Rate: 0.05, Volatility: 0.2}
+
+func TestPrice(t *testing.T) {
+	call, err := BlackScholes.Price(atm)
+	require.NoError(t, err)
+	assert.InDelta(t, 10.4506, call, 1e-4)
+
+	put := atm
+	put.Call = false
+	value, err := BlackScholes.Price(put)
+	require.NoError(t, err)
+	assert.InDelta(t, 5.5735, value, 1e-4)
+	// Put-call parity
+	assert.InDelta(t, call-value, 100-100*0.951229, 1e-4)
+
+	// On a future the forward needs no carrying, so calls and puts at the
+	// money are worth the same
+	call, err = Black76.Price(atm)
+	require.NoError(t, err)
+	value, err = Black76.Price(put)
+	require.NoError(t, err)
+	assert.InDelta(t, 7.5771, call, 1e-4)
+	assert.InDelta(t, call, value, 1e-9)
+
+	_, err = BlackScholes.Price(Inputs{Call: true, Underlying: 100, Strike: 100, Volatility: 0.2})
+	assert.ErrorIs(t, err, ErrInvalidInputs)
+}
+
+func TestGreeks(t *testing.T) {
+	g, err := BlackScholes.Greeks(atm)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6368, g.Delta, 1e-4)
+	assert.InDelta(t, 0.018762, g.Gamma, 1e-6)
+	assert.InDelta(t, 0.37524, g.Vega, 1e-5)
+	assert.InDelta(t, -6.4140/365, g.Theta, 1e-5)
+	assert.InDelta(t, 0.53232, g.Rho, 1e-5)
+
+	put := atm
+	put.Call = false
+	g, err = BlackScholes.Greeks(put)
+	require.NoError(t, err)
+	assert.InDelta(t, -0.3632, g.Delta, 1e-4)
+	assert.InDelta(t, 0.018762, g.Gamma, 1e-6)
+	assert.InDelta(t, -0.41890, g.Rho, 1e-5)
+
+	g, err = Black76.Greeks(atm)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5135, g.Delta, 1e-4)
+	assert.InDelta(t, -7.5771/100, g.Rho, 1e-5)
+}
+
+func TestImpliedVolatility(t *testing.T) {
+	for _, model := range []Model{BlackScholes, Black76} {
+		for _, in := range []Inputs{
+			atm,
+			{Call: false, Underlying: 100, Strike: 80, Expiry: 0.1, Rate: 0.03, Volatility: 0.45},
+			{Call: true, Underlying: 100, Strike: 130, Expiry: 0.5, Rate: 0.04, Dividend: 0.02, Volatility: 0.6},
+			{Call: true, Underlying: 100, Strike: 60, Expiry: 2, Rate: 0.05, Volatility: 0.15},
+		} {
+			price, err := model.Price(in)
+			require.NoError(t, err)
+			vol, err := model.ImpliedVolatility(in, price)
+			require.NoError(t, err, "%s %+v", model, in)
+			assert.InDelta(t, in.Volatility, vol, 1e-5, "%s %+v", model, in)
+		}
+	}
+
+	// Below intrinsic value and above the underlying itself
+	_, err := BlackScholes.ImpliedVolatility(Inputs{Call: true, Underlying: 120, Strike: 100, Expiry: 0.5, Rate: 0.05}, 15)
+	assert.ErrorIs(t, err, ErrNoImpliedVolatility)
+	_, err = BlackScholes.ImpliedVolatility(atm, 101)
+	assert.ErrorIs(t, err, ErrNoImpliedVolatility)
+	_, err = BlackScholes.ImpliedVolatility(atm, 0)
+	assert.ErrorIs(t, err, ErrInvalidInputs)
+}
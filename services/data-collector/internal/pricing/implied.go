@@ -0,0 +1,87 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+)
+
+// The volatility range implied volatility is searched over, and how close
+// the model price has to come to the target
+const (
+	minVolatility   = 1e-4
+	maxVolatility   = 5.0
+	priceTolerance  = 1e-8
+	maxIterations   = 100
+	newtonMinVega   = 1e-8
+	initialVolGuess = 0.3
+)
+
+// ErrNoImpliedVolatility is returned for prices no volatility in the
+// searched range explains: below the option's intrinsic value, above its
+// upper no-arbitrage bound, or too far out of the money to tell apart
+var ErrNoImpliedVolatility = errors.New("price implies no volatility")
+
+// ImpliedVolatility returns the volatility at which the model values the
+// option in at price, ignoring in.Volatility. It takes Newton steps on vega
+// and falls back to bisection wherever one would leave the bracket.
+func (m Model) ImpliedVolatility(in Inputs, price float64) (float64, error) {
+	in.Volatility = initialVolGuess
+	if !in.valid() || price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return 0, ErrInvalidInputs
+	}
+
+	lower, upper := m.bounds(in)
+	if price <= lower || price >= upper {
+		return 0, ErrNoImpliedVolatility
+	}
+
+	low, high := minVolatility, maxVolatility
+	in.Volatility = low
+	if m.price(in) > price {
+		return 0, ErrNoImpliedVolatility
+	}
+	in.Volatility = high
+	if m.price(in) < price {
+		return 0, ErrNoImpliedVolatility
+	}
+
+	vol := initialVolGuess
+	for i := 0; i < maxIterations; i++ {
+		in.Volatility = vol
+		diff := m.price(in) - price
+		if math.Abs(diff) < priceTolerance {
+			return vol, nil
+		}
+		// The price rises with volatility, so the root stays bracketed
+		if diff > 0 {
+			high = vol
+		} else {
+			low = vol
+		}
+
+		b := m.carry(in)
+		d1, _ := d1d2(in, b)
+		vega := in.Underlying * math.Exp((b-in.Rate)*in.Expiry) * normPDF(d1) * math.Sqrt(in.Expiry)
+		next := vol - diff/vega
+		if vega < newtonMinVega || next <= low || next >= high {
+			next = (low + high) / 2
+		}
+		vol = next
+	}
+	if high-low < 1e-6 {
+		return vol, nil
+	}
+	return 0, ErrNoImpliedVolatility
+}
+
+// bounds are the no-arbitrage bounds on the option's price: its discounted
+// intrinsic value and the discounted underlying or strike
+func (m Model) bounds(in Inputs) (float64, float64) {
+	b := m.carry(in)
+	carried := in.Underlying * math.Exp((b-in.Rate)*in.Expiry)
+	discounted := in.Strike * math.Exp(-in.Rate*in.Expiry)
+	if in.Call {
+		return math.Max(carried-discounted, 0), carried
+	}
+	return math.Max(discounted-carried, 0), discounted
+}
@@ -1,8 +1,12 @@
 package storage
 
 import (
+	"bufio"
 	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/dgraph-io/badger/v3"
@@ -54,7 +58,7 @@ func (w *BadgerWAL) WriteMarketData(data *models.MarketData) error {
 	// Generate timestamp-based key for ordering
 	key := make([]byte, 16)
 	binary.BigEndian.PutUint64(key[:8], uint64(time.Now().UnixNano()))
-	copy(key[8:], data.Symbol[:8]) // Symbol prefix for partitioning
+	copy(key[8:], data.Symbol) // Symbol prefix for partitioning, padded or cut to 8 bytes
 
 	// Serialize data (could use MessagePack here for even better performance)
 	value, err := data.MarshalBinary()
@@ -90,10 +94,13 @@ func (w *BadgerWAL) BatchWrite(data []*models.MarketData) error {
 	wb := w.db.NewWriteBatch()
 	defer wb.Cancel()
 
-	for _, item := range data {
+	// Consecutive timestamps keep entries of the same symbol written within
+	// one clock tick from overwriting each other
+	base := uint64(time.Now().UnixNano())
+	for i, item := range data {
 		key := make([]byte, 16)
-		binary.BigEndian.PutUint64(key[:8], uint64(time.Now().UnixNano()))
-		copy(key[8:], item.Symbol[:8])
+		binary.BigEndian.PutUint64(key[:8], base+uint64(i))
+		copy(key[8:], item.Symbol)
 
 		value, err := item.MarshalBinary()
 		if err != nil {
@@ -149,6 +156,97 @@ func (w *BadgerWAL) ReadRange(start, end time.Time) ([]*models.MarketData, error
 	return results, err
 }
 
+// walExportHeader starts every WAL export
+const walExportHeader = "tradecaptain-wal-export 1\n"
+
+// Export writes the entries logged between start and end to out, oldest
+// first, and returns how many it wrote. After the header line each entry is
+// its uvarint-prefixed key followed by its uvarint-prefixed value, exactly as
+// stored, so an export can be read back without opening the WAL.
+func (w *BadgerWAL) Export(out io.Writer, start, end time.Time) (int, error) {
+	buf := bufio.NewWriter(out)
+	if _, err := buf.WriteString(walExportHeader); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	err := w.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		startKey := make([]byte, 8)
+		binary.BigEndian.PutUint64(startKey, uint64(start.UnixNano()))
+		last := uint64(end.UnixNano())
+
+		lenBuf := make([]byte, binary.MaxVarintLen64)
+		for it.Seek(startKey); it.Valid(); it.Next() {
+			item := it.Item()
+			key := item.Key()
+			if len(key) < 8 || binary.BigEndian.Uint64(key[:8]) > last {
+				break
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			for _, field := range [][]byte{key, value} {
+				n := binary.PutUvarint(lenBuf, uint64(len(field)))
+				if _, err := buf.Write(lenBuf[:n]); err != nil {
+					return err
+				}
+				if _, err := buf.Write(field); err != nil {
+					return err
+				}
+			}
+			written++
+		}
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+	return written, buf.Flush()
+}
+
+// ReadWALExport decodes the market data of a file written by Export, in
+// logged order
+func ReadWALExport(r io.Reader) ([]*models.MarketData, error) {
+	in := bufio.NewReader(r)
+	header, err := in.ReadString('\n')
+	if err != nil || header != walExportHeader {
+		return nil, errors.New("not a WAL export: missing header")
+	}
+
+	readField := func() ([]byte, error) {
+		size, err := binary.ReadUvarint(in)
+		if err != nil {
+			return nil, err
+		}
+		field := make([]byte, size)
+		_, err = io.ReadFull(in, field)
+		return field, err
+	}
+
+	var results []*models.MarketData
+	for {
+		if _, err := readField(); err == io.EOF {
+			return results, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("WAL export entry %d: %w", len(results)+1, err)
+		}
+		value, err := readField()
+		if err != nil {
+			return nil, fmt.Errorf("WAL export entry %d: %w", len(results)+1, err)
+		}
+
+		var data models.MarketData
+		if err := data.UnmarshalBinary(value); err != nil {
+			return nil, fmt.Errorf("WAL export entry %d: %w", len(results)+1, err)
+		}
+		results = append(results, &data)
+	}
+}
+
 // Close shuts down the WAL
 func (w *BadgerWAL) Close() error {
 	w.kafka.Close()
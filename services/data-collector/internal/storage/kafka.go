@@ -3,8 +3,12 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"tradecaptain/data-collector/internal/decimal"
 	"tradecaptain/data-collector/internal/models"
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
@@ -15,48 +19,120 @@ type KafkaProducer struct {
 }
 
 func NewKafkaProducer(bootstrapServers string) (*KafkaProducer, error) {
-	// TODO: Initialize Kafka producer with proper configuration
-	// - Set up producer configuration with performance tuning
-	// - Configure batch settings for throughput optimization
-	// - Set up proper serialization and compression
-	// - Implement retry policy and error handling
-	// - Add monitoring and health check capabilities
-	// - Define topic naming conventions and mappings
-	panic("TODO: Implement Kafka producer initialization")
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers":  bootstrapServers,
+		"acks":               "all",
+		"enable.idempotence": true,
+		"compression.type":   "lz4",
+		"linger.ms":          5,
+		"batch.size":         65536,
+		"retries":            5,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+
+	k := &KafkaProducer{
+		producer: producer,
+		topics: map[string]string{
+			"market_data":   "market-data",
+			"consensus":     "market-data-consensus",
+			"crypto_data":   "crypto-data",
+			"price_alerts":  "price-alerts",
+			"news":          "news-articles",
+			"economic":      "economic-events",
+			"filings":       "sec-filings",
+			"market_events": "market-events",
+			"metrics":       "system-metrics",
+			"errors":        "system-errors",
+			"audit":         "audit-log",
+		},
+	}
+
+	// Drain delivery reports so the producer's event channel never blocks
+	go func() {
+		for event := range producer.Events() {
+			if msg, ok := event.(*kafka.Message); ok && msg.TopicPartition.Error != nil {
+				log.Printf("Kafka delivery failed for %s: %v", *msg.TopicPartition.Topic, msg.TopicPartition.Error)
+			}
+		}
+	}()
+
+	return k, nil
 }
 
 func (k *KafkaProducer) Close() {
-	// TODO: Gracefully close Kafka producer
-	// - Flush any pending messages
-	// - Wait for delivery confirmations
-	// - Close producer connection
-	// - Log closure status and statistics
-	panic("TODO: Implement Kafka producer closure")
+	if remaining := k.producer.Flush(10000); remaining > 0 {
+		log.Printf("Kafka producer closed with %d undelivered messages", remaining)
+	}
+	k.producer.Close()
+}
+
+// publishJSON serializes value and produces it to the mapped topic, keyed for partition ordering
+func (k *KafkaProducer) publishJSON(topicKey, key string, value interface{}, headers map[string]string) error {
+	topic, ok := k.topics[topicKey]
+	if !ok {
+		return fmt.Errorf("unknown topic mapping: %s", topicKey)
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s message: %w", topicKey, err)
+	}
+
+	msg := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            []byte(key),
+		Value:          payload,
+	}
+	for name, v := range headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: name, Value: []byte(v)})
+	}
+
+	if err := k.producer.Produce(msg, nil); err != nil {
+		return fmt.Errorf("failed to produce to %s: %w", topic, err)
+	}
+	return nil
 }
 
 // Market Data Streaming
+// PublishMarketData produces one raw quote, keyed by symbol so a symbol's
+// quotes stay ordered within a partition
 func (k *KafkaProducer) PublishMarketData(ctx context.Context, data *models.MarketData) error {
-	// TODO: Publish market data to Kafka topic
-	// - Serialize market data to JSON or Avro
-	// - Use symbol as partition key for ordering
-	// - Add proper headers with metadata
-	// - Handle publish failures with retry logic
-	// - Monitor message delivery confirmations
-	// - Add compression for network efficiency
-	panic("TODO: Implement market data publishing")
+	return k.publishJSON("market_data", data.Symbol, data, map[string]string{
+		"source": data.Source,
+	})
 }
 
+// PublishMarketDataBatch produces each quote in batch; the producer batches
+// them on the wire (linger.ms). A failed quote doesn't stop the rest.
 func (k *KafkaProducer) PublishMarketDataBatch(ctx context.Context, batch []*models.MarketData) error {
-	// TODO: Batch publish market data for efficiency
-	// - Process multiple market data points in single batch
-	// - Optimize batching for throughput vs latency
-	// - Handle partial batch failures gracefully
-	// - Maintain message ordering within partitions
-	// - Monitor batch processing performance
-	panic("TODO: Implement batch market data publishing")
+	var failed []string
+	for _, data := range batch {
+		if err := k.PublishMarketData(ctx, data); err != nil {
+			failed = append(failed, data.Symbol)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to publish market data for %s", strings.Join(failed, ","))
+	}
+	return nil
+}
+
+// PublishQuoteConsensus produces the cross-source consensus for a symbol,
+// keyed like the raw quotes it was computed from
+func (k *KafkaProducer) PublishQuoteConsensus(ctx context.Context, consensus *models.QuoteConsensus) error {
+	flagged := "false"
+	if len(consensus.Outliers) > 0 {
+		flagged = "true"
+	}
+	return k.publishJSON("consensus", consensus.Symbol, consensus, map[string]string{
+		"method":  consensus.Method,
+		"flagged": flagged,
+	})
 }
 
-func (k *KafkaProducer) PublishPriceAlert(ctx context.Context, symbol string, currentPrice, triggerPrice float64, alertType string) error {
+func (k *KafkaProducer) PublishPriceAlert(ctx context.Context, symbol string, currentPrice, triggerPrice decimal.Decimal, alertType string) error {
 	// TODO: Publish price alert events
 	// - Create price alert message structure
 	// - Use appropriate topic for alert routing
@@ -68,16 +144,12 @@ func (k *KafkaProducer) PublishPriceAlert(ctx context.Context, symbol string, cu
 
 // Crypto Data Streaming
 func (k *KafkaProducer) PublishCryptoData(ctx context.Context, data *models.CryptoData) error {
-	// TODO: Publish cryptocurrency data to Kafka
-	// - Handle crypto-specific fields and metadata
-	// - Use crypto symbol as partition key
-	// - Add exchange information in headers
-	// - Implement proper error handling
-	// - Monitor crypto data throughput
-	panic("TODO: Implement crypto data publishing")
+	return k.publishJSON("crypto_data", data.Symbol, data, map[string]string{
+		"source": data.Source,
+	})
 }
 
-func (k *KafkaProducer) PublishCryptoMarketUpdate(ctx context.Context, symbol string, price float64, volume float64, changePercent float64) error {
+func (k *KafkaProducer) PublishCryptoMarketUpdate(ctx context.Context, symbol string, price decimal.Decimal, volume float64, changePercent decimal.Decimal) error {
 	// TODO: Publish real-time crypto market updates
 	// - Create lightweight market update message
 	// - Optimize for high-frequency updates
@@ -88,64 +160,130 @@ func (k *KafkaProducer) PublishCryptoMarketUpdate(ctx context.Context, symbol st
 
 // News and Events Streaming
 func (k *KafkaProducer) PublishNewsArticle(ctx context.Context, article *models.NewsArticle) error {
-	// TODO: Publish news articles to Kafka
-	// - Serialize news article with full content
-	// - Add news categorization in headers
-	// - Include sentiment analysis results
-	// - Handle news source attribution
-	// - Add news priority levels for filtering
-	panic("TODO: Implement news article publishing")
+	return k.publishJSON("news", article.URL, article, map[string]string{
+		"source":   article.Source,
+		"category": article.Category,
+	})
 }
 
+// PublishEconomicEvent publishes a newly released observation, or a revision
+// of one carrying the value it replaces in previous_value, keyed by series
 func (k *KafkaProducer) PublishEconomicEvent(ctx context.Context, indicator *models.EconomicIndicator) error {
-	// TODO: Publish economic events and indicators
-	// - Handle FRED series data structure
-	// - Add economic calendar integration
-	// - Include data revision information
-	// - Set appropriate event priority levels
-	// - Handle different data frequencies
-	panic("TODO: Implement economic event publishing")
+	event := "release"
+	if indicator.PreviousValue != nil {
+		event = "revision"
+	}
+	return k.publishEconomic(event, indicator)
+}
+
+// PublishCurveInversion publishes a yield curve spread turning negative
+// ("inversion") or back to positive ("disinversion"), keyed by the spread's
+// series like its releases
+func (k *KafkaProducer) PublishCurveInversion(ctx context.Context, spread *models.EconomicIndicator, inverted bool) error {
+	event := "disinversion"
+	if inverted {
+		event = "inversion"
+	}
+	return k.publishEconomic(event, spread)
 }
 
+func (k *KafkaProducer) publishEconomic(event string, indicator *models.EconomicIndicator) error {
+	return k.publishJSON("economic", indicator.Series, indicator, map[string]string{
+		"event":     event,
+		"frequency": indicator.Frequency,
+		"source":    indicator.Source,
+	})
+}
+
+// PublishFiling adds a newly collected SEC filing to the symbol's filing feed
+func (k *KafkaProducer) PublishFiling(ctx context.Context, filing *models.Filing) error {
+	return k.publishJSON("filings", filing.Symbol, filing, map[string]string{
+		"form_type": filing.FormType,
+		"source":    filing.Source,
+	})
+}
+
+// PublishMarketEvent publishes something that happened to a symbol, such as
+// a material filing, keyed by symbol. Impact is "high", "medium" or "low".
 func (k *KafkaProducer) PublishMarketEvent(ctx context.Context, eventType, symbol, description string, impact string) error {
-	// TODO: Publish general market events
-	// - Create market event message schema
-	// - Add event classification and tagging
-	// - Include impact assessment information
-	// - Handle event correlation and relationships
-	// - Add geolocation data for global events
-	panic("TODO: Implement market event publishing")
+	event := map[string]interface{}{
+		"event_type":  eventType,
+		"symbol":      symbol,
+		"description": description,
+		"impact":      impact,
+		"timestamp":   time.Now().UTC(),
+	}
+
+	return k.publishJSON("market_events", symbol, event, map[string]string{
+		"event_type": eventType,
+		"impact":     impact,
+	})
 }
 
 // System Events and Monitoring
 func (k *KafkaProducer) PublishSystemMetric(ctx context.Context, service, metric string, value float64, tags map[string]string) error {
-	// TODO: Publish system performance metrics
-	// - Create metrics message structure
-	// - Add service identification and tagging
-	// - Include timestamp precision for metrics
-	// - Handle metric aggregation hints
-	// - Monitor metrics publishing performance
-	panic("TODO: Implement system metrics publishing")
+	event := map[string]interface{}{
+		"service":   service,
+		"metric":    metric,
+		"value":     value,
+		"tags":      tags,
+		"timestamp": time.Now().UTC(),
+	}
+
+	return k.publishJSON("metrics", service+":"+metric, event, map[string]string{
+		"metric": metric,
+	})
 }
 
 func (k *KafkaProducer) PublishErrorEvent(ctx context.Context, service, errorType, message string, severity string) error {
-	// TODO: Publish error events for monitoring
-	// - Create error event message schema
-	// - Add error classification and severity
-	// - Include stack trace and context information
-	// - Handle error deduplication logic
-	// - Set up error alerting integration
-	panic("TODO: Implement error event publishing")
+	event := map[string]interface{}{
+		"service":    service,
+		"error_type": errorType,
+		"message":    message,
+		"severity":   severity,
+		"timestamp":  time.Now().UTC(),
+	}
+
+	return k.publishJSON("errors", service, event, map[string]string{
+		"error_type": errorType,
+		"severity":   severity,
+	})
 }
 
+// PublishAuditLog records who did what to which resource. userID 0 is the
+// service itself (config reloads, scheduled jobs). Metadata values under
+// credential-looking keys are masked before they leave the process.
 func (k *KafkaProducer) PublishAuditLog(ctx context.Context, userID int, action, resource string, metadata map[string]interface{}) error {
-	// TODO: Publish audit log entries
-	// - Create audit log message structure
-	// - Add user and session information
-	// - Include detailed action metadata
-	// - Handle sensitive data masking
-	// - Ensure audit log integrity and ordering
-	panic("TODO: Implement audit log publishing")
+	masked := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		if isSensitiveKey(key) {
+			value = "***"
+		}
+		masked[key] = value
+	}
+
+	entry := map[string]interface{}{
+		"user_id":   userID,
+		"action":    action,
+		"resource":  resource,
+		"metadata":  masked,
+		"timestamp": time.Now().UTC(),
+	}
+
+	// Keyed by resource so entries for one resource stay ordered in a partition
+	return k.publishJSON("audit", resource, entry, map[string]string{
+		"action": action,
+	})
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range []string{"password", "secret", "token", "api_key", "apikey"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 // Topic Management
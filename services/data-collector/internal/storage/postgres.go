@@ -42,7 +42,7 @@ func (p *PostgresDB) SaveMarketData(ctx context.Context, data *models.MarketData
 	panic("TODO: Implement market data insertion")
 }
 
-func (p *PostgresDB) GetMarketData(ctx context.Context, symbol string, from, to time.Time) ([]*models.MarketData, error) {
+func (p *PostgresDB) GetMarketData(ctx context.Context, symbol string, from, to time.Time, adjustment models.PriceAdjustment) ([]*models.MarketData, error) {
 	// TODO: Retrieve historical market data
 	// - Build query with proper time range filtering
 	// - Add symbol filtering with case-insensitive matching
@@ -72,35 +72,7 @@ func (p *PostgresDB) UpdateMarketDataBatch(ctx context.Context, data []*models.M
 	panic("TODO: Implement batch market data updates")
 }
 
-// Crypto Data Operations
-func (p *PostgresDB) SaveCryptoData(ctx context.Context, data *models.CryptoData) error {
-	// TODO: Insert cryptocurrency data
-	// - Handle crypto-specific fields (market cap, 24h change)
-	// - Implement proper data validation
-	// - Add duplicate detection and handling
-	// - Use prepared statements for performance
-	panic("TODO: Implement crypto data insertion")
-}
-
-func (p *PostgresDB) GetCryptoData(ctx context.Context, symbol string, from, to time.Time) ([]*models.CryptoData, error) {
-	// TODO: Retrieve historical crypto data
-	// - Similar to market data but with crypto-specific fields
-	// - Handle crypto symbol normalization (BTC vs Bitcoin)
-	// - Add proper time zone handling for global crypto markets
-	panic("TODO: Implement crypto data retrieval")
-}
-
 // News Operations
-func (p *PostgresDB) SaveNewsArticle(ctx context.Context, article *models.NewsArticle) error {
-	// TODO: Insert news article into database
-	// - Check for duplicate articles by URL or title
-	// - Validate article content and metadata
-	// - Handle news categorization and tagging
-	// - Store sentiment analysis results
-	// - Add full-text search indexing preparation
-	panic("TODO: Implement news article insertion")
-}
-
 func (p *PostgresDB) GetNews(ctx context.Context, category string, limit int, offset int) ([]*models.NewsArticle, error) {
 	// TODO: Retrieve news articles with pagination
 	// - Filter by category, date range, source
@@ -121,25 +93,6 @@ func (p *PostgresDB) SearchNews(ctx context.Context, query string, limit int) ([
 	panic("TODO: Implement news search functionality")
 }
 
-// Economic Data Operations
-func (p *PostgresDB) SaveEconomicIndicator(ctx context.Context, indicator *models.EconomicIndicator) error {
-	// TODO: Insert economic indicator data
-	// - Handle FRED series data structure
-	// - Validate economic data consistency
-	// - Implement proper time series handling
-	// - Add data revision tracking
-	panic("TODO: Implement economic indicator insertion")
-}
-
-func (p *PostgresDB) GetEconomicIndicators(ctx context.Context, series []string, from, to time.Time) ([]*models.EconomicIndicator, error) {
-	// TODO: Retrieve economic indicators by series
-	// - Handle multiple economic series in one query
-	// - Add frequency filtering (daily, monthly, quarterly)
-	// - Implement proper date range handling
-	// - Add caching for frequently requested indicators
-	panic("TODO: Implement economic indicator retrieval")
-}
-
 // User and Portfolio Operations
 func (p *PostgresDB) CreateUserWatchlist(ctx context.Context, userID int, symbols []string) error {
 	// TODO: Create user watchlist
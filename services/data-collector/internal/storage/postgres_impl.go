@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"tradecaptain/data-collector/internal/corpactions"
+	"tradecaptain/data-collector/internal/decimal"
 	"tradecaptain/data-collector/internal/models"
 	"github.com/lib/pq"
 )
@@ -73,7 +77,50 @@ func (p *PostgresDB) SaveMarketData(ctx context.Context, data *models.MarketData
 	return nil
 }
 
-func (p *PostgresDB) GetMarketData(ctx context.Context, symbol string, from, to time.Time) ([]*models.MarketData, error) {
+// GetMarketData returns a symbol's stored quotes between from and to, as
+// traded or adjusted for corporate actions. Adjusted series run on through
+// the symbols the instrument had before a symbol change, under its current
+// one.
+func (p *PostgresDB) GetMarketData(ctx context.Context, symbol string, from, to time.Time, adjustment models.PriceAdjustment) ([]*models.MarketData, error) {
+	if adjustment == models.AdjustRaw {
+		return p.queryMarketData(ctx, symbol, from, to)
+	}
+	actions, err := p.CorporateActionHistory(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*models.MarketData
+	listings := corpactions.Listings(symbol, actions)
+	for i := len(listings) - 1; i >= 0; i-- {
+		start, end, ok := listingRange(listings[i], from, to)
+		if !ok {
+			continue
+		}
+		data, err := p.queryMarketData(ctx, listings[i].Symbol, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range data {
+			d.Symbol = symbol
+		}
+		results = append(results, data...)
+	}
+
+	corpactions.Adjust(results, actions, adjustment, func(symbol string, before time.Time) (decimal.Decimal, bool) {
+		var price decimal.Decimal
+		err := p.db.QueryRowContext(ctx, `
+			SELECT price FROM market_data
+			WHERE symbol = $1 AND timestamp < $2
+			ORDER BY timestamp DESC
+			LIMIT 1
+		`, symbol, before).Scan(&price)
+		return price, err == nil
+	})
+	return results, nil
+}
+
+func (p *PostgresDB) queryMarketData(ctx context.Context, symbol string, from, to time.Time) ([]*models.MarketData, error) {
 	query := `
 		SELECT id, symbol, price, volume, high, low, open, close, change, change_percent, market_cap, timestamp, source
 		FROM market_data
@@ -174,4 +221,1126 @@ func (p *PostgresDB) UpdateMarketDataBatch(ctx context.Context, data []*models.M
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+// GetMarketDataTimestamps returns the distinct timestamps stored for symbol
+// between from and to, oldest first
+func (p *PostgresDB) GetMarketDataTimestamps(ctx context.Context, symbol string, from, to time.Time) ([]time.Time, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT DISTINCT timestamp
+		FROM market_data
+		WHERE symbol = $1 AND timestamp BETWEEN $2 AND $3
+		ORDER BY timestamp ASC
+	`, symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query market data timestamps: %w", err)
+	}
+	defer rows.Close()
+
+	var timestamps []time.Time
+	for rows.Next() {
+		var ts time.Time
+		if err := rows.Scan(&ts); err != nil {
+			return nil, fmt.Errorf("failed to scan market data timestamp: %w", err)
+		}
+		timestamps = append(timestamps, ts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return timestamps, nil
+}
+
+// Data gap audit
+const createDataGapAuditTable = `
+	CREATE TABLE IF NOT EXISTS data_gap_audit (
+		id          BIGSERIAL PRIMARY KEY,
+		symbol      VARCHAR(20) NOT NULL,
+		interval    VARCHAR(8) NOT NULL,
+		gap_start   TIMESTAMPTZ NOT NULL,
+		gap_end     TIMESTAMPTZ NOT NULL,
+		priority    DOUBLE PRECISION NOT NULL DEFAULT 0,
+		status      VARCHAR(16) NOT NULL,
+		filled      INTEGER NOT NULL DEFAULT 0,
+		error       TEXT NOT NULL DEFAULT '',
+		detected_at TIMESTAMPTZ NOT NULL,
+		filled_at   TIMESTAMPTZ
+	);
+	CREATE INDEX IF NOT EXISTS idx_data_gap_audit_gap ON data_gap_audit (symbol, interval, gap_start);
+`
+
+// EnsureDataGapAudit creates the data_gap_audit table if it does not exist
+func (p *PostgresDB) EnsureDataGapAudit(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createDataGapAuditTable); err != nil {
+		return fmt.Errorf("failed to create data_gap_audit table: %w", err)
+	}
+	return nil
+}
+
+// SaveDataGap records a detected gap and sets its ID. A gap still awaiting a
+// fill attempt from an earlier scan keeps its record, brought up to date,
+// so each gap has one detected record plus one per attempt.
+func (p *PostgresDB) SaveDataGap(ctx context.Context, gap *models.DataGap) error {
+	err := p.db.QueryRowContext(ctx, `
+		UPDATE data_gap_audit SET gap_end = $4, priority = $5, detected_at = $6
+		WHERE id = (
+			SELECT id FROM data_gap_audit
+			WHERE symbol = $1 AND interval = $2 AND gap_start = $3 AND status = 'detected'
+			ORDER BY id DESC
+			LIMIT 1
+		)
+		RETURNING id
+	`, gap.Symbol, gap.Interval, gap.Start, gap.End, gap.Priority, gap.DetectedAt).Scan(&gap.ID)
+	if err == sql.ErrNoRows {
+		err = p.db.QueryRowContext(ctx, `
+			INSERT INTO data_gap_audit (symbol, interval, gap_start, gap_end, priority, status, filled, error, detected_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id
+		`, gap.Symbol, gap.Interval, gap.Start, gap.End, gap.Priority, gap.Status, gap.Filled, gap.Error, gap.DetectedAt,
+		).Scan(&gap.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save data gap for %s: %w", gap.Symbol, err)
+	}
+	return nil
+}
+
+// UpdateDataGap records the outcome of a fill attempt
+func (p *PostgresDB) UpdateDataGap(ctx context.Context, gap *models.DataGap) error {
+	var filledAt sql.NullTime
+	if !gap.FilledAt.IsZero() {
+		filledAt = sql.NullTime{Time: gap.FilledAt, Valid: true}
+	}
+
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE data_gap_audit SET status = $2, filled = $3, error = $4, filled_at = $5
+		WHERE id = $1
+	`, gap.ID, gap.Status, gap.Filled, gap.Error, filledAt)
+	if err != nil {
+		return fmt.Errorf("failed to update data gap %d: %w", gap.ID, err)
+	}
+	return nil
+}
+
+// CountDataGapAttempts returns how many unsuccessful fill attempts were made
+// since the given time for the gap of symbol and interval starting at start
+func (p *PostgresDB) CountDataGapAttempts(ctx context.Context, symbol, interval string, start, since time.Time) (int, error) {
+	var count int
+	err := p.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM data_gap_audit
+		WHERE symbol = $1 AND interval = $2 AND gap_start = $3 AND status IN ('failed', 'unfillable') AND detected_at >= $4
+	`, symbol, interval, start, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count data gap attempts: %w", err)
+	}
+	return count, nil
+}
+
+// Crypto snapshots are append-only, one row per pair, time and source;
+// streamed updates are throttled by the collector before they get here.
+// Prices are NUMERIC to keep decimals exact; tables created with DOUBLE
+// PRECISION prices scan into decimals all the same.
+const createCryptoDataTable = `
+	CREATE TABLE IF NOT EXISTS crypto_data (
+		id                 BIGSERIAL PRIMARY KEY,
+		symbol             VARCHAR(32) NOT NULL,
+		name               TEXT NOT NULL DEFAULT '',
+		price              NUMERIC(18, 8) NOT NULL,
+		volume_24h         DOUBLE PRECISION NOT NULL DEFAULT 0,
+		market_cap         DOUBLE PRECISION NOT NULL DEFAULT 0,
+		change_24h         NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		change_percent_24h NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		timestamp          TIMESTAMPTZ NOT NULL,
+		source             VARCHAR(32) NOT NULL,
+		UNIQUE (symbol, timestamp, source)
+	);
+`
+
+// EnsureCryptoData creates the crypto_data table if it does not exist
+func (p *PostgresDB) EnsureCryptoData(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createCryptoDataTable); err != nil {
+		return fmt.Errorf("failed to create crypto_data table: %w", err)
+	}
+	return nil
+}
+
+// SaveCryptoData stores a snapshot and sets its ID. A snapshot already stored
+// for the same pair, time and source is left alone.
+func (p *PostgresDB) SaveCryptoData(ctx context.Context, data *models.CryptoData) error {
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO crypto_data (symbol, name, price, volume_24h, market_cap, change_24h, change_percent_24h, timestamp, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (symbol, timestamp, source) DO NOTHING
+		RETURNING id
+	`, data.Symbol, data.Name, data.Price, data.Volume24h, data.MarketCap,
+		data.Change24h, data.ChangePercent24h, data.Timestamp, data.Source,
+	).Scan(&data.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to save crypto data for %s: %w", data.Symbol, err)
+	}
+	return nil
+}
+
+// GetCryptoData returns symbol's snapshots from every source between from
+// and to, oldest first
+func (p *PostgresDB) GetCryptoData(ctx context.Context, symbol string, from, to time.Time) ([]*models.CryptoData, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, symbol, name, price, volume_24h, market_cap, change_24h, change_percent_24h, timestamp, source
+		FROM crypto_data
+		WHERE symbol = $1 AND timestamp BETWEEN $2 AND $3
+		ORDER BY timestamp, source
+	`, symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crypto data: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.CryptoData
+	for rows.Next() {
+		data := &models.CryptoData{}
+		if err := rows.Scan(&data.ID, &data.Symbol, &data.Name, &data.Price, &data.Volume24h, &data.MarketCap,
+			&data.Change24h, &data.ChangePercent24h, &data.Timestamp, &data.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan crypto data: %w", err)
+		}
+		result = append(result, data)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return result, nil
+}
+
+// News articles are keyed by URL; the collector canonicalizes URLs so the
+// same story fetched twice is stored once
+const createNewsArticlesTable = `
+	CREATE TABLE IF NOT EXISTS news_articles (
+		id           BIGSERIAL PRIMARY KEY,
+		title        TEXT NOT NULL,
+		description  TEXT NOT NULL DEFAULT '',
+		url          TEXT NOT NULL UNIQUE,
+		source       VARCHAR(128) NOT NULL DEFAULT '',
+		author       TEXT NOT NULL DEFAULT '',
+		published_at TIMESTAMPTZ NOT NULL,
+		category     VARCHAR(64) NOT NULL DEFAULT '',
+		sentiment    DOUBLE PRECISION NOT NULL DEFAULT 0,
+		created_at   TIMESTAMPTZ NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_news_articles_published ON news_articles (published_at DESC);
+`
+
+// EnsureNewsArticles creates the news_articles table if it does not exist
+func (p *PostgresDB) EnsureNewsArticles(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createNewsArticlesTable); err != nil {
+		return fmt.Errorf("failed to create news_articles table: %w", err)
+	}
+	return nil
+}
+
+// SaveNewsArticle stores an article and sets its ID. It reports false,
+// leaving the stored article alone, when one with the same URL exists.
+func (p *PostgresDB) SaveNewsArticle(ctx context.Context, article *models.NewsArticle) (bool, error) {
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO news_articles (title, description, url, source, author, published_at, category, sentiment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (url) DO NOTHING
+		RETURNING id
+	`, article.Title, article.Description, article.URL, article.Source, article.Author,
+		article.PublishedAt, article.Category, article.Sentiment, article.CreatedAt,
+	).Scan(&article.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save news article %s: %w", article.URL, err)
+	}
+	return true, nil
+}
+
+// Economic indicators are append-only: each (series, date) keeps every vintage
+// that was collected, so a revision never overwrites the value it replaces
+const createEconomicIndicatorsTable = `
+	CREATE TABLE IF NOT EXISTS economic_indicators (
+		id             BIGSERIAL PRIMARY KEY,
+		series         VARCHAR(64) NOT NULL,
+		date           DATE NOT NULL,
+		realtime_start DATE NOT NULL,
+		value          DOUBLE PRECISION NOT NULL,
+		title          TEXT NOT NULL DEFAULT '',
+		units          TEXT NOT NULL DEFAULT '',
+		frequency      VARCHAR(32) NOT NULL DEFAULT '',
+		source         VARCHAR(32) NOT NULL,
+		last_updated   TIMESTAMPTZ NOT NULL,
+		UNIQUE (series, date, realtime_start)
+	);
+`
+
+const economicIndicatorColumns = `id, series, title, value, date, units, frequency, source, last_updated, realtime_start`
+
+// EnsureEconomicIndicators creates the economic_indicators table if it does not exist
+func (p *PostgresDB) EnsureEconomicIndicators(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createEconomicIndicatorsTable); err != nil {
+		return fmt.Errorf("failed to create economic_indicators table: %w", err)
+	}
+	return nil
+}
+
+// SaveEconomicIndicator stores a vintage of an observation and sets its ID.
+// It reports false, leaving the stored row alone, when that vintage is
+// already stored.
+func (p *PostgresDB) SaveEconomicIndicator(ctx context.Context, indicator *models.EconomicIndicator) (bool, error) {
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO economic_indicators (series, date, realtime_start, value, title, units, frequency, source, last_updated)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (series, date, realtime_start) DO NOTHING
+		RETURNING id
+	`, indicator.Series, indicator.Date, indicator.RealtimeStart, indicator.Value, indicator.Title,
+		indicator.Units, indicator.Frequency, indicator.Source, indicator.LastUpdated,
+	).Scan(&indicator.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save %s observation for %s: %w", indicator.Series, indicator.Date.Format("2006-01-02"), err)
+	}
+	return true, nil
+}
+
+// GetEconomicIndicators returns the latest vintage of each observation of the
+// given series between from and to, by series and then date
+func (p *PostgresDB) GetEconomicIndicators(ctx context.Context, series []string, from, to time.Time) ([]*models.EconomicIndicator, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT DISTINCT ON (series, date) `+economicIndicatorColumns+`
+		FROM economic_indicators
+		WHERE series = ANY($1) AND date BETWEEN $2 AND $3
+		ORDER BY series, date, realtime_start DESC
+	`, pq.Array(series), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query economic indicators: %w", err)
+	}
+	defer rows.Close()
+
+	var indicators []*models.EconomicIndicator
+	for rows.Next() {
+		indicator, err := scanEconomicIndicator(rows)
+		if err != nil {
+			return nil, err
+		}
+		indicators = append(indicators, indicator)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return indicators, nil
+}
+
+// GetEconomicVintage returns the latest stored vintage of series' observation
+// for date published before the given day, or nil if there is none
+func (p *PostgresDB) GetEconomicVintage(ctx context.Context, series string, date, before time.Time) (*models.EconomicIndicator, error) {
+	row := p.db.QueryRowContext(ctx, `
+		SELECT `+economicIndicatorColumns+`
+		FROM economic_indicators
+		WHERE series = $1 AND date = $2 AND realtime_start < $3
+		ORDER BY realtime_start DESC
+		LIMIT 1
+	`, series, date, before)
+
+	indicator, err := scanEconomicIndicator(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return indicator, err
+}
+
+// LatestEconomicVintage returns the newest vintage stored for series, zero
+// when the series has never been collected
+func (p *PostgresDB) LatestEconomicVintage(ctx context.Context, series string) (time.Time, error) {
+	var latest sql.NullTime
+	err := p.db.QueryRowContext(ctx, `
+		SELECT MAX(realtime_start) FROM economic_indicators WHERE series = $1
+	`, series).Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query latest %s vintage: %w", series, err)
+	}
+	return latest.Time, nil
+}
+
+func scanEconomicIndicator(row interface{ Scan(...interface{}) error }) (*models.EconomicIndicator, error) {
+	var indicator models.EconomicIndicator
+	err := row.Scan(&indicator.ID, &indicator.Series, &indicator.Title, &indicator.Value, &indicator.Date,
+		&indicator.Units, &indicator.Frequency, &indicator.Source, &indicator.LastUpdated, &indicator.RealtimeStart)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan economic indicator: %w", err)
+	}
+	return &indicator, nil
+}
+
+// SEC filings are stored once per symbol and accession number; a filing
+// naming several tracked companies is stored under each of them
+const createFilingsTable = `
+	CREATE TABLE IF NOT EXISTS sec_filings (
+		id               BIGSERIAL PRIMARY KEY,
+		symbol           VARCHAR(32) NOT NULL,
+		cik              INTEGER NOT NULL,
+		company          TEXT NOT NULL DEFAULT '',
+		form_type        VARCHAR(32) NOT NULL,
+		accession_number VARCHAR(32) NOT NULL,
+		filed_at         DATE NOT NULL,
+		accepted_at      TIMESTAMPTZ,
+		report_date      DATE,
+		items            TEXT NOT NULL DEFAULT '',
+		description      TEXT NOT NULL DEFAULT '',
+		document_url     TEXT NOT NULL,
+		index_url        TEXT NOT NULL,
+		source           VARCHAR(32) NOT NULL,
+		created_at       TIMESTAMPTZ NOT NULL,
+		UNIQUE (symbol, accession_number)
+	);
+	CREATE INDEX IF NOT EXISTS idx_sec_filings_symbol_filed ON sec_filings (symbol, filed_at DESC);
+`
+
+const filingColumns = `id, symbol, cik, company, form_type, accession_number, filed_at, accepted_at, report_date,
+	items, description, document_url, index_url, source, created_at`
+
+// EnsureFilings creates the sec_filings table if it does not exist
+func (p *PostgresDB) EnsureFilings(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createFilingsTable); err != nil {
+		return fmt.Errorf("failed to create sec_filings table: %w", err)
+	}
+	return nil
+}
+
+// SaveFiling stores a filing and sets its ID. It reports false, leaving the
+// stored filing alone, when the symbol already has one with that accession
+// number.
+func (p *PostgresDB) SaveFiling(ctx context.Context, filing *models.Filing) (bool, error) {
+	var acceptedAt, reportDate sql.NullTime
+	if !filing.AcceptedAt.IsZero() {
+		acceptedAt = sql.NullTime{Time: filing.AcceptedAt, Valid: true}
+	}
+	if !filing.ReportDate.IsZero() {
+		reportDate = sql.NullTime{Time: filing.ReportDate, Valid: true}
+	}
+
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO sec_filings (symbol, cik, company, form_type, accession_number, filed_at, accepted_at, report_date,
+			items, description, document_url, index_url, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (symbol, accession_number) DO NOTHING
+		RETURNING id
+	`, filing.Symbol, filing.CIK, filing.Company, filing.FormType, filing.AccessionNumber, filing.FiledAt,
+		acceptedAt, reportDate, filing.Items, filing.Description, filing.DocumentURL, filing.IndexURL,
+		filing.Source, filing.CreatedAt,
+	).Scan(&filing.ID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save filing %s for %s: %w", filing.AccessionNumber, filing.Symbol, err)
+	}
+	return true, nil
+}
+
+// GetFilings returns a symbol's filings newest first, limited to forms when
+// any are given
+func (p *PostgresDB) GetFilings(ctx context.Context, symbol string, forms []string, limit int) ([]*models.Filing, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT `+filingColumns+`
+		FROM sec_filings
+		WHERE symbol = $1 AND (cardinality($2::text[]) = 0 OR form_type = ANY($2))
+		ORDER BY filed_at DESC, accepted_at DESC NULLS LAST, id DESC
+		LIMIT $3
+	`, symbol, pq.Array(forms), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query filings: %w", err)
+	}
+	defer rows.Close()
+
+	var filings []*models.Filing
+	for rows.Next() {
+		var filing models.Filing
+		var acceptedAt, reportDate sql.NullTime
+		if err := rows.Scan(&filing.ID, &filing.Symbol, &filing.CIK, &filing.Company, &filing.FormType,
+			&filing.AccessionNumber, &filing.FiledAt, &acceptedAt, &reportDate, &filing.Items, &filing.Description,
+			&filing.DocumentURL, &filing.IndexURL, &filing.Source, &filing.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan filing: %w", err)
+		}
+		filing.AcceptedAt, filing.ReportDate = acceptedAt.Time, reportDate.Time
+		filings = append(filings, &filing)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return filings, nil
+}
+
+// LatestFilingDate returns the filing date of a symbol's newest stored
+// filing, zero when none has been collected
+func (p *PostgresDB) LatestFilingDate(ctx context.Context, symbol string) (time.Time, error) {
+	var latest sql.NullTime
+	err := p.db.QueryRowContext(ctx, `
+		SELECT MAX(filed_at) FROM sec_filings WHERE symbol = $1
+	`, symbol).Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query latest %s filing: %w", symbol, err)
+	}
+	return latest.Time, nil
+}
+
+// Treasury yield curves are stored one row per business day with a column
+// per tenor, NULL where the tenor was not quoted. The Treasury occasionally
+// corrects a day's curve, and the correction replaces it.
+const createYieldCurvesTable = `
+	CREATE TABLE IF NOT EXISTS treasury_yield_curves (
+		date       DATE PRIMARY KEY,
+		y_1m       DOUBLE PRECISION,
+		y_2m       DOUBLE PRECISION,
+		y_3m       DOUBLE PRECISION,
+		y_4m       DOUBLE PRECISION,
+		y_6m       DOUBLE PRECISION,
+		y_1y       DOUBLE PRECISION,
+		y_2y       DOUBLE PRECISION,
+		y_3y       DOUBLE PRECISION,
+		y_5y       DOUBLE PRECISION,
+		y_7y       DOUBLE PRECISION,
+		y_10y      DOUBLE PRECISION,
+		y_20y      DOUBLE PRECISION,
+		y_30y      DOUBLE PRECISION,
+		source     VARCHAR(32) NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL
+	);
+`
+
+// yieldColumn is the treasury_yield_curves column holding a tenor
+func yieldColumn(tenor string) string {
+	return "y_" + strings.ToLower(tenor)
+}
+
+// EnsureYieldCurves creates the treasury_yield_curves table if it does not exist
+func (p *PostgresDB) EnsureYieldCurves(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createYieldCurvesTable); err != nil {
+		return fmt.Errorf("failed to create treasury_yield_curves table: %w", err)
+	}
+	return nil
+}
+
+// SaveYieldCurve stores a day's curve, replacing a stored curve for that day
+// whose yields differ. It reports true only when the day was not stored yet.
+func (p *PostgresDB) SaveYieldCurve(ctx context.Context, curve *models.YieldCurve) (bool, error) {
+	tenors := models.YieldCurveTenors
+	columns := make([]string, len(tenors))
+	placeholders := make([]string, len(tenors))
+	updates := make([]string, len(tenors))
+	stored := make([]string, len(tenors))
+	excluded := make([]string, len(tenors))
+	args := []interface{}{curve.Date, curve.Source, curve.UpdatedAt}
+	for i, tenor := range tenors {
+		column := yieldColumn(tenor)
+		columns[i] = column
+		placeholders[i] = fmt.Sprintf("$%d", i+4)
+		updates[i] = column + " = EXCLUDED." + column
+		stored[i] = "t." + column
+		excluded[i] = "EXCLUDED." + column
+		var yield sql.NullFloat64
+		if value, ok := curve.Yields[tenor]; ok {
+			yield = sql.NullFloat64{Float64: value, Valid: true}
+		}
+		args = append(args, yield)
+	}
+
+	// xmax is zero on a freshly inserted row and set on an updated one
+	var inserted bool
+	err := p.db.QueryRowContext(ctx, `
+		INSERT INTO treasury_yield_curves AS t (date, source, updated_at, `+strings.Join(columns, ", ")+`)
+		VALUES ($1, $2, $3, `+strings.Join(placeholders, ", ")+`)
+		ON CONFLICT (date) DO UPDATE SET source = EXCLUDED.source, updated_at = EXCLUDED.updated_at, `+strings.Join(updates, ", ")+`
+		WHERE (`+strings.Join(stored, ", ")+`) IS DISTINCT FROM (`+strings.Join(excluded, ", ")+`)
+		RETURNING (xmax = 0)
+	`, args...).Scan(&inserted)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to save yield curve for %s: %w", curve.Date.Format("2006-01-02"), err)
+	}
+	return inserted, nil
+}
+
+// GetYieldCurve returns the latest curve on or before date, so weekends and
+// holidays get the previous business day's curve. It returns nil when no
+// curve that old is stored.
+func (p *PostgresDB) GetYieldCurve(ctx context.Context, date time.Time) (*models.YieldCurve, error) {
+	tenors := models.YieldCurveTenors
+	columns := make([]string, len(tenors))
+	for i, tenor := range tenors {
+		columns[i] = yieldColumn(tenor)
+	}
+
+	curve := &models.YieldCurve{Yields: make(map[string]float64, len(tenors))}
+	yields := make([]sql.NullFloat64, len(tenors))
+	dest := []interface{}{&curve.Date, &curve.Source, &curve.UpdatedAt}
+	for i := range yields {
+		dest = append(dest, &yields[i])
+	}
+
+	err := p.db.QueryRowContext(ctx, `
+		SELECT date, source, updated_at, `+strings.Join(columns, ", ")+`
+		FROM treasury_yield_curves
+		WHERE date <= $1
+		ORDER BY date DESC
+		LIMIT 1
+	`, date).Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query yield curve for %s: %w", date.Format("2006-01-02"), err)
+	}
+	for i, tenor := range tenors {
+		if yields[i].Valid {
+			curve.Yields[tenor] = yields[i].Float64
+		}
+	}
+	return curve, nil
+}
+
+// LatestYieldCurveDate returns the newest stored curve's date, zero when no
+// curve has been collected
+func (p *PostgresDB) LatestYieldCurveDate(ctx context.Context) (time.Time, error) {
+	var latest sql.NullTime
+	err := p.db.QueryRowContext(ctx, `
+		SELECT MAX(date) FROM treasury_yield_curves
+	`).Scan(&latest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query latest yield curve: %w", err)
+	}
+	return latest.Time, nil
+}
+
+// The instrument master: one row per instrument and its tickers on other
+// venues. Identifiers are NULL rather than empty when unknown so the unique
+// constraints only apply to real ones.
+const createInstrumentsTable = `
+	CREATE TABLE IF NOT EXISTS instruments (
+		id          BIGSERIAL PRIMARY KEY,
+		symbol      VARCHAR(32) NOT NULL UNIQUE,
+		name        TEXT NOT NULL DEFAULT '',
+		asset_class VARCHAR(16) NOT NULL,
+		exchange    VARCHAR(16) NOT NULL DEFAULT '',
+		currency    VARCHAR(8) NOT NULL DEFAULT '',
+		isin        CHAR(12) UNIQUE,
+		cusip       CHAR(9) UNIQUE,
+		figi        CHAR(12) UNIQUE,
+		lot_size    BIGINT NOT NULL DEFAULT 1,
+		tick_size   NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		sector      TEXT NOT NULL DEFAULT '',
+		industry    TEXT NOT NULL DEFAULT '',
+		status      VARCHAR(16) NOT NULL DEFAULT 'active',
+		updated_at  TIMESTAMPTZ NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS instrument_tickers (
+		instrument_id BIGINT NOT NULL REFERENCES instruments (id) ON DELETE CASCADE,
+		venue         VARCHAR(16) NOT NULL,
+		ticker        VARCHAR(32) NOT NULL,
+		PRIMARY KEY (venue, ticker)
+	);
+	CREATE INDEX IF NOT EXISTS idx_instrument_tickers_instrument ON instrument_tickers (instrument_id);
+`
+
+// EnsureInstruments creates the instruments and instrument_tickers tables if
+// they do not exist
+func (p *PostgresDB) EnsureInstruments(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createInstrumentsTable); err != nil {
+		return fmt.Errorf("failed to create instruments tables: %w", err)
+	}
+	return nil
+}
+
+// SaveInstruments stores instruments in one transaction: those without an ID
+// are inserted and given one, the others replace the stored instrument with
+// that ID, tickers included. Nothing is stored if any of them fails.
+func (p *PostgresDB) SaveInstruments(ctx context.Context, instruments []*models.Instrument) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, inst := range instruments {
+		args := []interface{}{inst.Symbol, inst.Name, inst.AssetClass, inst.Exchange, inst.Currency,
+			inst.ISIN, inst.CUSIP, inst.FIGI, inst.LotSize, inst.TickSize, inst.Sector, inst.Industry,
+			inst.Status, inst.UpdatedAt}
+
+		if inst.ID == 0 {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO instruments (symbol, name, asset_class, exchange, currency, isin, cusip, figi,
+					lot_size, tick_size, sector, industry, status, updated_at)
+				VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10, $11, $12, $13, $14)
+				RETURNING id
+			`, args...).Scan(&inst.ID)
+		} else {
+			var result sql.Result
+			result, err = tx.ExecContext(ctx, `
+				UPDATE instruments SET symbol = $1, name = $2, asset_class = $3, exchange = $4, currency = $5,
+					isin = NULLIF($6, ''), cusip = NULLIF($7, ''), figi = NULLIF($8, ''), lot_size = $9,
+					tick_size = $10, sector = $11, industry = $12, status = $13, updated_at = $14
+				WHERE id = $15
+			`, append(args, inst.ID)...)
+			if err == nil {
+				if n, _ := result.RowsAffected(); n == 0 {
+					err = fmt.Errorf("no instrument with id %d", inst.ID)
+				}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save instrument %s: %w", inst.Symbol, err)
+		}
+	}
+
+	// Tickers go after every instrument's old ones are gone, so one can move
+	// between instruments of the batch
+	ids := make([]int64, len(instruments))
+	for i, inst := range instruments {
+		ids[i] = inst.ID
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM instrument_tickers WHERE instrument_id = ANY($1)`, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to replace instrument tickers: %w", err)
+	}
+	for _, inst := range instruments {
+		for _, ticker := range inst.Tickers {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO instrument_tickers (instrument_id, venue, ticker) VALUES ($1, $2, $3)
+			`, inst.ID, ticker.Venue, ticker.Ticker); err != nil {
+				return fmt.Errorf("failed to save ticker %s:%s of %s: %w", ticker.Venue, ticker.Ticker, inst.Symbol, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit instruments: %w", err)
+	}
+	return nil
+}
+
+// GetInstruments returns the whole instrument master ordered by ID
+func (p *PostgresDB) GetInstruments(ctx context.Context) ([]*models.Instrument, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, symbol, name, asset_class, exchange, currency, COALESCE(isin, ''), COALESCE(cusip, ''),
+			COALESCE(figi, ''), lot_size, tick_size, sector, industry, status, updated_at
+		FROM instruments
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query instruments: %w", err)
+	}
+	defer rows.Close()
+
+	var instruments []*models.Instrument
+	byID := make(map[int64]*models.Instrument)
+	for rows.Next() {
+		var inst models.Instrument
+		if err := rows.Scan(&inst.ID, &inst.Symbol, &inst.Name, &inst.AssetClass, &inst.Exchange, &inst.Currency,
+			&inst.ISIN, &inst.CUSIP, &inst.FIGI, &inst.LotSize, &inst.TickSize, &inst.Sector, &inst.Industry,
+			&inst.Status, &inst.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan instrument: %w", err)
+		}
+		instruments = append(instruments, &inst)
+		byID[inst.ID] = &inst
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	tickers, err := p.db.QueryContext(ctx, `
+		SELECT instrument_id, venue, ticker FROM instrument_tickers ORDER BY instrument_id, venue, ticker
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query instrument tickers: %w", err)
+	}
+	defer tickers.Close()
+
+	for tickers.Next() {
+		var id int64
+		var ticker models.VenueTicker
+		if err := tickers.Scan(&id, &ticker.Venue, &ticker.Ticker); err != nil {
+			return nil, fmt.Errorf("failed to scan instrument ticker: %w", err)
+		}
+		if inst, ok := byID[id]; ok {
+			inst.Tickers = append(inst.Tickers, ticker)
+		}
+	}
+	if err := tickers.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return instruments, nil
+}
+
+// Corporate actions are keyed by symbol, type and ex-date, so collecting one
+// again updates it. Rows entered by operations have source 'manual' and are
+// only changed through the admin API.
+const createCorporateActionsTable = `
+	CREATE TABLE IF NOT EXISTS corporate_actions (
+		id          BIGSERIAL PRIMARY KEY,
+		symbol      VARCHAR(32) NOT NULL,
+		type        VARCHAR(16) NOT NULL,
+		ex_date     DATE NOT NULL,
+		record_date DATE,
+		pay_date    DATE,
+		ratio       NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		amount      NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		currency    VARCHAR(8) NOT NULL DEFAULT '',
+		new_symbol  VARCHAR(32) NOT NULL DEFAULT '',
+		cancelled   BOOLEAN NOT NULL DEFAULT FALSE,
+		note        TEXT NOT NULL DEFAULT '',
+		source      VARCHAR(32) NOT NULL,
+		updated_at  TIMESTAMPTZ NOT NULL,
+		UNIQUE (symbol, type, ex_date)
+	);
+	CREATE INDEX IF NOT EXISTS idx_corporate_actions_new_symbol ON corporate_actions (new_symbol)
+		WHERE type = 'symbol_change';
+`
+
+const corporateActionColumns = `id, symbol, type, ex_date, record_date, pay_date, ratio, amount, currency,
+	new_symbol, cancelled, note, source, updated_at`
+
+// EnsureCorporateActions creates the corporate_actions table if it does not exist
+func (p *PostgresDB) EnsureCorporateActions(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createCorporateActionsTable); err != nil {
+		return fmt.Errorf("failed to create corporate_actions table: %w", err)
+	}
+	return nil
+}
+
+func corporateActionArgs(action *models.CorporateAction) []interface{} {
+	var recordDate, payDate sql.NullTime
+	if !action.RecordDate.IsZero() {
+		recordDate = sql.NullTime{Time: action.RecordDate, Valid: true}
+	}
+	if !action.PayDate.IsZero() {
+		payDate = sql.NullTime{Time: action.PayDate, Valid: true}
+	}
+	return []interface{}{action.Symbol, action.Type, action.ExDate, recordDate, payDate, action.Ratio,
+		action.Amount, action.Currency, action.NewSymbol, action.Cancelled, action.Note, action.Source,
+		action.UpdatedAt}
+}
+
+// SaveCorporateAction stores an action and sets its ID. One without an ID
+// is inserted, replacing a stored action of the same symbol, type and
+// ex-date; one with an ID replaces the action with that ID.
+func (p *PostgresDB) SaveCorporateAction(ctx context.Context, action *models.CorporateAction) error {
+	args := corporateActionArgs(action)
+	var err error
+	if action.ID == 0 {
+		err = p.db.QueryRowContext(ctx, `
+			INSERT INTO corporate_actions (symbol, type, ex_date, record_date, pay_date, ratio, amount, currency,
+				new_symbol, cancelled, note, source, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (symbol, type, ex_date) DO UPDATE SET
+				record_date = EXCLUDED.record_date, pay_date = EXCLUDED.pay_date, ratio = EXCLUDED.ratio,
+				amount = EXCLUDED.amount, currency = EXCLUDED.currency, new_symbol = EXCLUDED.new_symbol,
+				cancelled = EXCLUDED.cancelled, note = EXCLUDED.note, source = EXCLUDED.source,
+				updated_at = EXCLUDED.updated_at
+			RETURNING id
+		`, args...).Scan(&action.ID)
+	} else {
+		var result sql.Result
+		result, err = p.db.ExecContext(ctx, `
+			UPDATE corporate_actions SET symbol = $1, type = $2, ex_date = $3, record_date = $4, pay_date = $5,
+				ratio = $6, amount = $7, currency = $8, new_symbol = $9, cancelled = $10, note = $11,
+				source = $12, updated_at = $13
+			WHERE id = $14
+		`, append(args, action.ID)...)
+		if err == nil {
+			if n, _ := result.RowsAffected(); n == 0 {
+				err = fmt.Errorf("no corporate action with id %d", action.ID)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save %s %s of %s: %w", action.Type, action.ExDate.Format("2006-01-02"), action.Symbol, err)
+	}
+	return nil
+}
+
+// MergeCorporateActions stores collected actions, updating those collected
+// before but leaving the ones entered by operations alone. It returns how
+// many were added or changed.
+func (p *PostgresDB) MergeCorporateActions(ctx context.Context, actions []*models.CorporateAction) (int, error) {
+	changed := 0
+	for _, action := range actions {
+		err := p.db.QueryRowContext(ctx, `
+			INSERT INTO corporate_actions (symbol, type, ex_date, record_date, pay_date, ratio, amount, currency,
+				new_symbol, cancelled, note, source, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (symbol, type, ex_date) DO UPDATE SET
+				record_date = EXCLUDED.record_date, pay_date = EXCLUDED.pay_date, ratio = EXCLUDED.ratio,
+				amount = EXCLUDED.amount, currency = EXCLUDED.currency, new_symbol = EXCLUDED.new_symbol,
+				source = EXCLUDED.source, updated_at = EXCLUDED.updated_at
+			WHERE corporate_actions.source <> 'manual'
+				AND (corporate_actions.record_date, corporate_actions.pay_date, corporate_actions.ratio,
+					corporate_actions.amount, corporate_actions.currency, corporate_actions.new_symbol)
+				IS DISTINCT FROM (EXCLUDED.record_date, EXCLUDED.pay_date, EXCLUDED.ratio,
+					EXCLUDED.amount, EXCLUDED.currency, EXCLUDED.new_symbol)
+			RETURNING id
+		`, corporateActionArgs(action)...).Scan(&action.ID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return changed, fmt.Errorf("failed to merge %s %s of %s: %w", action.Type, action.ExDate.Format("2006-01-02"), action.Symbol, err)
+		}
+		changed++
+	}
+	return changed, nil
+}
+
+// GetCorporateActions returns a symbol's actions by ex-date, cancelled ones
+// included
+func (p *PostgresDB) GetCorporateActions(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	return p.queryCorporateActions(ctx, `
+		SELECT `+corporateActionColumns+`
+		FROM corporate_actions
+		WHERE symbol = $1
+		ORDER BY ex_date, id
+	`, symbol)
+}
+
+// GetCorporateAction returns the action with the ID, nil when there is none
+func (p *PostgresDB) GetCorporateAction(ctx context.Context, id int64) (*models.CorporateAction, error) {
+	actions, err := p.queryCorporateActions(ctx, `
+		SELECT `+corporateActionColumns+` FROM corporate_actions WHERE id = $1
+	`, id)
+	if err != nil || len(actions) == 0 {
+		return nil, err
+	}
+	return actions[0], nil
+}
+
+// CorporateActionHistory returns the actions of symbol and of the symbols
+// the instrument traded under before changing to it, up to the change, by
+// ex-date
+func (p *PostgresDB) CorporateActionHistory(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	type listing struct {
+		symbol string
+		until  time.Time // zero for the current symbol
+	}
+	var history []*models.CorporateAction
+	seen := map[string]bool{symbol: true}
+	for pending := []listing{{symbol: symbol}}; len(pending) > 0; pending = pending[1:] {
+		current := pending[0]
+		actions, err := p.queryCorporateActions(ctx, `
+			SELECT `+corporateActionColumns+`
+			FROM corporate_actions
+			WHERE symbol = $1
+			UNION
+			SELECT `+corporateActionColumns+`
+			FROM corporate_actions
+			WHERE type = 'symbol_change' AND new_symbol = $1
+		`, current.symbol)
+		if err != nil {
+			return nil, err
+		}
+		for _, action := range actions {
+			switch {
+			case action.Symbol == current.symbol:
+				// A ticker freed by the change may have been reused since
+				if current.until.IsZero() || !action.ExDate.After(current.until) {
+					history = append(history, action)
+				}
+			case !seen[action.Symbol] && !action.Cancelled:
+				// The change itself comes with the old symbol's actions
+				seen[action.Symbol] = true
+				pending = append(pending, listing{symbol: action.Symbol, until: action.ExDate})
+			}
+		}
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].ExDate.Before(history[j].ExDate)
+	})
+	return history, nil
+}
+
+func (p *PostgresDB) queryCorporateActions(ctx context.Context, query string, args ...interface{}) ([]*models.CorporateAction, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query corporate actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*models.CorporateAction
+	for rows.Next() {
+		var action models.CorporateAction
+		var recordDate, payDate sql.NullTime
+		if err := rows.Scan(&action.ID, &action.Symbol, &action.Type, &action.ExDate, &recordDate, &payDate,
+			&action.Ratio, &action.Amount, &action.Currency, &action.NewSymbol, &action.Cancelled, &action.Note,
+			&action.Source, &action.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan corporate action: %w", err)
+		}
+		action.ExDate = action.ExDate.UTC()
+		action.RecordDate, action.PayDate = recordDate.Time, payDate.Time
+		actions = append(actions, &action)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return actions, nil
+}
+
+// listingRange clips from and to, both inclusive, to the time listing's
+// symbol was in use. It reports false when they do not overlap.
+func listingRange(listing corpactions.Listing, from, to time.Time) (time.Time, time.Time, bool) {
+	if listing.Since.After(from) {
+		from = listing.Since
+	}
+	if !listing.Until.IsZero() && !listing.Until.After(to) {
+		to = listing.Until.Add(-time.Microsecond)
+	}
+	return from, to, !from.After(to)
+}
+
+// Option quotes hold each contract's latest quote and the implied volatility
+// and Greeks computed from it, keyed by OCC symbol. Expired contracts keep
+// their last quote.
+const createOptionQuotesTable = `
+	CREATE TABLE IF NOT EXISTS option_quotes (
+		symbol             VARCHAR(32) PRIMARY KEY,
+		underlying         VARCHAR(32) NOT NULL,
+		type               VARCHAR(4) NOT NULL,
+		expiration         DATE NOT NULL,
+		strike             NUMERIC(18, 8) NOT NULL,
+		multiplier         BIGINT NOT NULL,
+		bid                NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		ask                NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		last               NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		volume             BIGINT NOT NULL DEFAULT 0,
+		open_interest      BIGINT NOT NULL DEFAULT 0,
+		underlying_price   NUMERIC(18, 8) NOT NULL DEFAULT 0,
+		implied_volatility DOUBLE PRECISION NOT NULL DEFAULT 0,
+		delta              DOUBLE PRECISION NOT NULL DEFAULT 0,
+		gamma              DOUBLE PRECISION NOT NULL DEFAULT 0,
+		theta              DOUBLE PRECISION NOT NULL DEFAULT 0,
+		vega               DOUBLE PRECISION NOT NULL DEFAULT 0,
+		rho                DOUBLE PRECISION NOT NULL DEFAULT 0,
+		timestamp          TIMESTAMPTZ NOT NULL,
+		source             VARCHAR(32) NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_option_quotes_underlying_expiration ON option_quotes (underlying, expiration);
+`
+
+// EnsureOptionQuotes creates the option_quotes table if it does not exist
+func (p *PostgresDB) EnsureOptionQuotes(ctx context.Context) error {
+	if _, err := p.db.ExecContext(ctx, createOptionQuotesTable); err != nil {
+		return fmt.Errorf("failed to create option_quotes table: %w", err)
+	}
+	return nil
+}
+
+// SaveOptionChain stores a chain's contracts in one transaction, replacing
+// their previous quotes
+func (p *PostgresDB) SaveOptionChain(ctx context.Context, chain *models.OptionChain) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, c := range chain.Contracts {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO option_quotes (symbol, underlying, type, expiration, strike, multiplier, bid, ask, last,
+				volume, open_interest, underlying_price, implied_volatility, delta, gamma, theta, vega, rho,
+				timestamp, source)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			ON CONFLICT (symbol) DO UPDATE SET
+				underlying = EXCLUDED.underlying, multiplier = EXCLUDED.multiplier, bid = EXCLUDED.bid,
+				ask = EXCLUDED.ask, last = EXCLUDED.last, volume = EXCLUDED.volume,
+				open_interest = EXCLUDED.open_interest, underlying_price = EXCLUDED.underlying_price,
+				implied_volatility = EXCLUDED.implied_volatility, delta = EXCLUDED.delta, gamma = EXCLUDED.gamma,
+				theta = EXCLUDED.theta, vega = EXCLUDED.vega, rho = EXCLUDED.rho, timestamp = EXCLUDED.timestamp,
+				source = EXCLUDED.source
+		`, c.Symbol, c.Underlying, c.Type, c.Expiration, c.Strike, c.Multiplier, c.Bid, c.Ask, c.Last,
+			c.Volume, c.OpenInterest, c.UnderlyingPrice, c.ImpliedVolatility, c.Delta, c.Gamma, c.Theta,
+			c.Vega, c.Rho, c.Timestamp, c.Source); err != nil {
+			return fmt.Errorf("failed to save option %s: %w", c.Symbol, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s option chain: %w", chain.Underlying, err)
+	}
+	return nil
+}
+
+// GetOptionChain returns the stored contracts of underlying expiring on
+// expiration, by strike with the call before the put, with the underlying
+// price of the latest quote and the stored expirations not yet past. It
+// returns nil when none are stored.
+func (p *PostgresDB) GetOptionChain(ctx context.Context, underlying string, expiration time.Time) (*models.OptionChain, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT symbol, underlying, type, expiration, strike, multiplier, bid, ask, last, volume, open_interest,
+			underlying_price, implied_volatility, delta, gamma, theta, vega, rho, timestamp, source
+		FROM option_quotes
+		WHERE underlying = $1 AND expiration = $2
+		ORDER BY strike, type
+	`, underlying, expiration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s option chain: %w", underlying, err)
+	}
+	defer rows.Close()
+
+	chain := &models.OptionChain{Underlying: underlying, Expiration: expiration}
+	for rows.Next() {
+		var c models.OptionContract
+		if err := rows.Scan(&c.Symbol, &c.Underlying, &c.Type, &c.Expiration, &c.Strike, &c.Multiplier,
+			&c.Bid, &c.Ask, &c.Last, &c.Volume, &c.OpenInterest, &c.UnderlyingPrice, &c.ImpliedVolatility,
+			&c.Delta, &c.Gamma, &c.Theta, &c.Vega, &c.Rho, &c.Timestamp, &c.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan option quote: %w", err)
+		}
+		c.Expiration = c.Expiration.UTC()
+		if c.Timestamp.After(chain.Timestamp) {
+			chain.Timestamp = c.Timestamp
+			chain.UnderlyingPrice = c.UnderlyingPrice
+		}
+		chain.Contracts = append(chain.Contracts, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	if len(chain.Contracts) == 0 {
+		return nil, nil
+	}
+
+	chain.Expirations, err = p.GetOptionExpirations(ctx, underlying, time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// GetOptionExpirations returns the expirations of underlying's stored
+// contracts on or after from, earliest first
+func (p *PostgresDB) GetOptionExpirations(ctx context.Context, underlying string, from time.Time) ([]time.Time, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT DISTINCT expiration FROM option_quotes
+		WHERE underlying = $1 AND expiration >= $2
+		ORDER BY expiration
+	`, underlying, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s option expirations: %w", underlying, err)
+	}
+	defer rows.Close()
+
+	var expirations []time.Time
+	for rows.Next() {
+		var expiration time.Time
+		if err := rows.Scan(&expiration); err != nil {
+			return nil, fmt.Errorf("failed to scan option expiration: %w", err)
+		}
+		expirations = append(expirations, expiration.UTC())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return expirations, nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"tradecaptain/data-collector/internal/decimal"
 	"tradecaptain/data-collector/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,14 +20,14 @@ func TestPostgresDB_SaveMarketData(t *testing.T) {
 
 	marketData := &models.MarketData{
 		Symbol:        "AAPL",
-		Price:         150.25,
+		Price:         decimal.MustParse("150.25"),
 		Volume:        1000000,
-		High:          151.0,
-		Low:           149.5,
-		Open:          150.0,
-		Close:         150.25,
-		Change:        0.25,
-		ChangePercent: 0.17,
+		High:          decimal.MustParse("151"),
+		Low:           decimal.MustParse("149.5"),
+		Open:          decimal.MustParse("150"),
+		Close:         decimal.MustParse("150.25"),
+		Change:        decimal.MustParse("0.25"),
+		ChangePercent: decimal.MustParse("0.17"),
 		MarketCap:     2500000000000,
 		Timestamp:     time.Now().UTC(),
 		Source:        "test",
@@ -57,12 +58,12 @@ func TestPostgresDB_GetMarketData_TimeRange(t *testing.T) {
 	for i := 0; i < 5; i++ {
 		marketData := &models.MarketData{
 			Symbol:    symbol,
-			Price:     float64(100 + i),
+			Price:     decimal.FromInt(int64(100 + i)),
 			Volume:    1000000,
-			High:      float64(101 + i),
-			Low:       float64(99 + i),
-			Open:      float64(100 + i),
-			Close:     float64(100 + i),
+			High:      decimal.FromInt(int64(101 + i)),
+			Low:       decimal.FromInt(int64(99 + i)),
+			Open:      decimal.FromInt(int64(100 + i)),
+			Close:     decimal.FromInt(int64(100 + i)),
 			Timestamp: baseTime.Add(time.Duration(i) * 24 * time.Hour),
 			Source:    "test",
 		}
@@ -73,7 +74,7 @@ func TestPostgresDB_GetMarketData_TimeRange(t *testing.T) {
 	from := baseTime.Add(24 * time.Hour)
 	to := baseTime.Add(72 * time.Hour)
 
-	results, err := db.GetMarketData(ctx, symbol, from, to)
+	results, err := db.GetMarketData(ctx, symbol, from, to, models.AdjustRaw)
 	require.NoError(t, err)
 	assert.Len(t, results, 2) // Should get days 1 and 2 (inclusive)
 
@@ -96,12 +97,12 @@ func TestPostgresDB_BatchUpdate(t *testing.T) {
 	for _, symbol := range symbols {
 		marketData := &models.MarketData{
 			Symbol:    symbol,
-			Price:     150.0,
+			Price:     decimal.MustParse("150"),
 			Volume:    1000000,
-			High:      151.0,
-			Low:       149.0,
-			Open:      150.0,
-			Close:     150.0,
+			High:      decimal.MustParse("151"),
+			Low:       decimal.MustParse("149"),
+			Open:      decimal.MustParse("150"),
+			Close:     decimal.MustParse("150"),
 			Timestamp: time.Now().UTC(),
 			Source:    "test",
 		}
@@ -130,12 +131,12 @@ func TestPostgresDB_Upsert_Behavior(t *testing.T) {
 	// Insert initial data
 	marketData := &models.MarketData{
 		Symbol:    "TEST",
-		Price:     100.0,
+		Price:     decimal.MustParse("100"),
 		Volume:    1000,
-		High:      101.0,
-		Low:       99.0,
-		Open:      100.0,
-		Close:     100.0,
+		High:      decimal.MustParse("101"),
+		Low:       decimal.MustParse("99"),
+		Open:      decimal.MustParse("100"),
+		Close:     decimal.MustParse("100"),
 		Timestamp: time.Now().UTC().Truncate(time.Minute), // Truncate for consistent comparison
 		Source:    "test",
 	}
@@ -144,7 +145,7 @@ func TestPostgresDB_Upsert_Behavior(t *testing.T) {
 	require.NoError(t, err)
 
 	// Update same record with different price
-	marketData.Price = 105.0
+	marketData.Price = decimal.FromInt(105)
 	marketData.Volume = 2000
 
 	err = db.SaveMarketData(ctx, marketData)
@@ -155,7 +156,7 @@ func TestPostgresDB_Upsert_Behavior(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, saved, 1)
 
-	assert.Equal(t, 105.0, saved[0].Price)
+	assert.Equal(t, decimal.FromInt(105), saved[0].Price)
 	assert.Equal(t, int64(2000), saved[0].Volume)
 }
 
@@ -219,12 +220,12 @@ func BenchmarkPostgresDB_BatchInsert(b *testing.B) {
 	for i := 0; i < batchSize; i++ {
 		marketData := &models.MarketData{
 			Symbol:    fmt.Sprintf("STOCK%d", i),
-			Price:     float64(100 + i),
+			Price:     decimal.FromInt(int64(100 + i)),
 			Volume:    int64(1000 + i),
-			High:      float64(101 + i),
-			Low:       float64(99 + i),
-			Open:      float64(100 + i),
-			Close:     float64(100 + i),
+			High:      decimal.FromInt(int64(101 + i)),
+			Low:       decimal.FromInt(int64(99 + i)),
+			Open:      decimal.FromInt(int64(100 + i)),
+			Close:     decimal.FromInt(int64(100 + i)),
 			Timestamp: time.Now().UTC(),
 			Source:    "benchmark",
 		}
@@ -1,17 +1,36 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/lib/pq"
+	"tradecaptain/data-collector/internal/calendar"
+	"tradecaptain/data-collector/internal/corpactions"
+	"tradecaptain/data-collector/internal/decimal"
 	"tradecaptain/data-collector/internal/models"
 )
 
 // QuestDBClient provides ultra-fast time-series data ingestion
 type QuestDBClient struct {
-	db *sql.DB
+	db          *sql.DB
+	instruments InstrumentResolver
+	actions     CorporateActionSource
+}
+
+// InstrumentResolver finds the instrument a symbol refers to;
+// *instruments.Master is one
+type InstrumentResolver interface {
+	Resolve(symbol string) (*models.Instrument, bool)
+}
+
+// CorporateActionSource gives the corporate actions of a symbol and the
+// symbols it traded under before; *PostgresDB is one
+type CorporateActionSource interface {
+	CorporateActionHistory(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
 }
 
 // NewQuestDBClient creates a new QuestDB client using PostgreSQL wire protocol
@@ -34,6 +53,17 @@ func NewQuestDBClient(connectionString string) (*QuestDBClient, error) {
 	return &QuestDBClient{db: db}, nil
 }
 
+// UseInstruments stores rows under the exchange the instrument master lists
+// for their symbol rather than the one the quote reported
+func (q *QuestDBClient) UseInstruments(instruments InstrumentResolver) {
+	q.instruments = instruments
+}
+
+// UseCorporateActions lets GetPriceHistory return adjusted series
+func (q *QuestDBClient) UseCorporateActions(actions CorporateActionSource) {
+	q.actions = actions
+}
+
 // InsertMarketData inserts market data using optimized batch operations
 func (q *QuestDBClient) InsertMarketData(data *models.MarketData) error {
 	query := `
@@ -43,6 +73,7 @@ func (q *QuestDBClient) InsertMarketData(data *models.MarketData) error {
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
+	exchange := q.exchange(data)
 	_, err := q.db.Exec(
 		query,
 		data.Symbol,
@@ -56,8 +87,8 @@ func (q *QuestDBClient) InsertMarketData(data *models.MarketData) error {
 		data.Close,
 		calculateVolatility(data),
 		classifyRisk(data),
-		determineMarketSession(data.Timestamp),
-		data.Exchange,
+		determineMarketSession(data, exchange),
+		exchange,
 		data.Timestamp,
 	)
 
@@ -88,6 +119,7 @@ func (q *QuestDBClient) BatchInsertMarketData(dataSlice []*models.MarketData) er
 	defer stmt.Close()
 
 	for _, data := range dataSlice {
+		exchange := q.exchange(data)
 		_, err = stmt.Exec(
 			data.Symbol,
 			data.Price,
@@ -100,8 +132,8 @@ func (q *QuestDBClient) BatchInsertMarketData(dataSlice []*models.MarketData) er
 			data.Close,
 			calculateVolatility(data),
 			classifyRisk(data),
-			determineMarketSession(data.Timestamp),
-			data.Exchange,
+			determineMarketSession(data, exchange),
+			exchange,
 			data.Timestamp,
 		)
 		if err != nil {
@@ -177,8 +209,10 @@ func (q *QuestDBClient) GetLatestPrices(symbols []string) (map[string]*models.Ma
 	return result, rows.Err()
 }
 
-// GetPriceHistory retrieves historical price data for backtesting
-func (q *QuestDBClient) GetPriceHistory(symbol string, start, end time.Time, interval string) ([]*models.MarketData, error) {
+// GetPriceHistory retrieves historical price data for backtesting, as traded
+// or adjusted for corporate actions. Adjusted history runs on through the
+// symbols the instrument had before a symbol change, under its current one.
+func (q *QuestDBClient) GetPriceHistory(symbol string, start, end time.Time, interval string, adjustment models.PriceAdjustment) ([]*models.MarketData, error) {
 	var query string
 
 	switch interval {
@@ -213,6 +247,38 @@ func (q *QuestDBClient) GetPriceHistory(symbol string, start, end time.Time, int
 		return nil, fmt.Errorf("unsupported interval: %s", interval)
 	}
 
+	if adjustment == models.AdjustRaw {
+		return q.queryPriceHistory(query, symbol, start, end)
+	}
+	if q.actions == nil {
+		return nil, errors.New("adjusted price history needs corporate actions")
+	}
+	actions, err := q.actions.CorporateActionHistory(context.Background(), symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*models.MarketData
+	listings := corpactions.Listings(symbol, actions)
+	for i := len(listings) - 1; i >= 0; i-- {
+		from, to, ok := listingRange(listings[i], start, end)
+		if !ok {
+			continue
+		}
+		bars, err := q.queryPriceHistory(query, listings[i].Symbol, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, bar := range bars {
+			bar.Symbol = symbol
+		}
+		result = append(result, bars...)
+	}
+	corpactions.Adjust(result, actions, adjustment, q.closeBefore)
+	return result, nil
+}
+
+func (q *QuestDBClient) queryPriceHistory(query, symbol string, start, end time.Time) ([]*models.MarketData, error) {
 	rows, err := q.db.Query(query, symbol, start, end)
 	if err != nil {
 		return nil, err
@@ -241,6 +307,18 @@ func (q *QuestDBClient) GetPriceHistory(symbol string, start, end time.Time, int
 	return result, rows.Err()
 }
 
+// closeBefore is the corpactions.CloseLookup of the stored ticks
+func (q *QuestDBClient) closeBefore(symbol string, before time.Time) (decimal.Decimal, bool) {
+	var price decimal.Decimal
+	err := q.db.QueryRow(`
+		SELECT price FROM market_data_realtime
+		WHERE symbol = $1 AND timestamp < $2
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, symbol, before).Scan(&price)
+	return price, err == nil
+}
+
 // GetPerformanceStats returns database performance statistics
 func (q *QuestDBClient) GetPerformanceStats() (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
@@ -272,11 +350,13 @@ func (q *QuestDBClient) Close() error {
 }
 
 // Helper functions
+// calculateVolatility is the day's range as a percentage of the price, as a
+// float for the DOUBLE volatility_pct column
 func calculateVolatility(data *models.MarketData) float64 {
-	if data.Price == 0 {
+	if data.Price.IsZero() {
 		return 0
 	}
-	return ((data.High - data.Low) / data.Price) * 100
+	return data.High.Sub(data.Low).Div(data.Price).Float64() * 100
 }
 
 func classifyRisk(data *models.MarketData) string {
@@ -289,10 +369,19 @@ func classifyRisk(data *models.MarketData) string {
 	return "low"
 }
 
-func determineMarketSession(timestamp time.Time) string {
-	hour := timestamp.Hour()
-	if hour >= 9 && hour < 16 {
-		return "market_hours"
+// exchange is the exchange column for data: its symbol's primary listing in
+// the instrument master, or else the exchange the quote reported
+func (q *QuestDBClient) exchange(data *models.MarketData) string {
+	if q.instruments != nil {
+		if inst, ok := q.instruments.Resolve(data.Symbol); ok && inst.Exchange != "" {
+			return inst.Exchange
+		}
 	}
-	return "after_hours"
+	return data.Exchange
+}
+
+// determineMarketSession classifies the quote time against its exchange's
+// calendar, so weekends, holidays and half days are not reported as market hours
+func determineMarketSession(data *models.MarketData, exchange string) string {
+	return calendar.Default().Resolve(exchange, data.Symbol).Session(data.Timestamp).String()
 }
\ No newline at end of file
@@ -2,7 +2,13 @@ package storage
 
 import (
 	"context"
+	crand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
 	"time"
 
 	"tradecaptain/data-collector/internal/models"
@@ -14,21 +20,33 @@ type RedisCache struct {
 }
 
 func NewRedisCache(connectionString string) (*RedisCache, error) {
-	// TODO: Implement Redis connection setup
-	// - Parse connection string and configure options
-	// - Set up connection pooling parameters
-	// - Configure retry policy and timeouts
-	// - Test connection and implement health checks
-	// - Set up proper authentication if required
-	panic("TODO: Implement Redis connection setup")
+	opts, err := redis.ParseURL(connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+
+	opts.PoolSize = 50
+	opts.MinIdleConns = 5
+	opts.DialTimeout = 5 * time.Second
+	opts.ReadTimeout = 3 * time.Second
+	opts.WriteTimeout = 3 * time.Second
+	opts.MaxRetries = 3
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping Redis: %w", err)
+	}
+
+	return &RedisCache{client: client}, nil
 }
 
 func (r *RedisCache) Close() error {
-	// TODO: Implement Redis connection closure
-	// - Close Redis client gracefully
-	// - Wait for pending operations to complete
-	// - Log closure status and any errors
-	panic("TODO: Implement Redis closure")
+	return r.client.Close()
 }
 
 // Market Data Caching
@@ -72,33 +90,169 @@ func (r *RedisCache) GetMultipleCachedMarketData(ctx context.Context, symbols []
 
 // Rate Limiting Cache
 func (r *RedisCache) CheckRateLimit(ctx context.Context, apiProvider string, limit int, window time.Duration) (bool, error) {
-	// TODO: Implement rate limiting using sliding window
-	// - Use Redis sorted sets for sliding window counter
-	// - Check current request count within time window
-	// - Clean up expired entries automatically
-	// - Return remaining capacity and reset time
-	// - Handle different rate limits per API provider
-	panic("TODO: Implement rate limiting check")
+	count, err := slidingWindowCountScript.Run(ctx, r.client,
+		[]string{rateLimitKey(apiProvider)},
+		window.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rate limit for %s: %w", apiProvider, err)
+	}
+	return count < limit, nil
 }
 
 func (r *RedisCache) IncrementRateLimit(ctx context.Context, apiProvider string, window time.Duration) error {
-	// TODO: Increment rate limit counter
-	// - Add current timestamp to sorted set
-	// - Set expiration on the sorted set key
-	// - Use atomic operations to prevent race conditions
-	// - Clean up old entries beyond window
-	panic("TODO: Implement rate limit increment")
+	// The random suffix keeps members unique when two replicas land on the same microsecond
+	err := slidingWindowAddScript.Run(ctx, r.client,
+		[]string{rateLimitKey(apiProvider)},
+		window.Milliseconds(),
+		strconv.FormatInt(rand.Int63(), 36),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to increment rate limit for %s: %w", apiProvider, err)
+	}
+	return nil
 }
 
 func (r *RedisCache) GetRateLimitStatus(ctx context.Context, apiProvider string, limit int, window time.Duration) (int, time.Time, error) {
-	// TODO: Get current rate limit status
-	// - Count current requests within window
-	// - Calculate remaining capacity
-	// - Determine reset time for rate limit
-	// - Return status for API client decision making
-	panic("TODO: Implement rate limit status retrieval")
+	values, err := slidingWindowStatusScript.Run(ctx, r.client,
+		[]string{rateLimitKey(apiProvider)},
+		window.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to get rate limit status for %s: %w", apiProvider, err)
+	}
+
+	// values = {count, reset_at_ms}; reset is when the oldest request leaves the window
+	remaining := limit - int(values[0])
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, time.UnixMilli(values[1]), nil
+}
+
+// TokenBucket is one bucket TakeTokens draws from
+type TokenBucket struct {
+	Name            string
+	Capacity        int
+	RefillPerSecond float64
+}
+
+// TakeTokens atomically refills buckets and draws tokens from every one of
+// them, or from none when any is short, so a request limited by several
+// buckets never spends one bucket's allowance without the others. Buckets are
+// shared by every replica using the same names, and time comes from the
+// Redis server so pods with skewed clocks still agree. remaining is the last
+// bucket's level; retryAfter is how long until every bucket has enough.
+func (r *RedisCache) TakeTokens(ctx context.Context, buckets []TokenBucket, tokens int) (bool, float64, time.Duration, error) {
+	if len(buckets) == 0 {
+		return false, 0, 0, errors.New("no token buckets to take from")
+	}
+
+	keys := make([]string, len(buckets))
+	args := []interface{}{tokens}
+	for i, bucket := range buckets {
+		keys[i] = tokenBucketKey(bucket.Name)
+		args = append(args, bucket.Capacity, strconv.FormatFloat(bucket.RefillPerSecond/1000, 'g', -1, 64))
+	}
+
+	result, err := tokenBucketScript.Run(ctx, r.client, keys, args...).Slice()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to take token from %s: %w", buckets[len(buckets)-1].Name, err)
+	}
+	if len(result) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected token bucket reply for %s: %v", buckets[len(buckets)-1].Name, result)
+	}
+
+	allowed, _ := result[0].(int64)
+	waitMs, _ := result[1].(int64)
+	remainingStr, _ := result[2].(string)
+	remaining, _ := strconv.ParseFloat(remainingStr, 64)
+
+	return allowed == 1, remaining, time.Duration(waitMs) * time.Millisecond, nil
+}
+
+func rateLimitKey(apiProvider string) string {
+	return "ratelimit:window:" + apiProvider
+}
+
+func tokenBucketKey(bucket string) string {
+	return "ratelimit:bucket:" + bucket
 }
 
+// Rate limiting scripts. All of them read the clock from Redis TIME so every
+// collector replica sees the same "now".
+var (
+	tokenBucketScript = redis.NewScript(`
+local requested = tonumber(ARGV[1])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local levels = {}
+local wait = 0
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[2 * i])
+	local rate = tonumber(ARGV[2 * i + 1])
+	local bucket = redis.call('HMGET', key, 'tokens', 'ts')
+	local tokens = tonumber(bucket[1]) or capacity
+	local ts = tonumber(bucket[2]) or now
+
+	tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+	if tokens < requested then
+		wait = math.max(wait, math.ceil((requested - tokens) / rate))
+	end
+	levels[i] = tokens
+end
+
+local allowed = 0
+if wait == 0 then
+	allowed = 1
+end
+
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[2 * i])
+	local rate = tonumber(ARGV[2 * i + 1])
+	if allowed == 1 then
+		levels[i] = levels[i] - requested
+	end
+	redis.call('HSET', key, 'tokens', tostring(levels[i]), 'ts', now)
+	redis.call('PEXPIRE', key, math.ceil(capacity / rate) * 2)
+end
+return {allowed, wait, tostring(levels[#KEYS])}
+`)
+
+	slidingWindowCountScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - tonumber(ARGV[1]))
+return redis.call('ZCARD', KEYS[1])
+`)
+
+	slidingWindowAddScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+redis.call('ZADD', KEYS[1], now, t[1] .. t[2] .. ':' .. ARGV[2])
+redis.call('PEXPIRE', KEYS[1], window)
+return redis.call('ZCARD', KEYS[1])
+`)
+
+	slidingWindowStatusScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local reset = now
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window
+end
+return {count, reset}
+`)
+)
+
 // Session and Authentication Caching
 func (r *RedisCache) CacheUserSession(ctx context.Context, sessionID string, userID int, ttl time.Duration) error {
 	// TODO: Cache user session data
@@ -194,29 +348,72 @@ func (r *RedisCache) FlushCache(ctx context.Context, pattern string) error {
 }
 
 // Distributed Locking
+var (
+	ErrLockNotAcquired = errors.New("lock held by another owner")
+	ErrLockNotHeld     = errors.New("lock not held")
+)
+
+var (
+	releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+	extendLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+func lockKeyName(name string) string {
+	return "lock:" + name
+}
+
+// AcquireLock takes lockKey for ttl and returns the ownership token needed to
+// extend or release it. ErrLockNotAcquired means another owner holds it.
 func (r *RedisCache) AcquireLock(ctx context.Context, lockKey string, ttl time.Duration) (string, error) {
-	// TODO: Implement distributed locking
-	// - Use Redis SET with NX and EX options
-	// - Generate unique lock token for ownership
-	// - Set appropriate lock timeout
-	// - Handle lock acquisition failures
-	panic("TODO: Implement distributed lock acquisition")
+	raw := make([]byte, 16)
+	if _, err := crand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	ok, err := r.client.SetNX(ctx, lockKeyName(lockKey), token, ttl).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire lock %s: %w", lockKey, err)
+	}
+	if !ok {
+		return "", ErrLockNotAcquired
+	}
+	return token, nil
 }
 
+// ReleaseLock deletes lockKey if token still owns it; a lock that already
+// expired or changed hands is left alone
 func (r *RedisCache) ReleaseLock(ctx context.Context, lockKey, token string) error {
-	// TODO: Release distributed lock safely
-	// - Verify lock ownership with token
-	// - Use Lua script for atomic lock release
-	// - Handle lock expiration scenarios
-	// - Log lock operations for debugging
-	panic("TODO: Implement distributed lock release")
+	released, err := releaseLockScript.Run(ctx, r.client, []string{lockKeyName(lockKey)}, token).Int()
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", lockKey, err)
+	}
+	if released == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
 }
 
+// ExtendLock resets the TTL of lockKey if token still owns it. ErrLockNotHeld
+// means ownership was lost and the holder must stop its work.
 func (r *RedisCache) ExtendLock(ctx context.Context, lockKey, token string, ttl time.Duration) error {
-	// TODO: Extend lock expiration time
-	// - Verify lock ownership before extension
-	// - Update lock TTL atomically
-	// - Handle extension failures gracefully
-	// - Prevent lock extension abuse
-	panic("TODO: Implement lock extension")
+	extended, err := extendLockScript.Run(ctx, r.client, []string{lockKeyName(lockKey)}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to extend lock %s: %w", lockKey, err)
+	}
+	if extended == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"tradecaptain/data-collector/internal/collector"
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/models"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+const loadgenUsage = `usage: data-collector loadgen [-symbols 1000] [-interval 1s] [-duration 1m] [-batch 1000]
+                             [-sinks wal,questdb,kafka] [-wal ./data/loadgen-wal]
+
+Generates a quote for every one of -symbols synthetic symbols (SYN00000, ...)
+each -interval, with the SYNTHETIC_* settings, and writes every round to the
+sinks concurrently: wal is BadgerWAL.BatchWrite into a scratch directory,
+questdb is QuestDBClient.BatchInsertMarketData against QUESTDB_URL, and kafka
+is the market data topic the gateway fans out over WebSocket. Throughput and
+batch latency per sink are logged every 10 seconds and at the end.
+`
+
+// loadReportInterval is how often loadgen logs sink statistics
+const loadReportInterval = 10 * time.Second
+
+// runLoadgen implements the loadgen subcommand
+func runLoadgen(args []string) error {
+	flags := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), loadgenUsage)
+		flags.PrintDefaults()
+	}
+	symbolCount := flags.Int("symbols", 1000, "number of synthetic symbols")
+	interval := flags.Duration("interval", time.Second, "time between rounds, 0 for back to back")
+	duration := flags.Duration("duration", time.Minute, "how long to run, 0 until interrupted")
+	batchSize := flags.Int("batch", 1000, "quotes per sink write")
+	sinkList := flags.String("sinks", "wal", "comma separated sinks: wal, questdb, kafka")
+	walPath := flags.String("wal", "./data/loadgen-wal", "scratch WAL directory for the wal sink")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *symbolCount < 1 || *batchSize < 1 {
+		return errors.New("-symbols and -batch must be at least 1")
+	}
+
+	cfg, err := config.LoadFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var sinks []*loadSink
+	for _, name := range strings.Split(*sinkList, ",") {
+		switch strings.TrimSpace(name) {
+		case "wal":
+			wal, err := storage.NewBadgerWAL(*walPath, cfg.KafkaBootstrapServers)
+			if err != nil {
+				return fmt.Errorf("failed to open WAL: %w", err)
+			}
+			defer wal.Close()
+			sinks = append(sinks, &loadSink{name: "wal", write: func(ctx context.Context, batch []*models.MarketData) error {
+				return wal.BatchWrite(batch)
+			}})
+		case "questdb":
+			if cfg.QuestDBURL == "" {
+				return errors.New("the questdb sink needs QUESTDB_URL")
+			}
+			questDB, err := storage.NewQuestDBClient(cfg.QuestDBURL)
+			if err != nil {
+				return err
+			}
+			defer questDB.Close()
+			sinks = append(sinks, &loadSink{name: "questdb", write: func(ctx context.Context, batch []*models.MarketData) error {
+				return questDB.BatchInsertMarketData(batch)
+			}})
+		case "kafka":
+			producer, err := storage.NewKafkaProducer(cfg.KafkaBootstrapServers)
+			if err != nil {
+				return fmt.Errorf("failed to initialize Kafka producer: %w", err)
+			}
+			defer producer.Close()
+			sinks = append(sinks, &loadSink{name: "kafka", write: producer.PublishMarketDataBatch})
+		default:
+			return fmt.Errorf("unknown sink %q: use wal, questdb or kafka", name)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	provider := collector.NewSyntheticProvider(collector.SyntheticOptionsFrom(cfg))
+	symbols := collector.SyntheticSymbols(*symbolCount)
+	log.Printf("Generating %d symbols every %s into %s", len(symbols), *interval, *sinkList)
+
+	started := time.Now()
+	lastReport := started
+	rounds := 0
+	for ctx.Err() == nil {
+		roundStart := time.Now()
+		quotes, err := provider.GetMultipleQuotes(ctx, symbols)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, sink := range sinks {
+			wg.Add(1)
+			go func(sink *loadSink) {
+				defer wg.Done()
+				sink.writeAll(ctx, quotes, *batchSize)
+			}(sink)
+		}
+		wg.Wait()
+		rounds++
+
+		if time.Since(lastReport) >= loadReportInterval {
+			lastReport = time.Now()
+			for _, sink := range sinks {
+				sink.report()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(*interval - time.Since(roundStart)):
+		}
+	}
+
+	log.Printf("Load run finished: %d rounds of %d quotes in %s", rounds, len(symbols), time.Since(started).Round(time.Second))
+	for _, sink := range sinks {
+		sink.report()
+	}
+	return nil
+}
+
+// loadSink is one storage layer under load, with its running statistics
+type loadSink struct {
+	name  string
+	write func(ctx context.Context, batch []*models.MarketData) error
+
+	records int
+	batches int
+	errors  int
+	busy    time.Duration // total time spent in write
+	slowest time.Duration
+}
+
+// writeAll writes quotes in batches of batchSize, logging only the first error
+func (s *loadSink) writeAll(ctx context.Context, quotes []*models.MarketData, batchSize int) {
+	for start := 0; start < len(quotes); start += batchSize {
+		end := start + batchSize
+		if end > len(quotes) {
+			end = len(quotes)
+		}
+
+		began := time.Now()
+		err := s.write(ctx, quotes[start:end])
+		took := time.Since(began)
+
+		s.batches++
+		s.busy += took
+		if took > s.slowest {
+			s.slowest = took
+		}
+		if err != nil {
+			s.errors++
+			if s.errors == 1 {
+				log.Printf("%s write failed: %v", s.name, err)
+			}
+			continue
+		}
+		s.records += end - start
+	}
+}
+
+func (s *loadSink) report() {
+	if s.batches == 0 {
+		return
+	}
+	rate := float64(s.records) / s.busy.Seconds()
+	log.Printf("%-7s %d quotes in %d batches: %.0f quotes/s, mean batch %s, slowest %s, %d failed batches",
+		s.name, s.records, s.batches, rate, (s.busy / time.Duration(s.batches)).Round(time.Microsecond),
+		s.slowest.Round(time.Microsecond), s.errors)
+}
@@ -2,15 +2,20 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 	"time"
 
+	"tradecaptain/data-collector/internal/admin"
 	"tradecaptain/data-collector/internal/collector"
 	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/corpactions"
+	"tradecaptain/data-collector/internal/instruments"
 	"tradecaptain/data-collector/internal/storage"
 	"tradecaptain/data-collector/internal/cache"
 
@@ -23,8 +28,51 @@ func main() {
 		log.Printf("Warning: .env file not found: %v", err)
 	}
 
-	// Initialize configuration
-	cfg := config.Load()
+	// One-off subcommands run instead of the collector
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := runBackfill(os.Args[2:]); err != nil && err != flag.ErrHelp {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "wal-export" {
+		if err := runWALExport(os.Args[2:]); err != nil && err != flag.ErrHelp {
+			log.Fatalf("WAL export failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-instruments" {
+		if err := runImportInstruments(os.Args[2:]); err != nil && err != flag.ErrHelp {
+			log.Fatalf("Instrument import failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "loadgen" {
+		if err := runLoadgen(os.Args[2:]); err != nil && err != flag.ErrHelp {
+			log.Fatalf("Load generation failed: %v", err)
+		}
+		return
+	}
+
+	// Initialize configuration: environment, overlaid by CONFIG_FILE when set
+	configPath := os.Getenv("CONFIG_FILE")
+	cfg, err := config.LoadFile(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// Provider clients record or replay their HTTP traffic when configured
+	if cfg.HTTPFixtureMode != "" {
+		transport, err := collector.NewFixtureTransport(cfg.HTTPFixtureMode, cfg.HTTPFixtureDir)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		collector.UseHTTPTransport(transport)
+		log.Printf("Provider HTTP fixtures: %s in %s", cfg.HTTPFixtureMode, cfg.HTTPFixtureDir)
+	}
 
 	// Initialize storage
 	db, err := storage.NewPostgresDB(cfg.DatabaseURL)
@@ -58,6 +106,13 @@ func main() {
 	}
 	defer wal.Close()
 
+	// Failed collections are persisted here so they survive restarts
+	retryQueue, err := collector.NewRetryQueue(cfg.RetryQueuePath, collector.RetryQueueConfigFrom(cfg))
+	if err != nil {
+		log.Fatalf("Failed to open retry queue: %v", err)
+	}
+	defer retryQueue.Close()
+
 	// Initialize Kafka producer
 	producer, err := storage.NewKafkaProducer(cfg.KafkaBootstrapServers)
 	if err != nil {
@@ -66,7 +121,33 @@ func main() {
 	defer producer.Close()
 
 	// Initialize data collector with optimized storage layers
-	dataCollector := collector.NewWithOptimizations(db, l1Cache, redisCache, wal, producer, cfg)
+	dataCollector := collector.NewWithOptimizations(db, l1Cache, redisCache, wal, retryQueue, producer, cfg)
+
+	// Symbols resolve through the instrument master to their primary listing
+	if err := db.EnsureInstruments(context.Background()); err != nil {
+		log.Fatalf("%v", err)
+	}
+	instrumentMaster := instruments.NewMaster(db)
+	if err := instrumentMaster.Load(context.Background()); err != nil {
+		log.Fatalf("Failed to load instrument master: %v", err)
+	}
+	log.Printf("Instrument master: %d instruments", instrumentMaster.Len())
+	dataCollector.UseInstruments(instrumentMaster)
+
+	// Splits and dividends, for adjusting stored price history
+	if err := db.EnsureCorporateActions(context.Background()); err != nil {
+		log.Fatalf("%v", err)
+	}
+	corporateActions := corpactions.NewBook(db)
+
+	// Offline runs play a recording back in place of the quote providers
+	if cfg.ReplayFile != "" {
+		replay, err := collector.NewReplayProviderFromConfig(cfg)
+		if err != nil {
+			log.Fatalf("Failed to load replay: %v", err)
+		}
+		dataCollector.UseReplay(replay)
+	}
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -81,6 +162,18 @@ func main() {
 		dataCollector.StartMarketDataCollection(ctx)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartStreamCollection(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartCryptoCollection(ctx)
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -93,6 +186,78 @@ func main() {
 		dataCollector.StartEconomicDataCollection(ctx)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartTreasuryCollection(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartFilingsCollection(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartCorporateActionsCollection(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartOptionsCollection(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartRetryProcessing(ctx)
+	}()
+
+	// Pick up instrument edits made through other replicas
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		instrumentMaster.Refresh(ctx, 10*time.Minute)
+	}()
+
+	// Scan for and fill gaps in stored series (one replica at a time)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dataCollector.StartGapDetection(ctx)
+	}()
+
+	// Reload the config file on SIGHUP or when it changes
+	if configPath != "" {
+		watcher := config.NewWatcher(configPath, 5*time.Second, func(newConfig *config.Config) error {
+			return dataCollector.UpdateCollectionConfig(ctx, newConfig)
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			watcher.Run(ctx)
+		}()
+	}
+
+	// Admin control-plane API
+	if cfg.AdminToken != "" {
+		adminServer := admin.NewServer(cfg.AdminAddr, cfg.AdminToken, dataCollector)
+		adminServer.UseInstruments(instrumentMaster)
+		adminServer.UseCorporateActions(corporateActions)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := adminServer.Run(ctx); err != nil && err != http.ErrServerClosed {
+				log.Printf("Admin API error: %v", err)
+			}
+		}()
+	} else {
+		log.Println("ADMIN_TOKEN not set, admin API disabled")
+	}
+
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"tradecaptain/data-collector/internal/config"
+	"tradecaptain/data-collector/internal/storage"
+)
+
+const walExportUsage = `usage: data-collector wal-export -out recording.wal [-from TIME] [-to TIME] [-wal ./data/wal]
+
+Copies the quotes logged to the local WAL between -from and -to (RFC 3339 or
+YYYY-MM-DD, default everything) to a file that REPLAY_FILE can play back.
+The WAL can only be opened by one process: stop the collector first.
+`
+
+// runWALExport implements the wal-export subcommand
+func runWALExport(args []string) error {
+	flags := flag.NewFlagSet("wal-export", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), walExportUsage)
+		flags.PrintDefaults()
+	}
+	outPath := flags.String("out", "", "export file to write")
+	fromTime := flags.String("from", "", "first logged time to export")
+	toTime := flags.String("to", "", "last logged time to export, defaults to now")
+	walPath := flags.String("wal", os.Getenv("WAL_PATH"), "WAL directory, defaults to WAL_PATH or ./data/wal")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		return errors.New("-out is required")
+	}
+	from := time.Unix(0, 0)
+	if *fromTime != "" {
+		var err error
+		if from, err = parseExportTime(*fromTime); err != nil {
+			return fmt.Errorf("-from: %w", err)
+		}
+	}
+	to := time.Now()
+	if *toTime != "" {
+		var err error
+		if to, err = parseExportTime(*toTime); err != nil {
+			return fmt.Errorf("-to: %w", err)
+		}
+	}
+	if *walPath == "" {
+		*walPath = "./data/wal"
+	}
+
+	cfg, err := config.LoadFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	wal, err := storage.NewBadgerWAL(*walPath, cfg.KafkaBootstrapServers)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL: %w", err)
+	}
+	defer wal.Close()
+
+	out, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	written, err := wal.Export(out, from, to)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export WAL: %w", err)
+	}
+
+	log.Printf("Exported %d WAL entries to %s", written, *outPath)
+	return nil
+}
+
+func parseExportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC 3339 nor YYYY-MM-DD", value)
+	}
+	return t, nil
+}